@@ -340,3 +340,16 @@ nicknames:
   - second
   - third
 `)
+
+var unionDataText = []byte(`
+steps:
+  - kind: run
+    command: make
+    args: [build, test]
+  - kind: copy
+    from: bin
+    to: dist
+  - command: tar
+    args: [-czf, dist.tar.gz, dist]
+    kind: run
+`)
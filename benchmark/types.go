@@ -231,3 +231,28 @@ type KeyToPath struct {
 	Path string `yaml:"path"`
 	Mode int    `yaml:"mode,omitempty"`
 }
+
+type UnionStruct = Pipeline
+
+type Pipeline struct {
+	Steps []Step `yaml:"steps"`
+}
+
+//yamly:union kind=run:*RunStep,copy:CopyStep
+type Step interface {
+	step()
+}
+
+type RunStep struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
+}
+
+func (*RunStep) step() {}
+
+type CopyStep struct {
+	From string `yaml:"from"`
+	To   string `yaml:"to"`
+}
+
+func (CopyStep) step() {}
@@ -29,3 +29,14 @@ func BenchmarkYamly_YAYAMLS_Engine_Unmarshal_Small(b *testing.B) {
 		}
 	}
 }
+
+func BenchmarkYamly_YAYAMLS_Engine_Unmarshal_Union(b *testing.B) {
+	b.SetBytes(int64(len(unionDataText)))
+	for i := 0; i < b.N; i++ {
+		var s UnionStruct
+		err := s.UnmarshalYAML(unionDataText)
+		if err != nil {
+			b.Error(err)
+		}
+	}
+}
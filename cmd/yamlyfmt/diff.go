@@ -0,0 +1,156 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+)
+
+const diffContextLines = 3
+
+type diffOpKind int8
+
+const (
+	diffOpEqual diffOpKind = iota
+	diffOpDelete
+	diffOpInsert
+)
+
+type diffOp struct {
+	kind diffOpKind
+	line string
+}
+
+// writeDiff writes a unified diff between old and new sources into w.
+func writeDiff(w io.Writer, oldName, newName string, oldSrc, newSrc []byte) {
+	ops := diffLines(splitLines(oldSrc), splitLines(newSrc))
+
+	fmt.Fprintf(w, "--- %s\n+++ %s\n", oldName, newName)
+
+	// oldLine and newLine are 1-based numbers of the lines the current operation refers to.
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == diffOpEqual {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		start := i - diffContextLines
+		if start < 0 {
+			start = 0
+		}
+		hunkOldStart, hunkNewStart := oldLine-(i-start), newLine-(i-start)
+
+		// extend the hunk while changes are separated by less than two contexts
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != diffOpEqual {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == diffOpEqual {
+				next++
+			}
+			if next == len(ops) || next-end > 2*diffContextLines {
+				end += min(next-end, diffContextLines)
+				break
+			}
+			end = next
+		}
+
+		var oldCount, newCount int
+		for _, op := range ops[start:end] {
+			if op.kind != diffOpInsert {
+				oldCount++
+			}
+			if op.kind != diffOpDelete {
+				newCount++
+			}
+		}
+
+		fmt.Fprintf(w, "@@ -%d,%d +%d,%d @@\n", hunkOldStart, oldCount, hunkNewStart, newCount)
+		for _, op := range ops[start:end] {
+			switch op.kind {
+			case diffOpEqual:
+				fmt.Fprint(w, " ", op.line)
+			case diffOpDelete:
+				fmt.Fprint(w, "-", op.line)
+			case diffOpInsert:
+				fmt.Fprint(w, "+", op.line)
+			}
+			if len(op.line) == 0 || op.line[len(op.line)-1] != '\n' {
+				fmt.Fprint(w, "\n\\ No newline at end of file\n")
+			}
+		}
+
+		for _, op := range ops[i:end] {
+			if op.kind != diffOpInsert {
+				oldLine++
+			}
+			if op.kind != diffOpDelete {
+				newLine++
+			}
+		}
+		i = end
+	}
+}
+
+func splitLines(src []byte) []string {
+	var lines []string
+	for len(src) > 0 {
+		idx := bytes.IndexByte(src, '\n')
+		if idx < 0 {
+			lines = append(lines, string(src))
+			break
+		}
+		lines = append(lines, string(src[:idx+1]))
+		src = src[idx+1:]
+	}
+	return lines
+}
+
+// diffLines computes the shortest edit script transforming old lines into new ones
+// using the longest common subsequence table.
+func diffLines(oldLines, newLines []string) []diffOp {
+	n, m := len(oldLines), len(newLines)
+	lcs := make([][]int, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if oldLines[i] == newLines[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]diffOp, 0, max(n, m))
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case oldLines[i] == newLines[j]:
+			ops = append(ops, diffOp{kind: diffOpEqual, line: oldLines[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, diffOp{kind: diffOpDelete, line: oldLines[i]})
+			i++
+		default:
+			ops = append(ops, diffOp{kind: diffOpInsert, line: newLines[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, diffOp{kind: diffOpDelete, line: oldLines[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, diffOp{kind: diffOpInsert, line: newLines[j]})
+	}
+	return ops
+}
@@ -0,0 +1,11 @@
+module github.com/KSpaceer/yamly/cmd/yamlyfmt
+
+go 1.21.1
+
+require github.com/KSpaceer/yamly/engines/yayamls v0.1.1
+
+require github.com/KSpaceer/yamly v0.1.1 // indirect
+
+replace github.com/KSpaceer/yamly => ../..
+
+replace github.com/KSpaceer/yamly/engines/yayamls => ../../engines/yayamls
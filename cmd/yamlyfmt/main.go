@@ -0,0 +1,215 @@
+// Command yamlyfmt formats YAML files using yayamls engine.
+//
+// Without an explicit path, it processes the standard input. Given a file,
+// it operates on that file; given a directory, it operates on all .yaml and .yml
+// files in that directory, recursively.
+package main
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astcmp"
+	"github.com/KSpaceer/yamly/engines/yayamls/encode"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+)
+
+var (
+	list        = flag.Bool("l", false, "list files whose formatting differs from yamlyfmt's")
+	write       = flag.Bool("w", false, "write result to (source) file instead of stdout")
+	doDiff      = flag.Bool("d", false, "display diffs instead of rewriting files")
+	indentation = flag.Int("indent", 2, "amount of spaces used for every nesting level")
+	lineWidth   = flag.Int("width", 0, "preferred line width for quoted strings (0 disables folding)")
+)
+
+func main() {
+	flag.Usage = Usage
+	flag.Parse()
+
+	if *indentation <= 0 {
+		fmt.Fprintln(os.Stderr, "indentation must be positive")
+		os.Exit(2)
+	}
+
+	args := flag.Args()
+	if len(args) == 0 {
+		if *write {
+			fmt.Fprintln(os.Stderr, "cannot use -w with standard input")
+			os.Exit(2)
+		}
+		if err := processFile("<standard input>", os.Stdin, os.Stdout); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	var failed bool
+	for _, arg := range args {
+		if err := walk(arg); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+func Usage() {
+	fmt.Fprintf(os.Stderr, "Usage of %s:\n", os.Args[0])
+	fmt.Fprintf(os.Stderr, "\tyamlyfmt [flags] [path ...]\n")
+	fmt.Fprintf(os.Stderr, "Flags:\n")
+	flag.PrintDefaults()
+}
+
+func walk(root string) error {
+	var errs []error
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || (path != root && !isYAMLFile(d.Name())) {
+			return nil
+		}
+		if err := processPath(path); err != nil {
+			errs = append(errs, err)
+		}
+		return nil
+	})
+	if err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+func isYAMLFile(name string) bool {
+	ext := filepath.Ext(name)
+	return !strings.HasPrefix(name, ".") && (ext == ".yaml" || ext == ".yml")
+}
+
+func processPath(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return processFile(path, f, os.Stdout)
+}
+
+func processFile(filename string, in io.Reader, out io.Writer) error {
+	src, err := io.ReadAll(in)
+	if err != nil {
+		return err
+	}
+
+	res, err := format(src)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if bytes.Equal(src, res) {
+		if !*list && !*write && !*doDiff {
+			_, err = out.Write(res)
+		}
+		return err
+	}
+
+	if *list {
+		fmt.Fprintln(out, filename)
+	}
+	if *write {
+		info, err := os.Stat(filename)
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(filename, res, info.Mode().Perm()); err != nil {
+			return err
+		}
+	}
+	if *doDiff {
+		fmt.Fprintf(out, "diff %s.orig %s\n", filename, filename)
+		writeDiff(out, filename+".orig", filename, src, res)
+	}
+	if !*list && !*write && !*doDiff {
+		_, err = out.Write(res)
+	}
+	return err
+}
+
+// format parses given source, serializes the resulting AST and checks
+// that the serialized source represents the same AST with the same tags and styles of scalars.
+// Comments are attached to the nodes of AST by their positions and written along with them.
+func format(src []byte) ([]byte, error) {
+	tree, comments, err := parse(src)
+	if err != nil {
+		return nil, err
+	}
+
+	w := encode.NewASTWriter(
+		encode.WithIndentation(*indentation),
+		encode.WithLineWidth(*lineWidth),
+		encode.WithComments(comments),
+	)
+	res, err := w.WriteBytes(tree)
+	if err != nil {
+		return nil, err
+	}
+	if len(res) > 0 && res[len(res)-1] != '\n' {
+		// scalar and flow collection documents are written without line break
+		res = append(res, '\n')
+	}
+
+	reparsed, reparsedComments, err := parse(res)
+	if err != nil {
+		return nil, fmt.Errorf("formatted source is not valid YAML: %w", err)
+	}
+	if !astcmp.NewComparator(astcmp.WithStyles()).Equal(tree, reparsed) {
+		return nil, errors.New("formatted source does not match the original one")
+	}
+	if !slices.Equal(commentTexts(comments), commentTexts(reparsedComments)) {
+		return nil, errors.New("formatted source does not keep the comments of the original one")
+	}
+	return res, nil
+}
+
+// parse parses the source and attaches comments to the nodes of the resulting AST.
+func parse(src []byte) (ast.Node, ast.CommentMap, error) {
+	var (
+		comments  []ast.Comment
+		positions = make(ast.Positions)
+	)
+	tree, err := parser.ParseBytes(
+		src,
+		parser.WithOmitStream(),
+		parser.WithComments(&comments),
+		parser.WithPositions(positions),
+	)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tree, ast.NewCommentMap(tree, positions, comments), nil
+}
+
+// commentTexts returns sorted texts of all comments in the map.
+func commentTexts(cm ast.CommentMap) []string {
+	var texts []string
+	for _, comments := range cm {
+		texts = append(texts, comments.Head...)
+		texts = append(texts, comments.Foot...)
+		if comments.Line != "" {
+			texts = append(texts, comments.Line)
+		}
+	}
+	slices.Sort(texts)
+	return texts
+}
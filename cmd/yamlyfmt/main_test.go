@@ -0,0 +1,263 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astcmp"
+)
+
+var update = flag.Bool("update", false, "update golden files")
+
+// setFlags sets command line flags for the test and restores them after it.
+// Flags are global, so tests using them can't be run in parallel.
+func setFlags(t *testing.T, l, w, d bool, indent, width int) {
+	t.Helper()
+	oldL, oldW, oldD, oldIndent, oldWidth := *list, *write, *doDiff, *indentation, *lineWidth
+	*list, *write, *doDiff, *indentation, *lineWidth = l, w, d, indent, width
+	t.Cleanup(func() {
+		*list, *write, *doDiff, *indentation, *lineWidth = oldL, oldW, oldD, oldIndent, oldWidth
+	})
+}
+
+func checkGolden(t *testing.T, golden string, got []byte) {
+	t.Helper()
+	path := filepath.Join("testdata", golden)
+	if *update {
+		if err := os.WriteFile(path, got, 0o644); err != nil {
+			t.Fatalf("failed to update golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file: %v", err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("result does not match %s:\ngot:\n%s\nwant:\n%s", golden, got, want)
+	}
+}
+
+func TestFormat(t *testing.T) {
+	type tcase struct {
+		name   string
+		input  string
+		golden string
+		indent int
+		width  int
+	}
+
+	tcases := []tcase{
+		{
+			name:   "comments",
+			input:  "comments.input",
+			golden: "comments.golden",
+			indent: 2,
+		},
+		{
+			name:   "comments with indentation 4",
+			input:  "comments.input",
+			golden: "comments_indent4.golden",
+			indent: 4,
+		},
+		{
+			name:   "comments with indentation 1",
+			input:  "comments.input",
+			golden: "comments_indent1.golden",
+			indent: 1,
+		},
+		{
+			name:   "line width",
+			input:  "width.input",
+			golden: "width.golden",
+			indent: 2,
+			width:  30,
+		},
+		{
+			name:   "stream",
+			input:  "stream.input",
+			golden: "stream.golden",
+			indent: 2,
+		},
+		{
+			name:   "formatted",
+			input:  "formatted.input",
+			golden: "formatted.input",
+			indent: 2,
+		},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			setFlags(t, false, false, false, tc.indent, tc.width)
+
+			src, err := os.ReadFile(filepath.Join("testdata", tc.input))
+			if err != nil {
+				t.Fatalf("failed to read input: %v", err)
+			}
+			var out bytes.Buffer
+			if err := processFile(tc.input, bytes.NewReader(src), &out); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			checkGolden(t, tc.golden, out.Bytes())
+
+			// formatting is idempotent
+			res, err := format(out.Bytes())
+			if err != nil {
+				t.Fatalf("unexpected error while formatting result: %v", err)
+			}
+			if !bytes.Equal(res, out.Bytes()) {
+				t.Errorf("formatting is not idempotent:\nfirst:\n%s\nsecond:\n%s", out.Bytes(), res)
+			}
+
+			// formatted source represents the same document with the same comments
+			tree, comments, err := parse(src)
+			if err != nil {
+				t.Fatalf("failed to parse input: %v", err)
+			}
+			formattedTree, formattedComments, err := parse(out.Bytes())
+			if err != nil {
+				t.Fatalf("failed to parse result: %v", err)
+			}
+			if !astcmp.NewComparator().Equal(tree, formattedTree) {
+				t.Errorf("formatted source does not match the original one")
+			}
+			if got, want := commentTexts(formattedComments), commentTexts(comments); !slices.Equal(got, want) {
+				t.Errorf("comments mismatch: got %q, want %q", got, want)
+			}
+		})
+	}
+}
+
+func TestFormat_KeepsStyles(t *testing.T) {
+	setFlags(t, false, false, false, 2, 0)
+
+	tcases := []struct {
+		name string
+		src  string
+	}{
+		{name: "empty value", src: "a:\n"},
+		{name: "escaped text", src: "a: \"\\t\"\n"},
+		{name: "local tag", src: "a: !custom x\n"},
+		{name: "verbatim tag", src: "a: !<tag:x> x\n"},
+		{name: "literal block scalar", src: "a: |\n  x\n"},
+		{name: "folded block scalar", src: "a: >\n  x\n"},
+		{name: "flow collections", src: "a: [x, {y: z}]\n"},
+		{name: "flow document", src: "[x, y]\n"},
+		{name: "scalar document", src: "x\n"},
+	}
+
+	for _, tc := range tcases {
+		t.Run(tc.name, func(t *testing.T) {
+			res, err := format([]byte(tc.src))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if string(res) != tc.src {
+				t.Errorf("expected %q, but got %q", tc.src, res)
+			}
+		})
+	}
+}
+
+func TestList(t *testing.T) {
+	setFlags(t, true, false, false, 2, 0)
+
+	for _, name := range []string{"comments.input", "formatted.input"} {
+		src, err := os.ReadFile(filepath.Join("testdata", name))
+		if err != nil {
+			t.Fatalf("failed to read input: %v", err)
+		}
+		var out bytes.Buffer
+		if err := processFile(name, bytes.NewReader(src), &out); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		want := ""
+		if name == "comments.input" {
+			want = name + "\n"
+		}
+		if out.String() != want {
+			t.Errorf("unexpected output for %s: got %q, want %q", name, out.String(), want)
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	setFlags(t, false, false, true, 2, 0)
+
+	src, err := os.ReadFile(filepath.Join("testdata", "stream.input"))
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+	var out bytes.Buffer
+	if err := processFile("stream.input", bytes.NewReader(src), &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	checkGolden(t, "stream.diff.golden", out.Bytes())
+}
+
+func TestWrite(t *testing.T) {
+	setFlags(t, false, true, false, 4, 0)
+
+	src, err := os.ReadFile(filepath.Join("testdata", "comments.input"))
+	if err != nil {
+		t.Fatalf("failed to read input: %v", err)
+	}
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(path, src, 0o600); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+	// files without YAML extension are skipped while walking directories
+	skipped := filepath.Join(dir, "notes.txt")
+	if err := os.WriteFile(skipped, src, 0o600); err != nil {
+		t.Fatalf("failed to write input: %v", err)
+	}
+
+	if err := walk(dir); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read result: %v", err)
+	}
+	checkGolden(t, "comments_indent4.golden", got)
+
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("failed to stat result: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0o600 {
+		t.Errorf("file permissions are changed: got %o", perm)
+	}
+
+	untouched, err := os.ReadFile(skipped)
+	if err != nil {
+		t.Fatalf("failed to read skipped file: %v", err)
+	}
+	if !bytes.Equal(untouched, src) {
+		t.Errorf("file without YAML extension is changed")
+	}
+}
+
+func TestFormat_InvalidSource(t *testing.T) {
+	setFlags(t, false, false, false, 2, 0)
+
+	var out bytes.Buffer
+	err := processFile("invalid.yaml", strings.NewReader("key: [unclosed\n"), &out)
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+	if !strings.HasPrefix(err.Error(), "invalid.yaml: ") {
+		t.Errorf("error does not name the file: %v", err)
+	}
+	if out.Len() > 0 {
+		t.Errorf("unexpected output: %q", out.String())
+	}
+}
@@ -21,9 +21,31 @@ var (
 	encodePointerReceiver = flag.Bool("encode-pointer-receiver", false, "use pointer receiver in encode methods")
 	engine                = flag.String("engine", "goyaml", "used parser engine for generated code")
 	inlineEmbedded        = flag.Bool("inline-embedded", false, "inline embedded fields into YAML mapping")
+	fieldNaming           = flag.String("field-naming", "as-is", "key naming: camel, snake, kebab, lower or as-is")
+	caseInsensitiveKeys   = flag.Bool("case-insensitive-keys", false, "match keys regardless of letter case")
+	ignoreKeySeparators   = flag.Bool("ignore-key-separators", false, "match keys ignoring '_' and '-' separators")
+	durationUnit          = flag.String("duration-unit", "ns", "unit of bare integers decoded into time.Duration")
+	timeFormat            = flag.String("time-format", "", "default layout of time.Time values (e.g. 2006-01-02)")
+	timeNanoseconds       = flag.Bool("time-nanoseconds", true, "keep nanoseconds of time.Time values")
+	codecs                stringsFlag
+	reflectFallback       = flag.Bool("reflect-fallback", false,
+		"use reflection for structs from other packages and types unsupported by generated code")
 )
 
+// stringsFlag is a flag which can be given several times.
+type stringsFlag []string
+
+func (f *stringsFlag) String() string {
+	return strings.Join(*f, " ")
+}
+
+func (f *stringsFlag) Set(value string) error {
+	*f = append(*f, value)
+	return nil
+}
+
 func main() {
+	flag.Var(&codecs, "codec", "register codec functions for type as pkg.Type=pkg.DecodeFn,pkg.EncodeFn (repeatable)")
 	flag.Parse()
 
 	args := flag.Args()
@@ -128,6 +150,15 @@ func generate(path string) error {
 		DisallowUnknownFields:  *disallowUnknownFields,
 		EncodePointerReceiver:  *encodePointerReceiver,
 		InlineEmbedded:         *inlineEmbedded,
+		ReflectFallback:        *reflectFallback,
+		FieldNaming:            *fieldNaming,
+		CaseInsensitiveKeys:    *caseInsensitiveKeys,
+		IgnoreKeySeparators:    *ignoreKeySeparators,
+		DurationUnit:           *durationUnit,
+		TimeFormat:             *timeFormat,
+		TimeNanoseconds:        *timeNanoseconds,
+		Codecs:                 codecs,
+		Unions:                 p.Unions,
 		OutputName:             outputName,
 		BuildTags:              trimmedBuildTags,
 		EngineGeneratorPackage: engineGeneratorPackage,
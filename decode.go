@@ -2,6 +2,7 @@ package yamly
 
 import (
 	"errors"
+	"strconv"
 	"time"
 )
 
@@ -90,6 +91,20 @@ type Decoder interface {
 	AddError(err error)
 }
 
+// WarningsDecoder is implemented by decoders which record non-fatal issues (e.g. DeprecatedKeyWarning).
+// Warnings do not stop decoding and are not returned by Error. Generated code reports warnings only
+// if the decoder implements this interface. Generated UnmarshalYAML methods drop the warnings,
+// because their signatures are defined by engines; use Unmarshal with WithWarnings option to get them.
+type WarningsDecoder interface {
+	Decoder
+
+	// AddWarning allows to add non-fatal issue to Decoder.
+	AddWarning(w error)
+
+	// Warnings returns stored warnings.
+	Warnings() []error
+}
+
 // ExtendedDecoder is used to extend Decoder interface with engine-specific
 // methods
 type ExtendedDecoder[T any] interface {
@@ -131,3 +146,73 @@ func (ufe *UnknownFieldError) Is(err error) bool {
 	_, ok := err.(*UnknownFieldError)
 	return ok
 }
+
+// DeprecatedKeyWarning is used by generated struct decoders to indicate
+// that field was set using deprecated alias of its key.
+type DeprecatedKeyWarning struct {
+	// Key is the actual key of the field.
+	Key string
+	// Alias is the deprecated key found in YAML document.
+	Alias string
+}
+
+func (dkw *DeprecatedKeyWarning) Error() string {
+	return "key " + strconv.Quote(dkw.Alias) + " is deprecated, use " + strconv.Quote(dkw.Key) + " instead"
+}
+
+func (dkw *DeprecatedKeyWarning) Is(err error) bool {
+	_, ok := err.(*DeprecatedKeyWarning)
+	return ok
+}
+
+// KeyConflictError is used by generated struct decoders to indicate that field
+// was set several times in one mapping using different keys (e.g. its key and deprecated alias).
+type KeyConflictError struct {
+	// Key is the actual key of the field.
+	Key string
+	// First and Second are the conflicting keys found in YAML document.
+	First, Second string
+}
+
+func (kce *KeyConflictError) Error() string {
+	return "conflicting keys " + strconv.Quote(kce.First) + " and " + strconv.Quote(kce.Second) +
+		" for field " + strconv.Quote(kce.Key)
+}
+
+func (kce *KeyConflictError) Is(err error) bool {
+	_, ok := err.(*KeyConflictError)
+	return ok
+}
+
+// KeyPosition describes an occurrence of mapping key.
+type KeyPosition struct {
+	// Entry is the one-based number of the entry in the mapping.
+	Entry int
+	// Line and Column are the one-based position of the key in YAML document.
+	// They are zero if engine does not track positions.
+	Line, Column int
+}
+
+func (kp KeyPosition) String() string {
+	s := "entry " + strconv.Itoa(kp.Entry)
+	if kp.Line > 0 {
+		s += " (line " + strconv.Itoa(kp.Line) + ", column " + strconv.Itoa(kp.Column) + ")"
+	}
+	return s
+}
+
+// DuplicateKeyError is used to indicate that the same key appears several times in one mapping.
+type DuplicateKeyError struct {
+	Key string
+	// First and Second are the occurrences of the key.
+	First, Second KeyPosition
+}
+
+func (dke *DuplicateKeyError) Error() string {
+	return "duplicate key " + strconv.Quote(dke.Key) + " at " + dke.First.String() + " and " + dke.Second.String()
+}
+
+func (dke *DuplicateKeyError) Is(err error) bool {
+	_, ok := err.(*DuplicateKeyError)
+	return ok
+}
@@ -17,6 +17,24 @@ type MarshalerYamly interface {
 	MarshalYamly(Inserter)
 }
 
+// Style is a hint for engine defining how the next inserted node should be rendered.
+type Style int8
+
+const (
+	// DefaultStyle lets engine choose the style of node.
+	DefaultStyle Style = iota
+	// FlowStyle renders sequence or mapping in flow style, e.g. [a, b] or {a: b}.
+	FlowStyle
+	// LiteralStyle renders string as literal block scalar ("|").
+	LiteralStyle
+	// FoldedStyle renders string as folded block scalar (">").
+	FoldedStyle
+	// SingleQuotedStyle renders string in single quotes.
+	SingleQuotedStyle
+	// DoubleQuotedStyle renders string in double quotes.
+	DoubleQuotedStyle
+)
+
 // Inserter allows inserting values into an YAML AST.
 // If any error occurs during insertion, further calls are no-op.
 type Inserter interface {
@@ -67,6 +85,27 @@ type Inserter interface {
 	InsertRawText([]byte, error)
 }
 
+// StyledInserter is implemented by inserters which support styles of inserted nodes.
+// Generated code sets styles only if the inserter implements this interface.
+type StyledInserter interface {
+	Inserter
+
+	// SetStyle sets the style of the next inserted node. FlowStyle is applied only to sequences and mappings,
+	// and the other styles are applied only to strings (including the ones inserted with InsertRawText).
+	// Engine can fall back to another style if given one can't be used for the node (e.g. block scalar in flow sequence).
+	SetStyle(Style)
+}
+
+// ExtendedInserter is used to extend Inserter interface with engine-specific
+// methods
+type ExtendedInserter[T any] interface {
+	Inserter
+
+	// InsertNode inserts given subtree into AST.
+	// Also, it accepts an error to make it comfortable to call engine-specific marshalers to provide arguments.
+	InsertNode(T, error)
+}
+
 // Encoder allows inserting values into an YAML AST
 // and encoding builded AST as string or bytes.
 type Encoder interface {
@@ -165,6 +204,27 @@ func (e *encoder[T]) InsertRawText(text []byte, err error) {
 	e.builder.InsertRawText(text, err)
 }
 
+// SetStyle sets the style of the next inserted node using underlying TreeBuilder if it supports styles.
+func (e *encoder[T]) SetStyle(style Style) {
+	if styledBuilder, ok := e.builder.(StyledInserter); ok {
+		styledBuilder.SetStyle(style)
+	}
+}
+
+// InsertNode inserts given subtree using underlying TreeBuilder if it supports inserting nodes.
+// Otherwise the subtree is serialized with TreeWriter and inserted as raw YAML.
+func (e *encoder[T]) InsertNode(node T, err error) {
+	if extBuilder, ok := e.builder.(ExtendedInserter[T]); ok {
+		extBuilder.InsertNode(node, err)
+		return
+	}
+	if err != nil {
+		e.builder.InsertRaw(nil, err)
+		return
+	}
+	e.builder.InsertRaw(e.writer.WriteBytes(node))
+}
+
 func (e *encoder[T]) EncodeToString() (string, error) {
 	tree, err := e.builder.Result()
 	if err != nil {
@@ -0,0 +1,162 @@
+package yamly
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// ErrEngineNotSelected is returned by Marshal and Unmarshal when engine is not specified
+// and it can't be chosen automatically, i.e. no engines or several engines are registered.
+var ErrEngineNotSelected = errors.New("engine is not selected")
+
+// Engine parses and serializes YAML documents, driving generated UnmarshalYamly
+// and MarshalYamly methods. Engines register themselves with RegisterEngine when imported.
+type Engine interface {
+	// Unmarshal parses YAML document and decodes it into v.
+	Unmarshal(data []byte, v UnmarshalerYamly, opts DecodeOptions) error
+	// Marshal encodes v into YAML document.
+	Marshal(v MarshalerYamly) ([]byte, error)
+}
+
+// DecodeOptions are the options of Unmarshal which are handled by engine.
+type DecodeOptions struct {
+	// UniqueKeys makes engine report DuplicateKeyError if some mapping contains the same key several times.
+	UniqueKeys bool
+}
+
+var (
+	enginesMu sync.RWMutex
+	engines   = map[string]Engine{}
+)
+
+// RegisterEngine makes an engine available by given name for Marshal and Unmarshal.
+// If RegisterEngine is called twice with the same name or if engine is nil, it panics.
+func RegisterEngine(name string, engine Engine) {
+	enginesMu.Lock()
+	defer enginesMu.Unlock()
+	if engine == nil {
+		panic("yamly: RegisterEngine engine is nil")
+	}
+	if _, dup := engines[name]; dup {
+		panic("yamly: RegisterEngine called twice for engine " + name)
+	}
+	engines[name] = engine
+}
+
+// Engines returns a sorted list of the names of the registered engines.
+func Engines() []string {
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	return engineNames()
+}
+
+// engineNames must be called with enginesMu held.
+func engineNames() []string {
+	names := make([]string, 0, len(engines))
+	for name := range engines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+type runtimeOptions struct {
+	engine   string
+	warnings *[]error
+	decode   DecodeOptions
+}
+
+// Option allows to modify Marshal and Unmarshal behavior.
+type Option func(*runtimeOptions)
+
+// WithEngine makes Marshal and Unmarshal use the engine registered by given name.
+// Without this option the only registered engine is used.
+func WithEngine(name string) Option {
+	return func(o *runtimeOptions) {
+		o.engine = name
+	}
+}
+
+// WithWarnings makes Unmarshal append warnings (e.g. DeprecatedKeyWarning) reported
+// during decoding to the slice pointed to by dst.
+func WithWarnings(dst *[]error) Option {
+	return func(o *runtimeOptions) {
+		o.warnings = dst
+	}
+}
+
+// WithUniqueKeys makes Unmarshal return DuplicateKeyError if some mapping
+// in YAML document contains the same key several times.
+func WithUniqueKeys() Option {
+	return func(o *runtimeOptions) {
+		o.decode.UniqueKeys = true
+	}
+}
+
+func newRuntimeOptions(opts []Option) runtimeOptions {
+	var o runtimeOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Unmarshal parses YAML document using the selected engine and decodes it into v.
+func Unmarshal(data []byte, v UnmarshalerYamly, opts ...Option) error {
+	o := newRuntimeOptions(opts)
+	engine, err := selectEngine(o)
+	if err != nil {
+		return err
+	}
+	if o.warnings != nil {
+		v = warningsCollector{UnmarshalerYamly: v, dst: o.warnings}
+	}
+	return engine.Unmarshal(data, v, o.decode)
+}
+
+// Marshal encodes v into YAML document using the selected engine.
+func Marshal(v MarshalerYamly, opts ...Option) ([]byte, error) {
+	engine, err := selectEngine(newRuntimeOptions(opts))
+	if err != nil {
+		return nil, err
+	}
+	return engine.Marshal(v)
+}
+
+// warningsCollector retrieves warnings from decoder after v is decoded.
+type warningsCollector struct {
+	UnmarshalerYamly
+	dst *[]error
+}
+
+func (wc warningsCollector) UnmarshalYamly(in Decoder) {
+	wc.UnmarshalerYamly.UnmarshalYamly(in)
+	if win, ok := in.(WarningsDecoder); ok {
+		*wc.dst = append(*wc.dst, win.Warnings()...)
+	}
+}
+
+func selectEngine(o runtimeOptions) (Engine, error) { // nolint: ireturn
+	enginesMu.RLock()
+	defer enginesMu.RUnlock()
+	if o.engine != "" {
+		engine, ok := engines[o.engine]
+		if !ok {
+			return nil, fmt.Errorf("unknown engine %q (forgotten import?)", o.engine)
+		}
+		return engine, nil
+	}
+	if len(engines) == 1 {
+		for _, engine := range engines {
+			return engine, nil
+		}
+	}
+	if len(engines) == 0 {
+		return nil, fmt.Errorf("%w: no engines are registered (forgotten import?)", ErrEngineNotSelected)
+	}
+	return nil, fmt.Errorf("%w: several engines are registered (%s), use WithEngine option",
+		ErrEngineNotSelected, strings.Join(engineNames(), ", "))
+}
@@ -17,7 +17,8 @@ type anyBuilder struct {
 	extractMergeMap bool
 	mergeMap        map[string]any
 
-	errors []error
+	uniqueKeys bool
+	errors     []error
 }
 
 func (a *anyBuilder) extractAnyValue(n *yaml.Node) (any, error) {
@@ -70,6 +71,12 @@ func (a *anyBuilder) visitSequenceNode(n *yaml.Node) {
 }
 
 func (a *anyBuilder) visitMappingNode(n *yaml.Node) {
+	if a.uniqueKeys {
+		if err := findDuplicateKey(n); err != nil {
+			a.appendError(err)
+			return
+		}
+	}
 	m := make(map[string]any, len(n.Content)/2)
 	entriesAmount := len(n.Content)
 	for i := 0; i < entriesAmount; i += 2 {
@@ -138,26 +145,7 @@ func (a *anyBuilder) visitScalarNode(n *yaml.Node) {
 	if a.extractString {
 		a.stringValue = n.Value
 	} else {
-		var err error
-		switch {
-		case schema.IsNull(n):
-			a.value = nil
-		case schema.IsTimestamp(n):
-			a.value, err = schema.ToTimestamp(n.Value)
-		case schema.IsUnsignedInteger(n):
-			a.value, err = schema.ToUnsignedInteger(n.Value, 64)
-		case schema.IsInteger(n):
-			a.value, err = schema.ToInteger(n.Value, 64)
-		case schema.IsFloat(n):
-			a.value, err = schema.ToFloat(n.Value, 64)
-		case schema.IsBoolean(n):
-			a.value, err = schema.ToBoolean(n.Value)
-		default:
-			a.value = n.Value
-		}
-		if err != nil {
-			a.appendError(err)
-		}
+		_, a.value = schema.Resolve(n)
 	}
 }
 
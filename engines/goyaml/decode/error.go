@@ -2,6 +2,10 @@ package decode
 
 import (
 	"fmt"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/engines/goyaml/schema"
+	"gopkg.in/yaml.v3"
 )
 
 type denyError struct {
@@ -29,3 +33,39 @@ type AliasDereferenceError struct {
 func (ade AliasDereferenceError) Error() string {
 	return fmt.Sprintf("failed to dereference alias %q", ade.name)
 }
+
+// findDuplicateKey returns yamly.DuplicateKeyError if given mapping node contains the same key several times.
+// Scalar keys are compared by their value and all null keys are considered equal.
+// Merge keys and non-scalar keys (e.g. aliases and collections) are not checked.
+func findDuplicateKey(n *yaml.Node) error {
+	type scalarKey struct {
+		value string
+		null  bool
+	}
+
+	if n == nil || n.Kind != yaml.MappingNode || len(n.Content) < 4 {
+		return nil
+	}
+
+	seen := make(map[scalarKey]int, len(n.Content)/2)
+	for i := 0; i < len(n.Content); i += 2 {
+		keyNode := n.Content[i]
+		if keyNode.Kind != yaml.ScalarNode || schema.IsMergeKey(keyNode) {
+			continue
+		}
+		key := scalarKey{value: keyNode.Value}
+		if schema.IsNull(keyNode) {
+			key = scalarKey{value: "null", null: true}
+		}
+		if j, ok := seen[key]; ok {
+			firstNode := n.Content[j]
+			return &yamly.DuplicateKeyError{
+				Key:    key.value,
+				First:  yamly.KeyPosition{Entry: j/2 + 1, Line: firstNode.Line, Column: firstNode.Column},
+				Second: yamly.KeyPosition{Entry: i/2 + 1, Line: keyNode.Line, Column: keyNode.Column},
+			}
+		}
+		seen[key] = i
+	}
+	return nil
+}
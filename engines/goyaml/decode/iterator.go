@@ -22,9 +22,34 @@ func (s *nodeIteratorImpl) empty() bool {
 	return s.i >= len(s.nodes)
 }
 
-func newNodeIterator(n *yaml.Node) nodeIterator {
-	return &nodeIteratorImpl{
-		i:     0,
-		nodes: n.Content,
+// newNodeIterator returns iterator over children of the node. The iterator is valid until reader is reset.
+func (r *ASTReader) newNodeIterator(n *yaml.Node) nodeIterator {
+	iter := r.iterators.get()
+	iter.nodes = n.Content
+	return iter
+}
+
+// freeList keeps values of type T to reuse them after reader is reset.
+type freeList[T any] struct {
+	items []*T
+	// used is the number of items given since the last reset
+	used int
+}
+
+func (l *freeList[T]) get() *T {
+	if l.used == len(l.items) {
+		l.items = append(l.items, new(T))
+	}
+	item := l.items[l.used]
+	l.used++
+	return item
+}
+
+// reset zeroes given items and makes them reusable.
+func (l *freeList[T]) reset() {
+	var zero T
+	for _, item := range l.items[:l.used] {
+		*item = zero
 	}
+	l.used = 0
 }
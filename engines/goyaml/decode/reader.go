@@ -3,6 +3,7 @@ package decode
 import (
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/KSpaceer/yamly"
@@ -10,7 +11,11 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-var _ yamly.ExtendedDecoder[*yaml.Node] = (*ASTReader)(nil)
+var (
+	_ yamly.ExtendedDecoder[*yaml.Node] = (*ASTReader)(nil)
+	_ yamly.BufferingDecoder            = (*ASTReader)(nil)
+	_ yamly.WarningsDecoder             = (*ASTReader)(nil)
+)
 
 type ASTReader struct {
 	route []routePoint
@@ -21,10 +26,19 @@ type ASTReader struct {
 	extractedCollectionState yamly.CollectionState
 	extractedValue           string
 
+	// iterators and states are kept in free lists to be reused after reset
+	iterators freeList[nodeIteratorImpl]
+	states    freeList[collectionState]
+
 	multipleDenyErrors bool
+	uniqueKeys         bool
 	fatalError         error
-	latestDenyError    error
 	denyErrors         []error
+	warnings           []error
+
+	// latestDeny is turned into error only if it is not discarded to avoid allocations
+	latestDeny    denyError
+	hasLatestDeny bool
 }
 
 type visitingConclusion int8
@@ -99,11 +113,12 @@ func (noopState) Size() int { return 0 }
 
 func (noopState) HasUnprocessedItems() bool { return false }
 
-func newCollectionState(iter nodeIterator, size int) yamly.CollectionState {
-	return &collectionState{
-		size:         size,
-		nodeIterator: iter,
-	}
+// newCollectionState returns collection state. The state is valid until reader is reset.
+func (r *ASTReader) newCollectionState(iter nodeIterator, size int) yamly.CollectionState { // nolint: ireturn
+	state := r.states.get()
+	state.size = size
+	state.nodeIterator = iter
+	return state
 }
 
 type ReaderOption func(*ASTReader)
@@ -114,6 +129,14 @@ func WithMultipleDenyErrors() ReaderOption {
 	}
 }
 
+// WithUniqueKeys makes reader add yamly.DuplicateKeyError if decoded mapping contains the same key several times.
+// Only scalar keys are checked.
+func WithUniqueKeys() ReaderOption {
+	return func(reader *ASTReader) {
+		reader.uniqueKeys = true
+	}
+}
+
 func NewASTReader(tree *yaml.Node, opts ...ReaderOption) *ASTReader {
 	r := ASTReader{}
 
@@ -125,6 +148,37 @@ func NewASTReader(tree *yaml.Node, opts ...ReaderOption) *ASTReader {
 	return &r
 }
 
+var readerPool = sync.Pool{
+	New: func() any { return new(ASTReader) },
+}
+
+// AcquireASTReader returns ASTReader for given AST from the pool of readers.
+// Release should be called after decoding to return the reader to the pool.
+func AcquireASTReader(tree *yaml.Node, opts ...ReaderOption) *ASTReader {
+	r := readerPool.Get().(*ASTReader) // nolint: forcetypeassert
+	r.multipleDenyErrors = false
+	r.uniqueKeys = false
+
+	for _, opt := range opts {
+		opt(r)
+	}
+
+	r.setAST(tree)
+	return r
+}
+
+// Reset makes the reader decode given AST, keeping the options of the reader.
+// Values obtained from the reader before Reset (e.g. collection states) must not be used after it.
+func (r *ASTReader) Reset(tree *yaml.Node) {
+	r.setAST(tree)
+}
+
+// Release returns the reader to the pool of readers. The reader must not be used after Release.
+func (r *ASTReader) Release() {
+	r.reset()
+	readerPool.Put(r)
+}
+
 func (r *ASTReader) setAST(tree *yaml.Node) {
 	r.reset()
 	if tree.Kind == yaml.DocumentNode {
@@ -144,8 +198,8 @@ func (r *ASTReader) TryNull() bool {
 	if r.hasFatalError() {
 		return false
 	}
-	if r.latestDenyError != nil {
-		r.latestDenyError = nil
+	if r.hasLatestDeny {
+		r.hasLatestDeny = false
 		return false
 	}
 	return true
@@ -157,9 +211,8 @@ func (r *ASTReader) Integer(bitSize int) int64 {
 	}
 	r.currentExpecter = expectInteger{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return 0
 	}
 	v, err := schema.ToInteger(r.extractedValue, bitSize)
@@ -176,9 +229,8 @@ func (r *ASTReader) Unsigned(bitSize int) uint64 {
 	}
 	r.currentExpecter = expectInteger{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return 0
 	}
 	v, err := schema.ToUnsignedInteger(r.extractedValue, bitSize)
@@ -195,9 +247,8 @@ func (r *ASTReader) Boolean() bool {
 	}
 	r.currentExpecter = expectBoolean{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return false
 	}
 	v, err := schema.ToBoolean(r.extractedValue)
@@ -214,9 +265,8 @@ func (r *ASTReader) Float(bitSize int) float64 {
 	}
 	r.currentExpecter = expectFloat{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return 0
 	}
 	v, err := schema.ToFloat(r.extractedValue, bitSize)
@@ -233,9 +283,8 @@ func (r *ASTReader) String() string {
 	}
 	r.currentExpecter = expectString{checkForNull: true}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return ""
 	}
 	return r.extractedValue
@@ -247,9 +296,8 @@ func (r *ASTReader) Timestamp() time.Time {
 	}
 	r.currentExpecter = expectTimestamp{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return time.Time{}
 	}
 	v, err := schema.ToTimestamp(r.extractedValue)
@@ -266,9 +314,8 @@ func (r *ASTReader) Sequence() yamly.CollectionState {
 	}
 	r.currentExpecter = expectSequence{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return noopCollectionState
 	}
 	return r.extractedCollectionState
@@ -280,11 +327,16 @@ func (r *ASTReader) Mapping() yamly.CollectionState {
 	}
 	r.currentExpecter = expectMapping{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return noopCollectionState
 	}
+	if r.uniqueKeys {
+		if err := findDuplicateKey(r.currentNode()); err != nil {
+			r.appendError(err)
+			return noopCollectionState
+		}
+	}
 	return r.extractedCollectionState
 }
 
@@ -294,12 +346,11 @@ func (r *ASTReader) Any() any {
 	}
 	r.currentExpecter = expectAny{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return nil
 	}
-	valueBuilder := anyBuilder{}
+	valueBuilder := anyBuilder{uniqueKeys: r.uniqueKeys}
 	v, err := valueBuilder.extractAnyValue(r.currentNode())
 	if err != nil {
 		r.appendError(err)
@@ -315,9 +366,8 @@ func (r *ASTReader) Raw() []byte {
 	}
 	r.currentExpecter = expectRaw{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return nil
 	}
 	curNode := r.currentNode()
@@ -338,9 +388,8 @@ func (r *ASTReader) Node() *yaml.Node {
 	}
 	r.currentExpecter = expectNode{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return nil
 	}
 	n := r.currentNode()
@@ -348,27 +397,29 @@ func (r *ASTReader) Node() *yaml.Node {
 	return n
 }
 
+// Buffer consumes current subtree and returns a function creating independent readers of the subtree
+// with the same options.
+func (r *ASTReader) Buffer() func() yamly.Decoder {
+	n := r.Node()
+	if n == nil {
+		return nil
+	}
+	multipleDenyErrors, uniqueKeys := r.multipleDenyErrors, r.uniqueKeys
+	return func() yamly.Decoder {
+		sub := &ASTReader{multipleDenyErrors: multipleDenyErrors, uniqueKeys: uniqueKeys}
+		sub.setAST(n)
+		return sub
+	}
+}
+
 func (r *ASTReader) Skip() {
 	if r.hasFatalError() {
 		return
 	}
 	r.currentExpecter = expectSkip{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
-	}
-}
-
-type complexPointOptions struct {
-	beforeVisitFuncs []func(*yaml.Node)
-}
-
-type complexPointOption func(*complexPointOptions)
-
-func beforeVisit(f func(*yaml.Node)) complexPointOption {
-	return func(options *complexPointOptions) {
-		options.beforeVisitFuncs = append(options.beforeVisitFuncs, f)
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 	}
 }
 
@@ -379,7 +430,7 @@ func (r *ASTReader) visitNode(n *yaml.Node) {
 	case yaml.SequenceNode, yaml.MappingNode:
 		point := r.peekRoutePoint()
 		if point.visitingResult.conclusion == visitingConclusionUnknown {
-			point.iter = newNodeIterator(n)
+			point.iter = r.newNodeIterator(n)
 		}
 		r.processComplexPoint(point, len(n.Content))
 	case yaml.ScalarNode:
@@ -403,7 +454,7 @@ func (r *ASTReader) visitScalarNode(n *yaml.Node) {
 	case visitingConclusionMatch:
 	case visitingConclusionDeny:
 		r.swapRoutePoint(point)
-		r.setLatestDeny(&denyError{
+		r.setLatestDeny(denyError{
 			expecter:    r.currentExpecter,
 			nodeContent: point.node.Value,
 		})
@@ -419,12 +470,7 @@ func (r *ASTReader) visitScalarNode(n *yaml.Node) {
 	}
 }
 
-func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts ...complexPointOption) {
-	var o complexPointOptions
-	for _, opt := range opts {
-		opt(&o)
-	}
-
+func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int) {
 	point.visitingResult = r.currentExpecter.process(point.node, point.visitingResult)
 	r.lastVisitingResult = point.visitingResult
 	r.swapRoutePoint(point)
@@ -433,7 +479,7 @@ func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts
 		r.popRoutePoint()
 	case visitingConclusionMatch:
 	case visitingConclusionDeny:
-		r.setLatestDeny(&denyError{
+		r.setLatestDeny(denyError{
 			expecter:    r.currentExpecter,
 			nodeContent: point.node.Value,
 		})
@@ -441,10 +487,6 @@ func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts
 		for r.lastVisitingResult.conclusion == visitingConclusionContinue && !point.iter.empty() {
 			node := point.iter.node()
 
-			for _, beforeVisitFunc := range o.beforeVisitFuncs {
-				beforeVisitFunc(node)
-			}
-
 			if node != nil {
 				r.pushRoutePoint(routePoint{
 					node: node,
@@ -461,20 +503,25 @@ func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts
 	}
 
 	if point.visitingResult.action == visitingActionExtract {
-		r.extractedCollectionState = newCollectionState(point.iter, childrenSize)
+		r.extractedCollectionState = r.newCollectionState(point.iter, childrenSize)
 		r.extractedValue = ""
 	}
 }
 
 func (r *ASTReader) reset() {
+	clear(r.route)
 	r.route = r.route[:0]
+	r.iterators.reset()
+	r.states.reset()
 	r.currentExpecter = nil
 	r.lastVisitingResult = visitingResult{}
 	r.extractedCollectionState = nil
 	r.extractedValue = ""
 	r.fatalError = nil
-	r.latestDenyError = nil
+	r.latestDeny = denyError{}
+	r.hasLatestDeny = false
 	r.denyErrors = r.denyErrors[:0]
+	r.warnings = nil
 }
 
 func (r *ASTReader) visitCurrentNode() {
@@ -520,8 +567,19 @@ func (r *ASTReader) swapRoutePoint(point routePoint) {
 	r.route[len(r.route)-1] = point
 }
 
-func (r *ASTReader) setLatestDeny(err *denyError) {
-	r.latestDenyError = yamly.DenyError(err)
+func (r *ASTReader) setLatestDeny(err denyError) {
+	r.latestDeny = err
+	r.hasLatestDeny = true
+}
+
+// takeLatestDeny returns the latest deny as error and discards it.
+func (r *ASTReader) takeLatestDeny() error {
+	if !r.hasLatestDeny {
+		return nil
+	}
+	r.hasLatestDeny = false
+	err := r.latestDeny
+	return yamly.DenyError(&err)
 }
 
 func (r *ASTReader) appendError(err error) {
@@ -545,11 +603,21 @@ func (r *ASTReader) Error() error {
 }
 
 func (r *ASTReader) AddError(err error) {
-	if r.fatalError == nil {
+	if err != nil && r.fatalError == nil {
 		r.setFatalError(err)
 	}
 }
 
+func (r *ASTReader) AddWarning(w error) {
+	if w != nil {
+		r.warnings = append(r.warnings, w)
+	}
+}
+
+func (r *ASTReader) Warnings() []error {
+	return r.warnings
+}
+
 func (r *ASTReader) setFatalError(err error) {
 	for _, point := range r.route {
 		if point.iter != nil {
@@ -684,6 +684,11 @@ func TestReader_ExpectAny(t *testing.T) {
 			src:      "'null'",
 			expected: "null",
 		},
+		{
+			name:     "quoted number",
+			src:      `"255"`,
+			expected: "255",
+		},
 		{
 			name:     "null",
 			src:      "null",
@@ -1017,3 +1022,200 @@ func (vs *valueStore) Add(v any) {
 func (vs *valueStore) Values() []any {
 	return *vs
 }
+
+// TestReader_Acquire is not parallel, because testing.AllocsPerRun panics in parallel tests.
+func TestReader_Acquire(t *testing.T) {
+	var tree yaml.Node
+	src := "name: pvc-claim\nreplicas: 3\naccessModes: [ReadWriteOnce, ReadOnlyMany]\n"
+	if err := yaml.Unmarshal([]byte(src), &tree); err != nil {
+		t.Fatalf("failed to unmarshal YAML: %v", err)
+	}
+
+	type value struct {
+		name        string
+		replicas    int64
+		accessModes []string
+	}
+
+	decodeWithPool := func(v *value) error {
+		r := decode.AcquireASTReader(&tree)
+		defer r.Release()
+		state := r.Mapping()
+		for state.HasUnprocessedItems() {
+			key := r.String()
+			if r.TryNull() {
+				continue
+			}
+			switch key {
+			case "name":
+				v.name = r.String()
+			case "replicas":
+				v.replicas = r.Integer(64)
+			case "accessModes":
+				v.accessModes = v.accessModes[:0]
+				seqState := r.Sequence()
+				for seqState.HasUnprocessedItems() {
+					v.accessModes = append(v.accessModes, r.String())
+				}
+			default:
+				r.Skip()
+			}
+		}
+		return r.Error()
+	}
+
+	expected := value{
+		name:        "pvc-claim",
+		replicas:    3,
+		accessModes: []string{"ReadWriteOnce", "ReadOnlyMany"},
+	}
+	var v value
+	// decoding several times to check that released readers are reused correctly
+	for i := 0; i < 3; i++ {
+		if err := decodeWithPool(&v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(expected, v) {
+			t.Fatalf("values are not equal:\nexpected: %v\n\ngot: %v", expected, v)
+		}
+	}
+
+	// the value reuses its slice, so no allocations are expected in steady state
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := decodeWithPool(&v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if allocs > 0 {
+		t.Errorf("expected no allocations, but got %v", allocs)
+	}
+}
+
+func TestReader_AddNilError(t *testing.T) {
+	t.Parallel()
+
+	var tree yaml.Node
+	if err := yaml.Unmarshal([]byte("first: 1\nsecond: 2\n"), &tree); err != nil {
+		t.Fatalf("failed to unmarshal YAML: %v", err)
+	}
+
+	r := decode.NewASTReader(&tree)
+	values := map[string]int64{}
+	state := r.Mapping()
+	for state.HasUnprocessedItems() {
+		key := r.String()
+		values[key] = r.Integer(64)
+		// nil error (e.g. successful encoding.TextUnmarshaler call result) must not stop decoding
+		r.AddError(nil)
+	}
+	if err := r.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[string]int64{"first": 1, "second": 2}
+	if !reflect.DeepEqual(expected, values) {
+		t.Errorf("values are not equal:\nexpected: %v\n\ngot: %v", expected, values)
+	}
+}
+
+func TestReader_UniqueKeys(t *testing.T) {
+	t.Parallel()
+
+	readMapping := func(r yamly.Decoder) {
+		state := r.Mapping()
+		for state.HasUnprocessedItems() {
+			r.Skip()
+		}
+	}
+	readAny := func(r yamly.Decoder) {
+		r.Any()
+	}
+
+	type tcase struct {
+		name     string
+		src      string
+		read     func(r yamly.Decoder)
+		expected *yamly.DuplicateKeyError
+	}
+
+	tcases := []tcase{
+		{
+			name: "unique keys",
+			src:  "replicas: 1\nname: app",
+			read: readMapping,
+		},
+		{
+			name: "duplicate keys",
+			src:  "replicas: 1\nname: app\nreplicas: 3",
+			read: readMapping,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "replicas",
+				First:  yamly.KeyPosition{Entry: 1, Line: 1, Column: 1},
+				Second: yamly.KeyPosition{Entry: 3, Line: 3, Column: 1},
+			},
+		},
+		{
+			name: "differently quoted duplicate keys",
+			src:  "{'name': first, \"name\": second}",
+			read: readMapping,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "name",
+				First:  yamly.KeyPosition{Entry: 1, Line: 1, Column: 2},
+				Second: yamly.KeyPosition{Entry: 2, Line: 1, Column: 17},
+			},
+		},
+		{
+			name: "null keys",
+			src:  "? ~\n: first\n? null\n: second",
+			read: readMapping,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "null",
+				First:  yamly.KeyPosition{Entry: 1, Line: 1, Column: 3},
+				Second: yamly.KeyPosition{Entry: 2, Line: 3, Column: 3},
+			},
+		},
+		{
+			name: "merge keys",
+			src:  "base: &base {a: 1}\nother: &other {b: 2}\nvalue:\n  <<: *base\n  <<: *other",
+			read: readAny,
+		},
+		{
+			name: "any",
+			src:  "spec:\n  replicas: 1\n  replicas: 3",
+			read: readAny,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "replicas",
+				First:  yamly.KeyPosition{Entry: 1, Line: 2, Column: 3},
+				Second: yamly.KeyPosition{Entry: 2, Line: 3, Column: 3},
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var tree yaml.Node
+			if err := yaml.Unmarshal([]byte(tc.src), &tree); err != nil {
+				t.Fatalf("failed to unmarshal YAML: %v", err)
+			}
+			r := decode.NewASTReader(&tree, decode.WithUniqueKeys())
+			tc.read(r)
+
+			err := r.Error()
+			if tc.expected == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var dkErr *yamly.DuplicateKeyError
+			if !errors.As(err, &dkErr) {
+				t.Fatalf("expected duplicate key error, but got %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, dkErr) {
+				t.Errorf("expected error %v, but got %v", tc.expected, dkErr)
+			}
+		})
+	}
+}
@@ -3,6 +3,7 @@ package encode
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/KSpaceer/yamly"
@@ -10,7 +11,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
-var _ yamly.TreeBuilder[*yaml.Node] = (*ASTBuilder)(nil)
+var (
+	_ yamly.TreeBuilder[*yaml.Node] = (*ASTBuilder)(nil)
+	_ yamly.StyledInserter          = (*ASTBuilder)(nil)
+)
 
 type ASTBuilder struct {
 	root  *yaml.Node
@@ -18,6 +22,9 @@ type ASTBuilder struct {
 
 	opts builderOpts
 
+	// style is the style of the next inserted node
+	style yamly.Style
+
 	fatalError error
 }
 
@@ -43,6 +50,35 @@ func NewASTBuilder(opts ...ASTBuilderOption) *ASTBuilder {
 	return &b
 }
 
+var builderPool = sync.Pool{
+	New: func() any { return new(ASTBuilder) },
+}
+
+// AcquireASTBuilder returns ASTBuilder from the pool of builders.
+// Release should be called after encoding to return the builder to the pool.
+// The AST built by acquired builder remains valid after Release.
+func AcquireASTBuilder(opts ...ASTBuilderOption) *ASTBuilder {
+	b := builderPool.Get().(*ASTBuilder) // nolint: forcetypeassert
+	b.opts = builderOpts{}
+
+	for _, opt := range opts {
+		opt(&b.opts)
+	}
+
+	return b
+}
+
+// Reset discards the AST built by the builder, keeping the options of the builder.
+func (b *ASTBuilder) Reset() {
+	b.reset()
+}
+
+// Release returns the builder to the pool of builders. The builder must not be used after Release.
+func (b *ASTBuilder) Release() {
+	b.reset()
+	builderPool.Put(b)
+}
+
 func (b *ASTBuilder) InsertInteger(val int64) {
 	insertNonNullValue(b, val, schema.FromInteger, 0)
 }
@@ -60,9 +96,21 @@ func (b *ASTBuilder) InsertFloat(val float64) {
 }
 
 func (b *ASTBuilder) InsertString(val string) {
-	style := yaml.DoubleQuotedStyle
-	if b.opts.unquoteOneliners && !isMultiline(val) {
-		style = 0
+	var style yaml.Style
+	switch b.style {
+	case yamly.LiteralStyle:
+		style = yaml.LiteralStyle
+	case yamly.FoldedStyle:
+		style = yaml.FoldedStyle
+	case yamly.SingleQuotedStyle:
+		style = yaml.SingleQuotedStyle
+	case yamly.DoubleQuotedStyle:
+		style = yaml.DoubleQuotedStyle
+	default:
+		style = yaml.DoubleQuotedStyle
+		if b.opts.unquoteOneliners && !isMultiline(val) {
+			style = 0
+		}
 	}
 	insertNonNullValue(b, val, func(s string) string { return s }, style)
 }
@@ -85,7 +133,8 @@ func (b *ASTBuilder) InsertNull() {
 func (b *ASTBuilder) StartSequence() {
 	b.insertNode(
 		&yaml.Node{
-			Kind: yaml.SequenceNode,
+			Kind:  yaml.SequenceNode,
+			Style: b.collectionStyle(),
 		},
 		true,
 	)
@@ -112,7 +161,8 @@ func (b *ASTBuilder) EndSequence() {
 func (b *ASTBuilder) StartMapping() {
 	b.insertNode(
 		&yaml.Node{
-			Kind: yaml.MappingNode,
+			Kind:  yaml.MappingNode,
+			Style: b.collectionStyle(),
 		},
 		true,
 	)
@@ -169,13 +219,32 @@ func (b *ASTBuilder) InsertRawText(data []byte, err error) {
 	b.InsertString(string(data))
 }
 
+// SetStyle sets the style of the next inserted node. Scalar styles are mapped to yaml.Style
+// of string scalar nodes and FlowStyle is mapped to yaml.FlowStyle of sequences and mappings.
+func (b *ASTBuilder) SetStyle(style yamly.Style) {
+	b.style = style
+}
+
 func (b *ASTBuilder) Result() (*yaml.Node, error) {
 	root := b.root
 	err := b.fatalError
+	b.reset()
+	return root, err
+}
+
+func (b *ASTBuilder) reset() {
+	clear(b.route)
 	b.route = b.route[:0]
 	b.root = nil
+	b.style = yamly.DefaultStyle
 	b.fatalError = nil
-	return root, err
+}
+
+func (b *ASTBuilder) collectionStyle() yaml.Style {
+	if b.style == yamly.FlowStyle {
+		return yaml.FlowStyle
+	}
+	return 0
 }
 
 func insertNonNullValue[T any](b *ASTBuilder, val T, converter func(T) string, style yaml.Style) {
@@ -190,6 +259,8 @@ func insertNonNullValue[T any](b *ASTBuilder, val T, converter func(T) string, s
 }
 
 func (b *ASTBuilder) insertNode(n *yaml.Node, pushToRoute bool) {
+	// style is applied only to the next inserted node
+	b.style = yamly.DefaultStyle
 	if b.fatalError != nil {
 		return
 	}
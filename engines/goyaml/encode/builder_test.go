@@ -237,6 +237,84 @@ func TestBuilder_Complex(t *testing.T) {
 	}
 }
 
+func TestBuilder_Styles(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		calls    func(b *encode.ASTBuilder)
+		expected string
+	}
+
+	tcases := []tcase{
+		{
+			name: "flow sequence",
+			calls: func(b *encode.ASTBuilder) {
+				b.StartMapping()
+				b.InsertString("tags")
+				b.SetStyle(yamly.FlowStyle)
+				b.StartSequence()
+				b.InsertString("a")
+				b.InsertInteger(1)
+				b.EndSequence()
+				b.EndMapping()
+			},
+			expected: "\"tags\": [\"a\", 1]\n",
+		},
+		{
+			name: "literal script",
+			calls: func(b *encode.ASTBuilder) {
+				b.StartMapping()
+				b.InsertString("script")
+				b.SetStyle(yamly.LiteralStyle)
+				b.InsertString("echo 1\necho 2\n")
+				b.EndMapping()
+			},
+			expected: "\"script\": |\n    echo 1\n    echo 2\n",
+		},
+		{
+			name: "single quoted",
+			calls: func(b *encode.ASTBuilder) {
+				b.SetStyle(yamly.SingleQuotedStyle)
+				b.InsertString("it's")
+			},
+			expected: "'it''s'\n",
+		},
+		{
+			name: "style is applied only to next node",
+			calls: func(b *encode.ASTBuilder) {
+				b.StartSequence()
+				b.SetStyle(yamly.FlowStyle)
+				b.StartSequence()
+				b.EndSequence()
+				b.InsertString("x")
+				b.EndSequence()
+			},
+			expected: "- []\n- \"x\"\n",
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			b := encode.NewASTBuilder()
+			tc.calls(b)
+			tree, err := b.Result()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			result, err := (&encode.ASTWriter{}).WriteString(tree)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("expected %q, but got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
 func TestBuilder_InsertRaw(t *testing.T) {
 	t.Parallel()
 	type tcase struct {
@@ -435,3 +513,44 @@ func TestBuilder_K8SManifest(t *testing.T) {
 		t.Errorf("values are not equal:\nexpected: %v\ngot: %v", expected, got)
 	}
 }
+
+func TestBuilder_Acquire(t *testing.T) {
+	t.Parallel()
+
+	expected := map[string]any{"name": "pvc-claim", "accessModes": []any{"ReadWriteOnce"}}
+
+	var trees []*yaml.Node
+	// building several times to check that released builders are reused correctly
+	for i := 0; i < 3; i++ {
+		b := encode.AcquireASTBuilder(encode.WithUnquotedOneLineStrings())
+		b.StartMapping()
+		b.InsertString("broken")
+		b.Reset()
+
+		b.StartMapping()
+		b.InsertString("name")
+		b.InsertString("pvc-claim")
+		b.InsertString("accessModes")
+		b.StartSequence()
+		b.InsertString("ReadWriteOnce")
+		b.EndSequence()
+		b.EndMapping()
+		result, err := b.Result()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		b.Release()
+		trees = append(trees, result)
+	}
+
+	// the trees must remain valid after the builders are released
+	for _, tree := range trees {
+		var got map[string]any
+		if err := tree.Decode(&got); err != nil {
+			t.Fatalf("failed to decode result: %v", err)
+		}
+		if !reflect.DeepEqual(expected, got) {
+			t.Errorf("values are not equal:\nexpected: %v\ngot: %v", expected, got)
+		}
+	}
+}
@@ -37,7 +37,8 @@ func (engineGenerator) WarningSuppressors() []string {
 func (engineGenerator) GenerateUnmarshalers(dst io.Writer, decodeFuncName, typeName string) error {
 	fmt.Fprintln(dst, "// UnmarshalYAML supports yaml.Unmarshaler interface")
 	fmt.Fprintln(dst, "func (v *"+typeName+") UnmarshalYAML(value *yaml.Node) error {")
-	fmt.Fprintln(dst, "  in := decode.NewASTReader(value)")
+	fmt.Fprintln(dst, "  in := decode.AcquireASTReader(value)")
+	fmt.Fprintln(dst, "  defer in.Release()")
 	fmt.Fprintln(dst, "  "+decodeFuncName+"(in, v)")
 	fmt.Fprintln(dst, "  return in.Error()")
 	fmt.Fprintln(dst, "}")
@@ -47,7 +48,8 @@ func (engineGenerator) GenerateUnmarshalers(dst io.Writer, decodeFuncName, typeN
 func (engineGenerator) GenerateMarshalers(dst io.Writer, encodeFuncName, typeName string) error {
 	fmt.Fprintln(dst, "// MarshalYAML support yaml.Marshaler interface")
 	fmt.Fprintln(dst, "func (v "+typeName+") MarshalYAML() (any, error) {")
-	fmt.Fprintln(dst, "out := encode.NewASTBuilder()")
+	fmt.Fprintln(dst, "  out := encode.AcquireASTBuilder()")
+	fmt.Fprintln(dst, "  defer out.Release()")
 	fmt.Fprintln(dst, "  "+encodeFuncName+"(out, v)")
 	fmt.Fprintln(dst, "  return out.Result()")
 	fmt.Fprintln(dst, "}")
@@ -61,11 +63,11 @@ func (engineGenerator) UnmarshalersImplementationCheck(
 	indent int,
 ) (generator.ImplementationResult, error) {
 	unmarshalIface := reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(unmarshalIface) {
+	if implements(t, unmarshalIface) {
 		whitespace := strings.Repeat(" ", indent)
 		fmt.Fprintln(dst, whitespace+"if extIn, ok := in.(yamly.ExtendedDecoder[*yaml.Node]); ok {")
-		fmt.Fprintln(dst, whitespace+"  in.AddError(("+outArg+").UnmarshalYAML(extIn.Node())")
-		fmt.Fprintln(dst, whitespace, "} else {")
+		fmt.Fprintln(dst, whitespace+"  in.AddError(("+outArg+").UnmarshalYAML(extIn.Node()))")
+		fmt.Fprintln(dst, whitespace+"} else {")
 		return generator.ImplementationResultConditional, nil
 	}
 	return generator.ImplementationResultFalse, nil
@@ -78,7 +80,7 @@ func (engineGenerator) MarshalersImplementationCheck(
 	indent int,
 ) (generator.ImplementationResult, error) {
 	marshalIface := reflect.TypeOf((*yaml.Marshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(marshalIface) {
+	if implements(t, marshalIface) {
 		fmt.Fprintln(dst, strings.Repeat(" ", indent)+"out.InsertRaw(yaml.Marshal("+inArg+"))")
 		return generator.ImplementationResultTrue, nil
 	}
@@ -89,9 +91,10 @@ func (engineGenerator) GenerateUnmarshalEmptyInterfaceAssertions(dst io.Writer,
 	whitespace := strings.Repeat(" ", indent)
 	fmt.Fprintln(dst, whitespace+"if m, ok := "+outArg+".(yaml.Unmarshaler); ok {")
 	fmt.Fprintln(dst, whitespace+"  if extIn, ok := in.(yamly.ExtendedDecoder[*yaml.Node]); ok {")
-	fmt.Fprintln(dst, whitespace+"    in.AddError(m.UnmarshalYAML(extIn.Node())")
+	fmt.Fprintln(dst, whitespace+"    in.AddError(m.UnmarshalYAML(extIn.Node()))")
 	fmt.Fprintln(dst, whitespace+"  } else {")
 	fmt.Fprintln(dst, whitespace+"    "+outArg+" = in.Any()")
+	fmt.Fprintln(dst, whitespace+"  }")
 	fmt.Fprintln(dst, whitespace+"} else {")
 	fmt.Fprintln(dst, whitespace+"  "+outArg+" = in.Any()")
 	fmt.Fprintln(dst, whitespace+"}")
@@ -103,3 +106,12 @@ func (engineGenerator) GenerateMarshalEmptyInterfaceAssertions(dst io.Writer, in
 	fmt.Fprintln(dst, whitespace+"out.InsertRaw(yaml.Marshal("+inArg+"))")
 	return nil
 }
+
+// implements checks if values of type t or pointers to them implement iface.
+// Interface types are checked as is, since pointers to interfaces have no methods.
+func implements(t, iface reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return t.Implements(iface)
+	}
+	return reflect.PtrTo(t).Implements(iface)
+}
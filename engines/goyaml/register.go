@@ -0,0 +1,49 @@
+package goyaml
+
+import (
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/engines/goyaml/decode"
+	"github.com/KSpaceer/yamly/engines/goyaml/encode"
+	"gopkg.in/yaml.v3"
+)
+
+// EngineName is the name of the engine used with yamly.WithEngine.
+const EngineName = "goyaml"
+
+func init() {
+	yamly.RegisterEngine(EngineName, engine{})
+}
+
+// engine implements yamly.Engine using pooled readers and builders.
+type engine struct{}
+
+func (engine) Unmarshal(data []byte, v yamly.UnmarshalerYamly, opts yamly.DecodeOptions) error {
+	var tree yaml.Node
+	if err := yaml.Unmarshal(data, &tree); err != nil {
+		return err
+	}
+	if tree.Kind == 0 {
+		// like yaml.Unmarshal, leaving v unchanged for empty document
+		return nil
+	}
+	var readerOpts []decode.ReaderOption
+	if opts.UniqueKeys {
+		readerOpts = append(readerOpts, decode.WithUniqueKeys())
+	}
+	in := decode.AcquireASTReader(&tree, readerOpts...)
+	defer in.Release()
+	v.UnmarshalYamly(in)
+	return in.Error()
+}
+
+func (engine) Marshal(v yamly.MarshalerYamly) ([]byte, error) {
+	out := encode.AcquireASTBuilder()
+	defer out.Release()
+	v.MarshalYamly(out)
+	tree, err := out.Result()
+	if err != nil {
+		return nil, err
+	}
+	var w encode.ASTWriter
+	return w.WriteBytes(tree)
+}
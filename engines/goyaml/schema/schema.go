@@ -18,6 +18,24 @@ func IsNull(n *yaml.Node) bool {
 	return n.ShortTag() == "!!null"
 }
 
+// Resolve derives the kind of scalar node and converts it into Go value.
+// Quoted and block scalars and scalars explicitly tagged as strings are always strings.
+// For more information see shared schema package.
+func Resolve(n *yaml.Node) (schema.Kind, any) {
+	if n.Kind != yaml.ScalarNode {
+		return schema.StringKind, ""
+	}
+	const stringStyles = yaml.SingleQuotedStyle | yaml.DoubleQuotedStyle | yaml.LiteralStyle | yaml.FoldedStyle
+	switch {
+	case IsNull(n):
+		return schema.NullKind, nil
+	case n.Style&stringStyles != 0, n.Style&yaml.TaggedStyle != 0 && n.ShortTag() == "!!str":
+		return schema.StringKind, n.Value
+	default:
+		return schema.Resolve(n.Value)
+	}
+}
+
 func IsBoolean(n *yaml.Node) bool {
 	if n.Kind != yaml.ScalarNode {
 		return false
@@ -0,0 +1,378 @@
+package schema
+
+import (
+	"math"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Kind is a type of YAML scalar derived from its text.
+type Kind int8
+
+const (
+	// StringKind means that scalar is a string.
+	StringKind Kind = iota
+	// NullKind means that scalar represents a null value.
+	NullKind
+	// BooleanKind means that scalar represents a boolean value.
+	BooleanKind
+	// UnsignedIntegerKind means that scalar represents a non-negative integer value.
+	UnsignedIntegerKind
+	// IntegerKind means that scalar represents a negative integer value.
+	IntegerKind
+	// FloatKind means that scalar represents a floating point number value.
+	FloatKind
+	// TimestampKind means that scalar represents a timedate.
+	TimestampKind
+)
+
+func (k Kind) String() string {
+	switch k {
+	case StringKind:
+		return "string"
+	case NullKind:
+		return "null"
+	case BooleanKind:
+		return "boolean"
+	case UnsignedIntegerKind:
+		return "unsigned integer"
+	case IntegerKind:
+		return "integer"
+	case FloatKind:
+		return "float"
+	case TimestampKind:
+		return "timestamp"
+	default:
+		return "unknown kind (" + strconv.Itoa(int(k)) + ")"
+	}
+}
+
+// Resolve derives the kind of plain scalar and converts it into Go value of the corresponding type:
+// nil, bool, uint64, int64, float64, time.Time or string.
+// A number is resolved as integer or float only if it fits into 64-bit Go type, like IsInteger and IsFloat report:
+// decimal integers out of integer range are resolved as floats, other numbers out of range - as strings.
+func Resolve(s string) (Kind, any) {
+	if s == "" {
+		return NullKind, nil
+	}
+	switch c := s[0]; {
+	case c == 'n' || c == 'N' || c == '~':
+		if IsNull(s) {
+			return NullKind, nil
+		}
+	case c == 't' || c == 'T' || c == 'f' || c == 'F':
+		if v, ok := tryGetBoolean(s); ok {
+			return BooleanKind, v
+		}
+	case c >= '0' && c <= '9' || c == '-' || c == '+' || c == '.':
+		if t, ok := parseTimestamp(s); ok {
+			return TimestampKind, t
+		}
+		return resolveNumber(s)
+	}
+	return StringKind, s
+}
+
+func resolveNumber(s string) (Kind, any) {
+	switch scanNumber(s) {
+	case decimalFormat:
+		if s[0] == '-' {
+			if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+				return IntegerKind, v
+			}
+		} else if v, err := strconv.ParseUint(strings.TrimPrefix(s, "+"), 10, 64); err == nil {
+			return UnsignedIntegerKind, v
+		}
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return FloatKind, v
+		}
+	case octalFormat:
+		if v, err := strconv.ParseUint(s[2:], 8, 64); err == nil {
+			return UnsignedIntegerKind, v
+		}
+	case hexadecimalFormat:
+		if v, err := strconv.ParseUint(s[2:], 16, 64); err == nil {
+			return UnsignedIntegerKind, v
+		}
+	case floatFormat:
+		if v, err := strconv.ParseFloat(s, 64); err == nil {
+			return FloatKind, v
+		}
+	case infinityFormat:
+		if s[0] == '-' {
+			return FloatKind, math.Inf(-1)
+		}
+		return FloatKind, math.Inf(1)
+	case notANumberFormat:
+		return FloatKind, math.NaN()
+	}
+	return StringKind, s
+}
+
+// numberFormat is a syntactic form of YAML number.
+type numberFormat int8
+
+const (
+	notNumberFormat numberFormat = iota
+	// [-+]?[0-9]+
+	decimalFormat
+	// 0o[0-7]+
+	octalFormat
+	// 0x[0-9a-fA-F]+
+	hexadecimalFormat
+	// [-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?
+	floatFormat
+	// [-+]?\.(inf|Inf|INF)
+	infinityFormat
+	// \.(nan|NaN|NAN)
+	notANumberFormat
+)
+
+func scanNumber(s string) numberFormat {
+	if len(s) > 2 && s[0] == '0' {
+		switch s[1] {
+		case 'o':
+			if skipDigits(s, 2, isOctalDigit) == len(s) {
+				return octalFormat
+			}
+			return notNumberFormat
+		case 'x':
+			if skipDigits(s, 2, isHexadecimalDigit) == len(s) {
+				return hexadecimalFormat
+			}
+			return notNumberFormat
+		}
+	}
+
+	i := 0
+	if i < len(s) && (s[i] == '-' || s[i] == '+') {
+		i++
+	}
+	if i < len(s) && s[i] == '.' {
+		switch s[i+1:] {
+		case "inf", "Inf", "INF":
+			return infinityFormat
+		case "nan", "NaN", "NAN":
+			if i == 0 {
+				return notANumberFormat
+			}
+			return notNumberFormat
+		}
+	}
+
+	format := decimalFormat
+	intEnd := skipDigits(s, i, isDecimalDigit)
+	hasIntPart := intEnd > i
+	i = intEnd
+	if i < len(s) && s[i] == '.' {
+		fracEnd := skipDigits(s, i+1, isDecimalDigit)
+		if !hasIntPart && fracEnd == i+1 {
+			return notNumberFormat
+		}
+		format, i = floatFormat, fracEnd
+	} else if !hasIntPart {
+		return notNumberFormat
+	}
+	if i < len(s) && (s[i] == 'e' || s[i] == 'E') {
+		i++
+		if i < len(s) && (s[i] == '-' || s[i] == '+') {
+			i++
+		}
+		expEnd := skipDigits(s, i, isDecimalDigit)
+		if expEnd == i {
+			return notNumberFormat
+		}
+		format, i = floatFormat, expEnd
+	}
+	if i != len(s) {
+		return notNumberFormat
+	}
+	return format
+}
+
+func skipDigits(s string, i int, isDigit func(byte) bool) int {
+	for i < len(s) && isDigit(s[i]) {
+		i++
+	}
+	return i
+}
+
+func isDecimalDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isOctalDigit(c byte) bool {
+	return c >= '0' && c <= '7'
+}
+
+func isHexadecimalDigit(c byte) bool {
+	return isDecimalDigit(c) || c >= 'a' && c <= 'f' || c >= 'A' && c <= 'F'
+}
+
+// isBase64 checks if s matches (?:[A-Za-z0-9+/]{4})*(?:[A-Za-z0-9+/]{3}=|[A-Za-z0-9+/]{2}==)?
+func isBase64(s string) bool {
+	if len(s)%4 != 0 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', isDecimalDigit(c), c == '+', c == '/':
+		case c == '=':
+			// padding is allowed only at the end of the last group
+			rest := s[i:]
+			return rest == "=" || rest == "=="
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// nextField returns the first whitespace separated field of s and the rest of s after it.
+func nextField(s string) (field, rest string) {
+	s = strings.TrimLeftFunc(s, unicode.IsSpace)
+	end := strings.IndexFunc(s, unicode.IsSpace)
+	if end == -1 {
+		return s, ""
+	}
+	return s[:end], s[end:]
+}
+
+// parseTimestamp parses timestamp in format
+// [0-9]{4}-[0-9]{1,2}-[0-9]{1,2}
+// (([Tt]|[ \t]+)[0-9]{1,2}:[0-9]{1,2}:[0-9]{1,2}(\.[0-9]*)?([ \t]*(Z|[-+][0-9]{1,2}(:[0-9]{2})?))?)?
+func parseTimestamp(s string) (time.Time, bool) {
+	// the shortest timestamp is date like 2001-1-2
+	if len(s) < 8 || s[4] != '-' {
+		return time.Time{}, false
+	}
+	sc := timestampScanner{src: s}
+	year, ok := sc.number(4, 4)
+	if !ok || !sc.consume('-') {
+		return time.Time{}, false
+	}
+	month, ok := sc.number(1, 2)
+	if !ok || month < 1 || month > 12 || !sc.consume('-') {
+		return time.Time{}, false
+	}
+	day, ok := sc.number(1, 2)
+	if !ok || day < 1 || day > daysIn(time.Month(month), year) {
+		return time.Time{}, false
+	}
+	if sc.done() {
+		return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC), true
+	}
+
+	if !sc.consume('T') && !sc.consume('t') && !sc.skipBlanks() {
+		return time.Time{}, false
+	}
+	hour, ok := sc.number(1, 2)
+	if !ok || hour > 23 || !sc.consume(':') {
+		return time.Time{}, false
+	}
+	minute, ok := sc.number(1, 2)
+	if !ok || minute > 59 || !sc.consume(':') {
+		return time.Time{}, false
+	}
+	sec, ok := sc.number(1, 2)
+	if !ok || sec > 59 {
+		return time.Time{}, false
+	}
+	var nsec int
+	if sc.consume('.') {
+		nsec = sc.fraction()
+	}
+	sc.skipBlanks()
+
+	t := time.Date(year, time.Month(month), day, hour, minute, sec, nsec, time.UTC)
+	switch {
+	case sc.done():
+		return t, true
+	case sc.consume('Z'):
+		return t, sc.done()
+	}
+
+	sign := 1
+	switch {
+	case sc.consume('-'):
+		sign = -1
+	case sc.consume('+'):
+	default:
+		return time.Time{}, false
+	}
+	offsetHours, ok := sc.number(1, 2)
+	if !ok {
+		return time.Time{}, false
+	}
+	var offsetMinutes int
+	if sc.consume(':') {
+		if offsetMinutes, ok = sc.number(2, 2); !ok {
+			return time.Time{}, false
+		}
+	}
+	if !sc.done() {
+		return time.Time{}, false
+	}
+	offset := sign * (offsetHours*60*60 + offsetMinutes*60)
+	t = t.Add(-time.Duration(offset) * time.Second)
+	// using local time zone if it has the same offset like time.Parse does
+	if _, localOffset := t.In(time.Local).Zone(); localOffset == offset {
+		return t.In(time.Local), true
+	}
+	return t.In(time.FixedZone("", offset)), true
+}
+
+func daysIn(month time.Month, year int) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+type timestampScanner struct {
+	src string
+	pos int
+}
+
+func (sc *timestampScanner) done() bool {
+	return sc.pos == len(sc.src)
+}
+
+func (sc *timestampScanner) consume(c byte) bool {
+	if sc.pos < len(sc.src) && sc.src[sc.pos] == c {
+		sc.pos++
+		return true
+	}
+	return false
+}
+
+func (sc *timestampScanner) skipBlanks() bool {
+	start := sc.pos
+	for sc.pos < len(sc.src) && (sc.src[sc.pos] == ' ' || sc.src[sc.pos] == '\t') {
+		sc.pos++
+	}
+	return sc.pos > start
+}
+
+// number reads decimal number with given minimal and maximal count of digits.
+func (sc *timestampScanner) number(minDigits, maxDigits int) (int, bool) {
+	var v, digits int
+	for ; digits < maxDigits && sc.pos < len(sc.src) && isDecimalDigit(sc.src[sc.pos]); digits++ {
+		v = v*10 + int(sc.src[sc.pos]-'0')
+		sc.pos++
+	}
+	return v, digits >= minDigits
+}
+
+// fraction reads fractional part of second as nanoseconds. Digits beyond nanoseconds are ignored.
+func (sc *timestampScanner) fraction() int {
+	var v int
+	scale := int(time.Second)
+	for ; sc.pos < len(sc.src) && isDecimalDigit(sc.src[sc.pos]); sc.pos++ {
+		if scale > 1 {
+			scale /= 10
+			v += int(sc.src[sc.pos]-'0') * scale
+		}
+	}
+	return v
+}
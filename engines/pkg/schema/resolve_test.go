@@ -0,0 +1,188 @@
+package schema_test
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/KSpaceer/yamly/engines/pkg/schema"
+)
+
+func TestResolve(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		src           string
+		expectedKind  schema.Kind
+		expectedValue any
+	}
+
+	tcases := []tcase{
+		{src: "", expectedKind: schema.NullKind},
+		{src: "~", expectedKind: schema.NullKind},
+		{src: "Null", expectedKind: schema.NullKind},
+		{src: "nil", expectedKind: schema.StringKind, expectedValue: "nil"},
+		{src: "true", expectedKind: schema.BooleanKind, expectedValue: true},
+		{src: "FALSE", expectedKind: schema.BooleanKind, expectedValue: false},
+		{src: "tRue", expectedKind: schema.StringKind, expectedValue: "tRue"},
+		{src: "255", expectedKind: schema.UnsignedIntegerKind, expectedValue: uint64(255)},
+		{src: "+255", expectedKind: schema.UnsignedIntegerKind, expectedValue: uint64(255)},
+		{src: "017", expectedKind: schema.UnsignedIntegerKind, expectedValue: uint64(17)},
+		{src: "010", expectedKind: schema.UnsignedIntegerKind, expectedValue: uint64(10)},
+		{src: "-255", expectedKind: schema.IntegerKind, expectedValue: int64(-255)},
+		{src: "0o17", expectedKind: schema.UnsignedIntegerKind, expectedValue: uint64(15)},
+		{src: "0x1F", expectedKind: schema.UnsignedIntegerKind, expectedValue: uint64(31)},
+		{src: "0o18", expectedKind: schema.StringKind, expectedValue: "0o18"},
+		{src: "0x", expectedKind: schema.StringKind, expectedValue: "0x"},
+		{src: "0xFFFFFFFFFFFFFFFFF", expectedKind: schema.StringKind, expectedValue: "0xFFFFFFFFFFFFFFFFF"},
+		{src: "0xFFFFFFFFFFFFFFFFFF", expectedKind: schema.StringKind, expectedValue: "0xFFFFFFFFFFFFFFFFFF"},
+		{src: "18446744073709551616", expectedKind: schema.FloatKind, expectedValue: 18446744073709551616.0},
+		{src: "99999999999999999999", expectedKind: schema.FloatKind, expectedValue: 99999999999999999999.0},
+		{src: "-9223372036854775809", expectedKind: schema.FloatKind, expectedValue: -9223372036854775809.0},
+		{src: "1e1000", expectedKind: schema.StringKind, expectedValue: "1e1000"},
+		{src: "2e-6", expectedKind: schema.FloatKind, expectedValue: 2e-6},
+		{src: "-.5", expectedKind: schema.FloatKind, expectedValue: -0.5},
+		{src: "1.", expectedKind: schema.FloatKind, expectedValue: 1.0},
+		{src: "1e", expectedKind: schema.StringKind, expectedValue: "1e"},
+		{src: ".", expectedKind: schema.StringKind, expectedValue: "."},
+		{src: "-.inf", expectedKind: schema.FloatKind, expectedValue: math.Inf(-1)},
+		{src: ".Inf", expectedKind: schema.FloatKind, expectedValue: math.Inf(1)},
+		{src: "-.nan", expectedKind: schema.StringKind, expectedValue: "-.nan"},
+		{src: "1_000", expectedKind: schema.StringKind, expectedValue: "1_000"},
+		{
+			src:           "2023-08-26",
+			expectedKind:  schema.TimestampKind,
+			expectedValue: time.Date(2023, time.August, 26, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			src:           "2002-1-2",
+			expectedKind:  schema.TimestampKind,
+			expectedValue: time.Date(2002, time.January, 2, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			src:           "2001-12-14t21:59:43.10Z",
+			expectedKind:  schema.TimestampKind,
+			expectedValue: time.Date(2001, time.December, 14, 21, 59, 43, 100000000, time.UTC),
+		},
+		{
+			src:           "2001-12-14 21:59:43.10",
+			expectedKind:  schema.TimestampKind,
+			expectedValue: time.Date(2001, time.December, 14, 21, 59, 43, 100000000, time.UTC),
+		},
+		{src: "2001-02-30", expectedKind: schema.StringKind, expectedValue: "2001-02-30"},
+		{src: "2001-12-14 25:00:00", expectedKind: schema.StringKind, expectedValue: "2001-12-14 25:00:00"},
+		{src: "2001-12-14T21:59", expectedKind: schema.StringKind, expectedValue: "2001-12-14T21:59"},
+		{src: "text", expectedKind: schema.StringKind, expectedValue: "text"},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.src, func(t *testing.T) {
+			t.Parallel()
+
+			kind, value := schema.Resolve(tc.src)
+			if kind != tc.expectedKind {
+				t.Fatalf("expected kind %s, but got %s", tc.expectedKind, kind)
+			}
+			if !reflect.DeepEqual(tc.expectedValue, value) {
+				t.Errorf("expected value %#v, but got %#v", tc.expectedValue, value)
+			}
+		})
+	}
+}
+
+func TestResolve_Predicates(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		src                     string
+		expectedInteger         bool
+		expectedUnsignedInteger bool
+		expectedFloat           bool
+		expectedResolveKind     schema.Kind
+	}
+
+	tcases := []tcase{
+		{src: "255", expectedInteger: true, expectedUnsignedInteger: true, expectedFloat: true,
+			expectedResolveKind: schema.UnsignedIntegerKind},
+		{src: "-255", expectedInteger: true, expectedFloat: true, expectedResolveKind: schema.IntegerKind},
+		{src: "010", expectedInteger: true, expectedUnsignedInteger: true, expectedFloat: true,
+			expectedResolveKind: schema.UnsignedIntegerKind},
+		{src: "0o17", expectedInteger: true, expectedUnsignedInteger: true, expectedResolveKind: schema.UnsignedIntegerKind},
+		{src: "0xFFFFFFFFFFFFFFFF", expectedInteger: true, expectedUnsignedInteger: true,
+			expectedResolveKind: schema.UnsignedIntegerKind},
+		{src: "0xFFFFFFFFFFFFFFFFFF", expectedResolveKind: schema.StringKind},
+		{src: "0o7777777777777777777777777", expectedResolveKind: schema.StringKind},
+		{src: "99999999999999999999", expectedFloat: true, expectedResolveKind: schema.FloatKind},
+		{src: "-9223372036854775809", expectedFloat: true, expectedResolveKind: schema.FloatKind},
+		{src: "2e-6", expectedFloat: true, expectedResolveKind: schema.FloatKind},
+		{src: "1e1000", expectedResolveKind: schema.StringKind},
+		{src: "-1e1000", expectedResolveKind: schema.StringKind},
+		{src: ".inf", expectedFloat: true, expectedResolveKind: schema.FloatKind},
+		{src: ".nan", expectedFloat: true, expectedResolveKind: schema.FloatKind},
+		{src: "text", expectedResolveKind: schema.StringKind},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.src, func(t *testing.T) {
+			t.Parallel()
+
+			if got := schema.IsInteger(tc.src); got != tc.expectedInteger {
+				t.Errorf("expected IsInteger to be %t, but got %t", tc.expectedInteger, got)
+			}
+			if got := schema.IsUnsignedInteger(tc.src); got != tc.expectedUnsignedInteger {
+				t.Errorf("expected IsUnsignedInteger to be %t, but got %t", tc.expectedUnsignedInteger, got)
+			}
+			if got := schema.IsFloat(tc.src); got != tc.expectedFloat {
+				t.Errorf("expected IsFloat to be %t, but got %t", tc.expectedFloat, got)
+			}
+			if kind, _ := schema.Resolve(tc.src); kind != tc.expectedResolveKind {
+				t.Errorf("expected kind %s, but got %s", tc.expectedResolveKind, kind)
+			}
+		})
+	}
+}
+
+func TestToInteger_LeadingZeros(t *testing.T) {
+	t.Parallel()
+
+	// YAML 1.2 core schema has no octal integers with leading zero, so "010" is decimal 10, not 8
+	if v, err := schema.ToInteger("010", 64); err != nil || v != 10 {
+		t.Errorf("expected integer 10, but got %d (error: %v)", v, err)
+	}
+	if v, err := schema.ToUnsignedInteger("010", 64); err != nil || v != 10 {
+		t.Errorf("expected unsigned integer 10, but got %d (error: %v)", v, err)
+	}
+	if v, err := schema.ToInteger("0o10", 64); err != nil || v != 8 {
+		t.Errorf("expected integer 8, but got %d (error: %v)", v, err)
+	}
+}
+
+func TestResolve_TimeZone(t *testing.T) {
+	t.Parallel()
+
+	const src = "2001-12-14 21:59:43.10 -5"
+	kind, value := schema.Resolve(src)
+	if kind != schema.TimestampKind {
+		t.Fatalf("expected kind %s, but got %s", schema.TimestampKind, kind)
+	}
+	expected := time.Date(2001, time.December, 15, 2, 59, 43, 100000000, time.UTC)
+	if ts := value.(time.Time); !ts.Equal(expected) { // nolint: forcetypeassert
+		t.Errorf("expected time %v, but got %v", expected, ts)
+	}
+	if _, offset := value.(time.Time).Zone(); offset != -5*60*60 { // nolint: forcetypeassert
+		t.Errorf("expected offset %d, but got %d", -5*60*60, offset)
+	}
+}
+
+func BenchmarkResolve(b *testing.B) {
+	srcs := []string{"text", "null", "true", "255", "-255", "0x1F", "2e-6", ".inf", "2001-12-14t21:59:43.10Z"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		for _, src := range srcs {
+			schema.Resolve(src)
+		}
+	}
+}
@@ -4,7 +4,6 @@ package schema
 import (
 	"fmt"
 	"math"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -56,25 +55,18 @@ func tryGetBoolean(s string) (v, isBoolean bool) {
 	}
 }
 
-var (
-	yamlDecimalIntegerRegex     = regexp.MustCompile(`^[-+]?[0-9]+$`)
-	yamlDecimalUnsignedRegex    = regexp.MustCompile(`^\+?[0-9]+$`)
-	yamlOctalIntegerRegex       = regexp.MustCompile(`^0o[0-7]+$`)
-	yamlHexadecimalIntegerRegex = regexp.MustCompile(`^0x[0-9a-fA-F]+$`)
-)
-
 // IsInteger shows if string can represent a signed integer value.
+// Integers out of 64-bit range are not considered as integers.
 func IsInteger(s string) bool {
-	return yamlDecimalIntegerRegex.MatchString(s) ||
-		yamlOctalIntegerRegex.MatchString(s) ||
-		yamlHexadecimalIntegerRegex.MatchString(s)
+	kind, _ := resolveNumber(s)
+	return kind == IntegerKind || kind == UnsignedIntegerKind
 }
 
 // IsUnsignedInteger shows if string can represent an unsigned integer value.
+// Integers out of 64-bit range are not considered as integers.
 func IsUnsignedInteger(s string) bool {
-	return yamlDecimalUnsignedRegex.MatchString(s) ||
-		yamlOctalIntegerRegex.MatchString(s) ||
-		yamlHexadecimalIntegerRegex.MatchString(s)
+	kind, _ := resolveNumber(s)
+	return kind == UnsignedIntegerKind
 }
 
 // FromInteger converts Go integer value into YAML integer.
@@ -84,6 +76,10 @@ func FromInteger(val int64) string {
 
 // ToInteger tries to convert YAML into Go integer with given bit size.
 func ToInteger(src string, bitSize int) (int64, error) {
+	if scanNumber(src) == decimalFormat {
+		// leading zeros do not make decimal integer octal in YAML
+		return strconv.ParseInt(src, 10, bitSize)
+	}
 	return strconv.ParseInt(src, 0, bitSize)
 }
 
@@ -94,20 +90,24 @@ func FromUnsignedInteger(val uint64) string {
 
 // ToUnsignedInteger tries to convert YAML into Go unsigned integer with given bit size.
 func ToUnsignedInteger(src string, bitSize int) (uint64, error) {
+	if scanNumber(src) == decimalFormat {
+		return strconv.ParseUint(strings.TrimPrefix(src, "+"), 10, bitSize)
+	}
 	return strconv.ParseUint(src, 0, bitSize)
 }
 
-var (
-	yamlFloatRegex         = regexp.MustCompile(`^[+-]?(?:\.[0-9]+|[0-9]+(?:\.[0-9]*)?)(?:[eE][-+]?[0-9]+)?$`)
-	yamlFloatInfinityRegex = regexp.MustCompile(`^[-+]?\.(?:inf|Inf|INF)$`)
-	yamlNotANumberRegex    = regexp.MustCompile(`^\.(?:nan|NaN|NAN)$`)
-)
-
 // IsFloat shows if string can represent a floating point number value.
+// Numbers out of 64-bit floating point range are not considered as floats.
 func IsFloat(s string) bool {
-	return yamlFloatRegex.MatchString(s) ||
-		yamlFloatInfinityRegex.MatchString(s) ||
-		yamlNotANumberRegex.MatchString(s)
+	switch scanNumber(s) {
+	case decimalFormat, floatFormat:
+		_, err := strconv.ParseFloat(s, 64)
+		return err == nil
+	case infinityFormat, notANumberFormat:
+		return true
+	default:
+		return false
+	}
 }
 
 // FromFloat converts Go float value into YAML float.
@@ -126,27 +126,26 @@ func FromFloat(val float64) string {
 
 // ToFloat tries to convert YAML into Go floating point number with given bit size.
 func ToFloat(src string, bitSize int) (float64, error) {
-	switch {
-	case yamlFloatInfinityRegex.MatchString(src):
+	switch scanNumber(src) {
+	case infinityFormat:
 		sign := 1
 		if src[0] == '-' {
 			sign = -1
 		}
 		return math.Inf(sign), nil
-	case yamlNotANumberRegex.MatchString(src):
+	case notANumberFormat:
 		return math.NaN(), nil
 	default:
 		return strconv.ParseFloat(src, bitSize)
 	}
 }
 
-var base64Regex = regexp.MustCompile(`^(?:[A-Za-z0-9+/]{4})*(?:[A-Za-z0-9+/]{3}=|[A-Za-z0-9+/]{2}==)?$`)
-
 // IsBinary shows if string represents a YAML binary scalar (!!binary)
 func IsBinary(s string) bool {
-	parts := strings.Fields(s)
-	for i := range parts {
-		if !base64Regex.MatchString(parts[i]) {
+	for s != "" {
+		var field string
+		field, s = nextField(s)
+		if !isBase64(field) {
 			return false
 		}
 	}
@@ -158,29 +157,28 @@ var timestampLayouts = []string{
 	time.RFC3339Nano,
 	time.DateOnly,
 	"2006-1-2T15:4:5.999999999Z07:00", // short RFC339Nano
-	"2001-1-2t15:4:5.999999999-07:00", // lower t + time zone without 'Z'
-	"2001-1-2 15:4:5.999999999",       // space separated
-	"2001-1-2",                        // date only short form
+	"2006-1-2t15:4:5.999999999-07:00", // lower t + time zone without 'Z'
+	"2006-1-2 15:4:5.999999999",       // space separated
+	"2006-1-2",                        // date only short form
 }
 
 // IsTimestamp shows if string represents a YAML timedate.
 func IsTimestamp(s string) bool {
-	for i := range timestampLayouts {
-		_, err := time.Parse(timestampLayouts[i], s)
-		if err == nil {
-			return true
-		}
-	}
-	return false
+	_, ok := parseTimestamp(s)
+	return ok
 }
 
-// FromTimestamp converts Go time.Time value into YAML timedate.
+// FromTimestamp converts Go time.Time value into YAML timedate in RFC 3339 format.
+// Sub-second precision is dropped.
 func FromTimestamp(val time.Time) string {
 	return val.Format(time.RFC3339)
 }
 
 // ToTimestamp tries to convert YAML string into Go time.Time value.
 func ToTimestamp(src string) (t time.Time, err error) {
+	if t, ok := parseTimestamp(src); ok {
+		return t, nil
+	}
 	for i := range timestampLayouts {
 		t, err = time.Parse(timestampLayouts[i], src)
 		if err == nil {
@@ -0,0 +1,241 @@
+package ast
+
+import (
+	"sync"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/pkg/slab"
+)
+
+// Arena allocates AST nodes in chunks instead of allocating every node separately.
+// Nodes allocated by Arena are valid until Release is called.
+//
+// Methods of nil Arena allocate nodes on heap like the package-level constructors.
+type Arena struct {
+	texts      slab.Slab[TextNode]
+	entries    slab.Slab[MappingEntryNode]
+	contents   slab.Slab[ContentNode]
+	properties slab.Slab[PropertiesNode]
+	sequences  slab.Slab[SequenceNode]
+	mappings   slab.Slab[MappingNode]
+	tags       slab.Slab[TagNode]
+	anchors    slab.Slab[AnchorNode]
+	aliases    slab.Slab[AliasNode]
+	indents    slab.Slab[IndentNode]
+
+	nodeSlices nodeSlices
+}
+
+var arenaPool = sync.Pool{
+	New: func() any { return new(Arena) },
+}
+
+// NewArena returns an empty Arena.
+func NewArena() *Arena {
+	return arenaPool.Get().(*Arena) // nolint: forcetypeassert
+}
+
+// Release frees all nodes allocated by the arena and makes the arena reusable.
+// Neither the arena nor its nodes must be used after Release.
+func (a *Arena) Release() {
+	if a == nil {
+		return
+	}
+	a.texts.Reset()
+	a.entries.Reset()
+	a.contents.Reset()
+	a.properties.Reset()
+	a.sequences.Reset()
+	a.mappings.Reset()
+	a.tags.Reset()
+	a.anchors.Reset()
+	a.aliases.Reset()
+	a.indents.Reset()
+	a.nodeSlices.reset()
+	arenaPool.Put(a)
+}
+
+// NewTextNode is an arena version of NewTextNode.
+func (a *Arena) NewTextNode(text string, opts ...TextNodeOption) *TextNode {
+	if a == nil {
+		return NewTextNode(text, opts...)
+	}
+	node := a.texts.Alloc()
+	node.text = text
+	for _, opt := range opts {
+		opt.apply(node)
+	}
+	return node
+}
+
+// NewMappingEntryNode is an arena version of NewMappingEntryNode.
+func (a *Arena) NewMappingEntryNode(key, value Node) *MappingEntryNode {
+	if a == nil {
+		return NewMappingEntryNode(key, value)
+	}
+	node := a.entries.Alloc()
+	node.key, node.value = key, value
+	return node
+}
+
+// NewContentNode is an arena version of NewContentNode.
+func (a *Arena) NewContentNode(properties, content Node) *ContentNode {
+	if a == nil {
+		return NewContentNode(properties, content)
+	}
+	node := a.contents.Alloc()
+	node.properties, node.content = properties, content
+	return node
+}
+
+// NewPropertiesNode is an arena version of NewPropertiesNode.
+func (a *Arena) NewPropertiesNode(tag, anchor Node) *PropertiesNode {
+	if a == nil {
+		return NewPropertiesNode(tag, anchor)
+	}
+	node := a.properties.Alloc()
+	node.tag, node.anchor = tag, anchor
+	return node
+}
+
+// NewSequenceNode is an arena version of NewSequenceNode.
+func (a *Arena) NewSequenceNode(entries []Node) *SequenceNode {
+	if a == nil {
+		return NewSequenceNode(entries)
+	}
+	node := a.sequences.Alloc()
+	node.entries = entries
+	return node
+}
+
+// NewMappingNode is an arena version of NewMappingNode.
+func (a *Arena) NewMappingNode(entries []Node) *MappingNode {
+	if a == nil {
+		return NewMappingNode(entries)
+	}
+	node := a.mappings.Alloc()
+	node.entries = entries
+	return node
+}
+
+// NewTagNode is an arena version of NewTagNode.
+func (a *Arena) NewTagNode(text string, opts ...TagNodeOption) *TagNode {
+	if a == nil {
+		return NewTagNode(text, opts...)
+	}
+	node := a.tags.Alloc()
+	node.text = text
+	for _, opt := range opts {
+		opt.apply(node)
+	}
+	return node
+}
+
+// NewAnchorNode is an arena version of NewAnchorNode.
+func (a *Arena) NewAnchorNode(text string) *AnchorNode {
+	if a == nil {
+		return NewAnchorNode(text)
+	}
+	node := a.anchors.Alloc()
+	node.text = text
+	return node
+}
+
+// NewAliasNode is an arena version of NewAliasNode.
+func (a *Arena) NewAliasNode(text string) *AliasNode {
+	if a == nil {
+		return NewAliasNode(text)
+	}
+	node := a.aliases.Alloc()
+	node.text = text
+	return node
+}
+
+// NewIndentNode is an arena version of NewIndentNode.
+func (a *Arena) NewIndentNode(indent int) *IndentNode {
+	if a == nil {
+		return NewIndentNode(indent)
+	}
+	node := a.indents.Alloc()
+	node.indent = indent
+	return node
+}
+
+// AppendNode is an arena version of builtin append for entries of collection nodes.
+func (a *Arena) AppendNode(entries []Node, n Node) []Node {
+	if a == nil {
+		return append(entries, n)
+	}
+	return a.nodeSlices.append(entries, n)
+}
+
+// AppendSequenceEntry is an arena version of SequenceNode.AppendEntry.
+func (a *Arena) AppendSequenceEntry(s *SequenceNode, n Node) {
+	if a == nil {
+		s.AppendEntry(n)
+		return
+	}
+	s.entries = a.nodeSlices.append(s.entries, n)
+}
+
+// AppendMappingEntry is an arena version of MappingNode.AppendEntry.
+func (a *Arena) AppendMappingEntry(m *MappingNode, n Node) {
+	if a == nil {
+		m.AppendEntry(n)
+		return
+	}
+	m.entries = a.nodeSlices.append(m.entries, n)
+}
+
+const (
+	minNodeSliceCapacity  = 4
+	minNodeSlicesChunkLen = 64
+	maxNodeSlicesChunkLen = 4096
+)
+
+// nodeSlices allocates backing arrays for entries of collection nodes.
+type nodeSlices struct {
+	chunks [][]Node
+	// chunk is the index of the current chunk
+	chunk int
+	// offset is the beginning of unused part of the current chunk
+	offset int
+}
+
+// append works like builtin append, but allocates new backing array from the chunks.
+func (s *nodeSlices) append(entries []Node, n Node) []Node {
+	if len(entries) == cap(entries) {
+		grown := s.alloc(max(2*cap(entries), minNodeSliceCapacity))
+		entries = append(grown, entries...)
+	}
+	return append(entries, n)
+}
+
+// alloc returns empty slice with given capacity. Capacity of the slice is limited,
+// so appending beyond it does not overwrite other slices.
+func (s *nodeSlices) alloc(capacity int) []Node {
+	for s.chunk < len(s.chunks) {
+		if c := s.chunks[s.chunk]; len(c)-s.offset >= capacity {
+			result := c[s.offset : s.offset : s.offset+capacity]
+			s.offset += capacity
+			return result
+		}
+		s.chunk++
+		s.offset = 0
+	}
+	size := minNodeSlicesChunkLen
+	if n := len(s.chunks); n > 0 {
+		size = min(2*len(s.chunks[n-1]), maxNodeSlicesChunkLen)
+	}
+	size = max(size, capacity)
+	s.chunks = append(s.chunks, make([]Node, size))
+	s.offset = capacity
+	return s.chunks[s.chunk][:0:capacity]
+}
+
+func (s *nodeSlices) reset() {
+	for i := 0; i <= s.chunk && i < len(s.chunks); i++ {
+		clear(s.chunks[i])
+	}
+	s.chunk = 0
+	s.offset = 0
+}
@@ -0,0 +1,90 @@
+// Package astalias contains functions to bind YAML aliases to the anchored nodes.
+package astalias
+
+import (
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/decode"
+	"github.com/KSpaceer/yamly/engines/yayamls/encode"
+)
+
+// Bind traverses AST in document order and returns the anchored node for every alias node.
+// Anchors are scoped by documents, so an alias can refer only to an anchor of its own document.
+// An error is returned if some alias refers to an unknown anchor.
+func Bind(root ast.Node) (map[*ast.AliasNode]ast.Node, error) {
+	b := aliasBinder{
+		anchors: decode.NewAnchorsKeeper(),
+		aliases: map[*ast.AliasNode]ast.Node{},
+	}
+	b.visit(root)
+	return b.aliases, b.err
+}
+
+// aliasBinder traverses AST in document order and binds every alias
+// to the node of the latest anchor with the same name.
+type aliasBinder struct {
+	anchors encode.AnchorsKeeper
+	aliases map[*ast.AliasNode]ast.Node
+	err     error
+}
+
+func (b *aliasBinder) visit(n ast.Node) {
+	if ast.ValidNode(n) {
+		n.Accept(b)
+	}
+}
+
+func (b *aliasBinder) VisitStreamNode(n *ast.StreamNode) {
+	for _, doc := range n.Documents() {
+		// anchors of previous documents can't be referred to
+		b.anchors = decode.NewAnchorsKeeper()
+		b.visit(doc)
+	}
+}
+
+func (*aliasBinder) VisitTagNode(*ast.TagNode) {}
+
+func (b *aliasBinder) VisitAnchorNode(n *ast.AnchorNode) {
+	b.anchors.StoreAnchor(n.Text())
+}
+
+func (b *aliasBinder) VisitAliasNode(n *ast.AliasNode) {
+	anchored, err := b.anchors.DereferenceAlias(n.Text())
+	if err != nil {
+		if b.err == nil {
+			b.err = err
+		}
+		return
+	}
+	b.aliases[n] = anchored
+}
+
+func (*aliasBinder) VisitTextNode(*ast.TextNode) {}
+
+func (b *aliasBinder) VisitSequenceNode(n *ast.SequenceNode) {
+	for _, entry := range n.Entries() {
+		b.visit(entry)
+	}
+}
+
+func (b *aliasBinder) VisitMappingNode(n *ast.MappingNode) {
+	for _, entry := range n.Entries() {
+		b.visit(entry)
+	}
+}
+
+func (b *aliasBinder) VisitMappingEntryNode(n *ast.MappingEntryNode) {
+	b.visit(n.Key())
+	b.visit(n.Value())
+}
+
+func (*aliasBinder) VisitNullNode(*ast.NullNode) {}
+
+func (b *aliasBinder) VisitPropertiesNode(n *ast.PropertiesNode) {
+	b.visit(n.Anchor())
+}
+
+func (b *aliasBinder) VisitContentNode(n *ast.ContentNode) {
+	b.visit(n.Properties())
+	b.anchors.BindToLatestAnchor(n)
+	b.visit(n.Content())
+}
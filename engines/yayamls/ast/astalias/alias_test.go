@@ -0,0 +1,83 @@
+package astalias_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astalias"
+	"github.com/KSpaceer/yamly/engines/yayamls/decode"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+)
+
+func TestBind(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name      string
+		src       string
+		expected  map[string]string
+		expectErr bool
+	}
+
+	tcases := []tcase{
+		{
+			name:     "single document",
+			src:      "a: &x 1\nb: *x\n",
+			expected: map[string]string{"x": "1"},
+		},
+		{
+			name:     "redefined anchor",
+			src:      "- &x 1\n- &x 2\n- *x\n",
+			expected: map[string]string{"x": "2"},
+		},
+		{
+			name:     "anchors of several documents",
+			src:      "--- &x 1\n--- [&x 2, *x]\n",
+			expected: map[string]string{"x": "2"},
+		},
+		{
+			name:      "alias to anchor of previous document",
+			src:       "--- &x 1\n--- *x\n",
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, err := parser.ParseString(tc.src)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+
+			aliases, err := astalias.Bind(tree)
+			if tc.expectErr {
+				var dereferenceErr decode.AliasDereferenceError
+				if !errors.As(err, &dereferenceErr) {
+					t.Fatalf("expected alias dereference error, but got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for alias, anchored := range aliases {
+				content, ok := anchored.(*ast.ContentNode)
+				if !ok {
+					t.Fatalf("expected content node for alias %q, but got %s", alias.Text(), anchored.Type())
+				}
+				txt, ok := content.Content().(*ast.TextNode)
+				if !ok || txt.Text() != tc.expected[alias.Text()] {
+					t.Errorf("expected alias %q to refer to %q", alias.Text(), tc.expected[alias.Text()])
+				}
+			}
+			if len(aliases) == 0 {
+				t.Errorf("no aliases are bound")
+			}
+		})
+	}
+}
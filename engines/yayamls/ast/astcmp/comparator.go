@@ -2,14 +2,105 @@
 package astcmp
 
 import (
+	"strconv"
+	"sync"
+
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astalias"
+	"github.com/KSpaceer/yamly/engines/yayamls/schema"
 )
 
+type options struct {
+	unorderedMappings bool
+	resolveAliases    bool
+	resolveScalars    bool
+	ignoreQuoting     bool
+	yaml11Booleans    bool
+	styles            bool
+}
+
+// semantic shows if any option changing structural comparison is set.
+func (o options) semantic() bool {
+	o.styles = false
+	return o != options{}
+}
+
+type ComparatorOption func(*options)
+
+// WithUnorderedMappings makes Comparator ignore the order of mapping entries.
+func WithUnorderedMappings() ComparatorOption {
+	return func(o *options) {
+		o.unorderedMappings = true
+	}
+}
+
+// WithResolvedAliases makes Comparator compare aliases as the nodes they refer to.
+// Anchors are ignored in this mode, so an alias and its expanded form are equal.
+func WithResolvedAliases() ComparatorOption {
+	return func(o *options) {
+		o.resolveAliases = true
+	}
+}
+
+// WithResolvedScalars makes Comparator compare plain scalars by their resolved values
+// (e.g. 0x10 equals 16, ~ equals null) instead of their texts.
+func WithResolvedScalars() ComparatorOption {
+	return func(o *options) {
+		o.resolveScalars = true
+	}
+}
+
+// WithIgnoreQuoting makes Comparator resolve quoted scalars the same way as plain ones,
+// so '16' equals 16 when scalars are resolved. Quoting style itself is never compared.
+func WithIgnoreQuoting() ComparatorOption {
+	return func(o *options) {
+		o.ignoreQuoting = true
+	}
+}
+
+// WithYAML11Booleans makes Comparator treat YAML 1.1 booleans (yes, no, on, off, y, n)
+// as boolean values when scalars are resolved.
+func WithYAML11Booleans() ComparatorOption {
+	return func(o *options) {
+		o.yaml11Booleans = true
+	}
+}
+
+// WithStyles makes Comparator compare presentation styles of nodes: quoting of scalars,
+// chomping of block scalars and whether null is written as an empty node.
+func WithStyles() ComparatorOption {
+	return func(o *options) {
+		o.styles = true
+	}
+}
+
 // Comparator implements AST comparing logic.
-type Comparator struct{}
+type Comparator struct {
+	opts options
+}
 
-func NewComparator() *Comparator {
-	return &Comparator{}
+func NewComparator(opts ...ComparatorOption) *Comparator {
+	c := &Comparator{}
+	for _, opt := range opts {
+		opt(&c.opts)
+	}
+	return c
+}
+
+type nodePair struct {
+	first, second ast.Node
+}
+
+type walkState struct {
+	stack []nodePair
+
+	firstAliases, secondAliases map[*ast.AliasNode]ast.Node
+}
+
+var walkStatePool = sync.Pool{
+	New: func() any {
+		return &walkState{stack: make([]nodePair, 0, 64)}
+	},
 }
 
 // Equal shows if two YAML ASTs are equal.
@@ -17,130 +108,346 @@ func (c *Comparator) Equal(first, second ast.Node) bool {
 	if first == nil || second == nil {
 		return first == nil && second == nil
 	}
-	firstCh, secondCh := make(chan ast.Node), make(chan ast.Node)
-	firstVisitor := &comparingVisitor{firstCh}
-	secondVisitor := &comparingVisitor{secondCh}
-	go func() {
-		first.Accept(firstVisitor)
-		close(firstCh)
-	}()
-	go func() {
-		second.Accept(secondVisitor)
-		close(secondCh)
+
+	st := walkStatePool.Get().(*walkState) // nolint: forcetypeassert
+	defer func() {
+		clear(st.stack[:cap(st.stack)])
+		st.stack = st.stack[:0]
+		st.firstAliases, st.secondAliases = nil, nil
+		walkStatePool.Put(st)
 	}()
 
-	result := true
-	for {
-		firstNode, firstOk := <-firstCh
-		secondNode, secondOk := <-secondCh
-		if !firstOk && !secondOk {
-			break
+	if c.opts.resolveAliases {
+		var err error
+		if st.firstAliases, err = astalias.Bind(first); err != nil {
+			return false
 		}
-		if !c.compareNodes(firstNode, secondNode) {
-			result = false
-			break
+		if st.secondAliases, err = astalias.Bind(second); err != nil {
+			return false
 		}
+		dropRecursiveAliases(st.firstAliases)
+		dropRecursiveAliases(st.secondAliases)
 	}
-	for range firstCh { // nolint: revive
+
+	return c.walk(st, first, second)
+}
+
+// walk compares nodes iteratively, pushing pairs of children to the stack of state.
+func (c *Comparator) walk(st *walkState, first, second ast.Node) bool {
+	base := len(st.stack)
+	st.stack = append(st.stack, nodePair{first, second})
+
+	for len(st.stack) > base {
+		p := st.stack[len(st.stack)-1]
+		st.stack = st.stack[:len(st.stack)-1]
+
+		f, s := c.normalize(p.first, st.firstAliases), c.normalize(p.second, st.secondAliases)
+		if !c.equalNodes(st, f, s) {
+			st.stack = st.stack[:base]
+			return false
+		}
+	}
+	return true
+}
+
+// normalize resolves aliases and removes properties wrappers not taking part in comparison.
+func (c *Comparator) normalize(n ast.Node, aliases map[*ast.AliasNode]ast.Node) ast.Node {
+	if !c.opts.semantic() {
+		return n
 	}
-	for range secondCh { // nolint: revive
+	for {
+		switch v := n.(type) {
+		case *ast.AliasNode:
+			anchored, ok := aliases[v]
+			if !ok {
+				return n
+			}
+			n = anchored
+		case *ast.ContentNode:
+			if tag, _ := properties(v.Properties()); tag != "" || (!c.opts.resolveAliases && hasAnchor(v.Properties())) {
+				return n
+			}
+			n = v.Content()
+		default:
+			return n
+		}
 	}
-	return result
 }
 
-func (c *Comparator) compareNodes(first, second ast.Node) bool {
-	if first == nil || second == nil {
+func (c *Comparator) equalNodes(st *walkState, first, second ast.Node) bool {
+	firstAbsent, secondAbsent := absent(first), absent(second)
+	if firstAbsent || secondAbsent {
+		return firstAbsent == secondAbsent
+	}
+
+	if c.opts.styles && !sameStyle(first, second) {
 		return false
 	}
+
+	if c.opts.resolveScalars && isScalar(first) && isScalar(second) {
+		return c.resolveScalar(first) == c.resolveScalar(second)
+	}
+
 	if first.Type() != second.Type() {
 		return false
 	}
 
 	switch f := first.(type) {
+	case *ast.StreamNode:
+		return pushEntries(st, f.Documents(), second.(*ast.StreamNode).Documents()) // nolint: forcetypeassert
+	case *ast.ContentNode:
+		s := second.(*ast.ContentNode) // nolint: forcetypeassert
+		st.stack = append(st.stack, nodePair{f.Content(), s.Content()}, nodePair{f.Properties(), s.Properties()})
+	case *ast.PropertiesNode:
+		s := second.(*ast.PropertiesNode) // nolint: forcetypeassert
+		st.stack = append(st.stack, nodePair{f.Tag(), s.Tag()})
+		if !c.opts.resolveAliases {
+			st.stack = append(st.stack, nodePair{f.Anchor(), s.Anchor()})
+		}
+	case *ast.SequenceNode:
+		return pushEntries(st, f.Entries(), second.(*ast.SequenceNode).Entries()) // nolint: forcetypeassert
+	case *ast.MappingNode:
+		s := second.(*ast.MappingNode) // nolint: forcetypeassert
+		if c.opts.unorderedMappings {
+			return c.pushUnorderedEntries(st, f.Entries(), s.Entries())
+		}
+		return pushEntries(st, f.Entries(), s.Entries())
+	case *ast.MappingEntryNode:
+		s := second.(*ast.MappingEntryNode) // nolint: forcetypeassert
+		st.stack = append(st.stack, nodePair{f.Value(), s.Value()}, nodePair{f.Key(), s.Key()})
+	case *ast.TagNode:
+		s := second.(*ast.TagNode) // nolint: forcetypeassert
+		return f.Text() == s.Text() && f.Handle() == s.Handle() && f.Verbatim() == s.Verbatim()
 	case ast.Texter:
 		if sf, ok := second.(ast.Texter); ok {
 			return f.Text() == sf.Text()
 		}
 		return false
-	default:
-		return true
 	}
+	return true
 }
 
-type comparingVisitor struct {
-	cmpChan chan<- ast.Node
+// pushEntries pushes pairs of entries to the stack in reversed order,
+// so the entries are compared in document order.
+func pushEntries(st *walkState, first, second []ast.Node) bool {
+	first, second = presentNodes(first), presentNodes(second)
+	if len(first) != len(second) {
+		return false
+	}
+	for i := len(first) - 1; i >= 0; i-- {
+		st.stack = append(st.stack, nodePair{first[i], second[i]})
+	}
+	return true
 }
 
-func (t *comparingVisitor) VisitStreamNode(n *ast.StreamNode) {
-	t.cmpChan <- n
-	for _, doc := range n.Documents() {
-		doc.Accept(t)
+// pushUnorderedEntries matches entries of two mappings by keys and pushes pairs of matched values.
+func (c *Comparator) pushUnorderedEntries(st *walkState, first, second []ast.Node) bool {
+	first, second = presentNodes(first), presentNodes(second)
+	if len(first) != len(second) {
+		return false
 	}
-}
 
-func (t *comparingVisitor) VisitTagNode(n *ast.TagNode) {
-	t.cmpChan <- n
+	matched := make([]bool, len(second))
+	pairs := make([]nodePair, 0, len(first))
+	for _, fe := range first {
+		fEntry, ok := fe.(*ast.MappingEntryNode)
+		if !ok {
+			return false
+		}
+		found := false
+		for j, se := range second {
+			sEntry, ok := se.(*ast.MappingEntryNode)
+			if !ok || matched[j] {
+				continue
+			}
+			if c.walk(st, fEntry.Key(), sEntry.Key()) {
+				matched[j] = true
+				found = true
+				pairs = append(pairs, nodePair{fEntry.Value(), sEntry.Value()})
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	st.stack = append(st.stack, pairs...)
+	return true
 }
 
-func (t *comparingVisitor) VisitAnchorNode(n *ast.AnchorNode) {
-	t.cmpChan <- n
+// absent shows if node doesn't take part in comparison,
+// i.e. it is nil or it is not accepted by visitors.
+func absent(n ast.Node) bool {
+	switch n.(type) {
+	case nil, *ast.BasicNode, *ast.IndentNode, *ast.BlockHeaderNode:
+		return true
+	default:
+		return false
+	}
 }
 
-func (t *comparingVisitor) VisitAliasNode(n *ast.AliasNode) {
-	t.cmpChan <- n
+func presentNodes(nodes []ast.Node) []ast.Node {
+	for i, n := range nodes {
+		if !absent(n) {
+			continue
+		}
+		result := make([]ast.Node, i, len(nodes))
+		copy(result, nodes[:i])
+		for _, rest := range nodes[i+1:] {
+			if !absent(rest) {
+				result = append(result, rest)
+			}
+		}
+		return result
+	}
+	return nodes
 }
 
-func (t *comparingVisitor) VisitTextNode(n *ast.TextNode) {
-	t.cmpChan <- n
+// sameStyle checks if scalars have the same presentation style. Nodes of other types are not checked.
+func sameStyle(first, second ast.Node) bool {
+	switch f := first.(type) {
+	case *ast.TextNode:
+		s, ok := second.(*ast.TextNode)
+		if !ok {
+			return true
+		}
+		return plainQuoting(f.QuotingType()) == plainQuoting(s.QuotingType()) && f.ChompingType() == s.ChompingType()
+	case *ast.NullNode:
+		s, ok := second.(*ast.NullNode)
+		return !ok || f.Implicit() == s.Implicit()
+	default:
+		return true
+	}
 }
 
-func (t *comparingVisitor) VisitContentNode(n *ast.ContentNode) {
-	t.cmpChan <- n
-	properties, content := n.Properties(), n.Content()
-	if properties != nil {
-		properties.Accept(t)
+// plainQuoting treats unknown quoting as absent, since both of them are written as plain scalars.
+func plainQuoting(q ast.QuotingType) ast.QuotingType {
+	if q == ast.UnknownQuotingType {
+		return ast.AbsentQuotingType
 	}
-	if content != nil {
-		content.Accept(t)
+	return q
+}
+
+func isScalar(n ast.Node) bool {
+	switch n.(type) {
+	case *ast.TextNode, *ast.NullNode:
+		return true
+	default:
+		return false
 	}
 }
 
-func (t *comparingVisitor) VisitSequenceNode(n *ast.SequenceNode) {
-	t.cmpChan <- n
-	for _, entry := range n.Entries() {
-		entry.Accept(t)
+type resolvedScalar struct {
+	kind  string
+	value string
+}
+
+// resolveScalar derives type and canonical value of scalar node.
+func (c *Comparator) resolveScalar(n ast.Node) resolvedScalar {
+	txtNode, ok := n.(*ast.TextNode)
+	if !ok {
+		return resolvedScalar{kind: "null"}
+	}
+	txt := txtNode.Text()
+	switch txtNode.QuotingType() {
+	case ast.SingleQuotingType, ast.DoubleQuotingType, ast.LiteralQuotingType, ast.FoldedQuotingType:
+		if !c.opts.ignoreQuoting {
+			return resolvedScalar{kind: "str", value: txt}
+		}
+		if txt == "" {
+			// empty plain scalar is null, but empty quoted string is not
+			return resolvedScalar{kind: "str"}
+		}
+		n = ast.NewTextNode(txt)
 	}
+
+	switch {
+	case schema.IsNull(n):
+		return resolvedScalar{kind: "null"}
+	case schema.IsBoolean(n):
+		v, _ := schema.ToBoolean(txt)
+		return resolvedScalar{kind: "bool", value: strconv.FormatBool(v)}
+	case schema.IsInteger(n):
+		if v, err := schema.ToInteger(txt, 64); err == nil {
+			return resolvedScalar{kind: "int", value: strconv.FormatInt(v, 10)}
+		}
+	case schema.IsFloat(n):
+		if v, err := schema.ToFloat(txt, 64); err == nil {
+			return resolvedScalar{kind: "float", value: strconv.FormatFloat(v, 'g', -1, 64)}
+		}
+	}
+	if c.opts.yaml11Booleans {
+		if v, ok := yaml11Boolean(txt); ok {
+			return resolvedScalar{kind: "bool", value: strconv.FormatBool(v)}
+		}
+	}
+	return resolvedScalar{kind: "str", value: txt}
 }
 
-func (t *comparingVisitor) VisitMappingNode(n *ast.MappingNode) {
-	t.cmpChan <- n
-	for _, entry := range n.Entries() {
-		entry.Accept(t)
+func yaml11Boolean(s string) (v, ok bool) {
+	switch s {
+	case "y", "Y", "yes", "Yes", "YES", "on", "On", "ON":
+		return true, true
+	case "n", "N", "no", "No", "NO", "off", "Off", "OFF":
+		return false, true
+	default:
+		return false, false
 	}
 }
 
-func (t *comparingVisitor) VisitMappingEntryNode(n *ast.MappingEntryNode) {
-	t.cmpChan <- n
-	key, value := n.Key(), n.Value()
-	if key != nil {
-		key.Accept(t)
+func properties(n ast.Node) (tag, anchor string) {
+	props, ok := n.(*ast.PropertiesNode)
+	if !ok {
+		return "", ""
+	}
+	if t, ok := props.Tag().(*ast.TagNode); ok {
+		tag = t.Text()
 	}
-	if value != nil {
-		value.Accept(t)
+	if a, ok := props.Anchor().(*ast.AnchorNode); ok {
+		anchor = a.Text()
 	}
+	return tag, anchor
 }
 
-func (t *comparingVisitor) VisitNullNode(n *ast.NullNode) {
-	t.cmpChan <- n
+func hasAnchor(n ast.Node) bool {
+	_, anchor := properties(n)
+	return anchor != ""
 }
 
-func (t *comparingVisitor) VisitPropertiesNode(n *ast.PropertiesNode) {
-	t.cmpChan <- n
-	anchor, tag := n.Anchor(), n.Tag()
-	if anchor != nil {
-		anchor.Accept(t)
+// dropRecursiveAliases removes aliases placed inside the nodes they refer to.
+// Such aliases are compared by names to avoid infinite walk.
+func dropRecursiveAliases(aliases map[*ast.AliasNode]ast.Node) {
+	checked := make(map[ast.Node]struct{}, len(aliases))
+	for _, anchored := range aliases {
+		if _, ok := checked[anchored]; ok {
+			continue
+		}
+		checked[anchored] = struct{}{}
+		for _, alias := range nestedAliases(anchored, nil) {
+			if aliases[alias] == anchored {
+				delete(aliases, alias)
+			}
+		}
 	}
-	if tag != nil {
-		tag.Accept(t)
+}
+
+func nestedAliases(n ast.Node, dst []*ast.AliasNode) []*ast.AliasNode {
+	switch v := n.(type) {
+	case *ast.AliasNode:
+		dst = append(dst, v)
+	case *ast.ContentNode:
+		dst = nestedAliases(v.Content(), dst)
+	case *ast.SequenceNode:
+		for _, entry := range v.Entries() {
+			dst = nestedAliases(entry, dst)
+		}
+	case *ast.MappingNode:
+		for _, entry := range v.Entries() {
+			dst = nestedAliases(entry, dst)
+		}
+	case *ast.MappingEntryNode:
+		dst = nestedAliases(v.Key(), dst)
+		dst = nestedAliases(v.Value(), dst)
 	}
+	return dst
 }
@@ -0,0 +1,233 @@
+package astcmp_test
+
+import (
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astcmp"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+)
+
+func TestComparator_Equal(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		first    string
+		second   string
+		opts     []astcmp.ComparatorOption
+		expected bool
+	}
+
+	tcases := []tcase{
+		{
+			name:     "equal",
+			first:    "a: 1\nb: [x, y]\n",
+			second:   "a: 1\nb:\n  - x\n  - y\n",
+			expected: true,
+		},
+		{
+			name:     "different scalars",
+			first:    "a: 1\n",
+			second:   "a: 2\n",
+			expected: false,
+		},
+		{
+			name:     "different nesting",
+			first:    "- [a]\n- b\n",
+			second:   "- [a, b]\n",
+			expected: false,
+		},
+		{
+			name:     "different key order",
+			first:    "{a: 1, b: 2}",
+			second:   "{b: 2, a: 1}",
+			expected: false,
+		},
+		{
+			name:     "unordered mappings",
+			first:    "{a: 1, b: 2}",
+			second:   "{b: 2, a: 1}",
+			opts:     []astcmp.ComparatorOption{astcmp.WithUnorderedMappings()},
+			expected: true,
+		},
+		{
+			name:     "unordered mappings with different values",
+			first:    "{a: 1, b: 2}",
+			second:   "{b: 1, a: 2}",
+			opts:     []astcmp.ComparatorOption{astcmp.WithUnorderedMappings()},
+			expected: false,
+		},
+		{
+			name:     "alias and expanded form",
+			first:    "base: &base {x: 1}\nderived: *base\n",
+			second:   "base: {x: 1}\nderived: {x: 1}\n",
+			expected: false,
+		},
+		{
+			name:     "resolved aliases",
+			first:    "base: &base {x: 1}\nderived: *base\n",
+			second:   "base: {x: 1}\nderived: {x: 1}\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedAliases()},
+			expected: true,
+		},
+		{
+			name:     "recursive alias",
+			first:    "&a [*a]",
+			second:   "&a [*a]",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedAliases()},
+			expected: true,
+		},
+		{
+			name:     "different integer forms",
+			first:    "a: 0x10\n",
+			second:   "a: 16\n",
+			expected: false,
+		},
+		{
+			name:     "resolved integers",
+			first:    "a: 0x10\n",
+			second:   "a: 16\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedScalars()},
+			expected: true,
+		},
+		{
+			name:     "resolved nulls",
+			first:    "a:\nb: ~\nc: null\n",
+			second:   "a: Null\nb: NULL\nc: ~\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedScalars()},
+			expected: true,
+		},
+		{
+			name:     "integer and float",
+			first:    "a: 1\n",
+			second:   "a: 1.0\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedScalars()},
+			expected: false,
+		},
+		{
+			name:     "quoted scalar is string",
+			first:    "a: '16'\n",
+			second:   "a: 16\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedScalars()},
+			expected: false,
+		},
+		{
+			name:     "ignore quoting",
+			first:    "a: '0x10'\n",
+			second:   "a: 16\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedScalars(), astcmp.WithIgnoreQuoting()},
+			expected: true,
+		},
+		{
+			name:     "YAML 1.1 booleans",
+			first:    "[yes, off]",
+			second:   "[true, false]",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedScalars(), astcmp.WithYAML11Booleans()},
+			expected: true,
+		},
+		{
+			name:     "YAML 1.2 booleans",
+			first:    "[yes, off]",
+			second:   "[true, false]",
+			opts:     []astcmp.ComparatorOption{astcmp.WithResolvedScalars()},
+			expected: false,
+		},
+		{
+			name:     "different tag handles",
+			first:    "a: !foo x\n",
+			second:   "a: !!foo x\n",
+			expected: false,
+		},
+		{
+			name:     "verbatim and shorthand tags",
+			first:    "a: !<tag:x> x\n",
+			second:   "a: !tag:x x\n",
+			expected: false,
+		},
+		{
+			name:     "different quoting",
+			first:    "a: 'x'\nb: \"y\"\n",
+			second:   "a: x\nb: y\n",
+			expected: true,
+		},
+		{
+			name:     "styles",
+			first:    "a: 'x'\nb: \"y\"\n",
+			second:   "a: x\nb: y\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithStyles()},
+			expected: false,
+		},
+		{
+			name:     "styles of block scalars",
+			first:    "a: |\n  x\n",
+			second:   "a: |+\n  x\n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithStyles()},
+			expected: false,
+		},
+		{
+			name:     "same styles",
+			first:    "a: 'x'\nb: >-\n  y\nc:\n",
+			second:   "a:   'x'\nb: >-\n      y\nc:   \n",
+			opts:     []astcmp.ComparatorOption{astcmp.WithStyles()},
+			expected: true,
+		},
+		{
+			name:   "all semantic options",
+			first:  "defaults: &d {port: 0x50, debug: on}\nservice: *d\n",
+			second: "service: {debug: true, port: 80}\ndefaults: {port: '80', debug: yes}\n",
+			opts: []astcmp.ComparatorOption{
+				astcmp.WithUnorderedMappings(),
+				astcmp.WithResolvedAliases(),
+				astcmp.WithResolvedScalars(),
+				astcmp.WithIgnoreQuoting(),
+				astcmp.WithYAML11Booleans(),
+			},
+			expected: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			first, err := parser.ParseString(tc.first)
+			if err != nil {
+				t.Fatalf("failed to parse first source: %v", err)
+			}
+			second, err := parser.ParseString(tc.second)
+			if err != nil {
+				t.Fatalf("failed to parse second source: %v", err)
+			}
+
+			cmp := astcmp.NewComparator(tc.opts...)
+			if result := cmp.Equal(first, second); result != tc.expected {
+				t.Errorf("expected %t, but got %t", tc.expected, result)
+			}
+			if result := cmp.Equal(second, first); result != tc.expected {
+				t.Errorf("expected %t for swapped arguments, but got %t", tc.expected, result)
+			}
+		})
+	}
+}
+
+func BenchmarkComparator_Equal(b *testing.B) {
+	src := "a: 1\nb: [x, y, {c: d, e: [f, g]}]\nh: {i: j, k: l}\n"
+	first, err := parser.ParseString(src)
+	if err != nil {
+		b.Fatalf("failed to parse source: %v", err)
+	}
+	second, err := parser.ParseString(src)
+	if err != nil {
+		b.Fatalf("failed to parse source: %v", err)
+	}
+
+	cmp := astcmp.NewComparator()
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !cmp.Equal(first, second) {
+			b.Fatal("expected equal ASTs")
+		}
+	}
+}
@@ -0,0 +1,511 @@
+// Package astdiff contains types and methods to find structural differences between YAML ASTs.
+//
+// Every change is located by the number of document in the stream and by yamlpath expression
+// pointing to the changed node within the document. If positions of nodes recorded by parser
+// are provided with WithPositions option, changes also contain line and column of the nodes in sources.
+package astdiff
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astalias"
+	"github.com/KSpaceer/yamly/engines/yayamls/token"
+)
+
+// ChangeType describes the kind of change.
+type ChangeType int8
+
+const (
+	UnknownChangeType ChangeType = iota
+	// Added means that node is present only in the second AST.
+	Added
+	// Removed means that node is present only in the first AST.
+	Removed
+	// Changed means that node is present in both ASTs, but has different value.
+	Changed
+	// Moved means that mapping entry is present in both ASTs, but has different position
+	// relative to other entries.
+	Moved
+)
+
+func (t ChangeType) String() string {
+	switch t {
+	case Added:
+		return "Added"
+	case Removed:
+		return "Removed"
+	case Changed:
+		return "Changed"
+	case Moved:
+		return "Moved"
+	default:
+		return "Unknown"
+	}
+}
+
+func (t ChangeType) symbol() string {
+	switch t {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	case Changed:
+		return "~"
+	case Moved:
+		return ">"
+	default:
+		return "?"
+	}
+}
+
+// Change is a single difference between two ASTs.
+type Change struct {
+	Type ChangeType
+	// Document is the index of document in stream.
+	Document int
+	// Path is yamlpath expression locating the node in the document.
+	Path string
+	// From is the node of the first AST. From is nil for added nodes.
+	From ast.Node
+	// To is the node of the second AST. To is nil for removed nodes.
+	To ast.Node
+	// FromPos is the position of the node in the first source. FromPos is zero for added nodes
+	// and if positions of the first AST are not provided.
+	FromPos token.Position
+	// ToPos is the position of the node in the second source. ToPos is zero for removed nodes
+	// and if positions of the second AST are not provided.
+	ToPos token.Position
+}
+
+// String returns human-readable representation of change.
+func (c Change) String() string {
+	var sb strings.Builder
+	sb.WriteString(c.Type.symbol())
+	sb.WriteByte(' ')
+	if c.Document > 0 {
+		sb.WriteString("document ")
+		sb.WriteString(strconv.Itoa(c.Document))
+		sb.WriteString(": ")
+	}
+	sb.WriteString(c.Path)
+	pos := c.FromPos
+	if c.Type == Added {
+		pos = c.ToPos
+	}
+	if pos.Row > 0 {
+		sb.WriteString(" (line ")
+		sb.WriteString(strconv.Itoa(pos.Row))
+		sb.WriteString(", column ")
+		sb.WriteString(strconv.Itoa(pos.Column))
+		sb.WriteByte(')')
+	}
+	switch c.Type {
+	case Added:
+		sb.WriteString(": ")
+		sb.WriteString(Render(c.To))
+	case Removed:
+		sb.WriteString(": ")
+		sb.WriteString(Render(c.From))
+	case Changed:
+		sb.WriteString(": ")
+		sb.WriteString(Render(c.From))
+		sb.WriteString(" => ")
+		sb.WriteString(Render(c.To))
+	}
+	return sb.String()
+}
+
+// Changes is a list of differences between two ASTs in document order.
+type Changes []Change
+
+// String returns human-readable representation of changes, one change per line.
+func (c Changes) String() string {
+	var sb strings.Builder
+	for _, change := range c {
+		sb.WriteString(change.String())
+		sb.WriteByte('\n')
+	}
+	return sb.String()
+}
+
+type options struct {
+	ignoreKeyOrder bool
+	ignoreQuoting  bool
+	resolveAliases bool
+	fromPositions  ast.Positions
+	toPositions    ast.Positions
+}
+
+type Option func(*options)
+
+// WithIgnoreKeyOrder makes Differ ignore the order of mapping entries.
+func WithIgnoreKeyOrder() Option {
+	return func(o *options) {
+		o.ignoreKeyOrder = true
+	}
+}
+
+// WithIgnoreQuoting makes Differ ignore quoting style of scalars.
+func WithIgnoreQuoting() Option {
+	return func(o *options) {
+		o.ignoreQuoting = true
+	}
+}
+
+// WithResolveAliases makes Differ compare aliases as the nodes they refer to.
+// Anchors are ignored in this mode, so an alias and its expanded form are considered equal.
+func WithResolveAliases() Option {
+	return func(o *options) {
+		o.resolveAliases = true
+	}
+}
+
+// WithPositions makes Differ locate changes in sources using positions of nodes
+// of the first and the second AST recorded by parser (see parser.WithPositions).
+// Any of the positions can be nil.
+func WithPositions(from, to ast.Positions) Option {
+	return func(o *options) {
+		o.fromPositions = from
+		o.toPositions = to
+	}
+}
+
+// Differ implements AST diffing logic.
+type Differ struct {
+	opts options
+}
+
+func NewDiffer(opts ...Option) *Differ {
+	d := &Differ{}
+	for _, opt := range opts {
+		opt(&d.opts)
+	}
+	return d
+}
+
+// Diff returns the list of changes transforming the first AST into the second one.
+func (d *Differ) Diff(from, to ast.Node) (Changes, error) {
+	s := diffState{opts: d.opts}
+	if d.opts.resolveAliases {
+		var err error
+		if s.fromAliases, err = astalias.Bind(from); err != nil {
+			return nil, err
+		}
+		if s.toAliases, err = astalias.Bind(to); err != nil {
+			return nil, err
+		}
+		s.inProgress = map[[2]ast.Node]struct{}{}
+	}
+
+	fromDocs, toDocs := documents(from), documents(to)
+	for i := 0; i < len(fromDocs) || i < len(toDocs); i++ {
+		s.document = i
+		switch {
+		case i >= len(toDocs):
+			s.report(Removed, "$", fromDocs[i], nil)
+		case i >= len(fromDocs):
+			s.report(Added, "$", nil, toDocs[i])
+		default:
+			s.diff("$", fromDocs[i], toDocs[i])
+		}
+	}
+	return s.changes, nil
+}
+
+func documents(n ast.Node) []ast.Node {
+	if !ast.ValidNode(n) {
+		return nil
+	}
+	if stream, ok := n.(*ast.StreamNode); ok {
+		return stream.Documents()
+	}
+	return []ast.Node{n}
+}
+
+type diffState struct {
+	opts options
+
+	fromAliases, toAliases map[*ast.AliasNode]ast.Node
+	// inProgress contains pairs of nodes being compared to stop on recursive aliases
+	inProgress map[[2]ast.Node]struct{}
+
+	document int
+	changes  Changes
+}
+
+func (s *diffState) report(tp ChangeType, path string, from, to ast.Node) {
+	s.reportAt(tp, path, from, to, from, to)
+}
+
+// reportAt reports the change located at given nodes, e.g. at mapping entries instead of their values,
+// because empty values don't have positions.
+func (s *diffState) reportAt(tp ChangeType, path string, from, to, fromAt, toAt ast.Node) {
+	change := Change{
+		Type:     tp,
+		Document: s.document,
+		Path:     path,
+		From:     from,
+		To:       to,
+	}
+	if from != nil {
+		change.FromPos, _ = s.opts.fromPositions.Find(fromAt)
+	}
+	if to != nil {
+		change.ToPos, _ = s.opts.toPositions.Find(toAt)
+	}
+	s.changes = append(s.changes, change)
+}
+
+func (s *diffState) resolve(n ast.Node, aliases map[*ast.AliasNode]ast.Node) ast.Node {
+	if alias, ok := n.(*ast.AliasNode); ok && s.opts.resolveAliases {
+		if anchored, ok := aliases[alias]; ok {
+			return anchored
+		}
+	}
+	return n
+}
+
+func (s *diffState) diff(path string, from, to ast.Node) {
+	from, to = s.resolve(from, s.fromAliases), s.resolve(to, s.toAliases)
+	if s.inProgress != nil {
+		pair := [2]ast.Node{from, to}
+		if _, ok := s.inProgress[pair]; ok {
+			return
+		}
+		s.inProgress[pair] = struct{}{}
+		defer delete(s.inProgress, pair)
+	}
+
+	fromProps, fromContent := splitContent(from)
+	toProps, toContent := splitContent(to)
+	if !s.equalProperties(fromProps, toProps) {
+		s.report(Changed, path, from, to)
+		return
+	}
+
+	switch f := fromContent.(type) {
+	case *ast.MappingNode:
+		if t, ok := toContent.(*ast.MappingNode); ok {
+			s.diffMappings(path, f, t)
+			return
+		}
+	case *ast.SequenceNode:
+		if t, ok := toContent.(*ast.SequenceNode); ok {
+			s.diffSequences(path, f, t)
+			return
+		}
+	}
+
+	if !s.equalScalars(fromContent, toContent) {
+		s.report(Changed, path, from, to)
+	}
+}
+
+func (s *diffState) diffMappings(path string, from, to *ast.MappingNode) {
+	fromEntries, toEntries := mappingEntries(from), mappingEntries(to)
+	toIndex := make(map[string]int, len(toEntries))
+	for i, entry := range toEntries {
+		toIndex[entry.key] = i
+	}
+	fromIndex := make(map[string]int, len(fromEntries))
+	for i, entry := range fromEntries {
+		fromIndex[entry.key] = i
+	}
+
+	var commonFrom, commonTo []int
+	for i, entry := range fromEntries {
+		j, ok := toIndex[entry.key]
+		if !ok {
+			s.reportAt(Removed, keyPath(path, entry.key), entry.node.Value(), nil, entry.node, nil)
+			continue
+		}
+		commonFrom = append(commonFrom, i)
+		commonTo = append(commonTo, j)
+		s.diff(keyPath(path, entry.key), entry.node.Value(), toEntries[j].node.Value())
+	}
+	for _, entry := range toEntries {
+		if _, ok := fromIndex[entry.key]; !ok {
+			s.reportAt(Added, keyPath(path, entry.key), nil, entry.node.Value(), nil, entry.node)
+		}
+	}
+
+	if s.opts.ignoreKeyOrder {
+		return
+	}
+	// entries of the longest increasing subsequence keep their relative order,
+	// all other common entries are considered moved
+	kept := longestIncreasing(commonTo)
+	for k, i := range commonFrom {
+		if _, ok := kept[k]; !ok {
+			fromEntry, toEntry := fromEntries[i], toEntries[commonTo[k]]
+			s.reportAt(Moved, keyPath(path, fromEntry.key), fromEntry.node.Value(), toEntry.node.Value(),
+				fromEntry.node, toEntry.node)
+		}
+	}
+}
+
+func (s *diffState) diffSequences(path string, from, to *ast.SequenceNode) {
+	fromEntries, toEntries := from.Entries(), to.Entries()
+	for i := 0; i < len(fromEntries) || i < len(toEntries); i++ {
+		elemPath := path + "[" + strconv.Itoa(i) + "]"
+		switch {
+		case i >= len(toEntries):
+			s.report(Removed, elemPath, fromEntries[i], nil)
+		case i >= len(fromEntries):
+			s.report(Added, elemPath, nil, toEntries[i])
+		default:
+			s.diff(elemPath, fromEntries[i], toEntries[i])
+		}
+	}
+}
+
+func (s *diffState) equalProperties(from, to ast.Node) bool {
+	fromTag, fromAnchor := properties(from)
+	toTag, toAnchor := properties(to)
+	if fromTag != toTag {
+		return false
+	}
+	return s.opts.resolveAliases || fromAnchor == toAnchor
+}
+
+func (s *diffState) equalScalars(from, to ast.Node) bool {
+	if from == nil || to == nil {
+		return from == nil && to == nil
+	}
+	if from.Type() != to.Type() {
+		return false
+	}
+	switch f := from.(type) {
+	case *ast.TextNode:
+		t := to.(*ast.TextNode) // nolint: forcetypeassert
+		if f.Text() != t.Text() {
+			return false
+		}
+		return s.opts.ignoreQuoting || normalizeQuoting(f.QuotingType()) == normalizeQuoting(t.QuotingType())
+	case ast.Texter:
+		return f.Text() == to.(ast.Texter).Text() // nolint: forcetypeassert
+	case *ast.NullNode:
+		return true
+	default:
+		return false
+	}
+}
+
+func normalizeQuoting(q ast.QuotingType) ast.QuotingType {
+	if q == ast.UnknownQuotingType {
+		return ast.AbsentQuotingType
+	}
+	return q
+}
+
+// splitContent returns properties and content of the node.
+func splitContent(n ast.Node) (props, content ast.Node) {
+	c, ok := n.(*ast.ContentNode)
+	if !ok {
+		return nil, n
+	}
+	return c.Properties(), c.Content()
+}
+
+func properties(n ast.Node) (tag, anchor string) {
+	props, ok := n.(*ast.PropertiesNode)
+	if !ok {
+		return "", ""
+	}
+	if t, ok := props.Tag().(*ast.TagNode); ok {
+		tag = t.Text()
+	}
+	if a, ok := props.Anchor().(*ast.AnchorNode); ok {
+		anchor = a.Text()
+	}
+	return tag, anchor
+}
+
+type mappingEntry struct {
+	key  string
+	node *ast.MappingEntryNode
+}
+
+func mappingEntries(m *ast.MappingNode) []mappingEntry {
+	entries := make([]mappingEntry, 0, len(m.Entries()))
+	for _, n := range m.Entries() {
+		entry, ok := n.(*ast.MappingEntryNode)
+		if !ok {
+			continue
+		}
+		entries = append(entries, mappingEntry{key: keyText(entry.Key()), node: entry})
+	}
+	return entries
+}
+
+// keyText returns the text of scalar key or the rendered form of complex key.
+func keyText(n ast.Node) string {
+	_, content := splitContent(n)
+	switch c := content.(type) {
+	case *ast.TextNode:
+		return c.Text()
+	case *ast.NullNode:
+		return ""
+	default:
+		return Render(n)
+	}
+}
+
+func keyPath(path, key string) string {
+	if isSimpleKey(key) {
+		return path + "." + key
+	}
+	return path + "['" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(key) + "']"
+}
+
+func isSimpleKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	for _, r := range key {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// longestIncreasing returns the set of positions of the longest increasing subsequence of values.
+func longestIncreasing(values []int) map[int]struct{} {
+	// tails[l] is the position of the smallest tail of increasing subsequence with length l+1
+	tails := make([]int, 0, len(values))
+	prev := make([]int, len(values))
+	for i, v := range values {
+		lo, hi := 0, len(tails)
+		for lo < hi {
+			mid := (lo + hi) / 2
+			if values[tails[mid]] < v {
+				lo = mid + 1
+			} else {
+				hi = mid
+			}
+		}
+		prev[i] = -1
+		if lo > 0 {
+			prev[i] = tails[lo-1]
+		}
+		if lo == len(tails) {
+			tails = append(tails, i)
+		} else {
+			tails[lo] = i
+		}
+	}
+
+	result := make(map[int]struct{}, len(tails))
+	if len(tails) == 0 {
+		return result
+	}
+	for i := tails[len(tails)-1]; i >= 0; i = prev[i] {
+		result[i] = struct{}{}
+	}
+	return result
+}
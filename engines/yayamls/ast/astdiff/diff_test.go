@@ -0,0 +1,191 @@
+package astdiff_test
+
+import (
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astdiff"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+	"github.com/KSpaceer/yamly/engines/yayamls/token"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		from     string
+		to       string
+		opts     []astdiff.Option
+		expected string
+	}
+
+	tcases := []tcase{
+		{
+			name: "equal",
+			from: "a: 1\nb: [x, y]\n",
+			to:   "a: 1\nb:\n  - x\n  - y\n",
+		},
+		{
+			name:     "changed scalar",
+			from:     "image:\n  tag: 1.4\n",
+			to:       "image:\n  tag: 1.5\n",
+			expected: "~ $.image.tag: 1.4 => 1.5\n",
+		},
+		{
+			name:     "added and removed entries",
+			from:     "a: 1\nb: 2\n",
+			to:       "a: 1\nc: {d: 3}\n",
+			expected: "- $.b: 2\n+ $.c: {d: 3}\n",
+		},
+		{
+			name:     "sequence elements",
+			from:     "ports: [80, 443]\n",
+			to:       "ports: [80, 8443, 9000]\n",
+			expected: "~ $.ports[1]: 443 => 8443\n+ $.ports[2]: 9000\n",
+		},
+		{
+			name:     "changed type",
+			from:     "a: [1]\n",
+			to:       "a: {b: 1}\n",
+			expected: "~ $.a: [1] => {b: 1}\n",
+		},
+		{
+			name:     "key order",
+			from:     "a: 1\nb: 2\nc: 3\n",
+			to:       "b: 2\nc: 3\na: 1\n",
+			expected: "> $.a\n",
+		},
+		{
+			name: "ignore key order",
+			from: "a: 1\nb: 2\nc: 3\n",
+			to:   "b: 2\nc: 3\na: 1\n",
+			opts: []astdiff.Option{astdiff.WithIgnoreKeyOrder()},
+		},
+		{
+			name:     "quoting",
+			from:     "a: 'x'\nb: y\n",
+			to:       "a: \"x\"\nb: y\n",
+			expected: "~ $.a: 'x' => \"x\"\n",
+		},
+		{
+			name: "ignore quoting",
+			from: "a: 'x'\nb: y\n",
+			to:   "a: \"x\"\nb: y\n",
+			opts: []astdiff.Option{astdiff.WithIgnoreQuoting()},
+		},
+		{
+			name:     "alias and expanded form",
+			from:     "base: &base {x: 1}\nderived: *base\n",
+			to:       "base: {x: 1}\nderived: {x: 1}\n",
+			expected: "~ $.base: &base {x: 1} => {x: 1}\n~ $.derived: *base => {x: 1}\n",
+		},
+		{
+			name: "resolve aliases",
+			from: "base: &base {x: 1}\nderived: *base\n",
+			to:   "base: {x: 1}\nderived: {x: 1}\n",
+			opts: []astdiff.Option{astdiff.WithResolveAliases()},
+		},
+		{
+			name:     "resolve aliases with changed value",
+			from:     "base: &base {x: 1}\nderived: *base\n",
+			to:       "base: {x: 1}\nderived: {x: 2}\n",
+			opts:     []astdiff.Option{astdiff.WithResolveAliases()},
+			expected: "~ $.derived.x: 1 => 2\n",
+		},
+		{
+			name:     "tags",
+			from:     "a: !!str 1\n",
+			to:       "a: 1\n",
+			expected: "~ $.a: !!str 1 => 1\n",
+		},
+		{
+			name:     "complex keys",
+			from:     "'a b': 1\n",
+			to:       "'a b': 2\n",
+			expected: "~ $['a b']: 1 => 2\n",
+		},
+		{
+			name:     "multiple documents",
+			from:     "---\na: 1\n---\nb: 2\n",
+			to:       "---\na: 1\n---\nb: 3\n---\nc: 4\n",
+			expected: "~ document 1: $.b: 2 => 3\n+ document 2: $: {c: 4}\n",
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			from, err := parser.ParseString(tc.from)
+			if err != nil {
+				t.Fatalf("failed to parse first source: %v", err)
+			}
+			to, err := parser.ParseString(tc.to)
+			if err != nil {
+				t.Fatalf("failed to parse second source: %v", err)
+			}
+
+			changes, err := astdiff.NewDiffer(tc.opts...).Diff(from, to)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if result := changes.String(); result != tc.expected {
+				t.Errorf("expected:\n%s\nbut got:\n%s", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestDiff_Positions(t *testing.T) {
+	t.Parallel()
+
+	from := "image:\n  tag: 1.4\nremoved: x\nmoved: 1\nkept: 2\n"
+	to := "image:\n  tag: 1.5\nkept: 2\nmoved: 1\nadded:\n  - y\n"
+
+	fromPositions, toPositions := make(ast.Positions), make(ast.Positions)
+	fromTree, err := parser.ParseString(from, parser.WithPositions(fromPositions))
+	if err != nil {
+		t.Fatalf("failed to parse first source: %v", err)
+	}
+	toTree, err := parser.ParseString(to, parser.WithPositions(toPositions))
+	if err != nil {
+		t.Fatalf("failed to parse second source: %v", err)
+	}
+
+	changes, err := astdiff.NewDiffer(astdiff.WithPositions(fromPositions, toPositions)).Diff(fromTree, toTree)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []struct {
+		path    string
+		fromPos token.Position
+		toPos   token.Position
+	}{
+		{path: "$.image.tag", fromPos: token.Position{Row: 2, Column: 8}, toPos: token.Position{Row: 2, Column: 8}},
+		{path: "$.removed", fromPos: token.Position{Row: 3, Column: 1}},
+		{path: "$.added", toPos: token.Position{Row: 5, Column: 1}},
+		{path: "$.moved", fromPos: token.Position{Row: 4, Column: 1}, toPos: token.Position{Row: 4, Column: 1}},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("expected %d changes, but got:\n%s", len(expected), changes)
+	}
+	for i, e := range expected {
+		c := changes[i]
+		if c.Path != e.path || c.FromPos != e.fromPos || c.ToPos != e.toPos {
+			t.Errorf("change %d: expected %s at %v => %v, but got %s at %v => %v",
+				i, e.path, e.fromPos, e.toPos, c.Path, c.FromPos, c.ToPos)
+		}
+	}
+
+	expectedString := "~ $.image.tag (line 2, column 8): 1.4 => 1.5\n" +
+		"- $.removed (line 3, column 1): x\n" +
+		"+ $.added (line 5, column 1): [y]\n" +
+		"> $.moved (line 4, column 1)\n"
+	if result := changes.String(); result != expectedString {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expectedString, result)
+	}
+}
@@ -0,0 +1,81 @@
+package astdiff
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+)
+
+// Render returns single-line flow-style representation of the node.
+func Render(n ast.Node) string {
+	var sb strings.Builder
+	render(&sb, n)
+	return sb.String()
+}
+
+func render(sb *strings.Builder, n ast.Node) {
+	switch n := n.(type) {
+	case nil:
+		sb.WriteString("<none>")
+	case *ast.ContentNode:
+		tag, anchor := properties(n.Properties())
+		if tag != "" {
+			sb.WriteString("!!")
+			sb.WriteString(tag)
+			sb.WriteByte(' ')
+		}
+		if anchor != "" {
+			sb.WriteByte('&')
+			sb.WriteString(anchor)
+			sb.WriteByte(' ')
+		}
+		render(sb, n.Content())
+	case *ast.AliasNode:
+		sb.WriteByte('*')
+		sb.WriteString(n.Text())
+	case *ast.NullNode:
+		sb.WriteString("null")
+	case *ast.TextNode:
+		switch n.QuotingType() {
+		case ast.SingleQuotingType:
+			sb.WriteByte('\'')
+			sb.WriteString(strings.ReplaceAll(n.Text(), "'", "''"))
+			sb.WriteByte('\'')
+		case ast.DoubleQuotingType:
+			sb.WriteString(strconv.Quote(n.Text()))
+		default:
+			if strings.ContainsAny(n.Text(), "\n\r") {
+				sb.WriteString(strconv.Quote(n.Text()))
+			} else {
+				sb.WriteString(n.Text())
+			}
+		}
+	case *ast.SequenceNode:
+		sb.WriteByte('[')
+		for i, entry := range n.Entries() {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			render(sb, entry)
+		}
+		sb.WriteByte(']')
+	case *ast.MappingNode:
+		sb.WriteByte('{')
+		for i, entry := range n.Entries() {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			render(sb, entry)
+		}
+		sb.WriteByte('}')
+	case *ast.MappingEntryNode:
+		render(sb, n.Key())
+		sb.WriteString(": ")
+		render(sb, n.Value())
+	default:
+		sb.WriteByte('<')
+		sb.WriteString(n.Type().String())
+		sb.WriteByte('>')
+	}
+}
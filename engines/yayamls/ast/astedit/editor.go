@@ -0,0 +1,311 @@
+// Package astedit contains types and methods to modify YAML AST in place.
+//
+// Editor locates nodes with yamlpath expressions and changes only the matched parts of AST,
+// so untouched nodes keep their tags, anchors and styles (quoting, chomping of block scalars,
+// flow collections and empty values) when written back by encode.ASTWriter. Comments are kept
+// if they are collected while parsing (see parser.WithComments) and written with encode.WithComments.
+// Line breaks of folded scalars and the indentation of source are not kept.
+package astedit
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+	"github.com/KSpaceer/yamly/engines/yayamls/yamlpath"
+)
+
+var (
+	// ErrNoMatch is returned when path expression does not match any node.
+	ErrNoMatch = errors.New("path does not match any node")
+	// ErrRootNode is returned on attempt to replace, delete, rename or move the root node.
+	ErrRootNode = errors.New("root node can't be edited in place")
+	// ErrAmbiguousMatch is returned when operation requires a single node, but path matches several ones.
+	ErrAmbiguousMatch = errors.New("path matches more than one node")
+	// ErrKeyExists is returned on attempt to insert or rename entry with a key already present in mapping.
+	ErrKeyExists = errors.New("key already exists")
+)
+
+// Editor implements AST editing logic.
+type Editor struct {
+	root ast.Node
+}
+
+func NewEditor(root ast.Node) *Editor {
+	return &Editor{root: root}
+}
+
+// Root returns the edited AST.
+func (e *Editor) Root() ast.Node {
+	return e.root
+}
+
+// Set replaces every node matched by expr with given value. Aliases are replaced as well,
+// leaving the anchored nodes untouched. The same value node is shared by all replaced positions.
+func (e *Editor) Set(expr string, value ast.Node) error {
+	matches, err := e.find(expr)
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		if err := replace(m, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetText replaces text of every scalar matched by expr. Tag, anchor and quoting style of
+// the replaced scalar are kept. If the scalar was plain, but the new text can't be represented
+// as a plain scalar, the text is double-quoted.
+func (e *Editor) SetText(expr, text string) error {
+	matches, err := e.find(expr)
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		current := located(m)
+		if err := replace(m, withText(current, m.Node, text)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InsertEntry inserts an entry with given key and value at the position index of every mapping
+// matched by expr. Negative index appends the entry to the end of mapping.
+func (e *Editor) InsertEntry(expr string, index int, key string, value ast.Node) error {
+	matches, err := e.find(expr)
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		mapping, ok := m.Content().(*ast.MappingNode)
+		if !ok {
+			return fmt.Errorf("failed to insert entry %q: expected mapping, but got %s", key, m.Content().Type())
+		}
+		if entryIndex(mapping, key) >= 0 {
+			return fmt.Errorf("failed to insert entry %q: %w", key, ErrKeyExists)
+		}
+		mapping.InsertEntry(index, ast.NewMappingEntryNode(newText(key, nil), value))
+	}
+	return nil
+}
+
+// InsertElement inserts value at the position index of every sequence matched by expr.
+// Negative index appends the value to the end of sequence.
+func (e *Editor) InsertElement(expr string, index int, value ast.Node) error {
+	matches, err := e.find(expr)
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		seq, ok := m.Content().(*ast.SequenceNode)
+		if !ok {
+			return fmt.Errorf("failed to insert element: expected sequence, but got %s", m.Content().Type())
+		}
+		seq.InsertEntry(index, value)
+	}
+	return nil
+}
+
+// Rename changes the key of every mapping entry whose value is matched by expr.
+// Tag, anchor and quoting style of the key are kept.
+func (e *Editor) Rename(expr, key string) error {
+	matches, err := e.find(expr)
+	if err != nil {
+		return err
+	}
+	for _, m := range matches {
+		entry, ok := m.Parent.(*ast.MappingEntryNode)
+		if !ok {
+			if m.Parent == nil {
+				return ErrRootNode
+			}
+			return fmt.Errorf("failed to rename entry: expected mapping value, but got sequence element")
+		}
+		mapping := m.Collection.(*ast.MappingNode) // nolint: forcetypeassert
+		if idx := entryIndex(mapping, key); idx >= 0 && idx != m.Index {
+			return fmt.Errorf("failed to rename entry to %q: %w", key, ErrKeyExists)
+		}
+		entry.SetKey(withText(entry.Key(), entry.Key(), key))
+	}
+	return nil
+}
+
+// Delete removes every mapping entry or sequence element matched by expr.
+func (e *Editor) Delete(expr string) error {
+	matches, err := e.find(expr)
+	if err != nil {
+		return err
+	}
+
+	type position struct {
+		collection ast.Node
+		index      int
+	}
+	positions := make([]position, 0, len(matches))
+	seen := make(map[position]struct{}, len(matches))
+	for _, m := range matches {
+		if m.Collection == nil {
+			return ErrRootNode
+		}
+		pos := position{collection: m.Collection, index: m.Index}
+		if _, ok := seen[pos]; ok {
+			continue
+		}
+		seen[pos] = struct{}{}
+		positions = append(positions, pos)
+	}
+
+	// removing entries from the end to keep positions of remaining entries valid
+	sort.SliceStable(positions, func(i, j int) bool {
+		return positions[i].index > positions[j].index
+	})
+	for _, pos := range positions {
+		switch c := pos.collection.(type) {
+		case *ast.MappingNode:
+			c.RemoveEntry(pos.index)
+		case *ast.SequenceNode:
+			c.RemoveEntry(pos.index)
+		}
+	}
+	return nil
+}
+
+// Move moves the mapping entry or sequence element matched by expr to the position index
+// of the same collection. Negative index moves the entry to the end. The path must match exactly one node.
+func (e *Editor) Move(expr string, index int) error {
+	matches, err := e.find(expr)
+	if err != nil {
+		return err
+	}
+	if len(matches) > 1 {
+		return ErrAmbiguousMatch
+	}
+	m := matches[0]
+
+	switch c := m.Collection.(type) {
+	case *ast.MappingNode:
+		c.InsertEntry(index, c.RemoveEntry(m.Index))
+	case *ast.SequenceNode:
+		c.InsertEntry(index, c.RemoveEntry(m.Index))
+	default:
+		return ErrRootNode
+	}
+	return nil
+}
+
+func (e *Editor) find(expr string) ([]yamlpath.Match, error) {
+	matches, err := yamlpath.Find(e.root, expr)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoMatch, expr)
+	}
+	return matches, nil
+}
+
+// located returns the node placed at the matched position, i.e. without alias resolution.
+func located(m yamlpath.Match) ast.Node {
+	switch p := m.Parent.(type) {
+	case *ast.MappingEntryNode:
+		return p.Value()
+	case *ast.SequenceNode:
+		return p.Entries()[m.Index]
+	default:
+		return m.Node
+	}
+}
+
+func replace(m yamlpath.Match, value ast.Node) error {
+	switch p := m.Parent.(type) {
+	case *ast.MappingEntryNode:
+		p.SetValue(value)
+	case *ast.SequenceNode:
+		p.SetEntry(m.Index, value)
+	default:
+		return ErrRootNode
+	}
+	return nil
+}
+
+// withText creates a scalar with given text keeping properties of current node
+// and quoting style of the scalar node resolved from current.
+func withText(current, resolved ast.Node, text string) ast.Node {
+	var properties ast.Node
+	if c, ok := current.(*ast.ContentNode); ok {
+		properties = c.Properties()
+	}
+	for {
+		c, ok := resolved.(*ast.ContentNode)
+		if !ok {
+			break
+		}
+		resolved = c.Content()
+	}
+
+	txt := newText(text, resolved)
+	if !ast.ValidNode(properties) {
+		return txt
+	}
+	return ast.NewContentNode(properties, txt)
+}
+
+func newText(text string, previous ast.Node) *ast.TextNode {
+	quoting := ast.AbsentQuotingType
+	if prev, ok := previous.(*ast.TextNode); ok && prev.QuotingType() != ast.UnknownQuotingType {
+		quoting = prev.QuotingType()
+	}
+	if quoting == ast.AbsentQuotingType && !isPlainSafe(text) {
+		quoting = ast.DoubleQuotingType
+	}
+	return ast.NewTextNode(text, ast.WithQuotingType(quoting))
+}
+
+// isPlainSafe checks if text is read back unchanged when written as plain scalar.
+func isPlainSafe(text string) bool {
+	if text == "" {
+		return false
+	}
+	tree, err := parser.ParseString(text, parser.WithOmitStream())
+	if err != nil {
+		return false
+	}
+	for {
+		c, ok := tree.(*ast.ContentNode)
+		if !ok {
+			break
+		}
+		if ast.ValidNode(c.Properties()) {
+			return false
+		}
+		tree = c.Content()
+	}
+	txt, ok := tree.(*ast.TextNode)
+	return ok && txt.Text() == text
+}
+
+func entryIndex(mapping *ast.MappingNode, key string) int {
+	for i, entry := range mapping.Entries() {
+		entry, ok := entry.(*ast.MappingEntryNode)
+		if !ok {
+			continue
+		}
+		k := entry.Key()
+		for {
+			c, ok := k.(*ast.ContentNode)
+			if !ok {
+				break
+			}
+			k = c.Content()
+		}
+		if txt, ok := k.(*ast.TextNode); ok && txt.Text() == key {
+			return i
+		}
+	}
+	return -1
+}
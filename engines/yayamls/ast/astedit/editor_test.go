@@ -0,0 +1,390 @@
+package astedit_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astedit"
+	"github.com/KSpaceer/yamly/engines/yayamls/encode"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+)
+
+const values = `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 443
+services:
+  api: *defaults
+`
+
+func TestEditor(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name      string
+		edit      func(e *astedit.Editor) error
+		expected  string
+		expectErr error
+	}
+
+	tcases := []tcase{
+		{
+			name: "set text keeps quoting",
+			edit: func(e *astedit.Editor) error {
+				return e.SetText("$.image.tag", "1.5.0")
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.5.0"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 443
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "set text keeps anchor",
+			edit: func(e *astedit.Editor) error {
+				return e.SetText("$.defaults.version", "1.5")
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.5
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 443
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "set text quotes unsafe plain scalar",
+			edit: func(e *astedit.Editor) error {
+				return e.SetText("$.image.pullPolicy", "Always # for now")
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: "Always # for now"
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 443
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "set replaces alias",
+			edit: func(e *astedit.Editor) error {
+				return e.Set("$.services.api", ast.NewTextNode("disabled"))
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 443
+services:
+  api: disabled
+`,
+		},
+		{
+			name: "set sequence element",
+			edit: func(e *astedit.Editor) error {
+				return e.Set("$.ports[-1]", ast.NewTextNode("8443"))
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 8443
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "insert entry",
+			edit: func(e *astedit.Editor) error {
+				return e.InsertEntry("$.image", 1, "digest", ast.NewTextNode("sha256:abc"))
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  digest: sha256:abc
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 443
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "insert element",
+			edit: func(e *astedit.Editor) error {
+				return e.InsertElement("$.ports", 0, ast.NewTextNode("22"))
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 22
+  - 80
+  - 443
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "rename",
+			edit: func(e *astedit.Editor) error {
+				return e.Rename("$.resources.memory", "mem")
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  mem: 128Mi
+ports:
+  - 80
+  - 443
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "delete",
+			edit: func(e *astedit.Editor) error {
+				return e.Delete("$['image', 'ports']")
+			},
+			expected: `defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "delete with filter",
+			edit: func(e *astedit.Editor) error {
+				return e.Delete("$.ports[?(@ > 100)]")
+			},
+			expected: `image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+services:
+  api: *defaults
+`,
+		},
+		{
+			name: "move",
+			edit: func(e *astedit.Editor) error {
+				return e.Move("$.services", 0)
+			},
+			expected: `services:
+  api: *defaults
+image:
+  repository: 'registry.local/app'
+  tag: "1.4.2"
+  pullPolicy: IfNotPresent
+defaults: &defaults
+  replicas: 2
+  version: 1.4
+resources: !!map
+  cpu: 100m
+  memory: 128Mi
+ports:
+  - 80
+  - 443
+`,
+		},
+		{
+			name: "no match",
+			edit: func(e *astedit.Editor) error {
+				return e.SetText("$.image.digest", "sha256:abc")
+			},
+			expectErr: astedit.ErrNoMatch,
+		},
+		{
+			name: "insert existing key",
+			edit: func(e *astedit.Editor) error {
+				return e.InsertEntry("$.image", -1, "tag", ast.NewTextNode("latest"))
+			},
+			expectErr: astedit.ErrKeyExists,
+		},
+		{
+			name: "rename to existing key",
+			edit: func(e *astedit.Editor) error {
+				return e.Rename("$.resources.cpu", "memory")
+			},
+			expectErr: astedit.ErrKeyExists,
+		},
+		{
+			name: "delete root",
+			edit: func(e *astedit.Editor) error {
+				return e.Delete("$")
+			},
+			expectErr: astedit.ErrRootNode,
+		},
+		{
+			name: "ambiguous move",
+			edit: func(e *astedit.Editor) error {
+				return e.Move("$.ports[*]", 0)
+			},
+			expectErr: astedit.ErrAmbiguousMatch,
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, err := parser.ParseString(values, parser.WithOmitStream())
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+
+			err = tc.edit(astedit.NewEditor(tree))
+			if tc.expectErr != nil {
+				if !errors.Is(err, tc.expectErr) {
+					t.Fatalf("expected error %v, but got %v", tc.expectErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			result, err := encode.NewASTWriter().WriteString(tree)
+			if err != nil {
+				t.Fatalf("failed to write AST: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("expected:\n%s\nbut got:\n%s", tc.expected, result)
+			}
+		})
+	}
+}
+
+const styled = `# settings
+literal: |
+  first
+  second
+folded: >-
+  folded text
+list: [a, b] # flow
+map: {k: v}
+empty:
+escaped: "a\nb"
+single: 'it''s'
+local: !foo bar
+version: 1
+`
+
+func TestEditor_KeepsUntouchedNodes(t *testing.T) {
+	t.Parallel()
+
+	var comments []ast.Comment
+	positions := make(ast.Positions)
+	tree, err := parser.ParseString(
+		styled,
+		parser.WithOmitStream(),
+		parser.WithComments(&comments),
+		parser.WithPositions(positions),
+	)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+	cm := ast.NewCommentMap(tree, positions, comments)
+
+	if err := astedit.NewEditor(tree).SetText("$.version", "2"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	result, err := encode.NewASTWriter(encode.WithComments(cm)).WriteString(tree)
+	if err != nil {
+		t.Fatalf("failed to write AST: %v", err)
+	}
+	expected := strings.Replace(styled, "version: 1", "version: 2", 1)
+	if result != expected {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, result)
+	}
+}
@@ -0,0 +1,163 @@
+package ast
+
+import (
+	"sort"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/token"
+)
+
+// Comment is a YAML comment met in source.
+type Comment struct {
+	// Text is the text of the comment, including leading '#' and excluding trailing whitespaces.
+	Text string
+	// Start is the position of '#' character in source.
+	Start token.Position
+	// Trailing shows if the comment follows some content on the same line.
+	Trailing bool
+}
+
+// Comments are comments attached to a node.
+type Comments struct {
+	// Head contains full-line comments preceding the node.
+	Head []string
+	// Line is the comment following the node on the same line.
+	Line string
+	// Foot contains full-line comments following the node. Only documents have foot comments.
+	Foot []string
+}
+
+// CommentMap maps nodes to comments attached to them.
+type CommentMap map[Node]*Comments
+
+// NewCommentMap attaches comments to the nodes of the tree, using positions of nodes in source.
+//
+// Comments are attached to documents, mapping entries and block sequence entries.
+// A full-line comment becomes a head comment of the first such node starting after the comment.
+// A trailing comment becomes a line comment of the innermost node started on the line of the comment
+// or before it. Comments after the last node are attached to the last document as foot comments.
+// Nodes inside flow collections are not considered, so comments inside them are attached
+// to the nodes surrounding the collection.
+func NewCommentMap(root Node, positions Positions, comments []Comment) CommentMap {
+	cm := make(CommentMap)
+	if len(comments) == 0 {
+		return cm
+	}
+
+	b := commentMapBuilder{positions: positions}
+	if stream, ok := root.(*StreamNode); ok {
+		for _, doc := range stream.Documents() {
+			b.addAnchor(doc, true)
+		}
+	} else {
+		b.addAnchor(root, true)
+	}
+
+	for _, c := range comments {
+		// the index of the first anchor started after the line of the comment
+		i := sort.Search(len(b.anchors), func(i int) bool {
+			return b.anchors[i].start.Row > c.Start.Row
+		})
+		if c.Trailing && i > 0 {
+			if attached := cm.get(b.anchors[i-1].node); attached.Line == "" {
+				attached.Line = c.Text
+				continue
+			}
+		}
+
+		if i < len(b.anchors) {
+			attached := cm.get(b.anchors[i].node)
+			attached.Head = append(attached.Head, c.Text)
+			continue
+		}
+
+		foot := root
+		if b.lastDocument != nil {
+			foot = b.lastDocument
+		}
+		attached := cm.get(foot)
+		attached.Foot = append(attached.Foot, c.Text)
+	}
+	return cm
+}
+
+func (cm CommentMap) get(n Node) *Comments {
+	comments, ok := cm[n]
+	if !ok {
+		comments = &Comments{}
+		cm[n] = comments
+	}
+	return comments
+}
+
+// commentAnchor is a node comments can be attached to.
+type commentAnchor struct {
+	node  Node
+	start token.Position
+}
+
+type commentMapBuilder struct {
+	positions Positions
+	// anchors are kept in order of appearance, so they are sorted by start position
+	// and outer nodes precede the inner ones starting at the same position
+	anchors      []commentAnchor
+	lastDocument Node
+}
+
+func (b *commentMapBuilder) addAnchor(n Node, document bool) {
+	idx := len(b.anchors)
+	b.anchors = append(b.anchors, commentAnchor{node: n})
+	start, ok := b.walk(n)
+	if !ok {
+		// the node has no position in source, e.g. empty node
+		b.anchors = append(b.anchors[:idx], b.anchors[idx+1:]...)
+		return
+	}
+	b.anchors[idx].start = start
+	if document {
+		b.lastDocument = n
+	}
+}
+
+// walk collects anchors inside the node and returns the start position of the node.
+func (b *commentMapBuilder) walk(n Node) (token.Position, bool) {
+	if !ValidNode(n) {
+		return token.Position{}, false
+	}
+	start, ok := b.positions[n]
+	if n.Type() == NullType {
+		// null nodes are shared, so they don't have positions
+		ok = false
+	}
+
+	var anchorChildren bool
+	switch n := n.(type) {
+	case *MappingNode:
+		anchorChildren = !n.Flow()
+	case *SequenceNode:
+		anchorChildren = !n.Flow()
+	}
+	if ok {
+		// flow collection, which entries are not considered as anchors
+		anchorChildren = false
+	}
+
+	for _, child := range childNodes(n) {
+		var (
+			childStart token.Position
+			childOk    bool
+		)
+		if anchorChildren {
+			idx := len(b.anchors)
+			b.addAnchor(child, false)
+			if childOk = len(b.anchors) > idx; childOk {
+				childStart = b.anchors[idx].start
+			}
+		} else {
+			childStart, childOk = b.walk(child)
+		}
+		if childOk && (!ok || positionLess(childStart, start)) {
+			start, ok = childStart, true
+		}
+	}
+	return start, ok
+}
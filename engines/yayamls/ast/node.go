@@ -129,6 +129,10 @@ const (
 	SingleQuotingType
 	// DoubleQuotingType means that string is enclosed in double quotes
 	DoubleQuotingType
+	// LiteralQuotingType means that string is written as literal block scalar ("|")
+	LiteralQuotingType
+	// FoldedQuotingType means that string is written as folded block scalar (">")
+	FoldedQuotingType
 )
 
 // Node is a single element of YAML AST
@@ -167,16 +171,26 @@ func (b *BasicNode) Type() NodeType {
 
 func (*BasicNode) Accept(Visitor) {}
 
-var invalidNode = &BasicNode{NodeType: InvalidType}
+// basicNodes contains shared BasicNode for every NodeType, so marker nodes do not require allocations.
+var basicNodes = func() (nodes [NullType + 1]BasicNode) {
+	for i := range nodes {
+		nodes[i].NodeType = NodeType(i)
+	}
+	return nodes
+}()
+
+var invalidNode = &basicNodes[InvalidType]
 
 func NewInvalidNode() Node {
 	return invalidNode
 }
 
+// NewBasicNode returns BasicNode with given type. The returned node is shared and must not be modified.
 func NewBasicNode(tp NodeType) *BasicNode {
-	return &BasicNode{
-		NodeType: tp,
+	if tp < 0 || int(tp) >= len(basicNodes) {
+		return &BasicNode{NodeType: tp}
 	}
+	return &basicNodes[tp]
 }
 
 type StreamNode struct {
@@ -229,8 +243,37 @@ func NewPropertiesNode(tag, anchor Node) *PropertiesNode {
 	}
 }
 
+// TagNodeOption allows to modify YAML element associated with TagNode during creation.
+type TagNodeOption interface {
+	apply(*TagNode)
+}
+
+type tagHandleOption string
+
+func (o tagHandleOption) apply(node *TagNode) {
+	node.handle = string(o)
+}
+
+// WithTagHandle sets the handle of shorthand tag (e.g. "!", "!!" or "!e!").
+func WithTagHandle(handle string) TagNodeOption {
+	return tagHandleOption(handle)
+}
+
+type verbatimTagOption struct{}
+
+func (verbatimTagOption) apply(node *TagNode) {
+	node.verbatim = true
+}
+
+// WithVerbatimTag marks tag as verbatim one (e.g. !<tag:yaml.org,2002:str>).
+func WithVerbatimTag() TagNodeOption {
+	return verbatimTagOption{}
+}
+
 type TagNode struct {
-	text string
+	text     string
+	handle   string
+	verbatim bool
 }
 
 func (*TagNode) Type() NodeType {
@@ -241,14 +284,37 @@ func (t *TagNode) Accept(v Visitor) {
 	v.VisitTagNode(t)
 }
 
+// Text returns the tag without handle, i.e. the suffix of shorthand tag or the whole verbatim tag.
 func (t *TagNode) Text() string {
 	return t.text
 }
 
-func NewTagNode(text string) *TagNode {
-	return &TagNode{
+// Handle returns the handle of shorthand tag. Tags created without handle have secondary handle ("!!").
+// Verbatim tags have no handle.
+func (t *TagNode) Handle() string {
+	switch {
+	case t.verbatim:
+		return ""
+	case t.handle == "":
+		return "!!"
+	default:
+		return t.handle
+	}
+}
+
+// Verbatim shows if tag is written in verbatim form (e.g. !<tag:yaml.org,2002:str>).
+func (t *TagNode) Verbatim() bool {
+	return t.verbatim
+}
+
+func NewTagNode(text string, opts ...TagNodeOption) *TagNode {
+	node := TagNode{
 		text: text,
 	}
+	for _, opt := range opts {
+		opt.apply(&node)
+	}
+	return &node
 }
 
 type AnchorNode struct {
@@ -328,22 +394,45 @@ type TextNodeOption interface {
 	apply(*TextNode)
 }
 
-type textNodeOptionFunc func(*TextNode)
+type quotingTypeOption QuotingType
 
-func (f textNodeOptionFunc) apply(o *TextNode) {
-	f(o)
+func (o quotingTypeOption) apply(node *TextNode) {
+	node.quotingType = QuotingType(o)
 }
 
 // WithQuotingType sets given QuotingType for TextNode string.
 func WithQuotingType(t QuotingType) TextNodeOption {
-	return textNodeOptionFunc(func(node *TextNode) {
-		node.quotingType = t
-	})
+	return quotingTypeOption(t)
+}
+
+type chompingTypeOption ChompingType
+
+func (o chompingTypeOption) apply(node *TextNode) {
+	node.chompingType = ChompingType(o)
+}
+
+// WithChompingType sets given ChompingType for literal or folded TextNode string.
+func WithChompingType(t ChompingType) TextNodeOption {
+	return chompingTypeOption(t)
+}
+
+type escapedTextOption struct{}
+
+func (escapedTextOption) apply(node *TextNode) {
+	node.escaped = true
+}
+
+// WithEscapedText marks quoted TextNode string as kept in the source form, i.e. with escape sequences
+// of double quoted string or doubled quotes of single quoted string.
+func WithEscapedText() TextNodeOption {
+	return escapedTextOption{}
 }
 
 type TextNode struct {
-	quotingType QuotingType
-	text        string
+	quotingType  QuotingType
+	chompingType ChompingType
+	escaped      bool
+	text         string
 }
 
 func (*TextNode) Type() NodeType {
@@ -354,6 +443,16 @@ func (t *TextNode) QuotingType() QuotingType {
 	return t.quotingType
 }
 
+// ChompingType returns chomping type of literal or folded string.
+func (t *TextNode) ChompingType() ChompingType {
+	return t.chompingType
+}
+
+// Escaped shows if quoted string is kept in the source form (see WithEscapedText).
+func (t *TextNode) Escaped() bool {
+	return t.escaped
+}
+
 func (t *TextNode) Accept(v Visitor) {
 	v.VisitTextNode(t)
 }
@@ -422,6 +521,7 @@ func NewIndentNode(indent int) *IndentNode {
 
 type SequenceNode struct {
 	entries []Node
+	flow    bool
 }
 
 func (*SequenceNode) Type() NodeType {
@@ -436,16 +536,49 @@ func (s *SequenceNode) Entries() []Node {
 	return s.entries
 }
 
+// Flow shows if sequence should be written in flow style (e.g. [a, b]).
+func (s *SequenceNode) Flow() bool {
+	return s.flow
+}
+
+// SetFlow sets whether sequence should be written in flow style.
+func (s *SequenceNode) SetFlow(flow bool) {
+	s.flow = flow
+}
+
 func (s *SequenceNode) AppendEntry(n Node) {
 	s.entries = append(s.entries, n)
 }
 
+// InsertEntry inserts node at given position of entries. Negative or too large index appends the node.
+func (s *SequenceNode) InsertEntry(i int, n Node) {
+	if i < 0 || i >= len(s.entries) {
+		s.entries = append(s.entries, n)
+		return
+	}
+	s.entries = append(s.entries[:i+1], s.entries[i:]...)
+	s.entries[i] = n
+}
+
+// SetEntry replaces the entry at given position.
+func (s *SequenceNode) SetEntry(i int, n Node) {
+	s.entries[i] = n
+}
+
+// RemoveEntry removes the entry at given position and returns it.
+func (s *SequenceNode) RemoveEntry(i int) Node {
+	n := s.entries[i]
+	s.entries = append(s.entries[:i], s.entries[i+1:]...)
+	return n
+}
+
 func NewSequenceNode(entries []Node) *SequenceNode {
 	return &SequenceNode{entries: entries}
 }
 
 type MappingNode struct {
 	entries []Node
+	flow    bool
 }
 
 func (*MappingNode) Type() NodeType {
@@ -460,10 +593,42 @@ func (m *MappingNode) Entries() []Node {
 	return m.entries
 }
 
+// Flow shows if mapping should be written in flow style (e.g. {a: b}).
+func (m *MappingNode) Flow() bool {
+	return m.flow
+}
+
+// SetFlow sets whether mapping should be written in flow style.
+func (m *MappingNode) SetFlow(flow bool) {
+	m.flow = flow
+}
+
 func (m *MappingNode) AppendEntry(n Node) {
 	m.entries = append(m.entries, n)
 }
 
+// InsertEntry inserts node at given position of entries. Negative or too large index appends the node.
+func (m *MappingNode) InsertEntry(i int, n Node) {
+	if i < 0 || i >= len(m.entries) {
+		m.entries = append(m.entries, n)
+		return
+	}
+	m.entries = append(m.entries[:i+1], m.entries[i:]...)
+	m.entries[i] = n
+}
+
+// SetEntry replaces the entry at given position.
+func (m *MappingNode) SetEntry(i int, n Node) {
+	m.entries[i] = n
+}
+
+// RemoveEntry removes the entry at given position and returns it.
+func (m *MappingNode) RemoveEntry(i int) Node {
+	n := m.entries[i]
+	m.entries = append(m.entries[:i], m.entries[i+1:]...)
+	return n
+}
+
 func NewMappingNode(entries []Node) *MappingNode {
 	return &MappingNode{entries: entries}
 }
@@ -500,7 +665,9 @@ func NewMappingEntryNode(key, value Node) *MappingEntryNode {
 	return &MappingEntryNode{key: key, value: value}
 }
 
-type NullNode struct{}
+type NullNode struct {
+	implicit bool
+}
 
 func (*NullNode) Type() NodeType {
 	return NullType
@@ -510,8 +677,21 @@ func (n *NullNode) Accept(v Visitor) {
 	v.VisitNullNode(n)
 }
 
-var nullNode = &NullNode{}
+// Implicit shows if null is represented by an empty node (e.g. "key:").
+func (n *NullNode) Implicit() bool {
+	return n.implicit
+}
+
+var (
+	nullNode         = &NullNode{}
+	implicitNullNode = &NullNode{implicit: true}
+)
 
 func NewNullNode() *NullNode {
 	return nullNode
 }
+
+// NewImplicitNullNode returns null represented by an empty node.
+func NewImplicitNullNode() *NullNode {
+	return implicitNullNode
+}
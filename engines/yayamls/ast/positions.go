@@ -0,0 +1,55 @@
+package ast
+
+import "github.com/KSpaceer/yamly/engines/yayamls/token"
+
+// Positions contains start positions of nodes in source.
+// Only nodes with text (scalars and aliases) and flow collections have recorded positions.
+type Positions map[Node]token.Position
+
+// Find returns the start position of the node. If the position of the node is not recorded
+// (e.g. for block collections and mapping entries), the earliest position of its descendants is returned.
+// False is returned if neither the node nor its descendants have recorded positions.
+func (p Positions) Find(n Node) (token.Position, bool) {
+	if !ValidNode(n) {
+		return token.Position{}, false
+	}
+	if start, ok := p[n]; ok && n.Type() != NullType {
+		return start, true
+	}
+
+	var (
+		start token.Position
+		found bool
+	)
+	for _, child := range childNodes(n) {
+		if childStart, ok := p.Find(child); ok && (!found || positionLess(childStart, start)) {
+			start, found = childStart, true
+		}
+	}
+	return start, found
+}
+
+// childNodes returns the nodes which can have recorded positions.
+func childNodes(n Node) []Node {
+	switch n := n.(type) {
+	case *StreamNode:
+		return n.Documents()
+	case *MappingNode:
+		return n.Entries()
+	case *SequenceNode:
+		return n.Entries()
+	case *MappingEntryNode:
+		return []Node{n.Key(), n.Value()}
+	case *ContentNode:
+		return []Node{n.Content()}
+	default:
+		return nil
+	}
+}
+
+func positionLess(a, b token.Position) bool {
+	if a.Row != b.Row {
+		return a.Row < b.Row
+	}
+	return a.Column < b.Column
+}
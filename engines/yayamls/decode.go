@@ -1,7 +1,16 @@
 package yayamls
 
+import "github.com/KSpaceer/yamly/engines/yayamls/ast"
+
 // Unmarshaler interface can be implemented to customize type's behaviour when being
 // unmarshaled from YAML document using raw YAML.
 type Unmarshaler interface {
 	UnmarshalYAML([]byte) error
 }
+
+// NodeUnmarshaler interface can be implemented to customize type's behaviour when being
+// unmarshaled from YAML document using AST subtree. Unlike Unmarshaler, the subtree
+// is passed as is, without serializing and parsing.
+type NodeUnmarshaler interface {
+	UnmarshalYAMLNode(ast.Node) error
+}
@@ -2,14 +2,24 @@ package decode
 
 import (
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/encode"
 )
 
+var _ encode.AnchorsKeeper = (*anchorsKeeper)(nil)
+
 type anchorsKeeper struct {
 	anchors    map[string]ast.Node
 	metAnchor  bool
 	anchorName string
 }
 
+// NewAnchorsKeeper returns an encode.AnchorsKeeper used by ASTReader to dereference aliases.
+// It can be used to resolve aliases while traversing AST in document order.
+func NewAnchorsKeeper() encode.AnchorsKeeper { // nolint: ireturn
+	ak := newAnchorsKeeper()
+	return &ak
+}
+
 func newAnchorsKeeper() anchorsKeeper {
 	return anchorsKeeper{
 		anchors: map[string]ast.Node{},
@@ -17,13 +17,17 @@ type anyBuilder struct {
 	extractMergeMap bool
 	mergeMap        map[string]any
 
-	anchors *anchorsKeeper
-	errors  []error
+	anchors    *anchorsKeeper
+	uniqueKeys bool
+	positions  ast.Positions
+	errors     []error
 }
 
-func newAnyBuilder(anchors *anchorsKeeper) anyBuilder {
+func newAnyBuilder(anchors *anchorsKeeper, uniqueKeys bool, positions ast.Positions) anyBuilder {
 	return anyBuilder{
-		anchors: anchors,
+		anchors:    anchors,
+		uniqueKeys: uniqueKeys,
+		positions:  positions,
 	}
 }
 
@@ -86,6 +90,12 @@ func (a *anyBuilder) VisitSequenceNode(n *ast.SequenceNode) {
 }
 
 func (a *anyBuilder) VisitMappingNode(n *ast.MappingNode) {
+	if a.uniqueKeys {
+		if err := findDuplicateKey(n, a.positions); err != nil {
+			a.appendError(err)
+			return
+		}
+	}
 	entries := n.Entries()
 	m := make(map[string]any, len(entries))
 	for _, entry := range entries {
@@ -156,27 +166,7 @@ func (a *anyBuilder) VisitContentNode(n *ast.ContentNode) {
 }
 
 func (a *anyBuilder) extractAnyValueFromText(n *ast.TextNode) {
-	var err error
-	switch {
-	case schema.IsNull(n):
-		a.value = nil
-		return
-	case schema.IsTimestamp(n):
-		a.value, err = schema.ToTimestamp(n.Text())
-	case schema.IsUnsignedInteger(n):
-		a.value, err = schema.ToUnsignedInteger(n.Text(), 64)
-	case schema.IsInteger(n):
-		a.value, err = schema.ToInteger(n.Text(), 64)
-	case schema.IsFloat(n):
-		a.value, err = schema.ToFloat(n.Text(), 64)
-	case schema.IsBoolean(n):
-		a.value, err = schema.ToBoolean(n.Text())
-	default:
-		a.value = n.Text()
-	}
-	if err != nil {
-		a.appendError(err)
-	}
+	_, a.value = schema.Resolve(n)
 }
 
 func (a *anyBuilder) visitNode(n ast.Node) {
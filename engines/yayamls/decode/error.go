@@ -3,7 +3,9 @@ package decode
 import (
 	"fmt"
 
+	"github.com/KSpaceer/yamly"
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/schema"
 )
 
 type denyError struct {
@@ -27,3 +29,28 @@ type AliasDereferenceError struct {
 func (ade AliasDereferenceError) Error() string {
 	return fmt.Sprintf("failed to dereference alias %q", ade.name)
 }
+
+// findDuplicateKey returns yamly.DuplicateKeyError if given mapping contains the same key several times.
+// Keys are located in source using given positions.
+func findDuplicateKey(m *ast.MappingNode, positions ast.Positions) error {
+	key, first, second, found := schema.FindDuplicateKey(m)
+	if !found {
+		return nil
+	}
+	return &yamly.DuplicateKeyError{
+		Key:    key,
+		First:  keyPosition(m, first, positions),
+		Second: keyPosition(m, second, positions),
+	}
+}
+
+// keyPosition describes the key of i-th entry of the mapping.
+func keyPosition(m *ast.MappingNode, i int, positions ast.Positions) yamly.KeyPosition {
+	kp := yamly.KeyPosition{Entry: i + 1}
+	if entry, ok := m.Entries()[i].(*ast.MappingEntryNode); ok {
+		if start, ok := positions.Find(entry.Key()); ok {
+			kp.Line, kp.Column = start.Row, start.Column
+		}
+	}
+	return kp
+}
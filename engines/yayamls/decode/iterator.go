@@ -7,6 +7,8 @@ import (
 type nodeIteratorImpl struct {
 	i     int
 	nodes []ast.Node
+	// pair keeps the nodes of iterators over two children to avoid allocating slice for them
+	pair [2]ast.Node
 }
 
 func (s *nodeIteratorImpl) node() ast.Node {
@@ -22,44 +24,41 @@ func (s *nodeIteratorImpl) empty() bool {
 	return s.i >= len(s.nodes)
 }
 
-func newStreamIterator(s *ast.StreamNode) nodeIterator {
-	return &nodeIteratorImpl{
-		i:     0,
-		nodes: s.Documents(),
-	}
+// newIterator allocates iterator over given nodes. The iterator is valid until reader is reset.
+func (r *ASTReader) newIterator(nodes []ast.Node) nodeIterator {
+	iter := r.iterators.Alloc()
+	iter.nodes = nodes
+	return iter
 }
 
-func newSequenceIterator(s *ast.SequenceNode) nodeIterator {
-	return &nodeIteratorImpl{
-		i:     0,
-		nodes: s.Entries(),
-	}
+// newPairIterator allocates iterator over two given nodes. The iterator is valid until reader is reset.
+func (r *ASTReader) newPairIterator(first, second ast.Node) nodeIterator {
+	iter := r.iterators.Alloc()
+	iter.pair = [2]ast.Node{first, second}
+	iter.nodes = iter.pair[:]
+	return iter
 }
 
-func newMappingIterator(m *ast.MappingNode) nodeIterator {
-	return &nodeIteratorImpl{
-		i:     0,
-		nodes: m.Entries(),
-	}
+func (r *ASTReader) newStreamIterator(s *ast.StreamNode) nodeIterator {
+	return r.newIterator(s.Documents())
 }
 
-func newMappingEntryIterator(m *ast.MappingEntryNode) nodeIterator {
-	return &nodeIteratorImpl{
-		i:     0,
-		nodes: []ast.Node{m.Key(), m.Value()},
-	}
+func (r *ASTReader) newSequenceIterator(s *ast.SequenceNode) nodeIterator {
+	return r.newIterator(s.Entries())
 }
 
-func newPropertiesIterator(p *ast.PropertiesNode) nodeIterator {
-	return &nodeIteratorImpl{
-		i:     0,
-		nodes: []ast.Node{p.Anchor(), p.Tag()},
-	}
+func (r *ASTReader) newMappingIterator(m *ast.MappingNode) nodeIterator {
+	return r.newIterator(m.Entries())
 }
 
-func newContentIterator(c *ast.ContentNode) nodeIterator {
-	return &nodeIteratorImpl{
-		i:     0,
-		nodes: []ast.Node{c.Properties(), c.Content()},
-	}
+func (r *ASTReader) newMappingEntryIterator(m *ast.MappingEntryNode) nodeIterator {
+	return r.newPairIterator(m.Key(), m.Value())
+}
+
+func (r *ASTReader) newPropertiesIterator(p *ast.PropertiesNode) nodeIterator {
+	return r.newPairIterator(p.Anchor(), p.Tag())
+}
+
+func (r *ASTReader) newContentIterator(c *ast.ContentNode) nodeIterator {
+	return r.newPairIterator(c.Properties(), c.Content())
 }
@@ -3,16 +3,23 @@ package decode
 import (
 	"errors"
 	"fmt"
+	"maps"
+	"sync"
 	"time"
 
 	"github.com/KSpaceer/yamly"
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
 	"github.com/KSpaceer/yamly/engines/yayamls/encode"
 	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+	"github.com/KSpaceer/yamly/engines/yayamls/pkg/slab"
 	"github.com/KSpaceer/yamly/engines/yayamls/schema"
 )
 
-var _ yamly.ExtendedDecoder[ast.Node] = (*ASTReader)(nil)
+var (
+	_ yamly.ExtendedDecoder[ast.Node] = (*ASTReader)(nil)
+	_ yamly.BufferingDecoder          = (*ASTReader)(nil)
+	_ yamly.WarningsDecoder           = (*ASTReader)(nil)
+)
 
 type ASTReader struct {
 	route []routePoint
@@ -25,10 +32,30 @@ type ASTReader struct {
 
 	anchors anchorsKeeper
 
+	// arena contains the nodes of AST parsed by reader itself
+	arena *ast.Arena
+	// nodesEscaped shows if the nodes of arena are returned by Node, so the arena must not be reused
+	nodesEscaped bool
+	// escaped points to nodesEscaped of the reader owning the arena with decoded AST.
+	// Readers of buffered subtrees share it with the reader they are buffered by.
+	escaped *bool
+
+	// positions of AST nodes are used to locate duplicate keys
+	positions ast.Positions
+
+	// iterators and states are allocated in slabs to be reused after reset
+	iterators slab.Slab[nodeIteratorImpl]
+	states    slab.Slab[collectionState]
+
 	multipleDenyErrors bool
+	uniqueKeys         bool
 	fatalError         error
-	latestDenyError    error
 	denyErrors         []error
+	warnings           []error
+
+	// latestDeny is turned into error only if it is not discarded to avoid allocations
+	latestDeny    denyError
+	hasLatestDeny bool
 }
 
 type visitingConclusion int8
@@ -103,11 +130,12 @@ func (noopState) Size() int { return 0 }
 
 func (noopState) HasUnprocessedItems() bool { return false }
 
-func newCollectionState(iter nodeIterator, size int) yamly.CollectionState {
-	return &collectionState{
-		size:         size,
-		nodeIterator: iter,
-	}
+// newCollectionState allocates collection state. The state is valid until reader is reset.
+func (r *ASTReader) newCollectionState(iter nodeIterator, size int) yamly.CollectionState { // nolint: ireturn
+	state := r.states.Alloc()
+	state.size = size
+	state.nodeIterator = iter
+	return state
 }
 
 type ReaderOption func(*ASTReader)
@@ -118,12 +146,46 @@ func WithMultipleDenyErrors() ReaderOption {
 	}
 }
 
+// WithUniqueKeys makes reader add yamly.DuplicateKeyError if decoded mapping contains the same key several times.
+// Only scalar keys are checked.
+func WithUniqueKeys() ReaderOption {
+	return func(r *ASTReader) {
+		r.uniqueKeys = true
+	}
+}
+
+// WithPositions makes reader locate duplicate keys using given positions of AST nodes (see parser.WithPositions).
+// NewASTReaderFromBytes records positions of the parsed source into given map. With WithUniqueKeys option
+// it records positions even if this option is not used.
+func WithPositions(positions ast.Positions) ReaderOption {
+	return func(r *ASTReader) {
+		r.positions = positions
+	}
+}
+
+// NewASTReaderFromBytes parses the source and acquires ASTReader for the resulting AST.
+// Release should be called after decoding to reuse the memory allocated for AST and reader.
 func NewASTReaderFromBytes(src []byte, opts ...ReaderOption) (*ASTReader, error) {
-	tree, err := parser.ParseBytes(src, parser.WithOmitStream())
+	// options are applied before parsing to know if positions are required
+	r := acquireASTReader(opts)
+	if r.uniqueKeys && r.positions == nil {
+		r.positions = make(ast.Positions)
+	}
+	arena := ast.NewArena()
+	parseOpts := []parser.ParseOption{parser.WithOmitStream(), parser.WithArena(arena)}
+	if r.positions != nil {
+		parseOpts = append(parseOpts, parser.WithPositions(r.positions))
+	}
+	tree, err := parser.ParseBytes(src, parseOpts...)
 	if err != nil {
+		arena.Release()
+		r.Release()
 		return nil, err
 	}
-	return NewASTReader(tree, opts...), nil
+	r.setAST(tree)
+	r.arena = arena
+	r.escaped = &r.nodesEscaped
+	return r, nil
 }
 
 func NewASTReader(tree ast.Node, opts ...ReaderOption) *ASTReader {
@@ -137,6 +199,51 @@ func NewASTReader(tree ast.Node, opts ...ReaderOption) *ASTReader {
 	return &r
 }
 
+var readerPool = sync.Pool{
+	New: func() any { return &ASTReader{anchors: newAnchorsKeeper()} },
+}
+
+// AcquireASTReader returns ASTReader for given AST from the pool of readers.
+// Release should be called after decoding to return the reader to the pool.
+func AcquireASTReader(tree ast.Node, opts ...ReaderOption) *ASTReader {
+	r := acquireASTReader(opts)
+	r.setAST(tree)
+	return r
+}
+
+func acquireASTReader(opts []ReaderOption) *ASTReader {
+	r := readerPool.Get().(*ASTReader) // nolint: forcetypeassert
+	r.multipleDenyErrors = false
+	r.uniqueKeys = false
+	r.positions = nil
+
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Reset makes the reader decode given AST, keeping the options of the reader.
+// Values obtained from the reader before Reset (e.g. collection states) must not be used after it.
+func (r *ASTReader) Reset(tree ast.Node) {
+	if r.arena != nil {
+		// positions of the source parsed by reader don't belong to the new AST
+		r.positions = nil
+	}
+	r.releaseArena()
+	r.setAST(tree)
+}
+
+// releaseArena makes the arena reusable unless its nodes are returned by Node.
+func (r *ASTReader) releaseArena() {
+	if !r.nodesEscaped {
+		r.arena.Release()
+	}
+	r.arena = nil
+	r.nodesEscaped = false
+	r.escaped = nil
+}
+
 func (r *ASTReader) setAST(tree ast.Node) {
 	r.reset()
 	r.pushRoutePoint(routePoint{
@@ -153,8 +260,8 @@ func (r *ASTReader) TryNull() bool {
 	if r.hasFatalError() {
 		return false
 	}
-	if r.latestDenyError != nil {
-		r.latestDenyError = nil
+	if r.hasLatestDeny {
+		r.hasLatestDeny = false
 		return false
 	}
 	return true
@@ -166,9 +273,8 @@ func (r *ASTReader) Integer(bitSize int) int64 {
 	}
 	r.currentExpecter = expectInteger{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return 0
 	}
 	v, err := schema.ToInteger(r.extractedValue, bitSize)
@@ -185,9 +291,8 @@ func (r *ASTReader) Unsigned(bitSize int) uint64 {
 	}
 	r.currentExpecter = expectInteger{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return 0
 	}
 	v, err := schema.ToUnsignedInteger(r.extractedValue, bitSize)
@@ -204,9 +309,8 @@ func (r *ASTReader) Boolean() bool {
 	}
 	r.currentExpecter = expectBoolean{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return false
 	}
 	v, err := schema.ToBoolean(r.extractedValue)
@@ -223,9 +327,8 @@ func (r *ASTReader) Float(bitSize int) float64 {
 	}
 	r.currentExpecter = expectFloat{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return 0
 	}
 	v, err := schema.ToFloat(r.extractedValue, bitSize)
@@ -242,9 +345,8 @@ func (r *ASTReader) String() string {
 	}
 	r.currentExpecter = expectString{checkForNull: true}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return ""
 	}
 	return r.extractedValue
@@ -256,9 +358,8 @@ func (r *ASTReader) Timestamp() time.Time {
 	}
 	r.currentExpecter = expectTimestamp{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return time.Time{}
 	}
 	v, err := schema.ToTimestamp(r.extractedValue)
@@ -275,9 +376,8 @@ func (r *ASTReader) Sequence() yamly.CollectionState {
 	}
 	r.currentExpecter = expectSequence{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return noopCollectionState
 	}
 	return r.extractedCollectionState
@@ -289,11 +389,18 @@ func (r *ASTReader) Mapping() yamly.CollectionState {
 	}
 	r.currentExpecter = expectMapping{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return noopCollectionState
 	}
+	if r.uniqueKeys {
+		if m, ok := r.currentNode().(*ast.MappingNode); ok {
+			if err := findDuplicateKey(m, r.positions); err != nil {
+				r.appendError(err)
+				return noopCollectionState
+			}
+		}
+	}
 	return r.extractedCollectionState
 }
 
@@ -303,12 +410,11 @@ func (r *ASTReader) Any() any {
 	}
 	r.currentExpecter = expectAny{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return nil
 	}
-	valueBuilder := newAnyBuilder(&r.anchors)
+	valueBuilder := newAnyBuilder(&r.anchors, r.uniqueKeys, r.positions)
 	v, err := valueBuilder.extractAnyValue(r.currentNode())
 	if err != nil {
 		r.appendError(err)
@@ -324,12 +430,12 @@ func (r *ASTReader) Raw() []byte {
 	}
 	r.currentExpecter = expectRaw{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return nil
 	}
-	w := encode.NewASTWriter()
+	w := encode.AcquireASTWriter()
+	defer w.Release()
 	v, err := w.WriteBytes(r.currentNode())
 	if err != nil {
 		r.appendError(err)
@@ -340,14 +446,22 @@ func (r *ASTReader) Raw() []byte {
 }
 
 func (r *ASTReader) Node() ast.Node {
+	n := r.node()
+	if n != nil && r.escaped != nil {
+		// the node is used outside of reader, so the arena must not be reused
+		*r.escaped = true
+	}
+	return n
+}
+
+func (r *ASTReader) node() ast.Node {
 	if r.hasFatalError() {
 		return nil
 	}
 	r.currentExpecter = expectNode{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 		return nil
 	}
 	n := r.currentNode()
@@ -355,25 +469,84 @@ func (r *ASTReader) Node() ast.Node {
 	return n
 }
 
+// Buffer consumes current subtree and returns a function creating independent readers of the subtree
+// with the same options. Anchors met by the reader before the subtree can be dereferenced by the created readers.
+// The subtree remains owned by the reader, so the created readers must not be used after its Release.
+func (r *ASTReader) Buffer() func() yamly.Decoder {
+	anchors := maps.Clone(r.anchors.anchors)
+	n := r.node()
+	if n == nil {
+		return nil
+	}
+	multipleDenyErrors, uniqueKeys, positions, escaped := r.multipleDenyErrors, r.uniqueKeys, r.positions, r.escaped
+	return func() yamly.Decoder {
+		sub := &ASTReader{
+			anchors:            newAnchorsKeeper(),
+			multipleDenyErrors: multipleDenyErrors,
+			uniqueKeys:         uniqueKeys,
+			positions:          positions,
+			escaped:            escaped,
+		}
+		sub.setAST(n)
+		maps.Copy(sub.anchors.anchors, anchors)
+		return sub
+	}
+}
+
+// Release frees AST parsed by reader and returns the reader to the pool of readers.
+// Neither the reader nor nodes returned by it must be used after Release.
+// Nodes obtained with Node method remain valid.
+func (r *ASTReader) Release() {
+	r.reset()
+	r.releaseArena()
+	r.positions = nil
+	readerPool.Put(r)
+}
+
+// ExtendDecoder returns given decoder as yamly.ExtendedDecoder supporting yayamls AST nodes.
+// If the decoder does not support returning nodes, the nodes are obtained by parsing raw YAML.
+func ExtendDecoder(in yamly.Decoder) yamly.ExtendedDecoder[ast.Node] { // nolint: ireturn
+	if extIn, ok := in.(yamly.ExtendedDecoder[ast.Node]); ok {
+		return extIn
+	}
+	return rawNodeDecoder{in}
+}
+
+type rawNodeDecoder struct {
+	yamly.Decoder
+}
+
+func (r rawNodeDecoder) Node() ast.Node {
+	raw := r.Raw()
+	if raw == nil {
+		return nil
+	}
+	tree, err := parser.ParseBytes(raw, parser.WithOmitStream())
+	if err != nil {
+		r.AddError(err)
+		return nil
+	}
+	return tree
+}
+
 func (r *ASTReader) Skip() {
 	if r.hasFatalError() {
 		return
 	}
 	r.currentExpecter = expectSkip{}
 	r.visitCurrentNode()
-	if r.latestDenyError != nil || r.hasFatalError() {
-		r.appendError(r.latestDenyError)
-		r.latestDenyError = nil
+	if r.hasLatestDeny || r.hasFatalError() {
+		r.appendError(r.takeLatestDeny())
 	}
 }
 
 func (r *ASTReader) VisitStreamNode(n *ast.StreamNode) {
 	point := r.peekRoutePoint()
 	if point.visitingResult.conclusion == visitingConclusionUnknown {
-		point.iter = newStreamIterator(n)
+		point.iter = r.newStreamIterator(n)
 	}
 
-	r.processComplexPoint(point, len(n.Documents()))
+	r.processComplexPoint(point, len(n.Documents()), nil)
 }
 
 func (r *ASTReader) VisitTagNode(n *ast.TagNode) {
@@ -405,28 +578,28 @@ func (r *ASTReader) VisitTextNode(n *ast.TextNode) {
 func (r *ASTReader) VisitSequenceNode(n *ast.SequenceNode) {
 	point := r.peekRoutePoint()
 	if point.visitingResult.conclusion == visitingConclusionUnknown {
-		point.iter = newSequenceIterator(n)
+		point.iter = r.newSequenceIterator(n)
 	}
 
-	r.processComplexPoint(point, len(n.Entries()))
+	r.processComplexPoint(point, len(n.Entries()), nil)
 }
 
 func (r *ASTReader) VisitMappingNode(n *ast.MappingNode) {
 	point := r.peekRoutePoint()
 	if point.visitingResult.conclusion == visitingConclusionUnknown {
-		point.iter = newMappingIterator(n)
+		point.iter = r.newMappingIterator(n)
 	}
 
-	r.processComplexPoint(point, len(n.Entries()))
+	r.processComplexPoint(point, len(n.Entries()), nil)
 }
 
 func (r *ASTReader) VisitMappingEntryNode(n *ast.MappingEntryNode) {
 	point := r.peekRoutePoint()
 	if point.visitingResult.conclusion == visitingConclusionUnknown {
-		point.iter = newMappingEntryIterator(n)
+		point.iter = r.newMappingEntryIterator(n)
 	}
 
-	r.processComplexPoint(point, 2)
+	r.processComplexPoint(point, 2, nil)
 }
 
 func (r *ASTReader) VisitNullNode(n *ast.NullNode) {
@@ -459,19 +632,19 @@ func (r *ASTReader) VisitNullNode(n *ast.NullNode) {
 func (r *ASTReader) VisitPropertiesNode(n *ast.PropertiesNode) {
 	point := r.peekRoutePoint()
 	if point.visitingResult.conclusion == visitingConclusionUnknown {
-		point.iter = newPropertiesIterator(n)
+		point.iter = r.newPropertiesIterator(n)
 	}
 
-	r.processComplexPoint(point, 2)
+	r.processComplexPoint(point, 2, nil)
 }
 
 func (r *ASTReader) VisitContentNode(n *ast.ContentNode) {
 	point := r.peekRoutePoint()
 	if point.visitingResult.conclusion == visitingConclusionUnknown {
-		point.iter = newContentIterator(n)
+		point.iter = r.newContentIterator(n)
 	}
 
-	r.processComplexPoint(point, 2, beforeVisit(r.anchors.BindToLatestAnchor))
+	r.processComplexPoint(point, 2, r.anchors.BindToLatestAnchor)
 }
 
 func (r *ASTReader) visitTexterNode(n ast.TexterNode) {
@@ -485,7 +658,7 @@ func (r *ASTReader) visitTexterNode(n ast.TexterNode) {
 	case visitingConclusionMatch:
 	case visitingConclusionDeny:
 		r.swapRoutePoint(point)
-		r.setLatestDeny(&denyError{
+		r.setLatestDeny(denyError{
 			expecter: r.currentExpecter,
 			nt:       point.node.Type(),
 		})
@@ -501,24 +674,9 @@ func (r *ASTReader) visitTexterNode(n ast.TexterNode) {
 	}
 }
 
-type complexPointOptions struct {
-	beforeVisitFuncs []func(ast.Node)
-}
-
-type complexPointOption func(*complexPointOptions)
-
-func beforeVisit(f func(ast.Node)) complexPointOption {
-	return complexPointOption(func(options *complexPointOptions) {
-		options.beforeVisitFuncs = append(options.beforeVisitFuncs, f)
-	})
-}
-
-func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts ...complexPointOption) {
-	var o complexPointOptions
-	for _, opt := range opts {
-		opt(&o)
-	}
-
+// processComplexPoint visits the node of point with children. If beforeVisit is not nil,
+// it is called for every child before visiting it.
+func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, beforeVisit func(ast.Node)) {
 	point.visitingResult = r.currentExpecter.process(point.node, point.visitingResult)
 	r.lastVisitingResult = point.visitingResult
 	r.swapRoutePoint(point)
@@ -527,7 +685,7 @@ func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts
 		r.popRoutePoint()
 	case visitingConclusionMatch:
 	case visitingConclusionDeny:
-		r.setLatestDeny(&denyError{
+		r.setLatestDeny(denyError{
 			expecter: r.currentExpecter,
 			nt:       point.node.Type(),
 		})
@@ -535,8 +693,8 @@ func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts
 		for r.lastVisitingResult.conclusion == visitingConclusionContinue && !point.iter.empty() {
 			node := point.iter.node()
 
-			for _, beforeVisitFunc := range o.beforeVisitFuncs {
-				beforeVisitFunc(node)
+			if beforeVisit != nil {
+				beforeVisit(node)
 			}
 
 			if ast.ValidNode(node) {
@@ -555,7 +713,7 @@ func (r *ASTReader) processComplexPoint(point routePoint, childrenSize int, opts
 	}
 
 	if point.visitingResult.action == visitingActionExtract {
-		r.extractedCollectionState = newCollectionState(point.iter, childrenSize)
+		r.extractedCollectionState = r.newCollectionState(point.iter, childrenSize)
 		r.extractedValue = ""
 	}
 }
@@ -570,15 +728,20 @@ func (r *ASTReader) visitCurrentNode() {
 }
 
 func (r *ASTReader) reset() {
+	clear(r.route)
 	r.route = r.route[:0]
+	r.iterators.Reset()
+	r.states.Reset()
 	r.currentExpecter = nil
 	r.lastVisitingResult = visitingResult{}
 	r.extractedCollectionState = nil
 	r.extractedValue = ""
 	r.anchors.clear()
 	r.fatalError = nil
-	r.latestDenyError = nil
+	r.latestDeny = denyError{}
+	r.hasLatestDeny = false
 	r.denyErrors = r.denyErrors[:0]
+	r.warnings = nil
 }
 
 func (r *ASTReader) currentNode() ast.Node {
@@ -616,8 +779,19 @@ func (r *ASTReader) swapRoutePoint(point routePoint) {
 	r.route[len(r.route)-1] = point
 }
 
-func (r *ASTReader) setLatestDeny(err *denyError) {
-	r.latestDenyError = yamly.DenyError(err)
+func (r *ASTReader) setLatestDeny(err denyError) {
+	r.latestDeny = err
+	r.hasLatestDeny = true
+}
+
+// takeLatestDeny returns the latest deny as error and discards it.
+func (r *ASTReader) takeLatestDeny() error {
+	if !r.hasLatestDeny {
+		return nil
+	}
+	r.hasLatestDeny = false
+	err := r.latestDeny
+	return yamly.DenyError(&err)
 }
 
 func (r *ASTReader) appendError(err error) {
@@ -645,3 +819,13 @@ func (r *ASTReader) AddError(err error) {
 		r.fatalError = err
 	}
 }
+
+func (r *ASTReader) AddWarning(w error) {
+	if w != nil {
+		r.warnings = append(r.warnings, w)
+	}
+}
+
+func (r *ASTReader) Warnings() []error {
+	return r.warnings
+}
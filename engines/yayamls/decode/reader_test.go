@@ -11,6 +11,7 @@ import (
 
 	"github.com/KSpaceer/yamly"
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astcmp"
 	"github.com/KSpaceer/yamly/engines/yayamls/decode"
 	"github.com/KSpaceer/yamly/engines/yayamls/parser"
 )
@@ -867,6 +868,11 @@ func TestReader_ExpectAny(t *testing.T) {
 			src:      "'null'",
 			expected: "null",
 		},
+		{
+			name:     "quoted number",
+			src:      `"255"`,
+			expected: "255",
+		},
 		{
 			name:     "null",
 			src:      "null",
@@ -1015,17 +1021,17 @@ func TestReader_ExpectRaw(t *testing.T) {
 		{
 			name:     "simple sequence",
 			src:      "[1, 2, 3]",
-			expected: []byte("- 1\n- 2\n- 3\n"),
+			expected: []byte("[1, 2, 3]"),
 		},
 		{
 			name:     "sequence of mappings",
 			src:      "[{1: 2}, {3: 4}, {5: 6}]",
-			expected: []byte("- 1: 2\n- 3: 4\n- 5: 6\n"),
+			expected: []byte("[{1: 2}, {3: 4}, {5: 6}]"),
 		},
 		{
 			name:     "mapping of sequences",
 			src:      "[1, 2, 3]: [4, 5, 6]",
-			expected: []byte("? - 1\n  - 2\n  - 3\n:\n  - 4\n  - 5\n  - 6\n"),
+			expected: []byte("[1, 2, 3]: [4, 5, 6]\n"),
 		},
 	}
 
@@ -1195,6 +1201,250 @@ func TestReader_K8SManifest(t *testing.T) {
 	}
 }
 
+func TestReader_Release(t *testing.T) {
+	t.Parallel()
+
+	r, err := decode.NewASTReaderFromBytes([]byte("a: [b, c]\nd: {e: f}\n"))
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	state := r.Mapping()
+	if key := r.String(); key != "a" {
+		t.Fatalf("expected key %q, but got %q", "a", key)
+	}
+	node := r.Node()
+	for state.HasUnprocessedItems() {
+		r.Skip()
+	}
+	if err = r.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.Release()
+
+	// decoding another source, possibly reusing memory of the released reader
+	r, err = decode.NewASTReaderFromBytes([]byte("x: [y, z]\n"))
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	value := r.Any()
+	if err = r.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.Release()
+
+	expectedValue := map[string]any{"x": []any{"y", "z"}}
+	if !reflect.DeepEqual(expectedValue, value) {
+		t.Errorf("values are not equal:\nexpected: %v\n\ngot: %v", expectedValue, value)
+	}
+
+	expectedNode, err := parser.ParseString("[b, c]", parser.WithOmitStream())
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	if !astcmp.NewComparator().Equal(expectedNode, node) {
+		t.Errorf("node obtained before release was changed")
+	}
+}
+
+func TestReader_ReleaseBuffered(t *testing.T) {
+	t.Parallel()
+
+	r, err := decode.NewASTReaderFromBytes([]byte("a: {b: [c, d]}\n"))
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	state := r.Mapping()
+	if key := r.String(); key != "a" {
+		t.Fatalf("expected key %q, but got %q", "a", key)
+	}
+	next := r.Buffer()
+	for state.HasUnprocessedItems() {
+		r.Skip()
+	}
+	sub, ok := next().(*decode.ASTReader)
+	if !ok {
+		t.Fatalf("expected buffered reader to be *decode.ASTReader")
+	}
+	sub.Mapping()
+	if key := sub.String(); key != "b" {
+		t.Fatalf("expected key %q, but got %q", "b", key)
+	}
+	node := sub.Node()
+	if err = errors.Join(r.Error(), sub.Error()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.Release()
+
+	// decoding another source, possibly reusing memory of the released reader
+	r, err = decode.NewASTReaderFromBytes([]byte("x: {y: [z, w]}\n"))
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	r.Any()
+	if err = r.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	r.Release()
+
+	expectedNode, err := parser.ParseString("[c, d]", parser.WithOmitStream())
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	if !astcmp.NewComparator().Equal(expectedNode, node) {
+		t.Errorf("node obtained from buffered reader before release was changed")
+	}
+}
+
+// TestReader_Acquire is not parallel, because testing.AllocsPerRun panics in parallel tests.
+func TestReader_Acquire(t *testing.T) {
+	tree, err := parser.ParseString("name: pvc-claim\nreplicas: 3\naccessModes: [ReadWriteOnce, ReadOnlyMany]\n",
+		parser.WithOmitStream())
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+
+	type value struct {
+		name        string
+		replicas    int64
+		accessModes []string
+	}
+
+	decodeWithPool := func(v *value) error {
+		r := decode.AcquireASTReader(tree)
+		defer r.Release()
+		state := r.Mapping()
+		for state.HasUnprocessedItems() {
+			key := r.String()
+			if r.TryNull() {
+				continue
+			}
+			switch key {
+			case "name":
+				v.name = r.String()
+			case "replicas":
+				v.replicas = r.Integer(64)
+			case "accessModes":
+				v.accessModes = v.accessModes[:0]
+				seqState := r.Sequence()
+				for seqState.HasUnprocessedItems() {
+					v.accessModes = append(v.accessModes, r.String())
+				}
+			default:
+				r.Skip()
+			}
+		}
+		return r.Error()
+	}
+
+	expected := value{
+		name:        "pvc-claim",
+		replicas:    3,
+		accessModes: []string{"ReadWriteOnce", "ReadOnlyMany"},
+	}
+	var v value
+	// decoding several times to check that released readers are reused correctly
+	for i := 0; i < 3; i++ {
+		if err := decodeWithPool(&v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if !reflect.DeepEqual(expected, v) {
+			t.Fatalf("values are not equal:\nexpected: %v\n\ngot: %v", expected, v)
+		}
+	}
+
+	// the value reuses its slice, so no allocations are expected in steady state
+	allocs := testing.AllocsPerRun(100, func() {
+		if err := decodeWithPool(&v); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if allocs > 0 {
+		t.Errorf("expected no allocations, but got %v", allocs)
+	}
+}
+
+func TestReader_Reset(t *testing.T) {
+	t.Parallel()
+
+	r, err := decode.NewASTReaderFromBytes([]byte("[a, b]"), decode.WithMultipleDenyErrors())
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	defer r.Release()
+	r.Sequence()
+	r.Boolean()
+	if err = r.Error(); !errors.Is(err, yamly.ErrDenied) {
+		t.Fatalf("expected deny error, but got %v", err)
+	}
+
+	tree, err := parser.ParseString("{c: d}", parser.WithOmitStream())
+	if err != nil {
+		t.Fatalf("parser failed: %v", err)
+	}
+	r.Reset(tree)
+	value := r.Any()
+	if err = r.Error(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedValue := map[string]any{"c": "d"}
+	if !reflect.DeepEqual(expectedValue, value) {
+		t.Errorf("values are not equal:\nexpected: %v\n\ngot: %v", expectedValue, value)
+	}
+}
+
+func TestExtendDecoder(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name string
+		wrap func(r *decode.ASTReader) yamly.Decoder
+	}
+
+	tcases := []tcase{
+		{
+			name: "reader",
+			wrap: func(r *decode.ASTReader) yamly.Decoder { return r },
+		},
+		{
+			name: "decoder without nodes support",
+			wrap: func(r *decode.ASTReader) yamly.Decoder { return struct{ yamly.Decoder }{r} },
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, err := parser.ParseString("a: [b, {c: d}]\ne: f\n", parser.WithOmitStream())
+			if err != nil {
+				t.Fatalf("parser failed: %v", err)
+			}
+			r := decode.NewASTReader(tree)
+			in := tc.wrap(r)
+			state := in.Mapping()
+			if key := in.String(); key != "a" {
+				t.Fatalf("expected key %q, but got %q", "a", key)
+			}
+			node := decode.ExtendDecoder(in).Node()
+			for state.HasUnprocessedItems() {
+				in.Skip()
+			}
+			if err = in.Error(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			expected, err := parser.ParseString("[b, {c: d}]", parser.WithOmitStream())
+			if err != nil {
+				t.Fatalf("parser failed: %v", err)
+			}
+			if !astcmp.NewComparator().Equal(expected, node) {
+				t.Errorf("unexpected node")
+			}
+		})
+	}
+}
+
 type valueStore []any
 
 func (vs *valueStore) Add(v any) {
@@ -1204,3 +1454,106 @@ func (vs *valueStore) Add(v any) {
 func (vs *valueStore) Values() []any {
 	return *vs
 }
+
+func TestReader_UniqueKeys(t *testing.T) {
+	t.Parallel()
+
+	readMapping := func(r yamly.Decoder) {
+		state := r.Mapping()
+		for state.HasUnprocessedItems() {
+			r.Skip()
+		}
+	}
+	readAny := func(r yamly.Decoder) {
+		r.Any()
+	}
+
+	type tcase struct {
+		name     string
+		src      string
+		read     func(r yamly.Decoder)
+		expected *yamly.DuplicateKeyError
+	}
+
+	tcases := []tcase{
+		{
+			name: "unique keys",
+			src:  "replicas: 1\nname: app",
+			read: readMapping,
+		},
+		{
+			name: "duplicate keys",
+			src:  "replicas: 1\nname: app\nreplicas: 3",
+			read: readMapping,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "replicas",
+				First:  yamly.KeyPosition{Entry: 1, Line: 1, Column: 1},
+				Second: yamly.KeyPosition{Entry: 3, Line: 3, Column: 1},
+			},
+		},
+		{
+			name: "differently quoted duplicate keys",
+			src:  "{'name': first, \"name\": second}",
+			read: readMapping,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "name",
+				First:  yamly.KeyPosition{Entry: 1, Line: 1, Column: 2},
+				Second: yamly.KeyPosition{Entry: 2, Line: 1, Column: 17},
+			},
+		},
+		{
+			name: "null keys",
+			src:  "? ~\n: first\n? null\n: second",
+			read: readMapping,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "null",
+				First:  yamly.KeyPosition{Entry: 1, Line: 1, Column: 3},
+				Second: yamly.KeyPosition{Entry: 2, Line: 3, Column: 3},
+			},
+		},
+		{
+			name: "merge keys",
+			src:  "base: &base {a: 1}\nother: &other {b: 2}\nvalue:\n  <<: *base\n  <<: *other",
+			read: readAny,
+		},
+		{
+			name: "any",
+			src:  "spec:\n  replicas: 1\n  replicas: 3",
+			read: readAny,
+			expected: &yamly.DuplicateKeyError{
+				Key:    "replicas",
+				First:  yamly.KeyPosition{Entry: 1, Line: 2, Column: 3},
+				Second: yamly.KeyPosition{Entry: 2, Line: 3, Column: 3},
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			r, err := decode.NewASTReaderFromBytes([]byte(tc.src), decode.WithUniqueKeys())
+			if err != nil {
+				t.Fatalf("failed to parse YAML: %v", err)
+			}
+			defer r.Release()
+			tc.read(r)
+
+			err = r.Error()
+			if tc.expected == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var dkErr *yamly.DuplicateKeyError
+			if !errors.As(err, &dkErr) {
+				t.Fatalf("expected duplicate key error, but got %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, dkErr) {
+				t.Errorf("expected error %v, but got %v", tc.expected, dkErr)
+			}
+		})
+	}
+}
@@ -1,7 +1,16 @@
 package yayamls
 
+import "github.com/KSpaceer/yamly/engines/yayamls/ast"
+
 // Marshaler interface can be implemented to customize type's behaviour when being
 // marshaled into a YAML document returning raw representation
 type Marshaler interface {
 	MarshalYAML() ([]byte, error)
 }
+
+// NodeMarshaler interface can be implemented to customize type's behaviour when being
+// marshaled into a YAML document returning AST subtree. Unlike Marshaler, the subtree
+// is inserted as is, without serializing and parsing.
+type NodeMarshaler interface {
+	MarshalYAMLNode() (ast.Node, error)
+}
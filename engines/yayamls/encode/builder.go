@@ -2,6 +2,7 @@ package encode
 
 import (
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/KSpaceer/yamly"
@@ -10,15 +11,25 @@ import (
 	"github.com/KSpaceer/yamly/engines/yayamls/schema"
 )
 
-var _ yamly.TreeBuilder[ast.Node] = (*ASTBuilder)(nil)
+var (
+	_ yamly.TreeBuilder[ast.Node]      = (*ASTBuilder)(nil)
+	_ yamly.ExtendedInserter[ast.Node] = (*ASTBuilder)(nil)
+	_ yamly.StyledInserter             = (*ASTBuilder)(nil)
+)
 
 // ASTBuilder implements yamly.TreeBuilder
 type ASTBuilder struct {
 	root  ast.Node
 	route []ast.Node
 
+	// arena is used to allocate nodes of pooled builder
+	arena *ast.Arena
+
 	opts builderOpts
 
+	// style is the style of the next inserted node
+	style yamly.Style
+
 	fatalError error
 }
 
@@ -46,6 +57,42 @@ func NewASTBuilder(opts ...ASTBuilderOption) *ASTBuilder {
 	return &b
 }
 
+var builderPool = sync.Pool{
+	New: func() any { return new(ASTBuilder) },
+}
+
+// AcquireASTBuilder returns ASTBuilder from the pool of builders. The nodes of AST built by
+// acquired builder are allocated from arena, so the AST is valid only until Reset or Release.
+// Release should be called after encoding to return the builder to the pool.
+func AcquireASTBuilder(opts ...ASTBuilderOption) *ASTBuilder {
+	b := builderPool.Get().(*ASTBuilder) // nolint: forcetypeassert
+	b.opts = builderOpts{}
+
+	for _, opt := range opts {
+		opt(&b.opts)
+	}
+	b.arena = ast.NewArena()
+	return b
+}
+
+// Reset discards the AST built by the builder, keeping the options of the builder.
+func (b *ASTBuilder) Reset() {
+	b.reset()
+	if b.arena != nil {
+		b.arena.Release()
+		b.arena = ast.NewArena()
+	}
+}
+
+// Release frees AST built by the builder and returns the builder to the pool of builders.
+// Neither the builder nor the AST must be used after Release.
+func (b *ASTBuilder) Release() {
+	b.reset()
+	b.arena.Release()
+	b.arena = nil
+	builderPool.Put(b)
+}
+
 func (b *ASTBuilder) InsertInteger(val int64) {
 	insertNonNullValue(b, val, schema.FromInteger, ast.AbsentQuotingType)
 }
@@ -63,9 +110,21 @@ func (b *ASTBuilder) InsertFloat(val float64) {
 }
 
 func (b *ASTBuilder) InsertString(val string) {
-	quoting := ast.DoubleQuotingType
-	if b.opts.unquoteOneliners && !isMultiline(val) {
-		quoting = ast.AbsentQuotingType
+	var quoting ast.QuotingType
+	switch b.style {
+	case yamly.LiteralStyle:
+		quoting = ast.LiteralQuotingType
+	case yamly.FoldedStyle:
+		quoting = ast.FoldedQuotingType
+	case yamly.SingleQuotedStyle:
+		quoting = ast.SingleQuotingType
+	case yamly.DoubleQuotedStyle:
+		quoting = ast.DoubleQuotingType
+	default:
+		quoting = ast.DoubleQuotingType
+		if b.opts.unquoteOneliners && !isMultiline(val) {
+			quoting = ast.AbsentQuotingType
+		}
 	}
 	insertNonNullValue(b, val, func(t string) string { return t }, quoting)
 }
@@ -79,7 +138,8 @@ func (b *ASTBuilder) InsertNull() {
 }
 
 func (b *ASTBuilder) StartSequence() {
-	sequence := ast.NewSequenceNode(nil)
+	sequence := b.arena.NewSequenceNode(nil)
+	sequence.SetFlow(b.style == yamly.FlowStyle)
 	b.insertNode(sequence, true)
 }
 
@@ -99,7 +159,8 @@ func (b *ASTBuilder) EndSequence() {
 }
 
 func (b *ASTBuilder) StartMapping() {
-	mapping := ast.NewMappingNode(nil)
+	mapping := b.arena.NewMappingNode(nil)
+	mapping.SetFlow(b.style == yamly.FlowStyle)
 	b.insertNode(mapping, true)
 }
 
@@ -138,6 +199,63 @@ func (b *ASTBuilder) InsertRaw(data []byte, err error) {
 	b.insertNode(tree, false)
 }
 
+// InsertNode inserts given AST as subtree without copying it.
+func (b *ASTBuilder) InsertNode(n ast.Node, err error) {
+	if b.fatalError != nil {
+		return
+	}
+	if err != nil {
+		b.fatalError = err
+		return
+	}
+	if n == nil {
+		b.InsertNull()
+		return
+	}
+	switch n.Type() {
+	case ast.InvalidType:
+		b.fatalError = fmt.Errorf("failed to insert node: node is invalid")
+		return
+	case ast.StreamType:
+		documents := n.(*ast.StreamNode).Documents() // nolint: forcetypeassert
+		if len(documents) != 1 {
+			b.fatalError = fmt.Errorf("failed to insert node: expected single document, got stream of documents")
+			return
+		}
+		n = documents[0]
+	}
+	b.insertNode(n, false)
+}
+
+// ExtendInserter returns given inserter as yamly.ExtendedInserter supporting yayamls AST nodes.
+// If the inserter does not support inserting nodes, the nodes are serialized and inserted as raw YAML.
+func ExtendInserter(out yamly.Inserter) yamly.ExtendedInserter[ast.Node] { // nolint: ireturn
+	if extOut, ok := yamly.AsExtendedInserter[ast.Node](out); ok {
+		return extOut
+	}
+	return rawNodeInserter{out}
+}
+
+type rawNodeInserter struct {
+	yamly.Inserter
+}
+
+func (r rawNodeInserter) InsertNode(n ast.Node, err error) {
+	if err != nil {
+		r.InsertRaw(nil, err)
+		return
+	}
+	w := AcquireASTWriter()
+	defer w.Release()
+	r.InsertRaw(w.WriteBytes(n))
+}
+
+func (r rawNodeInserter) SetStyle(style yamly.Style) {
+	if styled, ok := r.Inserter.(yamly.StyledInserter); ok {
+		styled.SetStyle(style)
+	}
+}
+
 func (b *ASTBuilder) InsertRawText(text []byte, err error) {
 	if b.fatalError != nil {
 		return
@@ -149,18 +267,30 @@ func (b *ASTBuilder) InsertRawText(text []byte, err error) {
 	b.InsertString(string(text))
 }
 
+// SetStyle sets the style of the next inserted node. Scalar styles are mapped to ast.QuotingType
+// of string text nodes and FlowStyle makes sequences and mappings be written in flow style.
+func (b *ASTBuilder) SetStyle(style yamly.Style) {
+	b.style = style
+}
+
 func (b *ASTBuilder) Result() (ast.Node, error) {
 	root := b.root
 	err := b.fatalError
+	b.reset()
+	return root, err
+}
+
+func (b *ASTBuilder) reset() {
+	clear(b.route)
 	b.route = b.route[:0]
 	b.root = nil
+	b.style = yamly.DefaultStyle
 	b.fatalError = nil
-	return root, err
 }
 
 func insertNonNullValue[T any](t *ASTBuilder, val T, converter func(T) string, quotingType ast.QuotingType) {
 	t.insertNode(
-		ast.NewTextNode(
+		t.arena.NewTextNode(
 			converter(val),
 			ast.WithQuotingType(quotingType),
 		),
@@ -169,6 +299,8 @@ func insertNonNullValue[T any](t *ASTBuilder, val T, converter func(T) string, q
 }
 
 func (b *ASTBuilder) insertNode(n ast.Node, pushToRoute bool) {
+	// style is applied only to the next inserted node
+	b.style = yamly.DefaultStyle
 	if b.fatalError != nil {
 		return
 	}
@@ -182,8 +314,8 @@ func (b *ASTBuilder) insertNode(n ast.Node, pushToRoute bool) {
 	switch currentNode.Type() {
 	case ast.MappingType:
 		mapping := currentNode.(*ast.MappingNode) // nolint: forcetypeassert
-		entry := ast.NewMappingEntryNode(n, nil)
-		mapping.AppendEntry(entry)
+		entry := b.arena.NewMappingEntryNode(n, nil)
+		b.arena.AppendMappingEntry(mapping, entry)
 		b.pushNode(entry)
 	case ast.MappingEntryType:
 		entry := currentNode.(*ast.MappingEntryNode) // nolint: forcetypeassert
@@ -191,7 +323,7 @@ func (b *ASTBuilder) insertNode(n ast.Node, pushToRoute bool) {
 		b.popNode()
 	case ast.SequenceType:
 		sequence := currentNode.(*ast.SequenceNode) // nolint: forcetypeassert
-		sequence.AppendEntry(n)
+		b.arena.AppendSequenceEntry(sequence, n)
 	default:
 		b.fatalError = fmt.Errorf(
 			"cannot insert new node to tree: currenlty at node with type %s",
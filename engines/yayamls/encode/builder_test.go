@@ -364,6 +364,92 @@ func TestBuilder_InsertRaw(t *testing.T) {
 	}
 }
 
+func TestBuilder_InsertNode(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name   string
+		insert func(b *encode.ASTBuilder, n ast.Node)
+	}
+
+	tcases := []tcase{
+		{
+			name: "builder",
+			insert: func(b *encode.ASTBuilder, n ast.Node) {
+				b.InsertNode(n, nil)
+			},
+		},
+		{
+			name: "encoder",
+			insert: func(b *encode.ASTBuilder, n ast.Node) {
+				encode.ExtendInserter(yamly.NewEncoder(b, encode.NewASTWriter())).InsertNode(n, nil)
+			},
+		},
+		{
+			name: "inserter without nodes support",
+			insert: func(b *encode.ASTBuilder, n ast.Node) {
+				encode.ExtendInserter(struct{ yamly.Inserter }{b}).InsertNode(n, nil)
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			node := ast.NewSequenceNode([]ast.Node{
+				ast.NewTextNode("1"),
+				ast.NewMappingNode([]ast.Node{
+					ast.NewMappingEntryNode(ast.NewTextNode("a"), ast.NewTextNode("b")),
+				}),
+			})
+
+			b := encode.NewASTBuilder()
+			b.StartMapping()
+			b.InsertString("node")
+			tc.insert(b, node)
+			b.EndMapping()
+			result, err := b.Result()
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			compareAST(t, ast.NewMappingNode([]ast.Node{
+				ast.NewMappingEntryNode(
+					ast.NewTextNode("node", ast.WithQuotingType(ast.DoubleQuotingType)),
+					node,
+				),
+			}), result)
+		})
+	}
+}
+
+func TestExtendInserter_Union(t *testing.T) {
+	t.Parallel()
+
+	node := ast.NewSequenceNode([]ast.Node{ast.NewTextNode("1")})
+
+	b := encode.NewASTBuilder()
+	yamly.EncodeUnion(b, "kind", "nodes", func(out yamly.Inserter) {
+		out.StartMapping()
+		out.InsertString("node")
+		encode.ExtendInserter(out).InsertNode(node, nil)
+		out.EndMapping()
+	})
+	result, err := b.Result()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mapping, ok := result.(*ast.MappingNode)
+	if !ok || len(mapping.Entries()) != 2 {
+		t.Fatalf("expected mapping with discriminator and node entries, but got %v", result)
+	}
+	entry, ok := mapping.Entries()[1].(*ast.MappingEntryNode)
+	if !ok || entry.Value() != node {
+		t.Errorf("expected node to be inserted by the builder without serialization")
+	}
+}
+
 func TestBuilder_K8SManifest(t *testing.T) {
 	/*
 			apiVersion: v1
@@ -484,6 +570,92 @@ func TestBuilder_K8SManifest(t *testing.T) {
 	compareAST(t, expected, result)
 }
 
+// TestBuilder_Acquire is not parallel, because testing.AllocsPerRun panics in parallel tests.
+func TestBuilder_Acquire(t *testing.T) {
+	build := func(b yamly.TreeBuilder[ast.Node]) {
+		b.StartMapping()
+		b.InsertString("name")
+		b.InsertString("pvc-claim")
+		b.InsertString("accessModes")
+		b.StartSequence()
+		for i := 0; i < 20; i++ {
+			b.InsertString("ReadWriteOnce")
+		}
+		b.EndSequence()
+		b.InsertString("labels")
+		b.StartMapping()
+		b.InsertString("app")
+		b.InsertString("web")
+		b.EndMapping()
+		b.EndMapping()
+	}
+
+	b := encode.NewASTBuilder(encode.WithUnquotedOneLineStrings())
+	build(b)
+	expected, err := b.Result()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedText, err := encode.NewASTWriter().WriteString(expected)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	encodeWithPools := func() ([]byte, error) {
+		b := encode.AcquireASTBuilder(encode.WithUnquotedOneLineStrings())
+		defer b.Release()
+		build(b)
+		tree, err := b.Result()
+		if err != nil {
+			return nil, err
+		}
+		w := encode.AcquireASTWriter()
+		defer w.Release()
+		return w.WriteBytes(tree)
+	}
+
+	// encoding several times to check that released builders and writers are reused correctly
+	for i := 0; i < 3; i++ {
+		result, err := encodeWithPools()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if string(result) != expectedText {
+			t.Fatalf("expected:\n%s\n\ngot:\n%s", expectedText, result)
+		}
+	}
+
+	// only the resulting bytes are expected to be allocated in steady state
+	allocs := testing.AllocsPerRun(100, func() {
+		if _, err := encodeWithPools(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	})
+	if allocs > 1 {
+		t.Errorf("expected at most 1 allocation, but got %v", allocs)
+	}
+}
+
+func TestBuilder_Reset(t *testing.T) {
+	t.Parallel()
+
+	for _, b := range []*encode.ASTBuilder{encode.NewASTBuilder(), encode.AcquireASTBuilder()} {
+		b.StartMapping()
+		b.InsertString("key")
+		b.Reset()
+
+		b.StartSequence()
+		b.InsertNull()
+		b.EndSequence()
+		result, err := b.Result()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		compareAST(t, ast.NewSequenceNode([]ast.Node{ast.NewNullNode()}), result)
+		b.Release()
+	}
+}
+
 func compareAST(t *testing.T, expectedAST, gotAST ast.Node) {
 	t.Helper()
 
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"io"
 	"strings"
+	"sync"
+	"unicode/utf8"
 
 	"github.com/KSpaceer/yamly"
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
@@ -30,25 +32,69 @@ type ASTWriter struct {
 	beforeComplex string
 	beforeSimple  string
 
+	// flowLevel is the nesting level of flow collections, block scalars can't be written inside them
+	flowLevel int
+	// inKey shows if mapping key is being written, block scalars can't be written as keys
+	inKey bool
+
 	metAnchors map[string]struct{}
 
+	// lineComment is the comment to write at the end of the current line
+	lineComment string
+
 	opts writeOptions
 }
 
 func NewASTWriter(opts ...WriteOption) *ASTWriter {
 	w := ASTWriter{
-		buf:              bytes.NewBuffer(nil),
-		errors:           nil,
-		indentation:      defaultBasicIndentation,
-		indentationDelta: defaultIndendationDelta,
-		metAnchors:       map[string]struct{}{},
+		buf:        bytes.NewBuffer(nil),
+		errors:     nil,
+		metAnchors: map[string]struct{}{},
 	}
+	w.configure(opts)
+	return &w
+}
 
+var writerPool = sync.Pool{
+	New: func() any {
+		return &ASTWriter{
+			buf:        bytes.NewBuffer(nil),
+			metAnchors: map[string]struct{}{},
+		}
+	},
+}
+
+// AcquireASTWriter returns ASTWriter from the pool of writers.
+// Release should be called after writing to return the writer to the pool.
+func AcquireASTWriter(opts ...WriteOption) *ASTWriter {
+	w := writerPool.Get().(*ASTWriter) // nolint: forcetypeassert
+	w.configure(opts)
+	return w
+}
+
+func (w *ASTWriter) configure(opts []WriteOption) {
+	w.opts = writeOptions{}
 	for _, opt := range opts {
 		opt(&w.opts)
 	}
 
-	return &w
+	w.indentation = defaultBasicIndentation
+	w.indentationDelta = defaultIndendationDelta
+	if w.opts.indentationDelta > 0 {
+		w.indentationDelta = w.opts.indentationDelta
+	}
+}
+
+// Reset discards the data written to internal buffer, keeping the options of the writer.
+func (w *ASTWriter) Reset() {
+	w.reset()
+}
+
+// Release returns the writer to the pool of writers. The writer must not be used after Release.
+func (w *ASTWriter) Release() {
+	w.reset()
+	w.opts = writeOptions{}
+	writerPool.Put(w)
 }
 
 // AnchorsKeeper defines methods to bind anchors to aliases and dereference aliases.
@@ -65,12 +111,41 @@ type AnchorsKeeper interface {
 }
 
 type writeOptions struct {
-	anchorsKeeper AnchorsKeeper
+	anchorsKeeper    AnchorsKeeper
+	indentationDelta int
+	lineWidth        int
+	comments         ast.CommentMap
 }
 
 // WriteOption allows to modify ASTWriter behavior
 type WriteOption func(*writeOptions)
 
+// WithIndentation makes ASTWriter use given amount of spaces for every nesting level
+// instead of the default two.
+func WithIndentation(spaces int) WriteOption {
+	return func(options *writeOptions) {
+		options.indentationDelta = spaces
+	}
+}
+
+// WithLineWidth makes ASTWriter fold quoted strings longer than given width
+// into several lines. Strings are broken only at single spaces, so a word longer than
+// the width is left intact. Non-positive width disables folding.
+func WithLineWidth(width int) WriteOption {
+	return func(options *writeOptions) {
+		options.lineWidth = width
+	}
+}
+
+// WithComments makes ASTWriter write comments attached to the nodes (see ast.NewCommentMap).
+// Head comments are written on separate lines before the node, line comment - at the end
+// of the node's first line and foot comments - after the document.
+func WithComments(cm ast.CommentMap) WriteOption {
+	return func(options *writeOptions) {
+		options.comments = cm
+	}
+}
+
 // WithAnchorsKeeper makes ASTWriter use provided AnchorsKeeper and dereference
 // unknown aliases (i.e. aliases for anchors which are not contained in written AST)
 func WithAnchorsKeeper(ak AnchorsKeeper) WriteOption {
@@ -103,15 +178,21 @@ func (w *ASTWriter) WriteBytes(ast ast.Node) ([]byte, error) {
 	if err := w.write(ast); err != nil {
 		return nil, err
 	}
-	data := w.buf.Bytes()
-	w.buf = bytes.NewBuffer(nil)
+	// copying data to keep the buffer for the next writing
+	data := bytes.Clone(w.buf.Bytes())
+	w.buf.Reset()
 	return data, nil
 }
 
-func (w *ASTWriter) write(ast ast.Node) error {
+func (w *ASTWriter) write(tree ast.Node) error {
 	w.reset()
 
-	ast.Accept(w)
+	if tree.Type() == ast.StreamType {
+		tree.Accept(w)
+		w.writeFootComments(tree)
+	} else {
+		w.writeDocument(tree)
+	}
 	if w.hasErrors() {
 		return w.error()
 	}
@@ -133,13 +214,21 @@ func (w *ASTWriter) error() error {
 func (w *ASTWriter) VisitStreamNode(n *ast.StreamNode) {
 	for _, doc := range n.Documents() {
 		w.buf.WriteString("---\n")
-		doc.Accept(w)
+		w.writeDocument(doc)
+		// documents written on a single line (scalars and flow collections) must be terminated
+		w.maybeWriteLineBreak()
 		w.buf.WriteString("...\n")
 	}
 }
 
 func (w *ASTWriter) VisitTagNode(n *ast.TagNode) {
-	w.buf.WriteString("!!")
+	if n.Verbatim() {
+		w.buf.WriteString("!<")
+		w.buf.WriteString(n.Text())
+		w.buf.WriteByte('>')
+		return
+	}
+	w.buf.WriteString(n.Handle())
 	w.buf.WriteString(n.Text())
 }
 
@@ -174,34 +263,63 @@ func (w *ASTWriter) VisitAliasNode(n *ast.AliasNode) {
 
 func (w *ASTWriter) VisitTextNode(n *ast.TextNode) {
 	w.writePreparedData(n)
-	switch txt := n.Text(); n.QuotingType() {
-	case ast.AbsentQuotingType:
-		if isMultiline(txt) {
-			w.writeMultilineLiteralText(txt)
-		} else {
+	switch txt, chomping := n.Text(), n.ChompingType(); n.QuotingType() {
+	case ast.AbsentQuotingType, ast.UnknownQuotingType:
+		// multiline text can't be written as plain scalar, so it is written as block scalar
+		switch {
+		case isMultiline(txt) && w.canWriteBlockText(txt):
+			w.writeMultilineLiteralText(txt, chomping)
+		case isMultiline(txt) || w.needsQuotingInFlow(txt):
+			w.writeDoubleQuotedText(txt)
+		default:
 			w.buf.WriteString(txt)
 		}
 	case ast.SingleQuotingType:
-		w.writeSingleQuotedText(txt)
+		if n.Escaped() {
+			w.writeQuotedText('\'', txt)
+		} else {
+			w.writeSingleQuotedText(txt)
+		}
 	case ast.DoubleQuotingType:
-		w.writeDoubleQuotedText(txt)
-	default:
-		if isMultiline(txt) {
+		if n.Escaped() {
+			w.writeQuotedText('"', txt)
+		} else {
 			w.writeDoubleQuotedText(txt)
+		}
+	case ast.LiteralQuotingType:
+		if w.canWriteBlockText(txt) {
+			w.writeMultilineLiteralText(txt, chomping)
 		} else {
-			w.buf.WriteString(txt)
+			w.writeDoubleQuotedText(txt)
+		}
+	case ast.FoldedQuotingType:
+		switch {
+		case w.canWriteBlockText(txt) && canBeFolded(txt):
+			w.writeFoldedBlockText(txt, chomping)
+		case w.canWriteBlockText(txt):
+			w.writeMultilineLiteralText(txt, chomping)
+		default:
+			w.writeDoubleQuotedText(txt)
 		}
+	default:
+		w.buf.WriteString(txt)
 	}
 }
 
 func (w *ASTWriter) VisitSequenceNode(n *ast.SequenceNode) {
 	w.writePreparedData(n)
+	if (n.Flow() && !w.hasInnerComments(n)) || w.flowLevel > 0 {
+		w.writeFlowSequence(n)
+		return
+	}
 	for _, entry := range n.Entries() {
+		w.writeHeadComments(entry)
 		w.maybeWriteIndentation()
 		w.buf.WriteByte('-')
 		w.increaseIndentation()
-		w.writeBeforeComplexElements(" ")
+		w.writeBeforeComplexElements(w.compactIndicatorSeparator())
 		w.writeBeforeSimpleElements(" ")
+		w.startLineComment(entry)
 		entry.Accept(w)
 		w.decreaseIndentation()
 		w.maybeWriteLineBreak()
@@ -210,8 +328,14 @@ func (w *ASTWriter) VisitSequenceNode(n *ast.SequenceNode) {
 
 func (w *ASTWriter) VisitMappingNode(n *ast.MappingNode) {
 	w.writePreparedData(n)
+	if (n.Flow() && !w.hasInnerComments(n)) || w.flowLevel > 0 {
+		w.writeFlowMapping(n)
+		return
+	}
 	for _, entry := range n.Entries() {
+		w.writeHeadComments(entry)
 		w.maybeWriteIndentation()
+		w.startLineComment(entry)
 		entry.Accept(w)
 		w.maybeWriteLineBreak()
 	}
@@ -222,11 +346,14 @@ func (w *ASTWriter) VisitMappingEntryNode(n *ast.MappingEntryNode) {
 
 	isComplexKey := isComplex(n.Key())
 	if isComplexKey {
-		w.buf.WriteString("? ")
+		w.buf.WriteByte('?')
 		w.increaseIndentation()
+		w.writeBeforeComplexElements(w.compactIndicatorSeparator())
 	}
 
+	w.inKey = true
 	key.Accept(w)
+	w.inKey = false
 
 	if isComplexKey {
 		w.decreaseIndentation()
@@ -242,6 +369,12 @@ func (w *ASTWriter) VisitMappingEntryNode(n *ast.MappingEntryNode) {
 }
 
 func (w *ASTWriter) VisitNullNode(n *ast.NullNode) {
+	if n.Implicit() && w.flowLevel == 0 {
+		// empty node is written as nothing, so the prepared whitespaces are dropped too
+		w.beforeComplex = ""
+		w.beforeSimple = ""
+		return
+	}
 	w.writePreparedData(n)
 	w.buf.WriteString(nullValue)
 }
@@ -264,7 +397,13 @@ func (w *ASTWriter) VisitContentNode(n *ast.ContentNode) {
 	w.writePreparedData(n)
 	properties, content := n.Properties(), n.Content()
 	if ast.ValidNode(properties) {
-		w.buf.WriteByte(' ')
+		if w.flowLevel > 0 {
+			// the content of flow collection is written without prepared whitespaces
+			w.writeBeforeSimpleElements(" ")
+			w.writeBeforeComplexElements(" ")
+		} else {
+			w.buf.WriteByte(' ')
+		}
 		properties.Accept(w)
 
 		if w.opts.anchorsKeeper != nil {
@@ -274,6 +413,55 @@ func (w *ASTWriter) VisitContentNode(n *ast.ContentNode) {
 	content.Accept(w)
 }
 
+// writeFlowSequence writes sequence in flow style, e.g. [a, b].
+// Nested collections are written in flow style too.
+func (w *ASTWriter) writeFlowSequence(n *ast.SequenceNode) {
+	w.flowLevel++
+	w.buf.WriteByte('[')
+	for i, entry := range n.Entries() {
+		if i > 0 {
+			w.buf.WriteString(", ")
+		}
+		w.writeFlowNode(entry)
+	}
+	w.buf.WriteByte(']')
+	w.flowLevel--
+}
+
+// writeFlowMapping writes mapping in flow style, e.g. {a: b}.
+// Nested collections are written in flow style too.
+func (w *ASTWriter) writeFlowMapping(n *ast.MappingNode) {
+	w.flowLevel++
+	w.buf.WriteByte('{')
+	for i, entry := range n.Entries() {
+		if i > 0 {
+			w.buf.WriteString(", ")
+		}
+		entryNode, ok := entry.(*ast.MappingEntryNode)
+		if !ok {
+			w.writeFlowNode(entry)
+			continue
+		}
+		w.inKey = true
+		w.writeFlowNode(entryNode.Key())
+		w.inKey = false
+		w.buf.WriteString(": ")
+		w.writeFlowNode(entryNode.Value())
+	}
+	w.buf.WriteByte('}')
+	w.flowLevel--
+}
+
+func (w *ASTWriter) writeFlowNode(n ast.Node) {
+	w.writeBeforeComplexElements("")
+	w.writeBeforeSimpleElements("")
+	if ast.ValidNode(n) {
+		n.Accept(w)
+	} else {
+		w.buf.WriteString(nullValue)
+	}
+}
+
 func (w *ASTWriter) writeBeforeComplexElements(s string) {
 	w.beforeComplex = s
 }
@@ -285,16 +473,38 @@ func (w *ASTWriter) writeBeforeSimpleElements(s string) {
 func (w *ASTWriter) writePreparedData(n ast.Node) {
 	switch n.Type() {
 	case ast.SequenceType, ast.MappingType:
-		w.buf.WriteString(w.beforeComplex)
+		if isComplex(n) {
+			w.writePrepared(w.beforeComplex)
+		} else {
+			// flow collections are placed like scalars
+			w.writePrepared(w.beforeSimple)
+		}
 	case ast.ContentType:
 		return
 	default:
-		w.buf.WriteString(w.beforeSimple)
+		w.writePrepared(w.beforeSimple)
 	}
 	w.beforeComplex = ""
 	w.beforeSimple = ""
 }
 
+func (w *ASTWriter) writePrepared(s string) {
+	if strings.HasPrefix(s, "\n") {
+		w.writeLineBreak()
+		s = s[1:]
+	}
+	w.buf.WriteString(s)
+}
+
+// compactIndicatorSeparator returns the separator between indicator ("-" or "?") and the block collection
+// following it. The collection starts on the same line if its entries can be aligned with the first one.
+func (w *ASTWriter) compactIndicatorSeparator() string {
+	if w.indentationDelta < 2 {
+		return "\n"
+	}
+	return strings.Repeat(" ", w.indentationDelta-1)
+}
+
 func (w *ASTWriter) increaseIndentation() {
 	w.indentation += w.indentationDelta
 }
@@ -323,20 +533,111 @@ func (w *ASTWriter) maybeWriteIndentation() {
 
 func (w *ASTWriter) maybeWriteLineBreak() {
 	if !w.hasWriteLineBreak() {
+		w.writeLineBreak()
+	}
+}
+
+// writeLineBreak writes line break, preceded by the pending line comment.
+func (w *ASTWriter) writeLineBreak() {
+	if w.lineComment != "" {
+		if bufData := w.buf.Bytes(); len(bufData) > 0 && bufData[len(bufData)-1] != ' ' {
+			w.buf.WriteByte(' ')
+		}
+		w.buf.WriteString(w.lineComment)
+		w.lineComment = ""
+	}
+	w.buf.WriteByte('\n')
+}
+
+// writeDocument writes the document root with attached comments.
+func (w *ASTWriter) writeDocument(doc ast.Node) {
+	w.writeHeadComments(doc)
+	w.startLineComment(doc)
+	doc.Accept(w)
+	if w.lineComment != "" {
+		w.writeLineBreak()
+	}
+	w.writeFootComments(doc)
+}
+
+// hasInnerComments checks if any node inside the collection has comments.
+// Comments can't be written inside flow collections, so such collections are written in block style.
+func (w *ASTWriter) hasInnerComments(n ast.Node) bool {
+	if len(w.opts.comments) == 0 {
+		return false
+	}
+	var entries []ast.Node
+	switch n := n.(type) {
+	case *ast.SequenceNode:
+		entries = n.Entries()
+	case *ast.MappingNode:
+		entries = n.Entries()
+	case *ast.MappingEntryNode:
+		entries = []ast.Node{n.Key(), n.Value()}
+	case *ast.ContentNode:
+		entries = []ast.Node{n.Content()}
+	}
+	for _, entry := range entries {
+		if entry == nil {
+			continue
+		}
+		if _, ok := w.opts.comments[entry]; ok || w.hasInnerComments(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHeadComments writes head comments of the node at the current indentation.
+func (w *ASTWriter) writeHeadComments(n ast.Node) {
+	comments := w.opts.comments[n]
+	if comments == nil || len(comments.Head) == 0 {
+		return
+	}
+	if bufData := w.buf.Bytes(); len(bufData) > 0 && !w.hasWriteLineBreak() {
+		// the node follows an indicator on the same line, so the node is moved to the next line
+		w.buf.Truncate(len(bytes.TrimRight(bufData, " ")))
+		w.writeLineBreak()
+	}
+	for _, c := range comments.Head {
+		w.writeIndentation()
+		w.buf.WriteString(c)
 		w.buf.WriteByte('\n')
 	}
 }
 
-func (w *ASTWriter) writeMultilineLiteralText(txt string) {
-	lines := strings.Split(txt, "\n")
-	chompingIndicator := yamlchar.StripChompingCharacter
-	if lines[len(lines)-1] == "" {
-		chompingIndicator = yamlchar.KeepChompingCharacter
+// startLineComment makes the writer write line comment of the node at the end of the current line.
+func (w *ASTWriter) startLineComment(n ast.Node) {
+	if comments := w.opts.comments[n]; comments != nil && comments.Line != "" {
+		w.lineComment = comments.Line
+	}
+}
+
+// writeFootComments writes foot comments of the node on separate lines without indentation.
+func (w *ASTWriter) writeFootComments(n ast.Node) {
+	comments := w.opts.comments[n]
+	if comments == nil || len(comments.Foot) == 0 {
+		return
+	}
+	if len(w.buf.Bytes()) > 0 {
+		w.maybeWriteLineBreak()
+	}
+	for _, c := range comments.Foot {
+		w.buf.WriteString(c)
+		w.buf.WriteByte('\n')
 	}
+}
+
+func (w *ASTWriter) writeMultilineLiteralText(txt string, chomping ast.ChompingType) {
+	lines := strings.Split(txt, "\n")
 	w.buf.WriteByte('|')
-	w.buf.WriteRune(chompingIndicator)
+	w.writeChompingIndicator(txt, chomping)
 	for i := range lines {
-		w.buf.WriteByte('\n')
+		if i == 0 {
+			w.writeLineBreak()
+		} else {
+			w.buf.WriteByte('\n')
+		}
 		if lines[i] != "" {
 			w.writeIndentation()
 			w.buf.WriteString(lines[i])
@@ -344,14 +645,75 @@ func (w *ASTWriter) writeMultilineLiteralText(txt string) {
 	}
 }
 
+// writeFoldedBlockText writes text as folded block scalar. Every line break of the text is written
+// as an empty line, because a single line break between lines is folded into a space while reading.
+// Text must satisfy canBeFolded.
+func (w *ASTWriter) writeFoldedBlockText(txt string, chomping ast.ChompingType) {
+	lines := strings.Split(txt, "\n")
+	w.buf.WriteByte('>')
+	w.writeChompingIndicator(txt, chomping)
+	for i := range lines {
+		if i == 0 {
+			w.writeLineBreak()
+		} else {
+			w.buf.WriteByte('\n')
+		}
+		if lines[i] == "" {
+			continue
+		}
+		if i > 0 {
+			w.buf.WriteByte('\n')
+		}
+		w.writeIndentation()
+		w.buf.WriteString(lines[i])
+	}
+}
+
+// writeChompingIndicator writes chomping indicator of block scalar header. Given chomping type is kept
+// if it is consistent with trailing line breaks of text, otherwise the indicator is derived from the text.
+func (w *ASTWriter) writeChompingIndicator(txt string, chomping ast.ChompingType) {
+	trimmed := strings.TrimRight(txt, "\n")
+	switch breaks := len(txt) - len(trimmed); {
+	case breaks == 0:
+		w.buf.WriteRune(yamlchar.StripChompingCharacter)
+	case breaks == 1 && trimmed != "" && chomping == ast.ClipChompingType:
+		// clipping is the default chomping, so it has no indicator
+	default:
+		w.buf.WriteRune(yamlchar.KeepChompingCharacter)
+	}
+}
+
+// canWriteBlockText checks if text can be written as block scalar at current position.
+func (w *ASTWriter) canWriteBlockText(txt string) bool {
+	if w.flowLevel > 0 || w.inKey {
+		return false
+	}
+	// leading whitespace would require explicit indentation indicator
+	if strings.HasPrefix(txt, " ") || strings.HasPrefix(txt, "\t") {
+		return false
+	}
+	for _, r := range txt {
+		if r != '\t' && r != '\n' && !isPrintable(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// needsQuotingInFlow checks if plain text would be read differently inside flow collection.
+func (w *ASTWriter) needsQuotingInFlow(txt string) bool {
+	if w.flowLevel == 0 {
+		return false
+	}
+	return txt == "" || strings.ContainsFunc(txt, yamlchar.IsFlowIndicatorChar)
+}
+
 func (w *ASTWriter) writeSingleQuotedText(txt string) {
 	txt, err := yamlchar.ConvertToYAMLSingleQuotedString(txt)
 	if err != nil {
 		w.appendError(err)
 	}
-	w.buf.WriteByte('\'')
-	w.buf.WriteString(txt)
-	w.buf.WriteByte('\'')
+	w.writeQuotedText('\'', txt)
 }
 
 func (w *ASTWriter) writeDoubleQuotedText(txt string) {
@@ -359,9 +721,67 @@ func (w *ASTWriter) writeDoubleQuotedText(txt string) {
 	if err != nil {
 		w.appendError(err)
 	}
-	w.buf.WriteByte('"')
-	w.buf.WriteString(txt)
-	w.buf.WriteByte('"')
+	w.writeQuotedText('"', txt)
+}
+
+// writeQuotedText writes text, which is already escaped, enclosed in given quotes.
+// A single line break is folded into a space while reading, so line breaks of the text
+// are written as empty lines.
+func (w *ASTWriter) writeQuotedText(quote byte, txt string) {
+	w.buf.WriteByte(quote)
+	for i, line := range strings.Split(txt, "\n") {
+		if i > 0 {
+			w.buf.WriteString("\n\n")
+			w.writeIndentation()
+		}
+		w.writeFoldedText(line)
+	}
+	w.buf.WriteByte(quote)
+}
+
+// writeFoldedText writes quoted text content, breaking it into several lines
+// if line width is set. A line break in quoted text is folded into a single space
+// while reading, so text is broken only at single spaces between non-space characters
+// and the space itself is replaced with the break.
+func (w *ASTWriter) writeFoldedText(txt string) {
+	if w.opts.lineWidth <= 0 {
+		w.buf.WriteString(txt)
+		return
+	}
+
+	column := w.currentColumn()
+	firstChunk := true
+	for len(txt) > 0 {
+		end := foldingPoint(txt)
+		word := txt[:end]
+		if !firstChunk && column+len(word) > w.opts.lineWidth {
+			w.buf.WriteByte('\n')
+			w.writeIndentation()
+			column = w.indentation
+			// the break replaces the space preceding the word
+			word = word[1:]
+		}
+		w.buf.WriteString(word)
+		column += len(word)
+		firstChunk = false
+		txt = txt[end:]
+	}
+}
+
+// foldingPoint returns the index of the next space text can be folded at.
+// Leading space of text is considered a part of the returned chunk.
+func foldingPoint(txt string) int {
+	for i := 1; i < len(txt)-1; i++ {
+		if txt[i] == ' ' && txt[i-1] != ' ' && txt[i-1] != '\\' && txt[i+1] != ' ' {
+			return i
+		}
+	}
+	return len(txt)
+}
+
+func (w *ASTWriter) currentColumn() int {
+	bufData := w.buf.Bytes()
+	return len(bufData) - (bytes.LastIndexByte(bufData, '\n') + 1)
 }
 
 func (w *ASTWriter) reset() {
@@ -370,6 +790,9 @@ func (w *ASTWriter) reset() {
 	w.indentation = defaultBasicIndentation
 	w.beforeSimple = ""
 	w.beforeComplex = ""
+	w.flowLevel = 0
+	w.inKey = false
+	w.lineComment = ""
 	clear(w.metAnchors)
 }
 
@@ -377,7 +800,35 @@ func isMultiline(s string) bool {
 	return strings.ContainsRune(s, '\n')
 }
 
+// canBeFolded checks if text keeps its line breaks when written as folded block scalar.
+// Lines starting with whitespace and leading empty lines are not folded, so they are not supported.
+func canBeFolded(txt string) bool {
+	if strings.HasPrefix(txt, "\n") {
+		return false
+	}
+	return !strings.Contains(txt, "\n ") && !strings.Contains(txt, "\n\t")
+}
+
+// isPrintable checks if the character can be written without escaping.
+func isPrintable(r rune) bool {
+	switch {
+	case r == '\r', r == 0x85, r == 0x2028, r == 0x2029, r == 0xFEFF:
+		return false
+	case r < 0x20, r == 0x7F:
+		return false
+	default:
+		return r != utf8.RuneError
+	}
+}
+
+// isComplex checks if node is a block collection, which requires explicit key indicator when used as mapping key.
 func isComplex(n ast.Node) bool {
+	switch n := n.(type) {
+	case *ast.SequenceNode:
+		return !n.Flow()
+	case *ast.MappingNode:
+		return !n.Flow()
+	}
 	switch n.Type() {
 	case ast.SequenceType, ast.MappingType:
 		return true
@@ -2,10 +2,14 @@ package encode_test
 
 import (
 	"fmt"
+	"reflect"
 	"testing"
 
+	"github.com/KSpaceer/yamly"
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/decode"
 	"github.com/KSpaceer/yamly/engines/yayamls/encode"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
 )
 
 func TestWriteString(t *testing.T) {
@@ -19,6 +23,8 @@ func TestWriteString(t *testing.T) {
 
 		anchors     mockAnchorsKeeper
 		withAnchors bool
+
+		opts []encode.WriteOption
 	}
 
 	tcases := []tcase{
@@ -201,6 +207,41 @@ func TestWriteString(t *testing.T) {
 			},
 			withAnchors: true,
 		},
+		{
+			name: "custom indentation",
+			ast: ast.NewMappingNode([]ast.Node{
+				ast.NewMappingEntryNode(
+					ast.NewTextNode("sequence"),
+					ast.NewSequenceNode([]ast.Node{
+						ast.NewTextNode("value1"),
+						ast.NewTextNode("value2"),
+					}),
+				),
+			}),
+			expected: "sequence:\n    - value1\n    - value2\n",
+			opts:     []encode.WriteOption{encode.WithIndentation(4)},
+		},
+		{
+			name: "folded quoted strings",
+			ast: ast.NewMappingNode([]ast.Node{
+				ast.NewMappingEntryNode(
+					ast.NewTextNode("key"),
+					ast.NewTextNode(
+						"first second  third fourth",
+						ast.WithQuotingType(ast.DoubleQuotingType),
+					),
+				),
+				ast.NewMappingEntryNode(
+					ast.NewTextNode("other"),
+					ast.NewTextNode(
+						"unbreakable",
+						ast.WithQuotingType(ast.SingleQuotingType),
+					),
+				),
+			}),
+			expected: "key: \"first\n  second  third\n  fourth\"\nother: 'unbreakable'\n",
+			opts:     []encode.WriteOption{encode.WithLineWidth(12)},
+		},
 	}
 
 	for _, tc := range tcases {
@@ -208,7 +249,7 @@ func TestWriteString(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			var opts []encode.WriteOption
+			opts := tc.opts
 			if tc.withAnchors {
 				opts = append(opts, encode.WithAnchorsKeeper(&tc.anchors))
 			}
@@ -229,6 +270,136 @@ func TestWriteString(t *testing.T) {
 	}
 }
 
+func TestWriteString_Styles(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		calls    func(b *encode.ASTBuilder)
+		expected string
+	}
+
+	tcases := []tcase{
+		{
+			name: "flow sequence",
+			calls: func(b *encode.ASTBuilder) {
+				b.StartMapping()
+				b.InsertString("tags")
+				b.SetStyle(yamly.FlowStyle)
+				b.StartSequence()
+				b.InsertString("a")
+				b.InsertInteger(1)
+				b.EndSequence()
+				b.EndMapping()
+			},
+			expected: "\"tags\": [\"a\", 1]\n",
+		},
+		{
+			name: "flow mapping with nested collections",
+			calls: func(b *encode.ASTBuilder) {
+				b.SetStyle(yamly.FlowStyle)
+				b.StartMapping()
+				b.InsertString("list")
+				b.StartSequence()
+				b.InsertBoolean(true)
+				b.InsertNull()
+				b.EndSequence()
+				b.InsertString("empty")
+				b.StartMapping()
+				b.EndMapping()
+				b.EndMapping()
+			},
+			expected: "{\"list\": [true, null], \"empty\": {}}",
+		},
+		{
+			name: "literal script",
+			calls: func(b *encode.ASTBuilder) {
+				b.StartMapping()
+				b.InsertString("script")
+				b.SetStyle(yamly.LiteralStyle)
+				b.InsertString("echo 1\necho 2\n")
+				b.EndMapping()
+			},
+			expected: "\"script\": |+\n  echo 1\n  echo 2\n",
+		},
+		{
+			name: "folded text",
+			calls: func(b *encode.ASTBuilder) {
+				b.StartMapping()
+				b.InsertString("text")
+				b.SetStyle(yamly.FoldedStyle)
+				b.InsertString("first line\nsecond line")
+				b.EndMapping()
+			},
+			expected: "\"text\": >-\n  first line\n\n  second line\n",
+		},
+		{
+			name: "single quoted",
+			calls: func(b *encode.ASTBuilder) {
+				b.SetStyle(yamly.SingleQuotedStyle)
+				b.InsertString("key: value")
+			},
+			expected: "'key: value'",
+		},
+		{
+			name: "literal in flow sequence falls back to double quoted",
+			calls: func(b *encode.ASTBuilder) {
+				b.SetStyle(yamly.FlowStyle)
+				b.StartSequence()
+				b.SetStyle(yamly.LiteralStyle)
+				b.InsertString("a b")
+				b.EndSequence()
+			},
+			expected: "[\"a b\"]",
+		},
+		{
+			name: "style is applied only to next node",
+			calls: func(b *encode.ASTBuilder) {
+				b.StartSequence()
+				b.SetStyle(yamly.FlowStyle)
+				b.StartSequence()
+				b.EndSequence()
+				b.StartSequence()
+				b.InsertString("x")
+				b.EndSequence()
+				b.EndSequence()
+			},
+			expected: "- []\n- - \"x\"\n",
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			b := encode.NewASTBuilder()
+			tc.calls(b)
+			tree, err := b.Result()
+			if err != nil {
+				t.Fatalf("unexpected builder error: %v", err)
+			}
+
+			result, err := encode.NewASTWriter().WriteString(tree)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("expected %q, but got %q", tc.expected, result)
+			}
+
+			r, err := decode.NewASTReaderFromBytes([]byte(result))
+			if err != nil {
+				t.Fatalf("failed to parse written YAML: %v", err)
+			}
+			expectedValue, value := decode.NewASTReader(tree).Any(), r.Any()
+			if !reflect.DeepEqual(expectedValue, value) {
+				t.Errorf("expected to read %v, but got %v", expectedValue, value)
+			}
+		})
+	}
+}
+
 type mockAnchorsKeeper struct {
 	m      map[string]ast.Node
 	latest string
@@ -252,3 +423,149 @@ func (m *mockAnchorsKeeper) DereferenceAlias(alias string) (ast.Node, error) {
 	}
 	return anchored, nil
 }
+
+func TestWriteString_Comments(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		src      string
+		expected string
+		opts     []encode.WriteOption
+	}
+
+	tcases := []tcase{
+		{
+			name:     "head and line comments of mapping entries",
+			src:      "# head\na: 1 # line\n\n# before b\nb:\n  c: 2 # nested\n",
+			expected: "# head\na: 1 # line\n# before b\nb:\n  c: 2 # nested\n",
+		},
+		{
+			name:     "line comment of entry with block value",
+			src:      "a: # line\n  - x\n  # before y\n  - y\n",
+			expected: "a: # line\n  - x\n  # before y\n  - y\n",
+		},
+		{
+			name:     "compact sequence entries",
+			src:      "- # moved\n  a: 1 # line\n  b: 2\n",
+			expected: "# moved\n-   a: 1 # line\n    b: 2\n",
+			opts:     []encode.WriteOption{encode.WithIndentation(4)},
+		},
+		{
+			name:     "block scalar header",
+			src:      "a: | # header\n  text\n",
+			expected: "a: | # header\n  text\n",
+		},
+		{
+			name:     "scalar document",
+			src:      "# head\ntext # line\n# foot\n",
+			expected: "# head\ntext # line\n# foot\n",
+		},
+		{
+			name:     "comments only",
+			src:      "# first\n\n# second\n",
+			expected: "# first\n# second\n",
+		},
+		{
+			name:     "comments in flow collection",
+			src:      "a: {b: c, # inside\n  d: e}\n",
+			expected: "a: {b: c, d: e} # inside\n",
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var comments []ast.Comment
+			positions := make(ast.Positions)
+			tree, err := parser.ParseString(
+				tc.src,
+				parser.WithOmitStream(),
+				parser.WithComments(&comments),
+				parser.WithPositions(positions),
+			)
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			opts := append(tc.opts, encode.WithComments(ast.NewCommentMap(tree, positions, comments)))
+			result, err := encode.NewASTWriter(opts...).WriteString(tree)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("expected %q, but got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestWriteString_Parsed(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		src      string
+		expected string
+	}
+
+	tcases := []tcase{
+		{
+			name:     "empty values",
+			src:      "a:\nb:\n  -\n  - x\n",
+			expected: "a:\nb:\n  -\n  - x\n",
+		},
+		{
+			name:     "escaped double quoted text",
+			src:      "a: \"\\t\"\nb: \"a\\nb\\\\\"\n",
+			expected: "a: \"\\t\"\nb: \"a\\nb\\\\\"\n",
+		},
+		{
+			name:     "escaped single quoted text",
+			src:      "a: 'it''s'\n",
+			expected: "a: 'it''s'\n",
+		},
+		{
+			name:     "multiline quoted text",
+			src:      "a: 'x\n\n  y'\n",
+			expected: "a: 'x\n\n  y'\n",
+		},
+		{
+			name:     "tag handles",
+			src:      "a: !custom x\nb: !<tag:x> y\nc: !!str z\nd: !e!f w\ne: ! v\n",
+			expected: "a: !custom x\nb: !<tag:x> y\nc: !!str z\nd: !e!f w\ne: ! v\n",
+		},
+		{
+			name:     "flow collections",
+			src:      "a: [x, {y: z}]\nb: {}\n[c]: d\n",
+			expected: "a: [x, {y: z}]\nb: {}\n[c]: d\n",
+		},
+		{
+			name:     "block scalars chomping",
+			src:      "a: |\n  clip\nb: |-\n  strip\nc: |+\n  keep\n\nd: >\n  folded\n  clip\ne: >-\n  folded\n",
+			expected: "a: |\n  clip\nb: |-\n  strip\nc: |+\n  keep\n\nd: >\n  folded clip\ne: >-\n  folded\n",
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, err := parser.ParseString(tc.src, parser.WithOmitStream())
+			if err != nil {
+				t.Fatalf("unexpected parse error: %v", err)
+			}
+
+			result, err := encode.NewASTWriter().WriteString(tree)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if result != tc.expected {
+				t.Errorf("expected %q, but got %q", tc.expected, result)
+			}
+		})
+	}
+}
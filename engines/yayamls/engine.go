@@ -13,8 +13,11 @@ import (
 
 const (
 	pkgYayamls = "github.com/KSpaceer/yamly/engines/yayamls"
+	pkgAST     = "github.com/KSpaceer/yamly/engines/yayamls/ast"
 	pkgDecode  = "github.com/KSpaceer/yamly/engines/yayamls/decode"
 	pkgEncode  = "github.com/KSpaceer/yamly/engines/yayamls/encode"
+
+	pkgReflectCodec = "github.com/KSpaceer/yamly/reflectcodec"
 )
 
 // Generator is used in generated code.
@@ -25,13 +28,16 @@ type engineGenerator struct{}
 func (engineGenerator) Packages() map[string]string {
 	return map[string]string{
 		pkgYayamls: "yayamls",
+		pkgAST:     "ast",
 		pkgDecode:  "decode",
 		pkgEncode:  "encode",
+
+		pkgReflectCodec: "reflectcodec",
 	}
 }
 
 func (engineGenerator) WarningSuppressors() []string {
-	return []string{"*encode.ASTWriter", "*decode.ASTReader", "yayamls.Marshaler"}
+	return []string{"*encode.ASTWriter", "*decode.ASTReader", "yayamls.Marshaler", "ast.Node", "reflectcodec.Option"}
 }
 
 func (engineGenerator) GenerateUnmarshalers(dst io.Writer, decodeFuncName, typeName string) error {
@@ -41,6 +47,15 @@ func (engineGenerator) GenerateUnmarshalers(dst io.Writer, decodeFuncName, typeN
 	fmt.Fprintln(dst, "  if err != nil {")
 	fmt.Fprintln(dst, "    return err")
 	fmt.Fprintln(dst, "  }")
+	fmt.Fprintln(dst, "  defer in.Release()")
+	fmt.Fprintln(dst, "  "+decodeFuncName+"(in, v)")
+	fmt.Fprintln(dst, "  return in.Error()")
+	fmt.Fprintln(dst, "}")
+	fmt.Fprintln(dst)
+	fmt.Fprintln(dst, "// UnmarshalYAMLNode supports yayamls.NodeUnmarshaler interface")
+	fmt.Fprintln(dst, "func (v *"+typeName+") UnmarshalYAMLNode(node ast.Node) error {")
+	fmt.Fprintln(dst, "  in := decode.AcquireASTReader(node)")
+	fmt.Fprintln(dst, "  defer in.Release()")
 	fmt.Fprintln(dst, "  "+decodeFuncName+"(in, v)")
 	fmt.Fprintln(dst, "  return in.Error()")
 	fmt.Fprintln(dst, "}")
@@ -50,9 +65,23 @@ func (engineGenerator) GenerateUnmarshalers(dst io.Writer, decodeFuncName, typeN
 func (engineGenerator) GenerateMarshalers(dst io.Writer, encodeFuncName, typeName string) error {
 	fmt.Fprintln(dst, "// MarshalYAML supports yayamls.Marshaler")
 	fmt.Fprintln(dst, "func (v "+typeName+") MarshalYAML() ([]byte, error) {")
-	fmt.Fprintln(dst, "  out := yamly.NewEncoder(encode.NewASTBuilder(), encode.NewASTWriter())")
+	fmt.Fprintln(dst, "  out := encode.AcquireASTBuilder()")
+	fmt.Fprintln(dst, "  defer out.Release()")
+	fmt.Fprintln(dst, "  "+encodeFuncName+"(out, v)")
+	fmt.Fprintln(dst, "  tree, err := out.Result()")
+	fmt.Fprintln(dst, "  if err != nil {")
+	fmt.Fprintln(dst, "    return nil, err")
+	fmt.Fprintln(dst, "  }")
+	fmt.Fprintln(dst, "  w := encode.AcquireASTWriter()")
+	fmt.Fprintln(dst, "  defer w.Release()")
+	fmt.Fprintln(dst, "  return w.WriteBytes(tree)")
+	fmt.Fprintln(dst, "}")
+	fmt.Fprintln(dst)
+	fmt.Fprintln(dst, "// MarshalYAMLNode supports yayamls.NodeMarshaler")
+	fmt.Fprintln(dst, "func (v "+typeName+") MarshalYAMLNode() (ast.Node, error) {")
+	fmt.Fprintln(dst, "  out := encode.NewASTBuilder()")
 	fmt.Fprintln(dst, "  "+encodeFuncName+"(out, v)")
-	fmt.Fprintln(dst, "  return out.EncodeToBytes()")
+	fmt.Fprintln(dst, "  return out.Result()")
 	fmt.Fprintln(dst, "}")
 	return nil
 }
@@ -63,9 +92,17 @@ func (engineGenerator) UnmarshalersImplementationCheck(
 	outArg string,
 	indent int,
 ) (generator.ImplementationResult, error) {
+	whitespace := strings.Repeat(" ", indent)
+	nodeUnmarshalIface := reflect.TypeOf((*NodeUnmarshaler)(nil)).Elem()
+	if implements(t, nodeUnmarshalIface) {
+		fmt.Fprintln(dst, whitespace+"if node := decode.ExtendDecoder(in).Node(); node != nil {")
+		fmt.Fprintln(dst, whitespace+"  in.AddError(("+outArg+").UnmarshalYAMLNode(node))")
+		fmt.Fprintln(dst, whitespace+"}")
+		return generator.ImplementationResultTrue, nil
+	}
 	unmarshalIface := reflect.TypeOf((*Unmarshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(unmarshalIface) {
-		fmt.Fprintln(dst, strings.Repeat(" ", indent)+"in.AddError(("+outArg+").UnmarshalYAML(in.Raw()))")
+	if implements(t, unmarshalIface) {
+		fmt.Fprintln(dst, whitespace+"in.AddError(("+outArg+").UnmarshalYAML(in.Raw()))")
 		return generator.ImplementationResultTrue, nil
 	}
 	return generator.ImplementationResultFalse, nil
@@ -77,9 +114,15 @@ func (engineGenerator) MarshalersImplementationCheck(
 	inArg string,
 	indent int,
 ) (generator.ImplementationResult, error) {
+	whitespace := strings.Repeat(" ", indent)
+	nodeMarshalIface := reflect.TypeOf((*NodeMarshaler)(nil)).Elem()
+	if implements(t, nodeMarshalIface) {
+		fmt.Fprintln(dst, whitespace+"encode.ExtendInserter(out).InsertNode("+inArg+".MarshalYAMLNode())")
+		return generator.ImplementationResultTrue, nil
+	}
 	marshalIface := reflect.TypeOf((*Marshaler)(nil)).Elem()
-	if reflect.PtrTo(t).Implements(marshalIface) {
-		fmt.Fprintln(dst, strings.Repeat(" ", indent)+"out.InsertRaw("+inArg+".MarshalYAML())")
+	if implements(t, marshalIface) {
+		fmt.Fprintln(dst, whitespace+"out.InsertRaw("+inArg+".MarshalYAML())")
 		return generator.ImplementationResultTrue, nil
 	}
 	return generator.ImplementationResultFalse, nil
@@ -87,7 +130,11 @@ func (engineGenerator) MarshalersImplementationCheck(
 
 func (engineGenerator) GenerateUnmarshalEmptyInterfaceAssertions(dst io.Writer, outArg string, indent int) error {
 	whitespace := strings.Repeat(" ", indent)
-	fmt.Fprintln(dst, whitespace+"if m, ok := "+outArg+".(yayamls.Unmarshaler); ok {")
+	fmt.Fprintln(dst, whitespace+"if m, ok := "+outArg+".(yayamls.NodeUnmarshaler); ok {")
+	fmt.Fprintln(dst, whitespace+"  if node := decode.ExtendDecoder(in).Node(); node != nil {")
+	fmt.Fprintln(dst, whitespace+"    in.AddError(m.UnmarshalYAMLNode(node))")
+	fmt.Fprintln(dst, whitespace+"  }")
+	fmt.Fprintln(dst, whitespace+"} else if m, ok := "+outArg+".(yayamls.Unmarshaler); ok {")
 	fmt.Fprintln(dst, whitespace+"  in.AddError(m.UnmarshalYAML(in.Raw()))")
 	fmt.Fprintln(dst, whitespace+"} else {")
 	fmt.Fprintln(dst, whitespace+"  "+outArg+" = in.Any()")
@@ -97,11 +144,21 @@ func (engineGenerator) GenerateUnmarshalEmptyInterfaceAssertions(dst io.Writer,
 
 func (engineGenerator) GenerateMarshalEmptyInterfaceAssertions(dst io.Writer, inArg string, indent int) error {
 	whitespace := strings.Repeat(" ", indent)
-	fmt.Fprintln(dst, whitespace+"if m, ok := "+inArg+".(yayamls.Marshaler); ok {")
+	fmt.Fprintln(dst, whitespace+"if m, ok := "+inArg+".(yayamls.NodeMarshaler); ok {")
+	fmt.Fprintln(dst, whitespace+"  encode.ExtendInserter(out).InsertNode(m.MarshalYAMLNode())")
+	fmt.Fprintln(dst, whitespace+"} else if m, ok := "+inArg+".(yayamls.Marshaler); ok {")
 	fmt.Fprintln(dst, whitespace+"  out.InsertRaw(m.MarshalYAML())")
 	fmt.Fprintln(dst, whitespace+"} else {")
-	// TODO: add reflect-based marshaler for yayamls
-	fmt.Fprintln(dst, whitespace+"  out.InsertRaw(nil, nil)")
+	fmt.Fprintln(dst, whitespace+"  reflectcodec.Encode(out, "+inArg+")")
 	fmt.Fprintln(dst, whitespace+"}")
 	return nil
 }
+
+// implements checks if values of type t or pointers to them implement iface.
+// Interface types are checked as is, since pointers to interfaces have no methods.
+func implements(t, iface reflect.Type) bool {
+	if t.Kind() == reflect.Interface {
+		return t.Implements(iface)
+	}
+	return reflect.PtrTo(t).Implements(iface)
+}
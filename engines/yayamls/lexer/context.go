@@ -81,72 +81,62 @@ func (c *context) lineBreakRevertContext() {
 	}
 }
 
-func (c *context) matchSpecialToken(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) matchSpecialToken(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	if c.rawMode {
-		return c.rawMatching(t, r)
+		return c.rawMatching(t, start, r)
 	}
 
 	switch c.currentType() {
 	case blockContextType:
-		return c.blockMatching(t, r)
+		return c.blockMatching(t, start, r)
 	case flowContextType:
-		return c.flowMatching(t, r)
+		return c.flowMatching(t, start, r)
 	case commentContextType:
-		return c.commentMatching(t, r)
+		return c.commentMatching(t, start, r)
 	case multilineBlockStartContextType:
-		return c.multilineBlockStartMatching(t, r)
+		return c.multilineBlockStartMatching(t, start, r)
 	case singleQuoteContextType:
-		return c.singleQuoteMatching(t, r)
+		return c.singleQuoteMatching(t, start, r)
 	case doubleQuoteContextType:
-		return c.doubleQuoteMatching(t, r)
+		return c.doubleQuoteMatching(t, start, r)
 	case tagContextType:
-		return c.tagMatching(t, r)
+		return c.tagMatching(t, start, r)
 	default:
-		return c.baseMatching(t, r)
+		return c.baseMatching(t, start, r)
 	}
 }
 
-func (c *context) blockMatching(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) blockMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	tok := token.Token{Start: t.pos}
 	switch r {
 	case yamlchar.SequenceEntryCharacter:
-		lookaheadPred := func(runes []rune) bool {
-			return yamlchar.IsBlankChar(runes[0]) || runes[0] == EOF
-		}
-
-		if t.lookahead(1, lookaheadPred) && t.lookbehind(token.MayPrecedeWord) {
+		if isBlankOrEOF(t.peek(0)) && t.lookbehind(token.MayPrecedeWord) {
 			tok.End = t.pos
 			tok.Type = token.SequenceEntryType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 
-		if t.lookahead(3, func(runes []rune) bool {
-			return runes[0] == runes[1] && runes[1] == yamlchar.DirectiveEndCharacter &&
-				(yamlchar.IsBlankChar(runes[2]) || runes[2] == EOF)
-		}) && t.lookbehind(token.MayPrecedeWord) {
-			tok.Origin = string([]rune{r, t.ra.Next(), t.ra.Next()})
-			t.pos.Column += 2
+		if t.peek(0) == yamlchar.DirectiveEndCharacter && t.peek(1) == yamlchar.DirectiveEndCharacter &&
+			isBlankOrEOF(t.peek(2)) && t.lookbehind(token.MayPrecedeWord) {
+			t.skip(2)
+			tok.Origin = t.origin(start)
 			tok.End = t.pos
 			tok.Type = token.DirectiveEndType
 			return tok, true
 		}
 	case yamlchar.MappingKeyCharacter:
-		if t.lookahead(1, func(runes []rune) bool {
-			return yamlchar.IsBlankChar(runes[0]) || runes[0] == EOF
-		}) && t.lookbehind(token.MayPrecedeWord) {
+		if isBlankOrEOF(t.peek(0)) && t.lookbehind(token.MayPrecedeWord) {
 			tok.End = t.pos
 			tok.Type = token.MappingKeyType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.MappingValueCharacter:
-		if t.lookahead(1, func(runes []rune) bool {
-			return yamlchar.IsBlankChar(runes[0]) || runes[0] == EOF
-		}) {
+		if isBlankOrEOF(t.peek(0)) {
 			tok.End = t.pos
 			tok.Type = token.MappingValueType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.SequenceStartCharacter:
@@ -154,7 +144,7 @@ func (c *context) blockMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(flowContextType)
 			tok.End = t.pos
 			tok.Type = token.SequenceStartType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 
@@ -163,7 +153,7 @@ func (c *context) blockMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(flowContextType)
 			tok.End = t.pos
 			tok.Type = token.MappingStartType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.CommentCharacter:
@@ -171,35 +161,35 @@ func (c *context) blockMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(commentContextType)
 			tok.End = t.pos
 			tok.Type = token.CommentType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.AnchorCharacter:
 		if t.lookbehind(token.MayPrecedeWord) {
 			tok.End = t.pos
 			tok.Type = token.AnchorType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.AliasCharacter:
 		if t.lookbehind(token.MayPrecedeWord) {
 			tok.End = t.pos
 			tok.Type = token.AliasType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.TagCharacter:
 		c.switchContext(tagContextType)
 		tok.End = t.pos
 		tok.Type = token.TagType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.LiteralCharacter:
 		if t.lookbehind(token.MayPrecedeWord) {
 			c.switchContext(multilineBlockStartContextType)
 			tok.End = t.pos
 			tok.Type = token.LiteralType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.FoldedCharacter:
@@ -207,52 +197,48 @@ func (c *context) blockMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(multilineBlockStartContextType)
 			tok.End = t.pos
 			tok.Type = token.FoldedType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.SingleQuoteCharacter:
 		c.switchContext(singleQuoteContextType)
 		tok.End = t.pos
 		tok.Type = token.SingleQuoteType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.DoubleQuoteCharacter:
 		c.switchContext(doubleQuoteContextType)
 		tok.End = t.pos
 		tok.Type = token.DoubleQuoteType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.DirectiveCharacter:
 		tok.End = t.pos
 		tok.Type = token.DirectiveType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.DocumentEndCharacter:
-		if t.lookahead(3, func(runes []rune) bool {
-			return runes[0] == runes[1] && runes[1] == yamlchar.DocumentEndCharacter &&
-				(yamlchar.IsBlankChar(runes[2]) || runes[2] == EOF)
-		}) && t.lookbehind(token.MayPrecedeWord) {
-			tok.Origin = string([]rune{r, t.ra.Next(), t.ra.Next()})
-			t.pos.Column += 2
+		if t.peek(0) == yamlchar.DocumentEndCharacter && t.peek(1) == yamlchar.DocumentEndCharacter &&
+			isBlankOrEOF(t.peek(2)) && t.lookbehind(token.MayPrecedeWord) {
+			t.skip(2)
+			tok.Origin = t.origin(start)
 			tok.End = t.pos
 			tok.Type = token.DocumentEndType
 			return tok, true
 		}
 	}
-	return c.baseMatching(t, r)
+	return c.baseMatching(t, start, r)
 }
 
-func (c *context) flowMatching(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) flowMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	tok := token.Token{Start: t.pos}
 
 	switch r {
 	case yamlchar.MappingKeyCharacter:
-		if t.lookahead(1, func(runes []rune) bool {
-			return yamlchar.IsBlankChar(runes[0]) || runes[0] == EOF
-		}) && t.lookbehind(mayPrecedeWordInFlow) {
+		if isBlankOrEOF(t.peek(0)) && t.lookbehind(mayPrecedeWordInFlow) {
 			tok.End = t.pos
 			tok.Type = token.MappingKeyType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.MappingValueCharacter:
@@ -261,12 +247,11 @@ func (c *context) flowMatching(t *Tokenizer, r rune) (token.Token, bool) {
 				tok.Type == token.SingleQuoteType
 		}
 
-		if t.lookahead(1, func(runes []rune) bool {
-			return yamlchar.IsBlankChar(runes[0]) || yamlchar.IsFlowIndicatorChar(runes[0]) || runes[0] == EOF
-		}) || t.lookbehind(canBeAdjacent) {
+		if next := t.peek(0); isBlankOrEOF(next) || yamlchar.IsFlowIndicatorChar(next) ||
+			t.lookbehind(canBeAdjacent) {
 			tok.End = t.pos
 			tok.Type = token.MappingValueType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.SequenceStartCharacter:
@@ -274,7 +259,7 @@ func (c *context) flowMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(flowContextType)
 			tok.End = t.pos
 			tok.Type = token.SequenceStartType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.MappingStartCharacter:
@@ -282,40 +267,40 @@ func (c *context) flowMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(flowContextType)
 			tok.End = t.pos
 			tok.Type = token.MappingStartType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.SequenceEndCharacter:
 		c.revertContext()
 		tok.End = t.pos
 		tok.Type = token.SequenceEndType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.MappingEndCharacter:
 		c.revertContext()
 		tok.End = t.pos
 		tok.Type = token.MappingEndType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.AnchorCharacter:
 		if t.lookbehind(mayPrecedeWordInFlow) {
 			tok.End = t.pos
 			tok.Type = token.AnchorType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.AliasCharacter:
 		if t.lookbehind(mayPrecedeWordInFlow) {
 			tok.End = t.pos
 			tok.Type = token.AliasType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.TagCharacter:
 		c.switchContext(tagContextType)
 		tok.End = t.pos
 		tok.Type = token.TagType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.SingleQuoteCharacter:
 		if t.lookbehind(func(tok token.Token) bool {
@@ -324,7 +309,7 @@ func (c *context) flowMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(singleQuoteContextType)
 			tok.End = t.pos
 			tok.Type = token.SingleQuoteType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.DoubleQuoteCharacter:
@@ -334,71 +319,81 @@ func (c *context) flowMatching(t *Tokenizer, r rune) (token.Token, bool) {
 			c.switchContext(doubleQuoteContextType)
 			tok.End = t.pos
 			tok.Type = token.DoubleQuoteType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	case yamlchar.CollectEntryCharacter:
 		tok.End = t.pos
 		tok.Type = token.CollectEntryType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
+	case yamlchar.CommentCharacter:
+		if t.lookbehind(token.MayPrecedeWord) {
+			c.switchContext(commentContextType)
+			tok.End = t.pos
+			tok.Type = token.CommentType
+			tok.Origin = t.origin(start)
+			return tok, true
+		}
 	}
-	return c.baseMatching(t, r)
+	return c.baseMatching(t, start, r)
+}
+
+func isBlankOrEOF(r rune) bool {
+	return yamlchar.IsBlankChar(r) || r == EOF
 }
 
 func mayPrecedeWordInFlow(tok token.Token) bool {
 	return token.MayPrecedeWord(tok) || token.IsOpeningFlowIndicator(tok)
 }
 
-func (c *context) commentMatching(t *Tokenizer, r rune) (token.Token, bool) {
-	return c.baseMatching(t, r)
+func (c *context) commentMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
+	return c.baseMatching(t, start, r)
 }
 
-func (c *context) multilineBlockStartMatching(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) multilineBlockStartMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	tok := token.Token{Start: t.pos}
 	switch r {
 	case yamlchar.StripChompingCharacter:
 		tok.End = t.pos
 		tok.Type = token.StripChompingType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.KeepChompingCharacter:
 		tok.End = t.pos
 		tok.Type = token.KeepChompingType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.CommentCharacter:
 		if t.lookbehind(token.MayPrecedeWord) {
 			c.switchContext(commentContextType)
 			tok.End = t.pos
 			tok.Type = token.CommentType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	}
-	return c.baseMatching(t, r)
+	return c.baseMatching(t, start, r)
 }
 
-func (c *context) singleQuoteMatching(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) singleQuoteMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	tok := token.Token{Start: t.pos}
 	var escaped bool
 	if r == yamlchar.SingleQuoteCharacter {
-		if !c.escaped && t.lookahead(1, func(runes []rune) bool {
-			return runes[0] != yamlchar.SingleQuoteCharacter
-		}) {
+		if !c.escaped && t.peek(0) != yamlchar.SingleQuoteCharacter {
 			c.revertContext()
 			tok.End = t.pos
 			tok.Type = token.SingleQuoteType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 		escaped = !c.escaped
 	}
 	c.escaped = escaped
-	return c.baseMatching(t, r)
+	return c.baseMatching(t, start, r)
 }
 
-func (c *context) doubleQuoteMatching(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) doubleQuoteMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	tok := token.Token{Start: t.pos}
 	var escaped bool
 	switch r {
@@ -416,30 +411,30 @@ func (c *context) doubleQuoteMatching(t *Tokenizer, r rune) (token.Token, bool)
 			c.revertContext()
 			tok.End = t.pos
 			tok.Type = token.DoubleQuoteType
-			tok.Origin = string([]rune{r})
+			tok.Origin = t.origin(start)
 			return tok, true
 		}
 	}
 	c.escaped = escaped
-	return c.baseMatching(t, r)
+	return c.baseMatching(t, start, r)
 }
 
-func (c *context) tagMatching(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) tagMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	tok := token.Token{Start: t.pos}
 	if r == yamlchar.TagCharacter {
 		tok.End = t.pos
 		tok.Type = token.TagType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	}
-	return c.baseMatching(t, r)
+	return c.baseMatching(t, start, r)
 }
 
-func (c *context) rawMatching(t *Tokenizer, r rune) (token.Token, bool) {
-	return c.baseMatching(t, r)
+func (c *context) rawMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
+	return c.baseMatching(t, start, r)
 }
 
-func (c *context) baseMatching(t *Tokenizer, r rune) (token.Token, bool) {
+func (c *context) baseMatching(t *Tokenizer, start int, r rune) (token.Token, bool) {
 	tok := token.Token{Start: t.pos}
 	switch r {
 	case EOF:
@@ -449,22 +444,18 @@ func (c *context) baseMatching(t *Tokenizer, r rune) (token.Token, bool) {
 	case yamlchar.ByteOrderMarkCharacter:
 		tok.End = t.pos
 		tok.Type = token.BOMType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.CarriageReturnCharacter:
 		c.lineBreakRevertContext()
-		origin := []rune{r}
-		if t.lookahead(1, func(runes []rune) bool {
-			return runes[0] == yamlchar.LineFeedCharacter
-		}) {
-			origin = append(origin, t.ra.Next())
-			t.pos.Column++
+		if t.peek(0) == yamlchar.LineFeedCharacter {
+			t.skip(1)
 		}
 		tok.End = t.pos
 		t.pos.Column = 0
 		t.pos.Row++
 		tok.Type = token.LineBreakType
-		tok.Origin = string(origin)
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.LineFeedCharacter:
 		c.lineBreakRevertContext()
@@ -472,19 +463,19 @@ func (c *context) baseMatching(t *Tokenizer, r rune) (token.Token, bool) {
 		t.pos.Column = 0
 		t.pos.Row++
 		tok.Type = token.LineBreakType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.SpaceCharacter:
 		c.whitespaceRevertContext()
 		tok.End = t.pos
 		tok.Type = token.SpaceType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	case yamlchar.TabCharacter:
 		c.whitespaceRevertContext()
 		tok.End = t.pos
 		tok.Type = token.TabType
-		tok.Origin = string([]rune{r})
+		tok.Origin = t.origin(start)
 		return tok, true
 	}
 	return token.Token{}, false
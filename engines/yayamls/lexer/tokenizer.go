@@ -2,22 +2,36 @@
 package lexer
 
 import (
+	"errors"
+	"io"
 	"strings"
+	"unicode/utf8"
 
-	"github.com/KSpaceer/yamly/engines/yayamls/pkg/cpaccessor"
-	"github.com/KSpaceer/yamly/engines/yayamls/pkg/strslice"
 	"github.com/KSpaceer/yamly/engines/yayamls/token"
+	"github.com/KSpaceer/yamly/engines/yayamls/yamlchar"
 )
 
-const lookaheadBufferPreallocationSize = 8
+// EOF indicates the end of file.
+const EOF rune = -1
 
 // Tokenizer is used to transform the source text into lexical tokens.
+// Tokenizer scans the source bytes directly, decoding UTF-8 only for non-ASCII characters,
+// and origins of produced tokens reference the source text.
 type Tokenizer struct {
-	ra  cpaccessor.CheckpointingAccessor[rune]
+	// src is the source text. If the source is read from reader, src contains
+	// only the last read part of source.
+	src string
+	// r is the reader of the source, which is not read completely yet
+	r     io.Reader
+	chunk []byte
+	// lineEnd is the offset of the line break ending the read part of source
+	lineEnd int
+	readErr error
+	// off is the offset of the next character in source
+	off int
 	ctx context
 	pos token.Position
 
-	lookaheadBuf  []rune
 	lookbehindTok token.Token
 
 	preparedToken    token.Token
@@ -32,6 +46,8 @@ type TokenizerOption func(*tokenizerOpts)
 
 // WithUnsafe will make tokenizer to convert string to byte/rune slices using
 // unsafe package (maybe).
+//
+// Deprecated: tokenizer does not copy the source anymore, so the option has no effect.
 func WithUnsafe() TokenizerOption {
 	return func(opts *tokenizerOpts) {
 		opts.unsafe = true
@@ -45,25 +61,55 @@ func NewTokenizer(src string, opts ...TokenizerOption) *Tokenizer {
 	for _, opt := range opts {
 		opt(&o)
 	}
-	t := &Tokenizer{
-		ra:           cpaccessor.NewCheckpointingAccessor[rune](),
-		ctx:          newContext(),
-		lookaheadBuf: make([]rune, 0, lookaheadBufferPreallocationSize),
+	return &Tokenizer{
+		src: src,
+		ctx: newContext(),
 		pos: token.Position{
 			Row: 1,
 		},
 	}
-	var runeSrc []byte
-	if o.unsafe {
-		runeSrc = strslice.StringToBytesSlice(src)
-	} else {
-		runeSrc = []byte(src)
-	}
+}
+
+const readChunkSize = 4096
 
-	t.ra.SetStream(newRuneStream(runeSrc))
+// NewReaderTokenizer will create a Tokenizer used to produce tokens from the source read from given reader.
+// The source is read line by line while tokenizing, so only the current line is kept in memory
+// (in addition to origins of produced tokens). Reading error stops tokenizing as if the end of source
+// is reached and can be obtained using Err method.
+func NewReaderTokenizer(r io.Reader) *Tokenizer {
+	t := NewTokenizer("")
+	t.r, t.lineEnd = r, -1
 	return t
 }
 
+// Err returns the error occurred while reading the source, if any.
+func (t *Tokenizer) Err() error {
+	return t.readErr
+}
+
+// fill reads the source until the whole current line is available, so tokens
+// and characters looked ahead are not split between reads.
+func (t *Tokenizer) fill() {
+	for t.r != nil && t.lineEnd < t.off {
+		if i := strings.LastIndexByte(t.src[t.off:], '\n'); i >= 0 {
+			t.lineEnd = t.off + i
+			return
+		}
+		rest := t.src[t.off:]
+		if size := max(readChunkSize, len(rest)); cap(t.chunk) < size {
+			t.chunk = make([]byte, size)
+		}
+		n, err := t.r.Read(t.chunk[:cap(t.chunk)])
+		t.src, t.off, t.lineEnd = rest+string(t.chunk[:n]), 0, -1
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				t.readErr = err
+			}
+			t.r = nil
+		}
+	}
+}
+
 // SetRawMode sets tokenizer into raw mode making it ignore context of tokenizing.
 func (t *Tokenizer) SetRawMode() {
 	t.ctx.setRawModeValue(true)
@@ -87,22 +133,40 @@ func (t *Tokenizer) Next() token.Token {
 }
 
 func (t *Tokenizer) emitToken() token.Token {
+	t.fill()
 	tok := token.Token{}
-	var originBuilder strings.Builder
+	var (
+		originStart int
+		// invalidUTF8 shows if string token contains invalid UTF-8 sequences
+		invalidUTF8 bool
+	)
 	for {
+		startPos := t.pos
+		if n, invalid := t.skipOrdinary(); n > 0 {
+			// ordinary characters cannot start special token in any context
+			if tok.Type == token.UnknownType {
+				tok.Type = token.StringType
+				tok.Start = startPos
+				tok.Start.Column++
+				originStart = t.off - n
+				t.lookbehindTok = tok
+			}
+			invalidUTF8 = invalidUTF8 || invalid
+		}
 
-		r := t.ra.Next()
+		runeStart := t.off
+		r := t.nextRune()
 		t.pos.Column++
 
 		curPos := t.pos
 
-		specialTok, ok := t.ctx.matchSpecialToken(t, r)
+		specialTok, ok := t.ctx.matchSpecialToken(t, runeStart, r)
 		if ok {
 			if tok.Type != token.UnknownType {
 				t.preparedToken = specialTok
 				t.hasPreparedToken = true
 
-				tok.Origin = originBuilder.String()
+				tok.Origin = t.stringOrigin(originStart, runeStart, invalidUTF8)
 				tok.End = curPos
 				// decreasing column, because we are currently at rune right after
 				// string token
@@ -117,22 +181,106 @@ func (t *Tokenizer) emitToken() token.Token {
 		if tok.Type == token.UnknownType {
 			tok.Type = token.StringType
 			tok.Start = curPos
+			originStart = runeStart
 			t.lookbehindTok = tok
 		}
-		originBuilder.WriteRune(r)
+		if r == utf8.RuneError && t.off-runeStart == 1 {
+			invalidUTF8 = true
+		}
 	}
 	return tok
 }
 
-func (t *Tokenizer) lookahead(count int, predicate func([]rune) bool) bool {
-	t.ra.SetCheckpoint()
-	for i := 0; i < count; i++ {
-		t.lookaheadBuf = append(t.lookaheadBuf, t.ra.Next())
+// ordinaryBytes contains ASCII characters, which are not matched as special ones in any context.
+var ordinaryBytes = func() (table [utf8.RuneSelf]bool) {
+	for c := range table {
+		table[c] = !strings.ContainsRune("-?:,[]{}#&*!|>'\"%.+\\ \t\r\n", rune(c))
+	}
+	return table
+}()
+
+// skipOrdinary skips characters, which cannot be matched as special ones,
+// and returns the number of skipped bytes. Non-ASCII characters (except byte order mark)
+// are ordinary ones too.
+func (t *Tokenizer) skipOrdinary() (skipped int, invalidUTF8 bool) {
+	start := t.off
+	for t.off < len(t.src) {
+		c := t.src[t.off]
+		if c < utf8.RuneSelf {
+			if !ordinaryBytes[c] {
+				break
+			}
+			t.off++
+		} else {
+			r, size := utf8.DecodeRuneInString(t.src[t.off:])
+			if r == yamlchar.ByteOrderMarkCharacter {
+				break
+			}
+			if r == utf8.RuneError && size == 1 {
+				invalidUTF8 = true
+			}
+			t.off += size
+		}
+		t.pos.Column++
+	}
+	if t.off > start {
+		// escaping is reset by any ordinary character
+		t.ctx.escaped = false
+	}
+	return t.off - start, invalidUTF8
+}
+
+// nextRune returns the next character of source and moves to the character after it.
+func (t *Tokenizer) nextRune() rune {
+	if t.off >= len(t.src) {
+		return EOF
+	}
+	if c := t.src[t.off]; c < utf8.RuneSelf {
+		t.off++
+		return rune(c)
+	}
+	r, size := utf8.DecodeRuneInString(t.src[t.off:])
+	t.off += size
+	return r
+}
+
+// peek returns the character located at given offset (in bytes) after the current position.
+// Since all YAML indicators are ASCII characters, non-ASCII characters are not decoded
+// and utf8.RuneError is returned for them.
+func (t *Tokenizer) peek(offset int) rune {
+	if t.off+offset >= len(t.src) {
+		return EOF
+	}
+	if c := t.src[t.off+offset]; c < utf8.RuneSelf {
+		return rune(c)
+	}
+	return utf8.RuneError
+}
+
+// skip moves tokenizer count ASCII characters forward.
+func (t *Tokenizer) skip(count int) {
+	t.off += count
+	t.pos.Column += count
+}
+
+// origin returns the source text from start offset to the current position.
+func (t *Tokenizer) origin(start int) string {
+	return t.src[start:t.off]
+}
+
+// stringOrigin returns the source text of string token. Invalid UTF-8 sequences
+// are replaced with utf8.RuneError characters, one per each invalid byte.
+func (t *Tokenizer) stringOrigin(start, end int, invalidUTF8 bool) string {
+	origin := t.src[start:end]
+	if !invalidUTF8 {
+		return origin
+	}
+	var sb strings.Builder
+	sb.Grow(len(origin))
+	for _, r := range origin {
+		sb.WriteRune(r)
 	}
-	result := predicate(t.lookaheadBuf)
-	t.lookaheadBuf = t.lookaheadBuf[:0]
-	t.ra.Rollback()
-	return result
+	return sb.String()
 }
 
 func (t *Tokenizer) lookbehind(predicate func(token.Token) bool) bool {
@@ -1,7 +1,9 @@
 package lexer_test
 
 import (
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/KSpaceer/yamly/engines/yayamls/lexer"
 	"github.com/KSpaceer/yamly/engines/yayamls/token"
@@ -3034,6 +3036,59 @@ func TestTokenizer(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "comment in flow collection",
+			src:  "[a #b\n]",
+			expectedTokens: []token.Token{
+				{
+					Type:   token.SequenceStartType,
+					Start:  token.Position{Row: 1, Column: 1},
+					End:    token.Position{Row: 1, Column: 1},
+					Origin: "[",
+				},
+				{
+					Type:   token.StringType,
+					Start:  token.Position{Row: 1, Column: 2},
+					End:    token.Position{Row: 1, Column: 2},
+					Origin: "a",
+				},
+				{
+					Type:   token.SpaceType,
+					Start:  token.Position{Row: 1, Column: 3},
+					End:    token.Position{Row: 1, Column: 3},
+					Origin: " ",
+				},
+				{
+					Type:   token.CommentType,
+					Start:  token.Position{Row: 1, Column: 4},
+					End:    token.Position{Row: 1, Column: 4},
+					Origin: "#",
+				},
+				{
+					Type:   token.StringType,
+					Start:  token.Position{Row: 1, Column: 5},
+					End:    token.Position{Row: 1, Column: 5},
+					Origin: "b",
+				},
+				{
+					Type:   token.LineBreakType,
+					Start:  token.Position{Row: 1, Column: 6},
+					End:    token.Position{Row: 1, Column: 6},
+					Origin: "\n",
+				},
+				{
+					Type:   token.SequenceEndType,
+					Start:  token.Position{Row: 2, Column: 1},
+					End:    token.Position{Row: 2, Column: 1},
+					Origin: "]",
+				},
+				{
+					Type:  token.EOFType,
+					Start: token.Position{Row: 2, Column: 2},
+					End:   token.Position{Row: 2, Column: 2},
+				},
+			},
+		},
 	}
 
 	for _, tc := range tcases {
@@ -3041,29 +3096,34 @@ func TestTokenizer(t *testing.T) {
 		t.Run(tc.name, func(t *testing.T) {
 			t.Parallel()
 
-			tokenizer := lexer.NewTokenizer(tc.src)
+			// source read by single bytes must be tokenized the same way as the whole one
+			tokenizers := []*lexer.Tokenizer{
+				lexer.NewTokenizer(tc.src),
+				lexer.NewReaderTokenizer(iotest.OneByteReader(strings.NewReader(tc.src))),
+			}
+			for _, tokenizer := range tokenizers {
+				var rawModEnableIndex, rawModDisableIndex int
 
-			var rawModEnableIndex, rawModDisableIndex int
+				var (
+					tokens       []token.Token
+					currentToken token.Token
+				)
+				for i := 0; currentToken.Type != token.EOFType; i++ {
+					if rawModDisableIndex != len(tc.rawModDisableIndices) && i == tc.rawModDisableIndices[rawModDisableIndex] {
+						tokenizer.UnsetRawMode()
+						rawModDisableIndex++
+					}
 
-			var (
-				tokens       []token.Token
-				currentToken token.Token
-			)
-			for i := 0; currentToken.Type != token.EOFType; i++ {
-				if rawModDisableIndex != len(tc.rawModDisableIndices) && i == tc.rawModDisableIndices[rawModDisableIndex] {
-					tokenizer.UnsetRawMode()
-					rawModDisableIndex++
-				}
+					if rawModEnableIndex != len(tc.rawModEnableIndices) && i == tc.rawModEnableIndices[rawModEnableIndex] {
+						tokenizer.SetRawMode()
+						rawModEnableIndex++
+					}
 
-				if rawModEnableIndex != len(tc.rawModEnableIndices) && i == tc.rawModEnableIndices[rawModEnableIndex] {
-					tokenizer.SetRawMode()
-					rawModEnableIndex++
+					currentToken = tokenizer.Next()
+					tokens = append(tokens, currentToken)
 				}
-
-				currentToken = tokenizer.Next()
-				tokens = append(tokens, currentToken)
+				compareTokens(t, tc.expectedTokens, tokens)
 			}
-			compareTokens(t, tc.expectedTokens, tokens)
 		})
 	}
 }
@@ -3111,3 +3171,26 @@ func areTokensEqual(a, b token.Token) bool {
 	}
 	return true
 }
+
+func BenchmarkTokenizer(b *testing.B) {
+	const src = `# service description
+service:
+  name: "api-gateway"
+  replicas: 3
+  labels: {app: gateway, tier: 'frontend'}
+  ports:
+    - 80
+    - 443
+  description: |
+    Multiline description
+    with unicode: привет, 世界
+...
+`
+	b.ReportAllocs()
+	b.SetBytes(int64(len(src)))
+	for i := 0; i < b.N; i++ {
+		tz := lexer.NewTokenizer(src)
+		for tok := tz.Next(); tok.Type != token.EOFType; tok = tz.Next() {
+		}
+	}
+}
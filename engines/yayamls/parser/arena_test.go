@@ -0,0 +1,97 @@
+package parser_test
+
+import (
+	"os"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astcmp"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+)
+
+func TestParseString_Arena(t *testing.T) {
+	t.Parallel()
+
+	learnYAML, err := os.ReadFile("testdata/learnyaml.yaml")
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+
+	type tcase struct {
+		name string
+		src  string
+	}
+
+	tcases := []tcase{
+		{
+			name: "scalars",
+			src:  "plain\n--- 'single'\n--- \"double\"\n--- |\n  literal\n",
+		},
+		{
+			name: "collections",
+			src:  "a: [1, 2, {b: c}]\nd:\n  - e\n  - f: g\n",
+		},
+		{
+			name: "properties",
+			src:  "a: !!str &x 1\nb: *x\nc: &y [!custom d]\n? !!null\n: e\n",
+		},
+		{
+			name: "learn yaml",
+			src:  string(learnYAML),
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expected, err := parser.ParseString(tc.src)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			arena := ast.NewArena()
+			// parsing twice to check that released arena is reused correctly
+			for i := 0; i < 2; i++ {
+				result, err := parser.ParseString(tc.src, parser.WithArena(arena))
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !astcmp.NewComparator().Equal(expected, result) {
+					t.Fatalf("parsing result with arena differs from parsing result without arena")
+				}
+				arena.Release()
+				arena = ast.NewArena()
+			}
+			arena.Release()
+		})
+	}
+}
+
+func BenchmarkParseString_Arena(b *testing.B) {
+	data, err := os.ReadFile("testdata/learnyaml.yaml")
+	if err != nil {
+		b.Fatalf("failed to read test data: %v", err)
+	}
+	src := string(data)
+
+	b.Run("heap", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := parser.ParseString(src); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("arena", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			arena := ast.NewArena()
+			if _, err := parser.ParseString(src, parser.WithArena(arena)); err != nil {
+				b.Fatal(err)
+			}
+			arena.Release()
+		}
+	})
+}
@@ -9,6 +9,12 @@ import (
 
 // YAML specification: [196] s-l+block-node
 func (p *parser) parseBlockNode(ind *indentation, ctx context) ast.Node {
+	// block nodes are parsed repeatedly while trying alternatives of mapping values
+	// and flow nodes in block context, so their results are memoized
+	return p.memoized(blockNodeMemoRule, ind, ctx, (*parser).parseBlockNodeOnce)
+}
+
+func (p *parser) parseBlockNodeOnce(ind *indentation, ctx context) ast.Node {
 	if p.hasErrors() {
 		return ast.NewInvalidNode()
 	}
@@ -73,18 +79,19 @@ func (p *parser) parseBlockCollection(ind *indentation, ctx context) ast.Node {
 		return ast.NewInvalidNode()
 	}
 
+	p.collectionProperties = properties
 	p.setCheckpoint()
 	collection := p.parseSeqSpace(ind, ctx)
 	if ast.ValidNode(collection) {
 		p.commit()
-		return newContentNode(properties, collection)
+		return p.newContentNode(properties, collection)
 	}
 	p.rollback()
 	collection = p.parseBlockMapping(ind)
 	if !ast.ValidNode(collection) {
 		return ast.NewInvalidNode()
 	}
-	return newContentNode(properties, collection)
+	return p.newContentNode(properties, collection)
 }
 
 // YAML specification: [187] l+block-mapping
@@ -99,11 +106,15 @@ func (p *parser) parseBlockMapping(ind *indentation) ast.Node {
 	if !ast.ValidNode(p.parseIndent(&localInd)) {
 		return ast.NewInvalidNode()
 	}
+	eventsLen := p.eventsLen()
+	p.emitCollectionStart(MappingStartEventType)
 	entry := p.parseBlockMappingEntry(&localInd)
 	if !ast.ValidNode(entry) {
+		p.dropEvents(eventsLen)
 		return ast.NewInvalidNode()
 	}
-	entries := []ast.Node{entry}
+	entries := p.appendEntry(nil, entry)
+	p.cut()
 
 	for {
 		p.setCheckpoint()
@@ -117,21 +128,27 @@ func (p *parser) parseBlockMapping(ind *indentation) ast.Node {
 			break
 		}
 		p.commit()
-		entries = append(entries, entry)
+		entries = p.appendEntry(entries, entry)
+		p.cut()
 	}
 
-	return ast.NewMappingNode(entries)
+	p.emitEvent(Event{Type: MappingEndEventType})
+	return p.arena.NewMappingNode(entries)
 }
 
 func (p *parser) parseCompactMapping(ind *indentation) ast.Node {
 	if p.hasErrors() {
 		return ast.NewInvalidNode()
 	}
+	eventsLen := p.eventsLen()
+	p.emitCollectionStart(MappingStartEventType)
 	entry := p.parseBlockMappingEntry(ind)
 	if !ast.ValidNode(entry) {
+		p.dropEvents(eventsLen)
 		return ast.NewInvalidNode()
 	}
-	entries := []ast.Node{entry}
+	entries := p.appendEntry(nil, entry)
+	p.cut()
 
 	for {
 		p.setCheckpoint()
@@ -145,10 +162,12 @@ func (p *parser) parseCompactMapping(ind *indentation) ast.Node {
 			break
 		}
 		p.commit()
-		entries = append(entries, entry)
+		entries = p.appendEntry(entries, entry)
+		p.cut()
 	}
 
-	return ast.NewMappingNode(entries)
+	p.emitEvent(Event{Type: MappingEndEventType})
+	return p.arena.NewMappingNode(entries)
 }
 
 // YAML specification: [188] ns-l-block-map-entry
@@ -175,15 +194,16 @@ func (p *parser) parseBlockMappingImplicitEntry(ind *indentation) ast.Node {
 	key := p.parseBlockMappingImplicitKey()
 	if !ast.ValidNode(key) {
 		p.rollback()
-		key = ast.NewNullNode()
+		key = ast.NewImplicitNullNode()
 	} else {
 		p.commit()
 	}
+	p.emitNode(key)
 	value := p.parseBlockMappingImplicitValue(ind)
 	if !ast.ValidNode(value) {
 		return ast.NewInvalidNode()
 	}
-	return ast.NewMappingEntryNode(key, value)
+	return p.arena.NewMappingEntryNode(key, value)
 }
 
 // YAML specification: [193] ns-s-block-map-implicit-key
@@ -215,10 +235,11 @@ func (p *parser) parseBlockMappingImplicitValue(ind *indentation) ast.Node {
 		return value
 	}
 	p.rollback()
-	value = ast.NewNullNode()
+	value = ast.NewImplicitNullNode()
 	if !ast.ValidNode(p.parseComments()) {
 		return ast.NewInvalidNode()
 	}
+	p.emitNode(value)
 	return value
 }
 
@@ -235,12 +256,13 @@ func (p *parser) parseBlockMappingExplicitEntry(ind *indentation) ast.Node {
 	value := p.parseBlockMappingExplicitValue(ind)
 	if !ast.ValidNode(value) {
 		p.rollback()
-		value = ast.NewNullNode()
+		value = ast.NewImplicitNullNode()
+		p.emitNode(value)
 	} else {
 		p.commit()
 	}
 
-	return ast.NewMappingEntryNode(key, value)
+	return p.arena.NewMappingEntryNode(key, value)
 }
 
 // YAML specification: [189] c-l-block-map-explicit-key
@@ -293,11 +315,15 @@ func (p *parser) parseBlockSequence(ind *indentation) ast.Node {
 	if !ast.ValidNode(p.parseIndent(&localInd)) {
 		return ast.NewInvalidNode()
 	}
+	eventsLen := p.eventsLen()
+	p.emitCollectionStart(SequenceStartEventType)
 	entry := p.parseBlockSequenceEntry(&localInd)
 	if !ast.ValidNode(entry) {
+		p.dropEvents(eventsLen)
 		return ast.NewInvalidNode()
 	}
-	entries := []ast.Node{entry}
+	entries := p.appendEntry(nil, entry)
+	p.cut()
 
 	for {
 		p.setCheckpoint()
@@ -310,11 +336,13 @@ func (p *parser) parseBlockSequence(ind *indentation) ast.Node {
 			p.rollback()
 			break
 		}
-		entries = append(entries, entry)
 		p.commit()
+		entries = p.appendEntry(entries, entry)
+		p.cut()
 	}
 
-	return ast.NewSequenceNode(entries)
+	p.emitEvent(Event{Type: SequenceEndEventType})
+	return p.arena.NewSequenceNode(entries)
 }
 
 // YAML specification: [184] c-l-block-seq-entry
@@ -333,6 +361,12 @@ func (p *parser) parseBlockSequenceEntry(ind *indentation) ast.Node {
 
 // YAML specification: [185] s-l+block-indented
 func (p *parser) parseBlockIndented(ind *indentation, ctx context) ast.Node {
+	// block indented nodes are tried as compact collections and then as block nodes,
+	// so their results are memoized
+	return p.memoized(blockIndentedMemoRule, ind, ctx, (*parser).parseBlockIndentedOnce)
+}
+
+func (p *parser) parseBlockIndentedOnce(ind *indentation, ctx context) ast.Node {
 	if p.hasErrors() {
 		return ast.NewInvalidNode()
 	}
@@ -373,7 +407,8 @@ func (p *parser) parseBlockIndented(ind *indentation, ctx context) ast.Node {
 	p.rollback()
 
 	if ast.ValidNode(p.parseComments()) {
-		return ast.NewNullNode()
+		p.emitNode(ast.NewImplicitNullNode())
+		return ast.NewImplicitNullNode()
 	}
 	return ast.NewInvalidNode()
 }
@@ -383,11 +418,15 @@ func (p *parser) parseCompactSequence(ind *indentation) ast.Node {
 	if p.hasErrors() {
 		return ast.NewInvalidNode()
 	}
+	eventsLen := p.eventsLen()
+	p.emitCollectionStart(SequenceStartEventType)
 	entry := p.parseBlockSequenceEntry(ind)
 	if !ast.ValidNode(entry) {
+		p.dropEvents(eventsLen)
 		return ast.NewInvalidNode()
 	}
-	entries := []ast.Node{entry}
+	entries := p.appendEntry(nil, entry)
+	p.cut()
 
 	for {
 		p.setCheckpoint()
@@ -401,10 +440,21 @@ func (p *parser) parseCompactSequence(ind *indentation) ast.Node {
 			break
 		}
 		p.commit()
-		entries = append(entries, entry)
+		entries = p.appendEntry(entries, entry)
+		p.cut()
 	}
 
-	return ast.NewSequenceNode(entries)
+	p.emitEvent(Event{Type: SequenceEndEventType})
+	return p.arena.NewSequenceNode(entries)
+}
+
+// appendEntry appends the entry of block collection. While emitting events entries are not kept,
+// since their events are emitted already.
+func (p *parser) appendEntry(entries []ast.Node, entry ast.Node) []ast.Node {
+	if p.events != nil {
+		return entries
+	}
+	return p.arena.AppendNode(entries, entry)
 }
 
 // YAML specification: [199] s-l+block-scalar
@@ -447,7 +497,9 @@ func (p *parser) parseBlockScalar(ind *indentation, ctx context) ast.Node {
 	if !ast.ValidNode(content) {
 		return ast.NewInvalidNode()
 	}
-	return newContentNode(properties, content)
+	scalar := p.newContentNode(properties, content)
+	p.emitNode(scalar)
+	return scalar
 }
 
 // YAML specification: [182] c-l+folded
@@ -455,6 +507,7 @@ func (p *parser) parseFolded(ind *indentation) ast.Node {
 	if p.hasErrors() || p.tok.Type != token.FoldedType {
 		return ast.NewInvalidNode()
 	}
+	start := p.tok.Start
 	p.next()
 	header := p.parseBlockHeader()
 	if !ast.ValidNode(header) {
@@ -473,7 +526,7 @@ func (p *parser) parseFolded(ind *indentation) ast.Node {
 		foldedInd.mode = strictEqualityIndentationMode
 	}
 	content := p.parseFoldedContent(&foldedInd, castedHeader.ChompingIndicator())
-	return content
+	return p.markScalar(content, start, FoldedScalarStyle)
 }
 
 // YAML specification: [182] l-folded-content
@@ -496,7 +549,7 @@ func (p *parser) parseFoldedContent(ind *indentation, chomping ast.ChompingType)
 	text := buf.String()
 	buf.Reset()
 	bufsPool.Put(buf)
-	return ast.NewTextNode(text)
+	return p.arena.NewTextNode(text, ast.WithQuotingType(ast.FoldedQuotingType), ast.WithChompingType(chomping))
 }
 
 // YAML specification: [181] l-nb-diff-lines
@@ -734,6 +787,7 @@ func (p *parser) parseLiteral(ind *indentation) ast.Node {
 	if p.hasErrors() || p.tok.Type != token.LiteralType {
 		return ast.NewInvalidNode()
 	}
+	start := p.tok.Start
 	p.next()
 	header := p.parseBlockHeader()
 	if !ast.ValidNode(header) {
@@ -752,7 +806,7 @@ func (p *parser) parseLiteral(ind *indentation) ast.Node {
 		literalInd.mode = strictEqualityIndentationMode
 	}
 	content := p.parseLiteralContent(&literalInd, castedHeader.ChompingIndicator())
-	return content
+	return p.markScalar(content, start, LiteralScalarStyle)
 }
 
 // YAML specification: [173] l-literal-content
@@ -790,7 +844,7 @@ func (p *parser) parseLiteralContent(ind *indentation, chomping ast.ChompingType
 	text := buf.String()
 	buf.Reset()
 	bufsPool.Put(buf)
-	return ast.NewTextNode(text)
+	return p.arena.NewTextNode(text, ast.WithQuotingType(ast.LiteralQuotingType), ast.WithChompingType(chomping))
 }
 
 // YAML specification: [165] b-chomped-last
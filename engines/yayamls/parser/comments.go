@@ -1,6 +1,8 @@
 package parser
 
 import (
+	"strings"
+
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
 	"github.com/KSpaceer/yamly/engines/yayamls/token"
 )
@@ -79,6 +81,10 @@ func (p *parser) parseCommentText() ast.Node {
 	if p.hasErrors() || p.tok.Type != token.CommentType {
 		return ast.NewInvalidNode()
 	}
+	if p.comments != nil {
+		p.recordComment()
+		return ast.NewBasicNode(ast.CommentType)
+	}
 	p.next()
 
 	for token.IsNonBreak(p.tok) {
@@ -86,3 +92,25 @@ func (p *parser) parseCommentText() ast.Node {
 	}
 	return ast.NewBasicNode(ast.CommentType)
 }
+
+// recordComment consumes the comment text and appends it to recorded comments.
+func (p *parser) recordComment() {
+	comment := ast.Comment{
+		Start:    p.tok.Start,
+		Trailing: p.contentRow == p.tok.Start.Row,
+	}
+	var sb strings.Builder
+	for token.IsNonBreak(p.tok) {
+		sb.WriteString(p.tok.Origin)
+		p.next()
+	}
+	comment.Text = strings.TrimRight(sb.String(), " \t")
+	*p.comments = append(*p.comments, comment)
+}
+
+func (p *parser) commentsLen() int {
+	if p.comments == nil {
+		return 0
+	}
+	return len(*p.comments)
+}
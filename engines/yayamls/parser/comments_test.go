@@ -0,0 +1,84 @@
+package parser_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+	"github.com/KSpaceer/yamly/engines/yayamls/token"
+)
+
+func TestParseString_Comments(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		src      string
+		expected []ast.Comment
+	}
+
+	tcases := []tcase{
+		{
+			name: "no comments",
+			src:  "a: 1\n",
+		},
+		{
+			name: "full-line and trailing comments",
+			src:  "# head\na: 1 # line\n  # indented\nb: 2\n",
+			expected: []ast.Comment{
+				{Text: "# head", Start: token.Position{Row: 1, Column: 1}},
+				{Text: "# line", Start: token.Position{Row: 2, Column: 6}, Trailing: true},
+				{Text: "# indented", Start: token.Position{Row: 3, Column: 3}},
+			},
+		},
+		{
+			name: "comments in flow collection",
+			src:  "a: [x, # first\n  # second\n  y]\n",
+			expected: []ast.Comment{
+				{Text: "# first", Start: token.Position{Row: 1, Column: 8}, Trailing: true},
+				{Text: "# second", Start: token.Position{Row: 2, Column: 3}},
+			},
+		},
+		{
+			name: "comment characters inside scalars",
+			src:  "a: b#c\nd: \"e # f\"\ng: |\n  h # i\n",
+		},
+		{
+			name: "comment after block scalar header",
+			src:  "a: | # header   \n  text\n",
+			expected: []ast.Comment{
+				{Text: "# header", Start: token.Position{Row: 1, Column: 6}, Trailing: true},
+			},
+		},
+		{
+			name: "comments around documents",
+			src:  "# before\n--- # marker\na\n... # end\n",
+			expected: []ast.Comment{
+				{Text: "# before", Start: token.Position{Row: 1, Column: 1}},
+				{Text: "# marker", Start: token.Position{Row: 2, Column: 5}, Trailing: true},
+				{Text: "# end", Start: token.Position{Row: 4, Column: 5}, Trailing: true},
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var comments []ast.Comment
+			positions := make(ast.Positions)
+			if _, err := parser.ParseString(
+				tc.src,
+				parser.WithComments(&comments),
+				parser.WithPositions(positions),
+			); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(comments, tc.expected) {
+				t.Errorf("expected comments %+v, but got %+v", tc.expected, comments)
+			}
+		})
+	}
+}
@@ -10,6 +10,22 @@ import (
 
 // YAML specification: [211] l-yaml-stream
 func (p *parser) parseStream() ast.Node {
+	var docs []ast.Node
+	p.parseStreamStart()
+	for {
+		doc, ok := p.parseNextDocument()
+		if !ok {
+			break
+		}
+		docs = append(docs, doc)
+	}
+	p.checkStreamEnd()
+	return ast.NewStreamNode(docs)
+}
+
+// parseStreamStart skips document prefixes at the start of the stream.
+// Stream documents are parsed afterwards one by one using parseNextDocument.
+func (p *parser) parseStreamStart() {
 	for {
 		p.setCheckpoint()
 		if prefix := p.parseDocumentPrefix(); !ast.ValidNode(prefix) || prefix.Type() == ast.NullType {
@@ -18,16 +34,32 @@ func (p *parser) parseStream() ast.Node {
 		}
 		p.commit()
 	}
+	p.firstDocumentPending = true
+}
 
-	var docs []ast.Node
+// checkStreamEnd reports an error if the stream contains tokens not belonging to any document.
+func (p *parser) checkStreamEnd() {
+	if !p.hasErrors() && p.tok.Type != token.EOFType {
+		p.appendError(UnexpectedTokenError{Tok: p.tok})
+	}
+}
 
-	p.setCheckpoint()
-	doc := p.parseAnyDocument()
-	if !ast.ValidNode(doc) {
+// parseNextDocument parses the next document of the stream. Every parsed document is committed,
+// so the tokens of the document are not buffered anymore.
+func (p *parser) parseNextDocument() (ast.Node, bool) {
+	p.explicitDocument = false
+	clear(p.memo)
+	clear(p.nodeInfos)
+	if p.firstDocumentPending {
+		p.firstDocumentPending = false
+		p.setCheckpoint()
+		doc := p.parseAnyDocument()
+		if ast.ValidNode(doc) {
+			p.commit()
+			p.endDocument()
+			return doc, true
+		}
 		p.rollback()
-	} else {
-		p.commit()
-		docs = append(docs, doc)
 	}
 
 	for {
@@ -35,14 +67,14 @@ func (p *parser) parseStream() ast.Node {
 		if ast.ValidNode(p.parseSuffixesAndPrefixes()) {
 			p.commit()
 			p.setCheckpoint()
-			doc = p.parseAnyDocument()
+			doc := p.parseAnyDocument()
 			if !ast.ValidNode(doc) {
 				p.rollback()
-			} else {
-				docs = append(docs, doc)
-				p.commit()
+				continue
 			}
-			continue
+			p.commit()
+			p.endDocument()
+			return doc, true
 		}
 		p.rollback()
 
@@ -59,16 +91,21 @@ func (p *parser) parseStream() ast.Node {
 		p.rollback()
 
 		p.setCheckpoint()
-		doc = p.parseExplicitDocument()
+		doc := p.parseExplicitDocument()
 		if !ast.ValidNode(doc) {
 			p.rollback()
-			break
+			return nil, false
 		}
 		p.commit()
-		docs = append(docs, doc)
+		p.endDocument()
+		return doc, true
 	}
+}
 
-	return ast.NewStreamNode(docs)
+// endDocument emits the end of the parsed document and passes its events to EventReader.
+func (p *parser) endDocument() {
+	p.emitEvent(Event{Type: DocumentEndEventType, Explicit: p.tok.Type == token.DocumentEndType})
+	p.flushEvents()
 }
 
 func (p *parser) parseSuffixesAndPrefixes() ast.Node {
@@ -117,6 +154,7 @@ func (p *parser) parseAnyDocument() ast.Node {
 	p.rollback()
 	p.setCheckpoint()
 
+	p.emitEvent(Event{Type: DocumentStartEventType})
 	doc = p.parseBareDocument()
 	if ast.ValidNode(doc) {
 		p.commit()
@@ -150,7 +188,9 @@ func (p *parser) parseExplicitDocument() ast.Node {
 	if p.hasErrors() || p.tok.Type != token.DirectiveEndType {
 		return ast.NewInvalidNode()
 	}
+	p.explicitDocument = true
 	p.next()
+	p.emitEvent(Event{Type: DocumentStartEventType, Explicit: true})
 
 	p.setCheckpoint()
 	doc := p.parseBareDocument()
@@ -166,7 +206,8 @@ func (p *parser) parseExplicitDocument() ast.Node {
 		return ast.NewInvalidNode()
 	}
 	p.commit()
-	return ast.NewNullNode()
+	p.emitNode(ast.NewImplicitNullNode())
+	return ast.NewImplicitNullNode()
 }
 
 // YAML specification: [207] l-bare-document
@@ -251,6 +292,7 @@ func (p *parser) parseTagDirective() ast.Node {
 }
 
 // YAML specification: [89] c-tag-handle
+// The handle is returned as a tag node without suffix.
 func (p *parser) parseTagHandle() ast.Node {
 	if p.hasErrors() || p.tok.Type != token.TagType {
 		return ast.NewInvalidNode()
@@ -260,24 +302,25 @@ func (p *parser) parseTagHandle() ast.Node {
 	// YAML specification: [91] c-secondary-tag-handle
 	if p.tok.Type == token.TagType {
 		p.next()
-		return ast.NewBasicNode(ast.TagType)
+		return ast.NewTagNode("", ast.WithTagHandle("!!"))
 	}
 
 	// YAML specification: [92] c-named-tag-handle
 	p.setCheckpoint()
 	if p.tok.Type == token.StringType && p.tok.ConformsCharSet(yamlchar.WordCharSetType) {
+		name := p.tok.Origin
 		p.next()
 		if p.tok.Type == token.TagType {
 			p.next()
 			p.commit()
-			return ast.NewBasicNode(ast.TagType)
+			return ast.NewTagNode("", ast.WithTagHandle("!"+name+"!"))
 		}
 	}
 	p.rollback()
 
 	// else - primary
 	// YAML specification: [90] c-primary-tag-handle
-	return ast.NewBasicNode(ast.TagType)
+	return ast.NewTagNode("", ast.WithTagHandle("!"))
 }
 
 // YAML specification: [93] ns-tag-prefix
@@ -395,7 +438,7 @@ func (p *parser) parseDocumentPrefix() ast.Node {
 		p.commit()
 	}
 	if !notNull {
-		return ast.NewNullNode()
+		return ast.NewImplicitNullNode()
 	}
 	return ast.NewBasicNode(ast.DocumentPrefixType)
 }
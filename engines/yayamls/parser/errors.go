@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/KSpaceer/yamly/engines/yayamls/token"
@@ -115,3 +116,26 @@ type DeadEndError struct {
 func (d DeadEndError) Error() string {
 	return fmt.Sprintf("failed to parse data: meeting a 'dead end' token at position %s", d.Pos)
 }
+
+// ErrEventReaderClosed is returned by EventReader after it is closed.
+var ErrEventReaderClosed = errors.New("event reader is closed")
+
+// BacktrackError is used to indicate case when EventReader has to roll back a rule,
+// which events are already emitted, to parse the source. Events are emitted as soon
+// as block collection entries are parsed, so it can happen only for invalid documents.
+type BacktrackError struct {
+	Pos token.Position
+}
+
+func (b BacktrackError) Error() string {
+	return fmt.Sprintf("failed to parse data as stream: events preceding position %s are already emitted", b.Pos)
+}
+
+// UnexpectedTokenError is used to indicate case when some part of the stream can't be parsed as a document.
+type UnexpectedTokenError struct {
+	Tok token.Token
+}
+
+func (u UnexpectedTokenError) Error() string {
+	return fmt.Sprintf("failed to parse data: unexpected token %q at position %s", u.Tok.Origin, u.Tok.Start)
+}
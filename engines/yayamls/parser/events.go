@@ -0,0 +1,631 @@
+package parser
+
+import (
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/lexer"
+	"github.com/KSpaceer/yamly/engines/yayamls/token"
+	"github.com/KSpaceer/yamly/engines/yayamls/yamlchar"
+)
+
+// EventType defines type of parsing event.
+type EventType int8
+
+const (
+	UnknownEventType EventType = iota
+	StreamStartEventType
+	StreamEndEventType
+	DocumentStartEventType
+	DocumentEndEventType
+	MappingStartEventType
+	MappingEndEventType
+	SequenceStartEventType
+	SequenceEndEventType
+	ScalarEventType
+	AliasEventType
+)
+
+func (t EventType) String() string {
+	switch t {
+	case StreamStartEventType:
+		return "StreamStart"
+	case StreamEndEventType:
+		return "StreamEnd"
+	case DocumentStartEventType:
+		return "DocumentStart"
+	case DocumentEndEventType:
+		return "DocumentEnd"
+	case MappingStartEventType:
+		return "MappingStart"
+	case MappingEndEventType:
+		return "MappingEnd"
+	case SequenceStartEventType:
+		return "SequenceStart"
+	case SequenceEndEventType:
+		return "SequenceEnd"
+	case ScalarEventType:
+		return "Scalar"
+	case AliasEventType:
+		return "Alias"
+	default:
+		return "Unknown"
+	}
+}
+
+// ScalarStyle defines presentation style of scalar.
+type ScalarStyle int8
+
+const (
+	UnknownScalarStyle ScalarStyle = iota
+	PlainScalarStyle
+	SingleQuotedScalarStyle
+	DoubleQuotedScalarStyle
+	LiteralScalarStyle
+	FoldedScalarStyle
+)
+
+// Event is a single parsing event.
+type Event struct {
+	Type EventType
+	// Tag is the tag of scalar or collection. Tag handles are not kept,
+	// so shorthand tags contain only the suffix (e.g. "str" for "!!str").
+	Tag string
+	// Anchor is the anchor of scalar or collection.
+	Anchor string
+	// Value is the text of scalar with resolved escape sequences or the name of anchor referenced by alias.
+	Value string
+	// Style is the presentation style of scalar.
+	Style ScalarStyle
+	// Flow shows if collection is written in flow style.
+	Flow bool
+	// Explicit shows if document starts with directives end marker ("---")
+	// or ends with document end marker ("...").
+	Explicit bool
+	// Start is the position of the scalar or alias in source. Position is zero
+	// for empty nodes and events not related to scalars and aliases.
+	Start token.Position
+}
+
+type nodeInfo struct {
+	start token.Position
+	style ScalarStyle
+	flow  bool
+}
+
+func (p *parser) markScalar(n ast.Node, start token.Position, style ScalarStyle) ast.Node {
+	if p.nodeInfos != nil && ast.ValidNode(n) {
+		p.nodeInfos[n] = nodeInfo{start: start, style: style}
+	}
+	p.markPosition(n, start)
+	return n
+}
+
+func (p *parser) markFlowCollection(n ast.Node, start token.Position) ast.Node {
+	if p.nodeInfos != nil && ast.ValidNode(n) {
+		p.nodeInfos[n] = nodeInfo{start: start, flow: true}
+	}
+	p.markPosition(n, start)
+	return n
+}
+
+func (p *parser) markPosition(n ast.Node, start token.Position) {
+	// null nodes are shared and can't be distinguished by positions
+	if p.positions != nil && ast.ValidNode(n) && n.Type() != ast.NullType {
+		p.positions[n] = start
+	}
+}
+
+// eventsBatchSize is the amount of events, which are collected before passing them to EventReader.
+const eventsBatchSize = 64
+
+// eventJournal contains events emitted by grammar rules, which are not passed to EventReader yet.
+// Events of rolled back rules are removed from the journal. When a block collection entry is parsed,
+// the parser "cuts" the stream: events and tokens before the current position are not needed
+// anymore, since rules started before the cut are not rolled back in valid documents.
+type eventJournal struct {
+	events []Event
+	// flushed is the amount of events passed to EventReader
+	flushed int
+	// cutDepth is the depth of checkpoints stack at the last cut. Checkpoints below it
+	// are set before the cut and can't be rolled back.
+	cutDepth int
+	// cuts is the amount of performed cuts
+	cuts  int
+	flush func([]Event) bool
+}
+
+// eventsLen returns the total amount of events emitted by the parser.
+func (p *parser) eventsLen() int {
+	if p.events == nil {
+		return 0
+	}
+	return p.events.flushed + len(p.events.events)
+}
+
+// cuts returns the amount of cuts performed by the parser.
+func (p *parser) cuts() int {
+	if p.events == nil {
+		return 0
+	}
+	return p.events.cuts
+}
+
+func (p *parser) emitEvent(ev Event) {
+	if p.events != nil {
+		p.events.events = append(p.events.events, ev)
+	}
+}
+
+// emitNode emits events of the node parsed as a whole, e.g. scalar or flow collection.
+func (p *parser) emitNode(n ast.Node) {
+	if p.events == nil {
+		return
+	}
+	e := eventEmitter{infos: p.nodeInfos, events: p.events.events}
+	e.emit(n)
+	p.events.events = e.events
+}
+
+// emitCollectionStart emits the start of block collection with pending collection properties.
+func (p *parser) emitCollectionStart(tp EventType) {
+	if p.events == nil {
+		return
+	}
+	var e eventEmitter
+	if p.collectionProperties != nil {
+		p.collectionProperties.Accept(&e)
+		p.collectionProperties = nil
+	}
+	tag, anchor := e.properties()
+	p.emitEvent(Event{Type: tp, Tag: tag, Anchor: anchor})
+}
+
+// dropEvents removes events emitted after the given total amount of events.
+func (p *parser) dropEvents(eventsLen int) {
+	if p.events == nil {
+		return
+	}
+	if n := eventsLen - p.events.flushed; n >= 0 && n < len(p.events.events) {
+		clear(p.events.events[n:])
+		p.events.events = p.events.events[:n]
+	}
+}
+
+// cut releases events and tokens before the current position. It is called when
+// a block collection entry is parsed.
+func (p *parser) cut() {
+	if p.events == nil {
+		return
+	}
+	p.tokSrc.Cut()
+	p.events.cutDepth = len(p.savedStates)
+	p.events.cuts++
+	// the positions before the cut are not visited anymore
+	clear(p.memo)
+	clear(p.nodeInfos)
+	p.deadEndFinder.Reset()
+	if len(p.events.events) >= eventsBatchSize {
+		p.flushEvents()
+	}
+}
+
+// flushEvents passes emitted events to EventReader.
+func (p *parser) flushEvents() {
+	if p.events == nil || len(p.events.events) == 0 {
+		return
+	}
+	if !p.events.flush(p.events.events) {
+		p.appendError(ErrEventReaderClosed)
+	}
+	p.events.flushed += len(p.events.events)
+	// events are owned by the reader now
+	p.events.events = nil
+}
+
+// EventReader emits parsing events for YAML stream. The source is read and parsed while
+// events are emitted: events of block collections are emitted entry by entry, and tokens
+// of parsed entries are discarded, so memory used by EventReader does not depend on the size of
+// the stream or the document. Scalars and flow collections are emitted after parsing them as a whole.
+//
+// Parsing is performed in a separate goroutine, so EventReader must be read until the end
+// (io.EOF or an error) or closed.
+type EventReader struct {
+	batches chan eventsBatch
+	done    chan struct{}
+	events  []Event
+	// pending is the index of the next event to return
+	pending int
+	err     error
+}
+
+type eventsBatch struct {
+	events []Event
+	err    error
+}
+
+type readErrorer interface {
+	Err() error
+}
+
+// NewEventReader creates EventReader for the source read from the reader.
+// If the token stream constructor is provided with WithTokenStreamConstructor option,
+// the source is read completely before parsing, since the constructor requires the whole source.
+func NewEventReader(r io.Reader, opts ...ParseOption) *EventReader {
+	o := applyOptions(opts...)
+	er := &EventReader{
+		batches: make(chan eventsBatch, 1),
+		done:    make(chan struct{}),
+	}
+
+	var cts ConfigurableTokenStream
+	if o.tokenStreamConstructor != nil {
+		src, err := io.ReadAll(r)
+		if err != nil {
+			close(er.batches)
+			er.err = err
+			return er
+		}
+		cts = o.tokenStreamConstructor(string(src))
+	} else {
+		cts = lexer.NewReaderTokenizer(r)
+	}
+
+	p := getParser(newTokenSource(cts))
+	p.nodeInfos = map[ast.Node]nodeInfo{}
+	p.events = &eventJournal{flush: func(events []Event) bool {
+		return er.send(eventsBatch{events: events})
+	}}
+	go er.run(p, cts)
+	return er
+}
+
+func (r *EventReader) send(batch eventsBatch) bool {
+	select {
+	case r.batches <- batch:
+		return true
+	case <-r.done:
+		return false
+	}
+}
+
+func (r *EventReader) run(p *parser, cts ConfigurableTokenStream) {
+	defer close(r.batches)
+	defer p.release()
+
+	p.next()
+	p.startOfLine = true
+	p.emitEvent(Event{Type: StreamStartEventType})
+	p.parseStreamStart()
+	for !p.hasErrors() {
+		if _, ok := p.parseNextDocument(); !ok {
+			break
+		}
+	}
+	p.checkStreamEnd()
+
+	var err error
+	if re, ok := cts.(readErrorer); ok && re.Err() != nil {
+		// parsing errors are caused by incomplete source
+		err = re.Err()
+	} else {
+		err = p.error()
+	}
+	if err == nil {
+		p.emitEvent(Event{Type: StreamEndEventType})
+	}
+	p.flushEvents()
+	if err != nil && !errors.Is(err, ErrEventReaderClosed) {
+		r.send(eventsBatch{err: err})
+	}
+}
+
+// Next returns the next event of the stream. After StreamEnd event or an error
+// Next returns io.EOF or the same error respectively.
+func (r *EventReader) Next() (Event, error) {
+	for r.pending == len(r.events) {
+		if r.err != nil {
+			return Event{}, r.err
+		}
+		batch, ok := <-r.batches
+		if !ok {
+			r.err = io.EOF
+			continue
+		}
+		if batch.err != nil {
+			r.err = batch.err
+		}
+		r.events, r.pending = batch.events, 0
+	}
+	ev := r.events[r.pending]
+	r.pending++
+	return ev, nil
+}
+
+// Close stops parsing and releases resources used by EventReader. After closing Next returns
+// ErrEventReaderClosed, unless the end of the stream or an error is already reached.
+func (r *EventReader) Close() {
+	select {
+	case <-r.done:
+		return
+	default:
+	}
+	close(r.done)
+	// waiting for the parser to stop
+	for range r.batches {
+	}
+	if r.err == nil {
+		r.err = ErrEventReaderClosed
+	}
+	r.events, r.pending = nil, 0
+}
+
+// eventEmitter converts document AST into events.
+type eventEmitter struct {
+	infos  map[ast.Node]nodeInfo
+	events []Event
+
+	tag, anchor string
+}
+
+func (e *eventEmitter) emit(n ast.Node) {
+	if !ast.ValidNode(n) {
+		n = ast.NewNullNode()
+	}
+	n.Accept(e)
+}
+
+// properties returns and resets the properties of the current node.
+func (e *eventEmitter) properties() (tag, anchor string) {
+	tag, anchor = e.tag, e.anchor
+	e.tag, e.anchor = "", ""
+	return tag, anchor
+}
+
+func (e *eventEmitter) VisitStreamNode(n *ast.StreamNode) {
+	for _, doc := range n.Documents() {
+		e.emit(doc)
+	}
+}
+
+func (e *eventEmitter) VisitTagNode(n *ast.TagNode) {
+	e.tag = n.Text()
+	if e.tag == "" {
+		// non-specific tag
+		e.tag = "!"
+	}
+}
+
+func (e *eventEmitter) VisitAnchorNode(n *ast.AnchorNode) {
+	e.anchor = n.Text()
+}
+
+func (e *eventEmitter) VisitAliasNode(n *ast.AliasNode) {
+	e.events = append(e.events, Event{
+		Type:  AliasEventType,
+		Value: n.Text(),
+		Start: e.infos[n].start,
+	})
+}
+
+func (e *eventEmitter) VisitTextNode(n *ast.TextNode) {
+	info := e.infos[n]
+	tag, anchor := e.properties()
+	style := info.style
+	if style == UnknownScalarStyle {
+		style = quotingStyle(n.QuotingType())
+	}
+	e.events = append(e.events, Event{
+		Type:   ScalarEventType,
+		Tag:    tag,
+		Anchor: anchor,
+		Value:  scalarValue(n.Text(), style),
+		Style:  style,
+		Start:  info.start,
+	})
+}
+
+// scalarValue resolves escape sequences of quoted scalar text.
+func scalarValue(text string, style ScalarStyle) string {
+	var (
+		value string
+		err   error
+	)
+	switch style {
+	case SingleQuotedScalarStyle:
+		value, err = yamlchar.ConvertFromYAMLSingleQuotedString(text)
+	case DoubleQuotedScalarStyle:
+		value, err = yamlchar.ConvertFromYAMLDoubleQuotedString(text)
+	default:
+		return text
+	}
+	if err != nil {
+		return text
+	}
+	return value
+}
+
+func quotingStyle(q ast.QuotingType) ScalarStyle {
+	switch q {
+	case ast.SingleQuotingType:
+		return SingleQuotedScalarStyle
+	case ast.DoubleQuotingType:
+		return DoubleQuotedScalarStyle
+	case ast.LiteralQuotingType:
+		return LiteralScalarStyle
+	case ast.FoldedQuotingType:
+		return FoldedScalarStyle
+	default:
+		return PlainScalarStyle
+	}
+}
+
+func (e *eventEmitter) VisitSequenceNode(n *ast.SequenceNode) {
+	tag, anchor := e.properties()
+	e.events = append(e.events, Event{
+		Type:   SequenceStartEventType,
+		Tag:    tag,
+		Anchor: anchor,
+		Flow:   e.infos[n].flow || n.Flow(),
+	})
+	for _, entry := range n.Entries() {
+		if _, ok := entry.(*ast.MappingEntryNode); ok {
+			// single pair mapping in flow sequence
+			e.events = append(e.events, Event{Type: MappingStartEventType, Flow: true})
+			e.emit(entry)
+			e.events = append(e.events, Event{Type: MappingEndEventType})
+			continue
+		}
+		e.emit(entry)
+	}
+	e.events = append(e.events, Event{Type: SequenceEndEventType})
+}
+
+func (e *eventEmitter) VisitMappingNode(n *ast.MappingNode) {
+	tag, anchor := e.properties()
+	e.events = append(e.events, Event{
+		Type:   MappingStartEventType,
+		Tag:    tag,
+		Anchor: anchor,
+		Flow:   e.infos[n].flow || n.Flow(),
+	})
+	for _, entry := range n.Entries() {
+		e.emit(entry)
+	}
+	e.events = append(e.events, Event{Type: MappingEndEventType})
+}
+
+func (e *eventEmitter) VisitMappingEntryNode(n *ast.MappingEntryNode) {
+	e.emit(n.Key())
+	e.emit(n.Value())
+}
+
+func (e *eventEmitter) VisitNullNode(*ast.NullNode) {
+	tag, anchor := e.properties()
+	e.events = append(e.events, Event{
+		Type:   ScalarEventType,
+		Tag:    tag,
+		Anchor: anchor,
+		Style:  PlainScalarStyle,
+	})
+}
+
+func (e *eventEmitter) VisitPropertiesNode(n *ast.PropertiesNode) {
+	if ast.ValidNode(n.Tag()) {
+		n.Tag().Accept(e)
+	}
+	if ast.ValidNode(n.Anchor()) {
+		n.Anchor().Accept(e)
+	}
+}
+
+func (e *eventEmitter) VisitContentNode(n *ast.ContentNode) {
+	if ast.ValidNode(n.Properties()) {
+		n.Properties().Accept(e)
+	}
+	e.emit(n.Content())
+}
+
+// String returns event representation in yaml-test-suite event format.
+func (ev Event) String() string {
+	var sb strings.Builder
+	switch ev.Type {
+	case StreamStartEventType:
+		return "+STR"
+	case StreamEndEventType:
+		return "-STR"
+	case DocumentStartEventType:
+		if ev.Explicit {
+			return "+DOC ---"
+		}
+		return "+DOC"
+	case DocumentEndEventType:
+		if ev.Explicit {
+			return "-DOC ..."
+		}
+		return "-DOC"
+	case MappingStartEventType:
+		sb.WriteString("+MAP")
+		if ev.Flow {
+			sb.WriteString(" {}")
+		}
+	case MappingEndEventType:
+		return "-MAP"
+	case SequenceStartEventType:
+		sb.WriteString("+SEQ")
+		if ev.Flow {
+			sb.WriteString(" []")
+		}
+	case SequenceEndEventType:
+		return "-SEQ"
+	case AliasEventType:
+		return "=ALI *" + ev.Value
+	case ScalarEventType:
+		sb.WriteString("=VAL")
+	default:
+		return "???"
+	}
+
+	if ev.Anchor != "" {
+		sb.WriteString(" &")
+		sb.WriteString(ev.Anchor)
+	}
+	if ev.Tag != "" {
+		sb.WriteString(" <")
+		sb.WriteString(expandTag(ev.Tag))
+		sb.WriteString(">")
+	}
+	if ev.Type == ScalarEventType {
+		sb.WriteByte(' ')
+		switch ev.Style {
+		case SingleQuotedScalarStyle:
+			sb.WriteByte('\'')
+		case DoubleQuotedScalarStyle:
+			sb.WriteByte('"')
+		case LiteralScalarStyle:
+			sb.WriteByte('|')
+		case FoldedScalarStyle:
+			sb.WriteByte('>')
+		default:
+			sb.WriteByte(':')
+		}
+		sb.WriteString(testSuiteEscaper.Replace(ev.Value))
+	}
+	return sb.String()
+}
+
+// expandTag returns full tag name. Since tag handles are not kept, shorthand tags
+// are expanded using the secondary tag handle ("!!").
+func expandTag(tag string) string {
+	if tag == "!" || strings.Contains(tag, ":") || strings.HasPrefix(tag, "!") {
+		return tag
+	}
+	return "tag:yaml.org,2002:" + tag
+}
+
+var testSuiteEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"\n", `\n`,
+	"\t", `\t`,
+	"\r", `\r`,
+	"\b", `\b`,
+)
+
+// WriteTestSuiteEvents writes all events of the reader in yaml-test-suite event format, one event per line.
+func WriteTestSuiteEvents(w io.Writer, r *EventReader) error {
+	for {
+		ev, err := r.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if _, err := io.WriteString(w, ev.String()+"\n"); err != nil {
+			r.Close()
+			return err
+		}
+	}
+}
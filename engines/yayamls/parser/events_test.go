@@ -0,0 +1,279 @@
+package parser_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+	"github.com/KSpaceer/yamly/engines/yayamls/token"
+)
+
+func TestEventReader(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		src      string
+		expected []string
+	}
+
+	tcases := []tcase{
+		{
+			name:     "empty stream",
+			src:      "",
+			expected: []string{"+STR", "-STR"},
+		},
+		{
+			name: "block mapping",
+			src:  "a: &x 'b'\nc: \"d\"\ne:\n",
+			expected: []string{
+				"+STR", "+DOC", "+MAP",
+				"=VAL :a", "=VAL &x 'b",
+				"=VAL :c", `=VAL "d`,
+				"=VAL :e", "=VAL :",
+				"-MAP", "-DOC", "-STR",
+			},
+		},
+		{
+			name: "flow collections and aliases",
+			src:  "- &seq [1, {k: v}]\n- *seq\n",
+			expected: []string{
+				"+STR", "+DOC", "+SEQ",
+				"+SEQ [] &seq", "=VAL :1", "+MAP {}", "=VAL :k", "=VAL :v", "-MAP", "-SEQ",
+				"=ALI *seq",
+				"-SEQ", "-DOC", "-STR",
+			},
+		},
+		{
+			name: "block scalars and tags",
+			src:  "lit: |\n  a\n  b\nfold: >-\n  c\n  d\ntagged: !!int 1\n",
+			expected: []string{
+				"+STR", "+DOC", "+MAP",
+				"=VAL :lit", `=VAL |a\nb\n`,
+				"=VAL :fold", "=VAL >c d",
+				"=VAL :tagged", "=VAL <tag:yaml.org,2002:int> :1",
+				"-MAP", "-DOC", "-STR",
+			},
+		},
+		{
+			name: "multiple documents",
+			src:  "first\n...\n---\nsecond\n--- third\n",
+			expected: []string{
+				"+STR",
+				"+DOC", "=VAL :first", "-DOC ...",
+				"+DOC ---", "=VAL :second", "-DOC",
+				"+DOC ---", "=VAL :third", "-DOC",
+				"-STR",
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			var sb strings.Builder
+			if err := parser.WriteTestSuiteEvents(&sb, parser.NewEventReader(strings.NewReader(tc.src))); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			expected := strings.Join(tc.expected, "\n") + "\n"
+			if result := sb.String(); result != expected {
+				t.Errorf("expected:\n%s\nbut got:\n%s", expected, result)
+			}
+		})
+	}
+}
+
+func TestEventReader_Positions(t *testing.T) {
+	t.Parallel()
+
+	r := parser.NewEventReader(strings.NewReader("key: value\nlist:\n  - 'quoted'\n  - *alias\n"))
+	defer r.Close()
+
+	expected := map[string]token.Position{
+		"key":    {Row: 1, Column: 1},
+		"value":  {Row: 1, Column: 6},
+		"list":   {Row: 2, Column: 1},
+		"quoted": {Row: 3, Column: 5},
+		"alias":  {Row: 4, Column: 5},
+	}
+
+	for {
+		ev, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if ev.Type != parser.ScalarEventType && ev.Type != parser.AliasEventType {
+			continue
+		}
+		pos, ok := expected[ev.Value]
+		if !ok {
+			t.Fatalf("unexpected event %s", ev)
+		}
+		if ev.Start != pos {
+			t.Errorf("expected position %s for %q, but got %s", pos, ev.Value, ev.Start)
+		}
+	}
+}
+
+func TestEventReader_Error(t *testing.T) {
+	t.Parallel()
+
+	r := parser.NewEventReader(strings.NewReader("a: [b, c\n"))
+	var err error
+	for err == nil {
+		_, err = r.Next()
+	}
+	if errors.Is(err, io.EOF) {
+		t.Fatal("expected parsing error, but got EOF")
+	}
+	if _, nextErr := r.Next(); !errors.Is(nextErr, err) {
+		t.Errorf("expected the same error on subsequent call, but got %v", nextErr)
+	}
+}
+
+// entriesReader generates YAML document with a block sequence of entries.
+type entriesReader struct {
+	entries int
+	written int
+	buf     []byte
+}
+
+func (r *entriesReader) Read(p []byte) (int, error) {
+	for len(r.buf) < len(p) && r.written < r.entries {
+		r.buf = fmt.Appendf(r.buf, "- name: entry%d\n  tags: [a, b]\n  nested:\n    key: 'value'\n", r.written)
+		r.written++
+	}
+	if len(r.buf) == 0 {
+		return 0, io.EOF
+	}
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+	return n, nil
+}
+
+// TestEventReader_ConstantMemory is not parallel, since heap usage of other tests affects measurements.
+func TestEventReader_ConstantMemory(t *testing.T) {
+	const (
+		entries = 50000
+		// events per entry: mapping start and end, 3 keys, scalar value, sequence start and end
+		// with 2 scalars, nested mapping start and end with scalar key and value
+		entryEvents   = 2 + 3 + 1 + 4 + 4
+		streamEvents  = 4 + 2 + entries*entryEvents
+		measurements  = 10
+		maxHeapGrowth = 4 << 20
+	)
+
+	r := parser.NewEventReader(&entriesReader{entries: entries})
+	defer r.Close()
+
+	var (
+		ms        runtime.MemStats
+		baseHeap  uint64
+		maxGrowth uint64
+		count     int
+	)
+	for {
+		_, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		count++
+		if count%(streamEvents/measurements) == 0 {
+			runtime.GC()
+			runtime.ReadMemStats(&ms)
+			if baseHeap == 0 {
+				baseHeap = ms.HeapAlloc
+			} else if ms.HeapAlloc > baseHeap {
+				maxGrowth = max(maxGrowth, ms.HeapAlloc-baseHeap)
+			}
+		}
+	}
+	if count != streamEvents {
+		t.Errorf("expected %d events, but got %d", streamEvents, count)
+	}
+	if maxGrowth > maxHeapGrowth {
+		t.Errorf("heap grows while reading events: %d bytes", maxGrowth)
+	}
+}
+
+// yamlTestSuiteDirEnv is the environment variable containing the path to yaml-test-suite cases,
+// e.g. to the checkout of "data" branch of https://github.com/yaml/yaml-test-suite.
+// If it is not set, the cases from testdata are used.
+const yamlTestSuiteDirEnv = "YAML_TEST_SUITE_DIR"
+
+func TestEventReader_YAMLTestSuite(t *testing.T) {
+	t.Parallel()
+
+	dir := os.Getenv(yamlTestSuiteDirEnv)
+	if dir == "" {
+		dir = filepath.Join("testdata", "yaml-test-suite")
+	}
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		// every directory containing the source is a test case
+		if d.IsDir() || d.Name() != "in.yaml" {
+			return nil
+		}
+		caseDir := filepath.Dir(path)
+		name, err := filepath.Rel(dir, caseDir)
+		if err != nil {
+			return err
+		}
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			runYAMLTestSuiteCase(t, caseDir)
+		})
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to walk test suite directory: %v", err)
+	}
+}
+
+func runYAMLTestSuiteCase(t *testing.T, dir string) {
+	t.Helper()
+
+	src, err := os.Open(filepath.Join(dir, "in.yaml"))
+	if err != nil {
+		t.Fatalf("failed to open source: %v", err)
+	}
+	defer src.Close()
+
+	var sb strings.Builder
+	err = parser.WriteTestSuiteEvents(&sb, parser.NewEventReader(src))
+
+	// the source is invalid if the case contains "error" file
+	if _, statErr := os.Stat(filepath.Join(dir, "error")); statErr == nil {
+		if err == nil {
+			t.Errorf("expected error, but got events:\n%s", sb.String())
+		}
+		return
+	}
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected, err := os.ReadFile(filepath.Join(dir, "test.event"))
+	if err != nil {
+		t.Fatalf("failed to read expected events: %v", err)
+	}
+	if result := sb.String(); result != string(expected) {
+		t.Errorf("expected:\n%s\nbut got:\n%s", expected, result)
+	}
+}
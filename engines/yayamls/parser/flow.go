@@ -24,6 +24,7 @@ func (p *parser) parseFlowInBlock(ind *indentation) ast.Node {
 	if !ast.ValidNode(p.parseComments()) {
 		return ast.NewInvalidNode()
 	}
+	p.emitNode(node)
 	return node
 }
 
@@ -65,11 +66,11 @@ func (p *parser) parseGenericFlowNode(
 
 	if ast.ValidNode(node) {
 		p.commit()
-		return newContentNode(properties, node)
+		return p.newContentNode(properties, node)
 	}
 
 	p.rollback()
-	return newContentNode(properties, ast.NewNullNode())
+	return p.newContentNode(properties, ast.NewImplicitNullNode())
 }
 
 // YAML specification: [161] ns-flow-node
@@ -98,7 +99,7 @@ func (p *parser) parseFlowJSONNode(ind *indentation, ctx context) ast.Node {
 	if !ast.ValidNode(content) {
 		return ast.NewInvalidNode()
 	}
-	return newContentNode(properties, content)
+	return p.newContentNode(properties, content)
 }
 
 // YAML specification: [158] ns-flow-content
@@ -119,6 +120,17 @@ func (p *parser) parseFlowContent(ind *indentation, ctx context) ast.Node {
 
 // YAML specification: [157] c-flow-json-content
 func (p *parser) parseFlowJSONContent(ind *indentation, ctx context) ast.Node {
+	switch p.tok.Type {
+	case token.SequenceStartType, token.MappingStartType:
+		// flow collections are parsed repeatedly while trying implicit keys,
+		// so their results are memoized
+		return p.memoized(flowCollectionMemoRule, ind, ctx, (*parser).parseFlowJSONContentOnce)
+	default:
+		return p.parseFlowJSONContentOnce(ind, ctx)
+	}
+}
+
+func (p *parser) parseFlowJSONContentOnce(ind *indentation, ctx context) ast.Node {
 	switch p.tok.Type {
 	case token.SequenceStartType:
 		return p.parseFlowSequence(ind, ctx)
@@ -138,6 +150,7 @@ func (p *parser) parseDoubleQuoted(ind *indentation, ctx context) ast.Node {
 	if p.hasErrors() || p.tok.Type != token.DoubleQuoteType {
 		return ast.NewInvalidNode()
 	}
+	start := p.tok.Start
 	p.next()
 	text := p.parseDoubleText(ind, ctx)
 	if !ast.ValidNode(text) {
@@ -148,7 +161,7 @@ func (p *parser) parseDoubleQuoted(ind *indentation, ctx context) ast.Node {
 		return ast.NewInvalidNode()
 	}
 	p.next()
-	return text
+	return p.markScalar(text, start, DoubleQuotedScalarStyle)
 }
 
 // YAML specification: [121] nb-double-text
@@ -177,7 +190,7 @@ func (p *parser) parseDoubleOneLine() ast.Node {
 	text := buf.String()
 	buf.Reset()
 	bufsPool.Put(buf)
-	return ast.NewTextNode(text, ast.WithQuotingType(ast.DoubleQuotingType))
+	return p.arena.NewTextNode(text, ast.WithQuotingType(ast.DoubleQuotingType), ast.WithEscapedText())
 }
 
 // YAML specification: [116] nb-double-multi-line
@@ -209,7 +222,7 @@ func (p *parser) parseDoubleMultiLine(ind *indentation) ast.Node {
 	text := buf.String()
 	buf.Reset()
 	bufsPool.Put(buf)
-	return ast.NewTextNode(text, ast.WithQuotingType(ast.DoubleQuotingType))
+	return p.arena.NewTextNode(text, ast.WithQuotingType(ast.DoubleQuotingType), ast.WithEscapedText())
 }
 
 // YAML specification: [115] s-double-next-line
@@ -313,6 +326,7 @@ func (p *parser) parseSingleQuoted(ind *indentation, ctx context) ast.Node {
 	if p.hasErrors() || p.tok.Type != token.SingleQuoteType {
 		return ast.NewInvalidNode()
 	}
+	start := p.tok.Start
 	p.next()
 	text := p.parseSingleText(ind, ctx)
 	if !ast.ValidNode(text) {
@@ -323,7 +337,7 @@ func (p *parser) parseSingleQuoted(ind *indentation, ctx context) ast.Node {
 		return ast.NewInvalidNode()
 	}
 	p.next()
-	return text
+	return p.markScalar(text, start, SingleQuotedScalarStyle)
 }
 
 // YAML specification: [121] nb-single-text
@@ -352,7 +366,7 @@ func (p *parser) parseSingleOneLine() ast.Node {
 	text := buf.String()
 	buf.Reset()
 	bufsPool.Put(buf)
-	return ast.NewTextNode(text, ast.WithQuotingType(ast.SingleQuotingType))
+	return p.arena.NewTextNode(text, ast.WithQuotingType(ast.SingleQuotingType), ast.WithEscapedText())
 }
 
 // YAML specification: [125] nb-single-multi-line
@@ -384,7 +398,7 @@ func (p *parser) parseSingleMultiLine(ind *indentation) ast.Node {
 	text := buf.String()
 	buf.Reset()
 	bufsPool.Put(buf)
-	return ast.NewTextNode(text, ast.WithQuotingType(ast.SingleQuotingType))
+	return p.arena.NewTextNode(text, ast.WithQuotingType(ast.SingleQuotingType), ast.WithEscapedText())
 }
 
 // YAML specification: [124] s-single-next-line
@@ -431,6 +445,7 @@ func (p *parser) parseFlowMapping(ind *indentation, ctx context) ast.Node {
 	if p.hasErrors() || p.tok.Type != token.MappingStartType {
 		return ast.NewInvalidNode()
 	}
+	start := p.tok.Start
 	p.next()
 
 	p.setCheckpoint()
@@ -446,14 +461,15 @@ func (p *parser) parseFlowMapping(ind *indentation, ctx context) ast.Node {
 		p.commit()
 	} else {
 		p.rollback()
-		content = ast.NewMappingNode(nil)
+		content = p.arena.NewMappingNode(nil)
 	}
 
 	if p.tok.Type != token.MappingEndType {
 		return ast.NewInvalidNode()
 	}
 	p.next()
-	return content
+	content.(*ast.MappingNode).SetFlow(true) // nolint: forcetypeassert
+	return p.markFlowCollection(content, start)
 }
 
 // YAML specification: [141] ns-s-flow-map-entries
@@ -465,7 +481,7 @@ func (p *parser) parseFlowMappingEntries(ind *indentation, ctx context) ast.Node
 	if !ast.ValidNode(entry) {
 		return ast.NewInvalidNode()
 	}
-	entries := []ast.Node{entry}
+	entries := p.arena.AppendNode(nil, entry)
 
 	for {
 		p.setCheckpoint()
@@ -492,12 +508,12 @@ func (p *parser) parseFlowMappingEntries(ind *indentation, ctx context) ast.Node
 		if !ast.ValidNode(entry) {
 			p.rollback()
 		} else {
-			entries = append(entries, entry)
+			entries = p.arena.AppendNode(entries, entry)
 			p.commit()
 		}
 	}
 
-	return ast.NewMappingNode(entries)
+	return p.arena.NewMappingNode(entries)
 }
 
 // YAML specification: [142] ns-flow-map-entry
@@ -529,6 +545,7 @@ func (p *parser) parseFlowSequence(ind *indentation, ctx context) ast.Node {
 	if p.hasErrors() || p.tok.Type != token.SequenceStartType {
 		return ast.NewInvalidNode()
 	}
+	start := p.tok.Start
 	p.next()
 
 	p.setCheckpoint()
@@ -544,14 +561,15 @@ func (p *parser) parseFlowSequence(ind *indentation, ctx context) ast.Node {
 		p.commit()
 	} else {
 		p.rollback()
-		content = ast.NewSequenceNode(nil)
+		content = p.arena.NewSequenceNode(nil)
 	}
 
 	if p.tok.Type != token.SequenceEndType {
 		return ast.NewInvalidNode()
 	}
 	p.next()
-	return content
+	content.(*ast.SequenceNode).SetFlow(true) // nolint: forcetypeassert
+	return p.markFlowCollection(content, start)
 }
 
 // YAML specification: [136] in-flow
@@ -576,7 +594,7 @@ func (p *parser) parseFlowSequenceEntries(ind *indentation, ctx context) ast.Nod
 	if !ast.ValidNode(entry) {
 		return ast.NewInvalidNode()
 	}
-	entries := []ast.Node{entry}
+	entries := p.arena.AppendNode(nil, entry)
 
 	for {
 		p.setCheckpoint()
@@ -605,10 +623,10 @@ func (p *parser) parseFlowSequenceEntries(ind *indentation, ctx context) ast.Nod
 			break
 		}
 		p.commit()
-		entries = append(entries, entry)
+		entries = p.arena.AppendNode(entries, entry)
 	}
 
-	return ast.NewSequenceNode(entries)
+	return p.arena.NewSequenceNode(entries)
 }
 
 // YAML specification: [139] ns-flow-seq-entry
@@ -693,19 +711,25 @@ func (p *parser) parseFlowPairJSONKeyEntry(ind *indentation, ctx context) ast.No
 	if !ast.ValidNode(value) {
 		return ast.NewInvalidNode()
 	}
-	return ast.NewMappingEntryNode(key, value)
+	return p.arena.NewMappingEntryNode(key, value)
 }
 
 // YAML specification: [155] c-s-implicit-json-key
 func (p *parser) parseImplicitJSONKey(ctx context) ast.Node {
-	if p.hasErrors() {
-		return ast.NewInvalidNode()
-	}
+	// implicit keys are parsed for every entry candidate of flow and block mappings
+	// before trying other alternatives, so their results are memoized
 	localInd := indentation{
 		value: 0,
 		mode:  strictEqualityIndentationMode,
 	}
-	node := p.parseFlowJSONNode(&localInd, ctx)
+	return p.memoized(implicitJSONKeyMemoRule, &localInd, ctx, (*parser).parseImplicitJSONKeyOnce)
+}
+
+func (p *parser) parseImplicitJSONKeyOnce(ind *indentation, ctx context) ast.Node {
+	if p.hasErrors() {
+		return ast.NewInvalidNode()
+	}
+	node := p.parseFlowJSONNode(ind, ctx)
 	if !ast.ValidNode(node) {
 		return ast.NewInvalidNode()
 	}
@@ -732,19 +756,25 @@ func (p *parser) parseFlowPairYAMLKeyEntry(ind *indentation, ctx context) ast.No
 	if !ast.ValidNode(value) {
 		return ast.NewInvalidNode()
 	}
-	return ast.NewMappingEntryNode(key, value)
+	return p.arena.NewMappingEntryNode(key, value)
 }
 
 // YAML specification: [154] ns-s-implicit-yaml-key
 func (p *parser) parseImplicitYAMLKey(ctx context) ast.Node {
-	if p.hasErrors() {
-		return ast.NewInvalidNode()
-	}
+	// implicit keys are parsed for every entry candidate of flow and block mappings
+	// before trying other alternatives, so their results are memoized
 	localInd := indentation{
 		value: 0,
 		mode:  strictEqualityIndentationMode,
 	}
-	node := p.parseFlowYAMLNode(&localInd, ctx)
+	return p.memoized(implicitYAMLKeyMemoRule, &localInd, ctx, (*parser).parseImplicitYAMLKeyOnce)
+}
+
+func (p *parser) parseImplicitYAMLKeyOnce(ind *indentation, ctx context) ast.Node {
+	if p.hasErrors() {
+		return ast.NewInvalidNode()
+	}
+	node := p.parseFlowYAMLNode(ind, ctx)
 	if !ast.ValidNode(node) {
 		return ast.NewInvalidNode()
 	}
@@ -770,7 +800,7 @@ func (p *parser) parseFlowMappingExplicitEntry(ind *indentation, ctx context) as
 		return entry
 	}
 	p.rollback()
-	return ast.NewMappingEntryNode(ast.NewNullNode(), ast.NewNullNode())
+	return p.arena.NewMappingEntryNode(ast.NewImplicitNullNode(), ast.NewImplicitNullNode())
 }
 
 // YAML specification: [144] ns-flow-map-implicit-entry
@@ -806,7 +836,7 @@ func (p *parser) parseFlowMappingEmptyKeyEntry(ind *indentation, ctx context) as
 	if !ast.ValidNode(value) {
 		return ast.NewInvalidNode()
 	}
-	return ast.NewMappingEntryNode(ast.NewNullNode(), value)
+	return p.arena.NewMappingEntryNode(ast.NewImplicitNullNode(), value)
 }
 
 // YAML specification: [148] c-ns-flow-map-json-key-entry
@@ -831,12 +861,12 @@ func (p *parser) parseFlowMappingJSONKeyEntry(ind *indentation, ctx context) ast
 	value := p.parseFlowMappingAdjacentValue(ind, ctx)
 	if !ast.ValidNode(value) {
 		p.rollback()
-		value = ast.NewNullNode()
+		value = ast.NewImplicitNullNode()
 	} else {
 		p.commit()
 	}
 
-	return ast.NewMappingEntryNode(key, value)
+	return p.arena.NewMappingEntryNode(key, value)
 }
 
 // YAML specification: [149] c-ns-flow-map-adjacent-value
@@ -859,7 +889,7 @@ func (p *parser) parseFlowMappingAdjacentValue(ind *indentation, ctx context) as
 		p.commit()
 	} else {
 		p.rollback()
-		value = ast.NewNullNode()
+		value = ast.NewImplicitNullNode()
 	}
 	return value
 }
@@ -886,11 +916,11 @@ func (p *parser) parseFlowMappingYAMLKeyEntry(ind *indentation, ctx context) ast
 	value := p.parseFlowMappingSeparateValue(ind, ctx)
 	if !ast.ValidNode(value) {
 		p.rollback()
-		value = ast.NewNullNode()
+		value = ast.NewImplicitNullNode()
 	} else {
 		p.commit()
 	}
-	return ast.NewMappingEntryNode(key, value)
+	return p.arena.NewMappingEntryNode(key, value)
 }
 
 // YAML specification: [147] c-ns-flow-map-separate-value
@@ -914,16 +944,17 @@ func (p *parser) parseFlowMappingSeparateValue(ind *indentation, ctx context) as
 	}
 	p.rollback()
 
-	return ast.NewNullNode()
+	return ast.NewImplicitNullNode()
 }
 
 // YAML specification: [131] ns-plain
 func (p *parser) parsePlain(ind *indentation, ctx context) ast.Node {
+	start := p.tok.Start
 	switch ctx {
 	case flowInContext, flowOutContext:
-		return p.parsePlainMultiLine(ind, ctx)
+		return p.markScalar(p.parsePlainMultiLine(ind, ctx), start, PlainScalarStyle)
 	case blockKeyContext, flowKeyContext:
-		return p.parsePlainOneLine(ctx)
+		return p.markScalar(p.parsePlainOneLine(ctx), start, PlainScalarStyle)
 	default:
 		return ast.NewInvalidNode()
 	}
@@ -953,7 +984,7 @@ func (p *parser) parsePlainMultiLine(ind *indentation, ctx context) ast.Node {
 	text := buf.String()
 	buf.Reset()
 	bufsPool.Put(buf)
-	return ast.NewTextNode(text)
+	return p.arena.NewTextNode(text)
 }
 
 // YAML specification: [134] s-ns-plain-next-line
@@ -1016,7 +1047,7 @@ func (p *parser) parsePlainOneLine(ctx context) ast.Node {
 		return ast.NewInvalidNode()
 	}
 	text := buf.String()
-	return ast.NewTextNode(text)
+	return p.arena.NewTextNode(text)
 }
 
 // YAML specification: [132] nb-ns-plain-in-line
@@ -74,7 +74,7 @@ func (p *parser) parseIndentWithStrictEquality(indentation int) ast.Node {
 		}
 		p.next()
 	}
-	return ast.NewIndentNode(indentation)
+	return p.arena.NewIndentNode(indentation)
 }
 
 func (p *parser) parseIndentWithLowerBound(lowerBound int) ast.Node {
@@ -94,7 +94,7 @@ func (p *parser) parseIndentWithLowerBound(lowerBound int) ast.Node {
 		p.next()
 	}
 
-	return ast.NewIndentNode(indent)
+	return p.arena.NewIndentNode(indent)
 }
 
 // YAML specification: [64] s-indent-less-than
@@ -115,14 +115,14 @@ func (p *parser) parseBorderedIndent(indentation, lowBorder int) ast.Node {
 
 	for indentation > lowBorder {
 		if p.tok.Type != token.SpaceType {
-			return ast.NewIndentNode(currentIndent)
+			return p.arena.NewIndentNode(currentIndent)
 		}
 		p.next()
 		currentIndent++
 		indentation--
 	}
 
-	return ast.NewIndentNode(currentIndent)
+	return p.arena.NewIndentNode(currentIndent)
 }
 
 // YAML specification: [70] l-empty
@@ -82,3 +82,26 @@ func (b *BalanceChecker) SetMemento(m BalanceCheckerMemento) {
 func (b *BalanceChecker) Reset() {
 	b.stack = b.stack[:0]
 }
+
+type BalanceCheckerState struct {
+	stack            []token.Type
+	cannotBeBalanced bool
+}
+
+// State returns the copy of the checker state. Unlike memento, the state can be restored
+// after any changes of checker.
+func (b *BalanceChecker) State() BalanceCheckerState {
+	var stack []token.Type
+	if len(b.stack) > 0 {
+		stack = append(stack, b.stack...)
+	}
+	return BalanceCheckerState{
+		stack:            stack,
+		cannotBeBalanced: b.cannotBeBalanced,
+	}
+}
+
+func (b *BalanceChecker) SetState(s BalanceCheckerState) {
+	b.stack = append(b.stack[:0], s.stack...)
+	b.cannotBeBalanced = s.cannotBeBalanced
+}
@@ -128,3 +128,24 @@ func TestQuotesBalance(t *testing.T) {
 		})
 	}
 }
+
+func TestBalanceChecker_State(t *testing.T) {
+	t.Parallel()
+
+	b := balancecheck.NewBalanceChecker([][2]token.Type{
+		{token.SequenceStartType, token.SequenceEndType},
+		{token.MappingStartType, token.MappingEndType},
+	})
+	b.Add(token.SequenceStartType)
+	b.Add(token.MappingStartType)
+	state := b.State()
+
+	b.Add(token.MappingEndType)
+	b.Add(token.SequenceEndType)
+	b.Add(token.SequenceStartType)
+	b.SetState(state)
+
+	if !b.Add(token.MappingEndType) || !b.Add(token.SequenceEndType) || !b.IsBalanced() {
+		t.Error("expected restored state to be balanced by closing parentheses")
+	}
+}
@@ -0,0 +1,56 @@
+package parser
+
+import (
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/schema"
+)
+
+// checkDuplicateKeys traverses AST and returns yamly.DuplicateKeyError
+// for the first mapping containing the same key several times.
+// Keys are located in source using given positions.
+func checkDuplicateKeys(n ast.Node, positions ast.Positions) error {
+	switch n := n.(type) {
+	case *ast.StreamNode:
+		return checkDuplicateKeysOf(n.Documents(), positions)
+	case *ast.ContentNode:
+		return checkDuplicateKeys(n.Content(), positions)
+	case *ast.SequenceNode:
+		return checkDuplicateKeysOf(n.Entries(), positions)
+	case *ast.MappingNode:
+		if key, first, second, found := schema.FindDuplicateKey(n); found {
+			return &yamly.DuplicateKeyError{
+				Key:    key,
+				First:  keyPosition(n, first, positions),
+				Second: keyPosition(n, second, positions),
+			}
+		}
+		return checkDuplicateKeysOf(n.Entries(), positions)
+	case *ast.MappingEntryNode:
+		if err := checkDuplicateKeys(n.Key(), positions); err != nil {
+			return err
+		}
+		return checkDuplicateKeys(n.Value(), positions)
+	}
+	return nil
+}
+
+func checkDuplicateKeysOf(nodes []ast.Node, positions ast.Positions) error {
+	for _, n := range nodes {
+		if err := checkDuplicateKeys(n, positions); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// keyPosition describes the key of i-th entry of the mapping.
+func keyPosition(m *ast.MappingNode, i int, positions ast.Positions) yamly.KeyPosition {
+	kp := yamly.KeyPosition{Entry: i + 1}
+	if entry, ok := m.Entries()[i].(*ast.MappingEntryNode); ok {
+		if start, ok := positions.Find(entry.Key()); ok {
+			kp.Line, kp.Column = start.Row, start.Column
+		}
+	}
+	return kp
+}
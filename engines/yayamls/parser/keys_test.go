@@ -0,0 +1,84 @@
+package parser_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+)
+
+func TestParseString_UniqueKeys(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		src      string
+		expected *yamly.DuplicateKeyError
+	}
+
+	tcases := []tcase{
+		{
+			name: "unique keys",
+			src:  "a: 1\nb: {c: 2, d: 3}\n",
+		},
+		{
+			name: "top level duplicate",
+			src:  "a: 1\nb: 2\na: 3\n",
+			expected: &yamly.DuplicateKeyError{
+				Key:    "a",
+				First:  yamly.KeyPosition{Entry: 1, Line: 1, Column: 1},
+				Second: yamly.KeyPosition{Entry: 3, Line: 3, Column: 1},
+			},
+		},
+		{
+			name: "nested duplicate",
+			src:  "a:\n  - b: {c: 1, c: 2}\n",
+			expected: &yamly.DuplicateKeyError{
+				Key:    "c",
+				First:  yamly.KeyPosition{Entry: 1, Line: 2, Column: 9},
+				Second: yamly.KeyPosition{Entry: 2, Line: 2, Column: 15},
+			},
+		},
+		{
+			name: "duplicate in second document",
+			src:  "a: 1\n---\nb: 1\nb: 2\n",
+			expected: &yamly.DuplicateKeyError{
+				Key:    "b",
+				First:  yamly.KeyPosition{Entry: 1, Line: 3, Column: 1},
+				Second: yamly.KeyPosition{Entry: 2, Line: 4, Column: 1},
+			},
+		},
+		{
+			name: "same keys in different mappings",
+			src:  "a: {b: 1}\nc: {b: 2}\n",
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if _, err := parser.ParseString(tc.src); err != nil {
+				t.Fatalf("unexpected error without option: %v", err)
+			}
+
+			_, err := parser.ParseString(tc.src, parser.WithUniqueKeys())
+			if tc.expected == nil {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			var dkErr *yamly.DuplicateKeyError
+			if !errors.As(err, &dkErr) {
+				t.Fatalf("expected duplicate key error, but got %v", err)
+			}
+			if !reflect.DeepEqual(tc.expected, dkErr) {
+				t.Errorf("expected error %v, but got %v", tc.expected, dkErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,120 @@
+package parser
+
+import (
+	"slices"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser/internal/balancecheck"
+)
+
+// memoRule defines grammar rule with memoized results.
+type memoRule int8
+
+const (
+	flowCollectionMemoRule memoRule = iota
+	implicitJSONKeyMemoRule
+	implicitYAMLKeyMemoRule
+	blockNodeMemoRule
+	blockIndentedMemoRule
+)
+
+// memoKey identifies the evaluation of grammar rule at the position in token stream.
+type memoKey struct {
+	pos         int
+	rule        memoRule
+	ind         indentation
+	ctx         context
+	startOfLine bool
+}
+
+// memoEntry contains the result of rule evaluation and the parser state after it.
+type memoEntry struct {
+	node        ast.Node
+	end         int
+	ind         indentation
+	startOfLine bool
+	contentRow  int
+	balance     balancecheck.BalanceCheckerState
+	// collectionProperties are the pending properties of block collection after the rule evaluation
+	collectionProperties ast.Node
+	// comments are the comments recorded during the rule evaluation
+	comments []ast.Comment
+	// events are the events emitted during the rule evaluation
+	events []Event
+}
+
+// memoized evaluates the rule using parse function only once per position, indentation and context.
+// Indentation changed by the rule is restored as well, comments and events of the rule are replayed.
+// Successful results are reused by moving to the end position of the rule, failed ones - by
+// returning invalid node immediately, since the caller rolls back anyway.
+func (p *parser) memoized(
+	rule memoRule,
+	ind *indentation,
+	ctx context,
+	parse func(*parser, *indentation, context) ast.Node,
+) ast.Node {
+	if p.hasErrors() {
+		return ast.NewInvalidNode()
+	}
+	key := memoKey{
+		pos:         p.tokSrc.Position(),
+		rule:        rule,
+		ind:         *ind,
+		ctx:         ctx,
+		startOfLine: p.startOfLine,
+	}
+	if entry, ok := p.memo[key]; ok {
+		if !ast.ValidNode(entry.node) {
+			*ind = entry.ind
+			return entry.node
+		}
+		if tok, ok := p.tokSrc.Seek(entry.end); ok {
+			*ind = entry.ind
+			p.tok, p.startOfLine, p.contentRow = tok, entry.startOfLine, entry.contentRow
+			p.balanceChecker.SetState(entry.balance)
+			p.collectionProperties = entry.collectionProperties
+			if p.comments != nil {
+				*p.comments = append(*p.comments, entry.comments...)
+			}
+			for _, ev := range entry.events {
+				p.emitEvent(ev)
+			}
+			return entry.node
+		}
+	}
+
+	commentsLen, eventsLen, cuts := p.commentsLen(), p.eventsLen(), p.cuts()
+	node := parse(p, ind, ctx)
+	// results with errors are not memoized, because errors are not restored.
+	// Results of rules containing a cut are not memoized too, since the position
+	// of the rule start is not visited after the cut.
+	if p.hasErrors() || p.cuts() != cuts {
+		return node
+	}
+	// failures detected at the first token are cheaper to evaluate again than to memoize
+	if !ast.ValidNode(node) && p.tokSrc.Position() <= key.pos+1 {
+		return node
+	}
+	if p.memo == nil {
+		p.memo = make(map[memoKey]memoEntry)
+	}
+	entry := memoEntry{
+		node: node,
+		ind:  *ind,
+	}
+	if ast.ValidNode(node) {
+		entry.end = p.tokSrc.Position()
+		entry.startOfLine = p.startOfLine
+		entry.contentRow = p.contentRow
+		if p.comments != nil {
+			entry.comments = slices.Clone((*p.comments)[commentsLen:])
+		}
+		entry.balance = p.balanceChecker.State()
+		entry.collectionProperties = p.collectionProperties
+		if p.events != nil {
+			entry.events = slices.Clone(p.events.events[eventsLen-p.events.flushed:])
+		}
+	}
+	p.memo[key] = entry
+	return node
+}
@@ -1,8 +1,20 @@
 package parser
 
+import (
+	"runtime"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/lexer"
+)
+
 type parseOptions struct {
 	tokenStreamConstructor func(string) ConfigurableTokenStream
 	omitStream             bool
+	parallelism            int
+	arena                  *ast.Arena
+	uniqueKeys             bool
+	positions              ast.Positions
+	comments               *[]ast.Comment
 }
 
 // ParseOption allows to modify parser behavior
@@ -30,6 +42,51 @@ func WithOmitStream() ParseOption {
 	})
 }
 
+// WithParallelism will make parser split the stream into documents and parse them concurrently
+// using at most given number of goroutines. If n is not positive, runtime.GOMAXPROCS(0) is used.
+// Parallel parsing is used only by ParseString and ParseBytes.
+func WithParallelism(n int) ParseOption {
+	return parseOptionsFunc(func(options *parseOptions) {
+		if n <= 0 {
+			n = runtime.GOMAXPROCS(0)
+		}
+		options.parallelism = n
+	})
+}
+
+// WithArena will make parser allocate nodes using given arena, so the resulting AST
+// is valid until the arena is released. The arena is not used by parallel parsing.
+func WithArena(arena *ast.Arena) ParseOption {
+	return parseOptionsFunc(func(options *parseOptions) {
+		options.arena = arena
+	})
+}
+
+// WithUniqueKeys will make parser return yamly.DuplicateKeyError if some mapping contains the same key several times.
+// Only scalar keys are checked. The check is performed by ParseString and ParseBytes.
+// Positions of nodes are recorded to locate the keys, so parallel parsing is not used with this option.
+func WithUniqueKeys() ParseOption {
+	return parseOptionsFunc(func(options *parseOptions) {
+		options.uniqueKeys = true
+	})
+}
+
+// WithPositions will make parser record start positions of scalars, aliases and flow collections
+// into given map. Parallel parsing is not used with this option.
+func WithPositions(positions ast.Positions) ParseOption {
+	return parseOptionsFunc(func(options *parseOptions) {
+		options.positions = positions
+	})
+}
+
+// WithComments will make parser append comments of the source to given slice in order of appearance.
+// Parallel parsing is not used with this option.
+func WithComments(comments *[]ast.Comment) ParseOption {
+	return parseOptionsFunc(func(options *parseOptions) {
+		options.comments = comments
+	})
+}
+
 func applyOptions(opts ...ParseOption) parseOptions {
 	var o parseOptions
 	for _, opt := range opts {
@@ -37,3 +94,10 @@ func applyOptions(opts ...ParseOption) parseOptions {
 	}
 	return o
 }
+
+func (o *parseOptions) newTokenStream(src string) ConfigurableTokenStream {
+	if o.tokenStreamConstructor != nil {
+		return o.tokenStreamConstructor(src)
+	}
+	return lexer.NewTokenizer(src)
+}
@@ -0,0 +1,250 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/token"
+)
+
+var errIncompleteChunk = errors.New("chunk is not parsed completely")
+
+// parseParallel splits the stream into chunks at document boundaries and parses
+// the chunks concurrently. If any chunk fails to parse, the whole stream is parsed
+// sequentially, so the result and the returned error are the same as for sequential parsing.
+func parseParallel(src string, o *parseOptions) (ast.Node, error) {
+	chunks := splitDocuments(src)
+	if len(chunks) < 2 {
+		return ParseTokenStream(o.newTokenStream(src))
+	}
+
+	workers := o.parallelism
+	if workers > len(chunks) {
+		workers = len(chunks)
+	}
+
+	var (
+		results = make([][]ast.Node, len(chunks))
+		jobs    = make(chan int)
+		failed  atomic.Bool
+		wg      sync.WaitGroup
+	)
+	wg.Add(workers)
+	for i := 0; i < workers; i++ {
+		go func() {
+			defer wg.Done()
+			for idx := range jobs {
+				if failed.Load() {
+					continue
+				}
+				docs, err := parseChunk(chunks[idx], o)
+				if err != nil {
+					failed.Store(true)
+					continue
+				}
+				results[idx] = docs
+			}
+		}()
+	}
+	for idx := range chunks {
+		jobs <- idx
+	}
+	close(jobs)
+	wg.Wait()
+
+	if failed.Load() {
+		return ParseTokenStream(o.newTokenStream(src))
+	}
+
+	var docs []ast.Node
+	for _, chunkDocs := range results {
+		docs = append(docs, chunkDocs...)
+	}
+	return ast.NewStreamNode(docs), nil
+}
+
+func parseChunk(src string, o *parseOptions) ([]ast.Node, error) {
+	p := getParser(newTokenSource(o.newTokenStream(src)))
+	defer p.release()
+	tree, err := p.Parse()
+	if err != nil {
+		return nil, err
+	}
+	if p.tok.Type != token.EOFType {
+		// parser stopped before the end of chunk, so the chunk boundaries may be wrong
+		return nil, errIncompleteChunk
+	}
+	return tree.(*ast.StreamNode).Documents(), nil // nolint: forcetypeassert
+}
+
+// splitDocuments splits the stream into chunks, each containing whole documents.
+// Splitting is done by scanning lines for document markers, so it is cheap, but conservative:
+// markers inside quoted scalars and root block scalars are not considered as boundaries.
+func splitDocuments(src string) []string {
+	s := documentSplitter{src: src}
+	return s.split()
+}
+
+type documentSplitter struct {
+	src    string
+	chunks []string
+	// chunkStart is the offset of the current chunk
+	chunkStart int
+	// hasContent shows if the current chunk contains anything besides comments and directives
+	hasContent bool
+	// nodeStartPending shows if the root node of the current document has not started yet
+	nodeStartPending bool
+	// quote is the quote character of the unterminated quoted scalar
+	quote byte
+	// blockScalarPending shows if the root block scalar header was met,
+	// but the content indentation is unknown yet
+	blockScalarPending bool
+}
+
+func (s *documentSplitter) split() []string {
+	for offset := 0; offset < len(s.src); {
+		lineEnd := strings.IndexByte(s.src[offset:], '\n')
+		next := offset + lineEnd + 1
+		if lineEnd == -1 {
+			lineEnd, next = len(s.src)-offset, len(s.src)
+		}
+		line := strings.TrimSuffix(s.src[offset:offset+lineEnd], "\r")
+		if !s.scanLine(offset, next, line) {
+			// the rest of the stream cannot be split safely
+			break
+		}
+		offset = next
+	}
+	return append(s.chunks, s.src[s.chunkStart:])
+}
+
+// scanLine processes a single line starting at given offset. The next line starts at offset next.
+func (s *documentSplitter) scanLine(offset, next int, line string) bool {
+	if s.quote != 0 {
+		i, closed := skipQuoted(line, 0, s.quote)
+		if closed {
+			s.quote = 0
+			s.scanText(line[i:])
+		}
+		return true
+	}
+
+	if s.blockScalarPending {
+		if isBlankLine(line) {
+			return true
+		}
+		if line[0] != ' ' && line[0] != '\t' {
+			// block scalar content without indentation may contain document markers
+			return false
+		}
+		s.blockScalarPending = false
+		return true
+	}
+
+	line = strings.TrimPrefix(line, "\ufeff")
+	switch {
+	case isDocumentMarker(line, "---"):
+		if s.hasContent {
+			s.chunks = append(s.chunks, s.src[s.chunkStart:offset])
+			s.chunkStart = offset
+		}
+		s.hasContent = true
+		s.nodeStartPending = true
+		s.scanNodeStart(line[3:])
+	case isDocumentMarker(line, "..."):
+		s.chunks = append(s.chunks, s.src[s.chunkStart:next])
+		s.chunkStart = next
+		s.hasContent = false
+		s.nodeStartPending = false
+	case isBlankLine(line) || isCommentLine(line):
+	case !s.hasContent && line[0] == '%':
+		// directive before document
+	case s.nodeStartPending || !s.hasContent:
+		s.hasContent = true
+		s.nodeStartPending = true
+		s.scanNodeStart(line)
+	default:
+		s.scanText(line)
+	}
+	return true
+}
+
+// scanNodeStart checks if the text starts the root node of document with block scalar.
+func (s *documentSplitter) scanNodeStart(text string) {
+	text = strings.TrimLeft(text, " \t")
+	// skipping node properties
+	for len(text) > 0 && (text[0] == '!' || text[0] == '&') {
+		end := strings.IndexAny(text, " \t")
+		if end == -1 {
+			return
+		}
+		text = strings.TrimLeft(text[end:], " \t")
+	}
+	if text == "" || text[0] == '#' {
+		return
+	}
+	s.nodeStartPending = false
+	if text[0] == '|' || text[0] == '>' {
+		s.blockScalarPending = true
+		return
+	}
+	s.scanText(text)
+}
+
+// scanText looks for unterminated quoted scalars in the text.
+func (s *documentSplitter) scanText(text string) {
+	for i := 0; i < len(text); i++ {
+		switch c := text[i]; c {
+		case '#':
+			if i == 0 || text[i-1] == ' ' || text[i-1] == '\t' {
+				return
+			}
+		case '\'', '"':
+			if i > 0 && !strings.ContainsRune(" \t[{,:-?", rune(text[i-1])) {
+				continue
+			}
+			end, closed := skipQuoted(text, i+1, c)
+			if !closed {
+				s.quote = c
+				return
+			}
+			i = end - 1
+		}
+	}
+}
+
+// skipQuoted returns the offset after the closing quote and true if the quoted scalar
+// starting at offset i is terminated in the text.
+func skipQuoted(text string, i int, quote byte) (int, bool) {
+	for ; i < len(text); i++ {
+		switch text[i] {
+		case '\\':
+			if quote == '"' {
+				i++
+			}
+		case quote:
+			if quote == '\'' && i+1 < len(text) && text[i+1] == '\'' {
+				i++
+				continue
+			}
+			return i + 1, true
+		}
+	}
+	return len(text), false
+}
+
+func isDocumentMarker(line, marker string) bool {
+	return strings.HasPrefix(line, marker) && (len(line) == len(marker) ||
+		line[len(marker)] == ' ' || line[len(marker)] == '\t')
+}
+
+func isBlankLine(line string) bool {
+	return strings.TrimLeft(line, " \t") == ""
+}
+
+func isCommentLine(line string) bool {
+	return strings.HasPrefix(strings.TrimLeft(line, " \t"), "#")
+}
@@ -0,0 +1,65 @@
+package parser
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSplitDocuments(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		src      string
+		expected []string
+	}
+
+	tcases := []tcase{
+		{
+			name:     "single bare document",
+			src:      "a: 1\n",
+			expected: []string{"a: 1\n"},
+		},
+		{
+			name:     "explicit documents",
+			src:      "---\na: 1\n--- b\n---\n",
+			expected: []string{"---\na: 1\n", "--- b\n", "---\n"},
+		},
+		{
+			name:     "directives belong to next document",
+			src:      "a\n...\n%YAML 1.2\n---\nb\n",
+			expected: []string{"a\n...\n", "%YAML 1.2\n---\nb\n"},
+		},
+		{
+			name:     "leading comments",
+			src:      "# comment\n---\na\n",
+			expected: []string{"# comment\n---\na\n"},
+		},
+		{
+			name:     "quoted scalar",
+			src:      "a: \"b\n--- c\"\n---\nd: 'e''\n...'\n",
+			expected: []string{"a: \"b\n--- c\"\n", "---\nd: 'e''\n...'\n"},
+		},
+		{
+			name:     "indented root block scalar",
+			src:      "--- |\n  a\n--- >\n  b\n",
+			expected: []string{"--- |\n  a\n", "--- >\n  b\n"},
+		},
+		{
+			name:     "root block scalar without indentation",
+			src:      "--- !!str |\na\n---\nb\n",
+			expected: []string{"--- !!str |\na\n---\nb\n"},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			if result := splitDocuments(tc.src); !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %q, but got %q", tc.expected, result)
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+package parser_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astcmp"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+)
+
+func TestParseString_Parallel(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name string
+		src  string
+	}
+
+	tcases := []tcase{
+		{
+			name: "empty stream",
+			src:  "",
+		},
+		{
+			name: "single document",
+			src:  "a: 1\nb: [x, y]\n",
+		},
+		{
+			name: "explicit documents",
+			src:  "---\na: 1\n---\n- b\n- c\n--- d\n",
+		},
+		{
+			name: "bare and ended documents",
+			src:  "first\n...\nsecond\n...\n---\nthird\n",
+		},
+		{
+			name: "directives and comments",
+			src:  "# header\n%YAML 1.2\n---\na: 1\n...\n%YAML 1.2\n# comment\n---\nb: 2\n",
+		},
+		{
+			name: "empty documents",
+			src:  "---\n---\n# comment\n---\n",
+		},
+		{
+			name: "block scalars",
+			src:  "--- |\n  text\n---\nlit: |\n  a\n  b\n--- >\n  folded\n",
+		},
+		{
+			name: "root block scalar without indentation",
+			src:  "--- |\nfoo\n--- bar\n",
+		},
+		{
+			name: "multiline quoted scalars",
+			src:  "\"a\n...\nb\"\n---\nkey: 'c\n--- d'\n---\ne: 'it''s\n--- ok'\n",
+		},
+		{
+			name: "anchors in different documents",
+			src:  "--- &a x\n--- *a\n--- &a [y]\n--- *a\n",
+		},
+		{
+			name: "CRLF line breaks",
+			src:  "---\r\na: 1\r\n---\r\nb: 2\r\n",
+		},
+	}
+
+	var sb strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&sb, "---\nkind: Item\nmetadata:\n  name: item-%d\n  labels: {index: '%d'}\nspec:\n  script: |\n    echo %d\n", i, i, i)
+	}
+	tcases = append(tcases, tcase{name: "many documents", src: sb.String()})
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			expected, expectedErr := parser.ParseString(tc.src)
+			result, err := parser.ParseString(tc.src, parser.WithParallelism(4))
+			if (err == nil) != (expectedErr == nil) {
+				t.Fatalf("expected error %v, but got %v", expectedErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if !astcmp.NewComparator().Equal(expected, result) {
+				t.Errorf("parallel parsing result differs from sequential parsing result")
+			}
+		})
+	}
+}
+
+func TestParseString_ParallelError(t *testing.T) {
+	t.Parallel()
+
+	src := "---\na: 1\n---\nb: 2\n---\nc: [d, e\n"
+	_, expectedErr := parser.ParseString(src)
+	if expectedErr == nil {
+		t.Fatal("expected error for sequential parsing")
+	}
+	_, err := parser.ParseString(src, parser.WithParallelism(0))
+	if err == nil || err.Error() != expectedErr.Error() {
+		t.Errorf("expected error %v, but got %v", expectedErr, err)
+	}
+}
+
+func BenchmarkParseString_Parallel(b *testing.B) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "---\nkind: Item\nmetadata:\n  name: item-%d\nspec:\n  values: [1, 2, 3]\n", i)
+	}
+	src := sb.String()
+
+	for _, parallelism := range []int{1, 4} {
+		parallelism := parallelism
+		b.Run(fmt.Sprintf("parallelism=%d", parallelism), func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := parser.ParseString(src, parser.WithParallelism(parallelism)); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
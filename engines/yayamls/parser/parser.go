@@ -7,7 +7,6 @@ import (
 	"sync"
 
 	"github.com/KSpaceer/yamly/engines/yayamls/ast"
-	"github.com/KSpaceer/yamly/engines/yayamls/lexer"
 	"github.com/KSpaceer/yamly/engines/yayamls/parser/internal/balancecheck"
 	"github.com/KSpaceer/yamly/engines/yayamls/parser/internal/deadend"
 	"github.com/KSpaceer/yamly/engines/yayamls/pkg/strslice"
@@ -51,11 +50,36 @@ type parser struct {
 	errors         []error
 	balanceChecker balancecheck.BalanceChecker
 	deadEndFinder  deadend.Finder
+	// nodeInfos contains additional information about parsed scalars, aliases and flow collections.
+	// It is filled only when parser is used to emit events.
+	nodeInfos map[ast.Node]nodeInfo
+	// events contains emitted events if parser is used to emit events
+	events *eventJournal
+	// positions is filled with start positions of scalars, aliases and flow collections if not nil.
+	positions ast.Positions
+	// comments is filled with comments of the source if not nil.
+	comments *[]ast.Comment
+	// memo contains the results of rules evaluation for the current document
+	memo map[memoKey]memoEntry
+	// arena is used to allocate nodes. Nil arena allocates nodes on heap.
+	arena *ast.Arena
+	// firstDocumentPending shows if the first document of the stream (possibly bare) is not parsed yet
+	firstDocumentPending bool
 }
 
 type state struct {
 	startOfLine         bool
 	balanceCheckMemento balancecheck.BalanceCheckerMemento
+	// explicitDocument shows if the current document starts with directives end marker ("---")
+	explicitDocument bool
+	// contentRow is the row of the last consumed token, which is neither whitespace nor line break
+	contentRow int
+	// commentsLen is the amount of recorded comments at the moment of setting checkpoint
+	commentsLen int
+	// eventsLen is the amount of emitted events at the moment of setting checkpoint
+	eventsLen int
+	// collectionProperties are the properties of block collection, which start is not emitted yet
+	collectionProperties ast.Node
 }
 
 var parserPool = sync.Pool{}
@@ -84,7 +108,14 @@ func newParser(tokSrc *tokenSource) *parser {
 
 // ParseTokenStream builds an YAML AST using tokens from given token stream.
 func ParseTokenStream(cts ConfigurableTokenStream) (ast.Node, error) {
+	return parseTokenStream(cts, &parseOptions{})
+}
+
+func parseTokenStream(cts ConfigurableTokenStream, o *parseOptions) (ast.Node, error) {
 	p := newParser(newTokenSource(cts))
+	p.arena = o.arena
+	p.positions = o.positions
+	p.comments = o.comments
 	defer p.tokSrc.release()
 	return p.Parse()
 }
@@ -100,16 +131,27 @@ func ParseTokens(tokens []token.Token) (ast.Node, error) {
 // ParseString builds an YAML AST from parsing provided source string.
 func ParseString(src string, opts ...ParseOption) (ast.Node, error) {
 	o := applyOptions(opts...)
-	var cts ConfigurableTokenStream
-	if o.tokenStreamConstructor != nil {
-		cts = o.tokenStreamConstructor(src)
+	var (
+		tree ast.Node
+		err  error
+	)
+	if o.uniqueKeys && o.positions == nil {
+		// positions locate duplicate keys in source
+		o.positions = make(ast.Positions)
+	}
+	if o.parallelism > 1 && o.positions == nil && o.comments == nil {
+		tree, err = parseParallel(src, &o)
 	} else {
-		cts = lexer.NewTokenizer(src)
+		tree, err = parseTokenStream(o.newTokenStream(src), &o)
 	}
-	tree, err := ParseTokenStream(cts)
 	if err != nil {
 		return nil, err
 	}
+	if o.uniqueKeys {
+		if err = checkDuplicateKeys(tree, o.positions); err != nil {
+			return nil, err
+		}
+	}
 	if o.omitStream && tree.Type() == ast.StreamType {
 		stream := tree.(*ast.StreamNode) // nolint: forcetypeassert
 		if len(stream.Documents()) == 1 {
@@ -141,6 +183,9 @@ func (p *parser) Parse() (ast.Node, error) {
 
 func (p *parser) next() {
 	p.startOfLine = isStartOfLine(p.startOfLine, p.tok)
+	if isContentToken(p.tok) {
+		p.contentRow = p.tok.Start.Row
+	}
 	p.tok = p.tokSrc.Next()
 	switch p.tok.Type {
 	case token.EOFType:
@@ -171,6 +216,11 @@ func isStartOfLine(startOfLine bool, tok token.Token) bool {
 	}
 }
 
+// isContentToken checks if the token is a part of node or indicator, i.e. neither whitespace nor line break.
+func isContentToken(tok token.Token) bool {
+	return token.IsNonBreak(tok) && !token.IsWhiteSpace(tok) && tok.Type != token.UnknownType
+}
+
 func (p *parser) appendError(err error) {
 	p.errors = append(p.errors, err)
 }
@@ -191,14 +241,26 @@ func (p *parser) release() {
 	p.deadEndFinder.Reset()
 	p.errors = p.errors[:0]
 	p.state = state{startOfLine: true}
+	p.nodeInfos = nil
+	p.events = nil
+	p.positions = nil
+	p.comments = nil
+	clear(p.memo)
+	p.arena = nil
+	p.firstDocumentPending = false
 	parserPool.Put(p)
 }
 
 func (p *parser) setCheckpoint() {
 	p.tokSrc.SetCheckpoint()
 	p.savedStates = append(p.savedStates, state{
-		startOfLine:         p.startOfLine,
-		balanceCheckMemento: p.balanceChecker.Memento(),
+		startOfLine:          p.startOfLine,
+		balanceCheckMemento:  p.balanceChecker.Memento(),
+		explicitDocument:     p.explicitDocument,
+		contentRow:           p.contentRow,
+		commentsLen:          p.commentsLen(),
+		eventsLen:            p.eventsLen(),
+		collectionProperties: p.collectionProperties,
 	})
 }
 
@@ -206,6 +268,9 @@ func (p *parser) commit() {
 	p.tokSrc.Commit()
 	if savedStatesLen := len(p.savedStates); savedStatesLen > 0 {
 		p.savedStates = p.savedStates[:savedStatesLen-1]
+		if p.events != nil {
+			p.events.cutDepth = min(p.events.cutDepth, len(p.savedStates))
+		}
 	}
 }
 
@@ -215,12 +280,42 @@ func (p *parser) rollback() {
 		p.state = p.savedStates[savedStatesLen-1]
 		p.savedStates = p.savedStates[:savedStatesLen-1]
 		p.balanceChecker.SetMemento(p.state.balanceCheckMemento)
+		if p.comments != nil {
+			// comments of the rolled back rules are not the part of the source
+			*p.comments = (*p.comments)[:p.state.commentsLen]
+		}
+		if p.events != nil {
+			p.rollbackEvents()
+		}
+	}
+}
+
+// rollbackEvents removes events of the rolled back rules.
+func (p *parser) rollbackEvents() {
+	if len(p.savedStates) < p.events.cutDepth {
+		// the checkpoint is set before the cut, so the tokens after it are discarded
+		p.events.cutDepth = len(p.savedStates)
+		if !p.hasErrors() {
+			p.appendError(BacktrackError{Pos: p.tok.Start})
+		}
+		return
+	}
+	p.dropEvents(p.state.eventsLen)
+}
+
+// newContentNode wraps the content with properties. If properties contain neither tag nor anchor,
+// the content is returned as is.
+func (p *parser) newContentNode(properties, content ast.Node) ast.Node {
+	if !hasProperties(properties) {
+		return content
 	}
+	return p.arena.NewContentNode(properties, content)
 }
 
-func newContentNode(properties, content ast.Node) ast.Node {
-	if ast.ValidNode(properties) {
-		content = ast.NewContentNode(properties, content)
+func hasProperties(n ast.Node) bool {
+	properties, ok := n.(*ast.PropertiesNode)
+	if !ok {
+		return ast.ValidNode(n)
 	}
-	return content
+	return ast.ValidNode(properties.Tag()) || ast.ValidNode(properties.Anchor())
 }
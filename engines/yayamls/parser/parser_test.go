@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"errors"
 	"os"
 	"strings"
 	"testing"
@@ -648,7 +649,7 @@ func TestParseTokens(t *testing.T) {
 						),
 						ast.NewContentNode(
 							ast.NewPropertiesNode(
-								ast.NewTagNode("primary"),
+								ast.NewTagNode("primary", ast.WithTagHandle("!")),
 								nil,
 							),
 							ast.NewTextNode("\nfolded"),
@@ -1769,7 +1770,7 @@ func TestParseTokens(t *testing.T) {
 							[]ast.Node{
 								ast.NewContentNode(
 									ast.NewPropertiesNode(
-										ast.NewTagNode("!baz"),
+										ast.NewTagNode("!baz", ast.WithVerbatimTag()),
 										ast.NewInvalidNode(),
 									),
 									ast.NewTextNode("entity"),
@@ -2706,6 +2707,165 @@ func TestParseStringWithDefaultTokenStream(t *testing.T) {
 	}
 }
 
+func TestParseStringInvalidDocument(t *testing.T) {
+	t.Parallel()
+
+	tcases := []struct {
+		name string
+		src  string
+	}{
+		{
+			name: "unclosed double quoted scalar",
+			src:  "key: \"value\n",
+		},
+		{
+			name: "wrong indentation",
+			src:  "a: b\n  c: d\n",
+		},
+		{
+			name: "content after document",
+			src:  "a: b\n- c\n",
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := parser.ParseString(tc.src)
+			var unexpectedTokenErr parser.UnexpectedTokenError
+			if !errors.As(err, &unexpectedTokenErr) {
+				t.Errorf("expected unexpected token error, but got %v", err)
+			}
+		})
+	}
+}
+
+// TestParseStringPathological checks inputs, which make the parser try many alternatives of the same
+// nested nodes. The amount of allocations must grow linearly with the depth of nesting, i.e. every
+// nested node is parsed once. The test is not parallel, since allocations of other tests affect measurements.
+func TestParseStringPathological(t *testing.T) {
+	const (
+		depth = 50
+		// the amount of allocations for the depth multiplied by the factor must not grow faster than linearly
+		depthFactor         = 4
+		maxAllocationsRatio = depthFactor + 1
+	)
+
+	nestedSequence := func(depth int, inner ast.Node) ast.Node {
+		for i := 0; i < depth; i++ {
+			inner = ast.NewSequenceNode([]ast.Node{inner})
+		}
+		return inner
+	}
+	nestedMapping := func(depth int, inner ast.Node, newEntry func(inner ast.Node) ast.Node) ast.Node {
+		for i := 0; i < depth; i++ {
+			inner = ast.NewMappingNode([]ast.Node{newEntry(inner)})
+		}
+		return inner
+	}
+
+	type tcase struct {
+		name        string
+		src         func(depth int) string
+		expectedAST func(depth int) ast.Node
+	}
+
+	tcases := []tcase{
+		{
+			name: "flow sequences",
+			src: func(depth int) string {
+				return strings.Repeat("[", depth) + "x" + strings.Repeat("]", depth)
+			},
+			expectedAST: func(depth int) ast.Node {
+				return nestedSequence(depth, ast.NewTextNode("x"))
+			},
+		},
+		{
+			name: "flow mappings",
+			src: func(depth int) string {
+				return strings.Repeat("{a: ", depth) + "x" + strings.Repeat("}", depth)
+			},
+			expectedAST: func(depth int) ast.Node {
+				return nestedMapping(depth, ast.NewTextNode("x"), func(inner ast.Node) ast.Node {
+					return ast.NewMappingEntryNode(ast.NewTextNode("a"), inner)
+				})
+			},
+		},
+		{
+			name: "flow sequence as implicit key",
+			src: func(depth int) string {
+				return strings.Repeat("[", depth) + "x" + strings.Repeat("]", depth) + ": y"
+			},
+			expectedAST: func(depth int) ast.Node {
+				return ast.NewMappingNode([]ast.Node{
+					ast.NewMappingEntryNode(nestedSequence(depth, ast.NewTextNode("x")), ast.NewTextNode("y")),
+				})
+			},
+		},
+		{
+			name: "flow mappings as implicit keys",
+			src: func(depth int) string {
+				return strings.Repeat("{", depth) + "x" + strings.Repeat("}: y", depth)
+			},
+			expectedAST: func(depth int) ast.Node {
+				inner := ast.NewMappingNode([]ast.Node{
+					ast.NewMappingEntryNode(ast.NewTextNode("x"), ast.NewNullNode()),
+				})
+				return nestedMapping(depth, inner, func(inner ast.Node) ast.Node {
+					return ast.NewMappingEntryNode(inner, ast.NewTextNode("y"))
+				})
+			},
+		},
+		{
+			name: "compact block sequences",
+			src: func(depth int) string {
+				return strings.Repeat("- ", depth) + "x\n"
+			},
+			expectedAST: func(depth int) ast.Node {
+				return nestedSequence(depth, ast.NewTextNode("x"))
+			},
+		},
+		{
+			name: "explicit keys",
+			src: func(depth int) string {
+				return strings.Repeat("? ", depth) + "x\n"
+			},
+			expectedAST: func(depth int) ast.Node {
+				return nestedMapping(depth, ast.NewTextNode("x"), func(inner ast.Node) ast.Node {
+					return ast.NewMappingEntryNode(inner, ast.NewNullNode())
+				})
+			},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			var allocations [2]float64
+			for i, depth := range [...]int{depth, depth * depthFactor} {
+				src := tc.src(depth)
+				var (
+					result ast.Node
+					err    error
+				)
+				allocations[i] = testing.AllocsPerRun(1, func() {
+					result, err = parser.ParseString(src)
+				})
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+				compareAST(t, ast.NewStreamNode([]ast.Node{tc.expectedAST(depth)}), result)
+			}
+			if ratio := allocations[1] / allocations[0]; ratio > maxAllocationsRatio {
+				t.Errorf("allocations grow faster than depth: %v allocations for depth %d and %v for depth %d",
+					allocations[0], depth, allocations[1], depth*depthFactor)
+			}
+		})
+	}
+}
+
 func FuzzParseString(f *testing.F) {
 	seeds := []string{
 		"key:key",
@@ -95,7 +95,7 @@ func (p *parser) parseProperties(ind *indentation, ctx context) ast.Node {
 		p.rollback()
 	}
 
-	return ast.NewPropertiesNode(tag, anchor)
+	return p.arena.NewPropertiesNode(tag, anchor)
 }
 
 // YAML specification: [104] c-ns-alias-node
@@ -103,11 +103,12 @@ func (p *parser) parseAliasNode() ast.Node {
 	if p.hasErrors() || p.tok.Type != token.AliasType {
 		return ast.NewInvalidNode()
 	}
+	start := p.tok.Start
 	p.next()
 	if p.tok.Type == token.StringType && p.tok.ConformsCharSet(yamlchar.AnchorCharSetType) {
 		text := p.tok.Origin
 		p.next()
-		return ast.NewAliasNode(text)
+		return p.markScalar(p.arena.NewAliasNode(text), start, UnknownScalarStyle)
 	}
 	return ast.NewInvalidNode()
 }
@@ -120,7 +121,7 @@ func (p *parser) parseAnchorProperty() ast.Node {
 	p.setCheckpoint()
 	p.next()
 	if p.tok.Type == token.StringType && p.tok.ConformsCharSet(yamlchar.AnchorCharSetType) {
-		anchor := ast.NewAnchorNode(p.tok.Origin)
+		anchor := p.arena.NewAnchorNode(p.tok.Origin)
 		p.next()
 		p.commit()
 		return anchor
@@ -138,12 +139,12 @@ func (p *parser) parseTagProperty() ast.Node {
 	p.setCheckpoint()
 	// shorthand tag
 	// YAML specification: [99] c-ns-shorthand-tag
-	if ast.ValidNode(p.parseTagHandle()) && p.tok.Type == token.StringType &&
+	if handle, ok := p.parseTagHandle().(*ast.TagNode); ok && p.tok.Type == token.StringType &&
 		p.tok.ConformsCharSet(yamlchar.TagCharSetType) {
 		p.commit()
 		text := p.tok.Origin
 		p.next()
-		return ast.NewTagNode(text)
+		return p.arena.NewTagNode(text, ast.WithTagHandle(handle.Handle()))
 	}
 	p.rollback()
 
@@ -167,7 +168,7 @@ func (p *parser) parseTagProperty() ast.Node {
 		if len(cutToken.Origin) > 0 && cutToken.ConformsCharSet(yamlchar.URICharSetType) &&
 			p.tok.Origin[len(p.tok.Origin)-1] == '>' {
 			p.next()
-			return ast.NewTagNode(cutToken.Origin)
+			return p.arena.NewTagNode(cutToken.Origin, ast.WithVerbatimTag())
 		}
 	}
 
@@ -182,5 +183,5 @@ func (p *parser) parseTagProperty() ast.Node {
 
 	// non specific tag
 	// YAML specification: [100] c-non-specific-tag
-	return ast.NewTagNode("")
+	return p.arena.NewTagNode("", ast.WithTagHandle("!"))
 }
@@ -47,7 +47,7 @@ func newTokenSource(cts ConfigurableTokenStream) *tokenSource {
 
 func (ts *tokenSource) release() {
 	ts.CheckpointingAccessor.Reset()
-	accessorsPool.Put(&ts.CheckpointingAccessor)
+	accessorsPool.Put(ts.CheckpointingAccessor)
 }
 
 type simpleTokenStream struct {
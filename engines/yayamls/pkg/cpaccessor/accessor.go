@@ -13,6 +13,10 @@ type CheckpointingAccessor[T any] struct {
 	saved            T
 	bufIndicator     int
 	checkpointsStack []int
+	// bufStart is the position of the first buffer element in the stream
+	bufStart int
+	// consumed is the number of elements got from the stream
+	consumed int
 }
 
 const (
@@ -40,6 +44,8 @@ func (a *CheckpointingAccessor[T]) Reset() {
 	a.buf = a.buf[:0]
 	a.bufIndicator = withoutBuffer
 	a.checkpointsStack = a.checkpointsStack[:0]
+	a.bufStart = 0
+	a.consumed = 0
 }
 
 // Next moves accessor to the next element in the stream.
@@ -50,11 +56,15 @@ func (a *CheckpointingAccessor[T]) Next() T {
 		val = a.stream.Next()
 		if len(a.checkpointsStack) > 0 {
 			// if there any checkpoint, store element in buffer
+			if len(a.buf) == 0 {
+				a.bufStart = a.consumed
+			}
 			a.buf = append(a.buf, val)
 		} else {
 			// otherwise, remember it for the first checkpoint (to be able to rollback to it).
 			a.saved = val
 		}
+		a.consumed++
 	} else {
 		// get element from the buffer
 		val = a.buf[a.bufIndicator]
@@ -70,6 +80,38 @@ func (a *CheckpointingAccessor[T]) Next() T {
 	return val
 }
 
+// Position returns the number of elements accessed before the current position in the stream.
+func (a *CheckpointingAccessor[T]) Position() int {
+	if a.bufIndicator == withoutBuffer {
+		return a.consumed
+	}
+	return a.bufStart + a.bufIndicator
+}
+
+// Seek moves accessor forward to the given position if all elements before it are buffered.
+// Returned value is the last element before the position. If the position cannot be reached
+// using buffer, accessor is not moved and false is returned.
+func (a *CheckpointingAccessor[T]) Seek(pos int) (T, bool) {
+	var val T
+	if a.bufIndicator == withoutBuffer {
+		return val, false
+	}
+	idx := pos - a.bufStart
+	if idx <= a.bufIndicator || idx > len(a.buf) {
+		return val, false
+	}
+	val = a.buf[idx-1]
+	a.bufIndicator = idx
+	if a.bufIndicator == len(a.buf) {
+		if len(a.checkpointsStack) == 0 {
+			a.saved = val
+			a.buf = a.buf[:0]
+		}
+		a.bufIndicator = withoutBuffer
+	}
+	return val, true
+}
+
 // SetCheckpoint sets checkpoint at current position in the stream.
 func (a *CheckpointingAccessor[T]) SetCheckpoint() {
 	if a.bufIndicator == withoutBuffer {
@@ -115,6 +157,31 @@ func (a *CheckpointingAccessor[T]) Rollback() T {
 	}
 }
 
+// Cut discards buffered elements before the current position in the stream, so memory used by them can be freed.
+// Checkpoints set before the cut remain in the stack, but can't be used to return
+// to the elements before the cut: Rollback to such checkpoint moves accessor to the position of the cut.
+func (a *CheckpointingAccessor[T]) Cut() {
+	if a.bufIndicator == withoutBuffer {
+		if len(a.buf) > 0 {
+			a.saved = a.buf[len(a.buf)-1]
+		}
+		clear(a.buf)
+		a.buf = a.buf[:0]
+	} else {
+		if a.bufIndicator > 0 {
+			a.saved = a.buf[a.bufIndicator-1]
+		}
+		n := copy(a.buf, a.buf[a.bufIndicator:])
+		clear(a.buf[n:])
+		a.buf = a.buf[:n]
+		a.bufStart += a.bufIndicator
+		a.bufIndicator = 0
+	}
+	for i := range a.checkpointsStack {
+		a.checkpointsStack[i] = withoutBuffer
+	}
+}
+
 // Commit marks the latest checkpoint as successful and removes it.
 // Further Rollback calls will not return accessor to this checkpoint.
 func (a *CheckpointingAccessor[T]) Commit() {
@@ -494,3 +494,94 @@ func TestCommitWithNestedRollback(t *testing.T) {
 		t.Fatalf("expected %d but got %d", 3, value)
 	}
 }
+
+func TestSeek(t *testing.T) {
+	t.Parallel()
+
+	stream := &testStream[int]{
+		values: []int{1, 2, 3, 4, 5},
+	}
+
+	accessor := cpaccessor.NewCheckpointingAccessor[int]()
+	accessor.SetStream(stream)
+
+	accessor.Next()
+	accessor.SetCheckpoint()
+	accessor.Next()
+	accessor.Next()
+	accessor.Next()
+	if pos := accessor.Position(); pos != 4 {
+		t.Fatalf("expected position %d but got %d", 4, pos)
+	}
+	if _, ok := accessor.Seek(5); ok {
+		t.Fatal("expected seek to unbuffered position to fail")
+	}
+	accessor.Rollback()
+	if pos := accessor.Position(); pos != 1 {
+		t.Fatalf("expected position %d after rollback but got %d", 1, pos)
+	}
+
+	value, ok := accessor.Seek(3)
+	if !ok || value != 3 {
+		t.Fatalf("expected seek to return %d but got %d (%t)", 3, value, ok)
+	}
+	if pos := accessor.Position(); pos != 3 {
+		t.Fatalf("expected position %d after seek but got %d", 3, pos)
+	}
+	if value = accessor.Next(); value != 4 {
+		t.Fatalf("expected %d but got %d", 4, value)
+	}
+	if value = accessor.Next(); value != 5 {
+		t.Fatalf("expected %d but got %d", 5, value)
+	}
+	if pos := accessor.Position(); pos != 5 {
+		t.Fatalf("expected position %d but got %d", 5, pos)
+	}
+}
+
+func TestCut(t *testing.T) {
+	t.Parallel()
+
+	stream := &testStream[int]{
+		values: []int{1, 2, 3, 4, 5, 6},
+	}
+
+	accessor := cpaccessor.NewCheckpointingAccessor[int]()
+	accessor.SetStream(stream)
+
+	accessor.SetCheckpoint()
+	accessor.Next()
+	accessor.SetCheckpoint()
+	accessor.Next()
+	accessor.Next()
+	accessor.Rollback()
+	// cutting inside buffer keeps elements after current position
+	accessor.Cut()
+	if pos := accessor.Position(); pos != 1 {
+		t.Fatalf("expected position %d after cut but got %d", 1, pos)
+	}
+	if value := accessor.Next(); value != 2 {
+		t.Fatalf("expected %d but got %d", 2, value)
+	}
+	accessor.SetCheckpoint()
+	if value := accessor.Next(); value != 3 {
+		t.Fatalf("expected %d but got %d", 3, value)
+	}
+	accessor.Next()
+	accessor.Cut()
+
+	// rollback to checkpoint set before the cut returns to the position of the cut
+	if value := accessor.Rollback(); value != 4 {
+		t.Fatalf("expected %d before the cut but got %d", 4, value)
+	}
+	accessor.Commit()
+	if value := accessor.Next(); value != 5 {
+		t.Fatalf("expected %d but got %d", 5, value)
+	}
+	if pos := accessor.Position(); pos != 5 {
+		t.Fatalf("expected position %d but got %d", 5, pos)
+	}
+	if value := accessor.Next(); value != 6 {
+		t.Fatalf("expected %d but got %d", 6, value)
+	}
+}
@@ -0,0 +1,42 @@
+// Package slab contains an allocator of values of the same type placed in reusable chunks.
+package slab
+
+const (
+	minChunkSize = 16
+	maxChunkSize = 1024
+)
+
+// Slab allocates values of type T in chunks. Chunks are never reallocated,
+// so pointers to allocated values remain valid until Reset.
+// Zero Slab is ready to use.
+type Slab[T any] struct {
+	chunks [][]T
+	// chunk is the index of the current chunk
+	chunk int
+}
+
+// Alloc returns a pointer to zero value of type T.
+func (s *Slab[T]) Alloc() *T {
+	for s.chunk < len(s.chunks) {
+		if c := s.chunks[s.chunk]; len(c) < cap(c) {
+			s.chunks[s.chunk] = c[:len(c)+1]
+			return &s.chunks[s.chunk][len(c)]
+		}
+		s.chunk++
+	}
+	size := minChunkSize
+	if n := len(s.chunks); n > 0 {
+		size = min(2*cap(s.chunks[n-1]), maxChunkSize)
+	}
+	s.chunks = append(s.chunks, make([]T, 1, size))
+	return &s.chunks[s.chunk][0]
+}
+
+// Reset zeroes allocated values, keeping the chunks for reuse.
+func (s *Slab[T]) Reset() {
+	for i := 0; i <= s.chunk && i < len(s.chunks); i++ {
+		clear(s.chunks[i])
+		s.chunks[i] = s.chunks[i][:0]
+	}
+	s.chunk = 0
+}
@@ -0,0 +1,44 @@
+package yayamls
+
+import (
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/engines/yayamls/decode"
+	"github.com/KSpaceer/yamly/engines/yayamls/encode"
+)
+
+// EngineName is the name of the engine used with yamly.WithEngine.
+const EngineName = "yayamls"
+
+func init() {
+	yamly.RegisterEngine(EngineName, engine{})
+}
+
+// engine implements yamly.Engine using pooled readers, builders and writers.
+type engine struct{}
+
+func (engine) Unmarshal(data []byte, v yamly.UnmarshalerYamly, opts yamly.DecodeOptions) error {
+	var readerOpts []decode.ReaderOption
+	if opts.UniqueKeys {
+		readerOpts = append(readerOpts, decode.WithUniqueKeys())
+	}
+	in, err := decode.NewASTReaderFromBytes(data, readerOpts...)
+	if err != nil {
+		return err
+	}
+	defer in.Release()
+	v.UnmarshalYamly(in)
+	return in.Error()
+}
+
+func (engine) Marshal(v yamly.MarshalerYamly) ([]byte, error) {
+	out := encode.AcquireASTBuilder()
+	defer out.Release()
+	v.MarshalYamly(out)
+	tree, err := out.Result()
+	if err != nil {
+		return nil, err
+	}
+	w := encode.AcquireASTWriter()
+	defer w.Release()
+	return w.WriteBytes(tree)
+}
@@ -0,0 +1,55 @@
+package schema
+
+import "github.com/KSpaceer/yamly/engines/yayamls/ast"
+
+// FindDuplicateKey looks for the first key appearing several times in given mapping
+// and returns the key with zero-based indices of its entries.
+// Scalar keys are compared by their text and all null keys are considered equal.
+// Merge keys and non-scalar keys (e.g. aliases and collections) are not checked.
+func FindDuplicateKey(m *ast.MappingNode) (key string, first, second int, found bool) {
+	type scalarKey struct {
+		text string
+		null bool
+	}
+
+	entries := m.Entries()
+	if len(entries) < 2 {
+		return "", 0, 0, false
+	}
+
+	seen := make(map[scalarKey]int, len(entries))
+	for i, entry := range entries {
+		entryNode, ok := entry.(*ast.MappingEntryNode)
+		if !ok {
+			continue
+		}
+		var k scalarKey
+		n := unwrapContent(entryNode.Key())
+		if txt, ok := n.(*ast.TextNode); ok && !IsNull(txt) {
+			if IsMergeKey(txt) {
+				continue
+			}
+			k = scalarKey{text: txt.Text()}
+		} else if ast.ValidNode(n) && IsNull(n) {
+			k = scalarKey{text: "null", null: true}
+		} else {
+			continue
+		}
+		if j, ok := seen[k]; ok {
+			return k.text, j, i, true
+		}
+		seen[k] = i
+	}
+	return "", 0, 0, false
+}
+
+// unwrapContent returns the content of node with properties (anchor or tag).
+func unwrapContent(n ast.Node) ast.Node {
+	for {
+		c, ok := n.(*ast.ContentNode)
+		if !ok {
+			return n
+		}
+		n = c.Content()
+	}
+}
@@ -26,6 +26,24 @@ func IsNull(n ast.Node) bool {
 	return false
 }
 
+// Resolve derives the kind of scalar node and converts it into Go value. Quoted and block scalars are always strings.
+// For more information see shared schema package.
+func Resolve(n ast.Node) (schema.Kind, any) {
+	switch n.Type() {
+	case ast.NullType:
+		return schema.NullKind, nil
+	case ast.TextType:
+		txtNode := n.(*ast.TextNode) // nolint: forcetypeassert
+		switch txtNode.QuotingType() {
+		case ast.SingleQuotingType, ast.DoubleQuotingType, ast.LiteralQuotingType, ast.FoldedQuotingType:
+			return schema.StringKind, txtNode.Text()
+		default:
+			return schema.Resolve(txtNode.Text())
+		}
+	}
+	return schema.StringKind, ""
+}
+
 func IsBoolean(n ast.Node) bool {
 	if n.Type() != ast.TextType {
 		return false
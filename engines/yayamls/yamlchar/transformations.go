@@ -80,6 +80,9 @@ func ConvertFromYAMLDoubleQuotedString(s string) (string, error) {
 
 // ConvertToYAMLDoubleQuotedString 'quotes' given string as YAML double quoted string
 func ConvertToYAMLDoubleQuotedString(s string) (string, error) {
+	if !needsEscaping(s) {
+		return s, nil
+	}
 	var sb strings.Builder
 	sb.Grow(len(s))
 	for _, r := range s {
@@ -104,6 +107,15 @@ func unescapeCharacter(runes []rune, i int) (rune, int, error) {
 	}
 }
 
+func needsEscaping(s string) bool {
+	for _, r := range s {
+		if _, ok := escapeCharacter(r); ok {
+			return true
+		}
+	}
+	return false
+}
+
 func escapeCharacter(r rune) (string, bool) {
 	if result, ok := escapeASCIICharacter(r); ok {
 		return result, ok
@@ -221,8 +233,8 @@ func unescapeUnicodeCharacter(runes []rune, i int) (rune, int, error) {
 			return 0, 0, fmt.Errorf("expected to have 2 hexadecimal digits after \\x, but have %s",
 				string(runes[i:i+2]))
 		}
-		i += 2
 		escaped = runes[i : i+2]
+		i += 2
 	case 'u':
 		i++
 		if len(runes)-i < 4 {
@@ -232,8 +244,8 @@ func unescapeUnicodeCharacter(runes []rune, i int) (rune, int, error) {
 			return 0, 0, fmt.Errorf("expected to have 4 hexadecimal digits after \\u, but have %s",
 				string(runes[i:i+4]))
 		}
-		i += 4
 		escaped = runes[i : i+4]
+		i += 4
 	case 'U':
 		i++
 		if len(runes)-i < 8 {
@@ -243,8 +255,8 @@ func unescapeUnicodeCharacter(runes []rune, i int) (rune, int, error) {
 			return 0, 0, fmt.Errorf("expected to have 8 hexadecimal digits after \\U, but have %s",
 				string(runes[i:i+8]))
 		}
-		i += 8
 		escaped = runes[i : i+8]
+		i += 8
 	}
 	result, err := parseEscapedHexDigits(escaped)
 	return result, i, err
@@ -0,0 +1,412 @@
+package yamlpath
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/schema"
+)
+
+type filterExpr interface {
+	test(e *evaluator, m Match) bool
+}
+
+type orExpr struct {
+	left, right filterExpr
+}
+
+func (x orExpr) test(e *evaluator, m Match) bool {
+	return x.left.test(e, m) || x.right.test(e, m)
+}
+
+type andExpr struct {
+	left, right filterExpr
+}
+
+func (x andExpr) test(e *evaluator, m Match) bool {
+	return x.left.test(e, m) && x.right.test(e, m)
+}
+
+type notExpr struct {
+	expr filterExpr
+}
+
+func (x notExpr) test(e *evaluator, m Match) bool {
+	return !x.expr.test(e, m)
+}
+
+// existsExpr checks if relative path matches any node.
+type existsExpr struct {
+	path relativePath
+}
+
+func (x existsExpr) test(e *evaluator, m Match) bool {
+	_, ok := x.path.find(e, m)
+	return ok
+}
+
+type compareOp int8
+
+const (
+	opEqual compareOp = iota
+	opNotEqual
+	opLess
+	opLessOrEqual
+	opGreater
+	opGreaterOrEqual
+)
+
+type compareExpr struct {
+	op          compareOp
+	left, right operand
+}
+
+func (x compareExpr) test(e *evaluator, m Match) bool {
+	left, ok := x.left.value(e, m)
+	if !ok {
+		return false
+	}
+	right, ok := x.right.value(e, m)
+	if !ok {
+		return false
+	}
+
+	switch x.op {
+	case opEqual:
+		return left.equal(right)
+	case opNotEqual:
+		return !left.equal(right)
+	}
+
+	cmp, ok := left.compare(right)
+	if !ok {
+		return false
+	}
+	switch x.op {
+	case opLess:
+		return cmp < 0
+	case opLessOrEqual:
+		return cmp <= 0
+	case opGreater:
+		return cmp > 0
+	default:
+		return cmp >= 0
+	}
+}
+
+type operand interface {
+	value(e *evaluator, m Match) (scalar, bool)
+}
+
+// scalar is a value of filter operand. It is either a literal from the expression
+// or a text of the scalar node. Scalar nodes don't have a fixed type,
+// so the text is interpreted according to the literal it is compared with.
+type scalar struct {
+	text   string
+	isNull bool
+	// literal shows if text is taken from expression rather than AST
+	literal bool
+	// kind of literal; for node texts kind is always literalString
+	kind literalKind
+	node ast.Node
+}
+
+type literalKind int8
+
+const (
+	literalString literalKind = iota
+	literalNumber
+	literalBoolean
+	literalNull
+)
+
+func (s scalar) equal(other scalar) bool {
+	if !s.literal && other.literal {
+		return other.equal(s)
+	}
+	if s.literal && !other.literal {
+		switch s.kind {
+		case literalNull:
+			return other.isNull
+		case literalBoolean:
+			v, err := schema.ToBoolean(other.text)
+			return err == nil && other.isPlain() && strconv.FormatBool(v) == s.text
+		case literalNumber:
+			cmp, ok := s.compare(other)
+			return ok && other.isPlain() && cmp == 0
+		}
+	}
+	if s.isNull || other.isNull {
+		return s.isNull == other.isNull
+	}
+	return s.text == other.text
+}
+
+// compare compares scalars numerically if both can represent numbers
+// or lexicographically otherwise.
+func (s scalar) compare(other scalar) (int, bool) {
+	if s.isNull || other.isNull {
+		return 0, false
+	}
+	left, leftErr := strconv.ParseFloat(s.numberText(), 64)
+	right, rightErr := strconv.ParseFloat(other.numberText(), 64)
+	if leftErr == nil && rightErr == nil {
+		switch {
+		case left < right:
+			return -1, true
+		case left > right:
+			return 1, true
+		default:
+			return 0, true
+		}
+	}
+	if s.kind == literalNumber || other.kind == literalNumber {
+		return 0, false
+	}
+	return strings.Compare(s.text, other.text), true
+}
+
+func (s scalar) numberText() string {
+	if s.literal {
+		if s.kind == literalNumber {
+			return s.text
+		}
+		return ""
+	}
+	if !s.isPlain() {
+		return ""
+	}
+	n := ast.NewTextNode(s.text)
+	switch {
+	case schema.IsInteger(n):
+		v, err := schema.ToInteger(s.text, 64)
+		if err != nil {
+			return ""
+		}
+		return strconv.FormatInt(v, 10)
+	case schema.IsFloat(n):
+		v, err := schema.ToFloat(s.text, 64)
+		if err != nil {
+			return ""
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	default:
+		return ""
+	}
+}
+
+// isPlain shows if scalar node text is not quoted, i.e. it can be resolved to non-string type.
+func (s scalar) isPlain() bool {
+	txt, ok := s.node.(*ast.TextNode)
+	if !ok {
+		return false
+	}
+	switch txt.QuotingType() {
+	case ast.UnknownQuotingType, ast.AbsentQuotingType:
+		return true
+	default:
+		return false
+	}
+}
+
+type literalOperand struct {
+	v scalar
+}
+
+func (o literalOperand) value(*evaluator, Match) (scalar, bool) {
+	return o.v, true
+}
+
+type relativePath struct {
+	steps []step
+}
+
+func (rp relativePath) find(e *evaluator, m Match) (Match, bool) {
+	current := []Match{m}
+	for _, s := range rp.steps {
+		var next []Match
+		for _, cm := range current {
+			next = s.apply(e, cm, next)
+		}
+		current = next
+	}
+	if len(current) == 0 {
+		return Match{}, false
+	}
+	return current[0], true
+}
+
+func (rp relativePath) value(e *evaluator, m Match) (scalar, bool) {
+	found, ok := rp.find(e, m)
+	if !ok {
+		return scalar{}, false
+	}
+	n := e.content(found.Node)
+	switch n.Type() {
+	case ast.NullType:
+		return scalar{isNull: true, node: n}, true
+	case ast.TextType:
+		return scalar{text: n.(*ast.TextNode).Text(), isNull: schema.IsNull(n), node: n}, true // nolint: forcetypeassert
+	default:
+		return scalar{}, false
+	}
+}
+
+// YAML path filter grammar:
+//
+//	or         = and { "||" and }
+//	and        = unary { "&&" unary }
+//	unary      = "!" unary | "(" or ")" | comparison
+//	comparison = operand [ op operand ]
+//	operand    = "@" { step } | literal
+
+func (p *pathParser) parseOr() (filterExpr, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.hasPrefix("||") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpr{left: left, right: right}
+	}
+}
+
+func (p *pathParser) parseAnd() (filterExpr, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		p.skipSpaces()
+		if !p.hasPrefix("&&") {
+			return left, nil
+		}
+		p.pos += 2
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpr{left: left, right: right}
+	}
+}
+
+func (p *pathParser) parseUnary() (filterExpr, error) {
+	p.skipSpaces()
+	switch {
+	case p.peek() == '!' && !p.hasPrefix("!="):
+		p.pos++
+		expr, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return notExpr{expr: expr}, nil
+	case p.peek() == '(':
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return expr, nil
+	default:
+		return p.parseComparison()
+	}
+}
+
+var compareOperators = []struct {
+	token string
+	op    compareOp
+}{
+	// longer operators go first to be matched before their prefixes
+	{"==", opEqual},
+	{"!=", opNotEqual},
+	{"<=", opLessOrEqual},
+	{">=", opGreaterOrEqual},
+	{"<", opLess},
+	{">", opGreater},
+}
+
+func (p *pathParser) parseComparison() (filterExpr, error) {
+	left, err := p.parseOperand()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipSpaces()
+	for _, candidate := range compareOperators {
+		if !p.hasPrefix(candidate.token) {
+			continue
+		}
+		p.pos += len(candidate.token)
+		right, err := p.parseOperand()
+		if err != nil {
+			return nil, err
+		}
+		return compareExpr{op: candidate.op, left: left, right: right}, nil
+	}
+
+	rp, ok := left.(relativePath)
+	if !ok {
+		return nil, p.errorf("expected comparison operator after literal")
+	}
+	return existsExpr{path: rp}, nil
+}
+
+func (p *pathParser) parseOperand() (operand, error) {
+	p.skipSpaces()
+	switch c := p.peek(); {
+	case c == '@':
+		p.pos++
+		var rp relativePath
+		for p.peek() == '.' || p.peek() == '[' {
+			s, err := p.parseStep(true)
+			if err != nil {
+				return nil, err
+			}
+			rp.steps = append(rp.steps, s)
+		}
+		return rp, nil
+	case c == '\'' || c == '"':
+		s, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return literalOperand{v: scalar{text: s, literal: true, kind: literalString}}, nil
+	case c == '-' || c == '+' || c == '.' || isDigit(c):
+		start := p.pos
+		p.pos++
+		for !p.eof() && strings.IndexByte("0123456789.eE+-", p.src[p.pos]) >= 0 {
+			p.pos++
+		}
+		text := p.src[start:p.pos]
+		if _, err := strconv.ParseFloat(text, 64); err != nil {
+			p.pos = start
+			return nil, p.errorf("invalid number %q", text)
+		}
+		return literalOperand{v: scalar{text: text, literal: true, kind: literalNumber}}, nil
+	}
+
+	for _, keyword := range [...]string{"true", "false", "null"} {
+		if p.hasPrefix(keyword) {
+			p.pos += len(keyword)
+			v := scalar{text: keyword, literal: true, kind: literalBoolean}
+			if keyword == "null" {
+				v.kind, v.isNull = literalNull, true
+			}
+			return literalOperand{v: v}, nil
+		}
+	}
+	return nil, p.errorf("expected operand")
+}
@@ -0,0 +1,345 @@
+package yamlpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// SyntaxError is returned when path expression can't be parsed.
+type SyntaxError struct {
+	// Offset is the byte offset in the expression where the error occurred.
+	Offset int
+	Msg    string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("yamlpath: syntax error at offset %d: %s", e.Offset, e.Msg)
+}
+
+type step interface {
+	apply(e *evaluator, m Match, dst []Match) []Match
+}
+
+type keyStep struct {
+	key string
+}
+
+func (s keyStep) apply(e *evaluator, m Match, dst []Match) []Match {
+	return e.childByKey(m, s.key, dst)
+}
+
+type indexStep struct {
+	index int
+}
+
+func (s indexStep) apply(e *evaluator, m Match, dst []Match) []Match {
+	return e.childByIndex(m, s.index, dst)
+}
+
+type wildcardStep struct{}
+
+func (wildcardStep) apply(e *evaluator, m Match, dst []Match) []Match {
+	return e.children(m, dst)
+}
+
+type unionStep struct {
+	steps []step
+}
+
+func (s unionStep) apply(e *evaluator, m Match, dst []Match) []Match {
+	for _, sub := range s.steps {
+		dst = sub.apply(e, m, dst)
+	}
+	return dst
+}
+
+type filterStep struct {
+	cond filterExpr
+}
+
+func (s filterStep) apply(e *evaluator, m Match, dst []Match) []Match {
+	for _, child := range e.children(m, nil) {
+		if s.cond.test(e, child) {
+			dst = append(dst, child)
+		}
+	}
+	return dst
+}
+
+// descendantStep applies the nested step to the node and all its descendants.
+type descendantStep struct {
+	step step
+}
+
+func (s descendantStep) apply(e *evaluator, m Match, dst []Match) []Match {
+	for _, d := range e.descendants(m, nil) {
+		dst = s.step.apply(e, d, dst)
+	}
+	return dst
+}
+
+type pathParser struct {
+	src string
+	pos int
+}
+
+func (p *pathParser) errorf(format string, args ...any) error {
+	return &SyntaxError{Offset: p.pos, Msg: fmt.Sprintf(format, args...)}
+}
+
+func (p *pathParser) eof() bool {
+	return p.pos >= len(p.src)
+}
+
+func (p *pathParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *pathParser) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(p.src[p.pos:], prefix)
+}
+
+func (p *pathParser) skipSpaces() {
+	for !p.eof() && (p.src[p.pos] == ' ' || p.src[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+func (p *pathParser) expect(s string) error {
+	if !p.hasPrefix(s) {
+		return p.errorf("expected %q", s)
+	}
+	p.pos += len(s)
+	return nil
+}
+
+func (p *pathParser) parsePath() ([]step, error) {
+	p.skipSpaces()
+	if p.peek() == '$' {
+		p.pos++
+	}
+
+	var steps []step
+	for {
+		p.skipSpaces()
+		if p.eof() {
+			return steps, nil
+		}
+		s, err := p.parseStep(false)
+		if err != nil {
+			return nil, err
+		}
+		steps = append(steps, s)
+	}
+}
+
+// parseStep parses a single step. Relative steps (used in filters) can't contain
+// wildcards, filters or recursive descent.
+func (p *pathParser) parseStep(relative bool) (step, error) {
+	switch {
+	case p.hasPrefix(".."):
+		if relative {
+			return nil, p.errorf("recursive descent is not allowed in filter paths")
+		}
+		p.pos += 2
+		var (
+			nested step
+			err    error
+		)
+		if p.peek() == '[' {
+			nested, err = p.parseBracket(relative)
+		} else {
+			nested, err = p.parseDotName(relative)
+		}
+		if err != nil {
+			return nil, err
+		}
+		return descendantStep{step: nested}, nil
+	case p.peek() == '.':
+		p.pos++
+		return p.parseDotName(relative)
+	case p.peek() == '[':
+		return p.parseBracket(relative)
+	default:
+		return nil, p.errorf("unexpected character %q", p.peek())
+	}
+}
+
+func (p *pathParser) parseDotName(relative bool) (step, error) {
+	if p.peek() == '*' {
+		if relative {
+			return nil, p.errorf("wildcards are not allowed in filter paths")
+		}
+		p.pos++
+		return wildcardStep{}, nil
+	}
+	if p.peek() == '\'' || p.peek() == '"' {
+		key, err := p.parseQuoted()
+		if err != nil {
+			return nil, err
+		}
+		return keyStep{key: key}, nil
+	}
+
+	start := p.pos
+	for !p.eof() && !isNameTerminator(p.src[p.pos], relative) {
+		p.pos++
+	}
+	if start == p.pos {
+		return nil, p.errorf("expected key name")
+	}
+	return keyStep{key: p.src[start:p.pos]}, nil
+}
+
+func isNameTerminator(c byte, relative bool) bool {
+	switch c {
+	case '.', '[':
+		return true
+	case ' ', '\t', ')', '=', '!', '<', '>', '&', '|':
+		// these characters finish the relative path in filter expression
+		return relative
+	default:
+		return false
+	}
+}
+
+func (p *pathParser) parseBracket(relative bool) (step, error) {
+	if err := p.expect("["); err != nil {
+		return nil, err
+	}
+	p.skipSpaces()
+
+	var result step
+	switch c := p.peek(); {
+	case c == '*':
+		if relative {
+			return nil, p.errorf("wildcards are not allowed in filter paths")
+		}
+		p.pos++
+		result = wildcardStep{}
+	case c == '?':
+		if relative {
+			return nil, p.errorf("nested filters are not allowed")
+		}
+		p.pos++
+		p.skipSpaces()
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		cond, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		p.skipSpaces()
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		result = filterStep{cond: cond}
+	case c == '\'' || c == '"':
+		var union unionStep
+		for {
+			key, err := p.parseQuoted()
+			if err != nil {
+				return nil, err
+			}
+			union.steps = append(union.steps, keyStep{key: key})
+			p.skipSpaces()
+			if p.peek() != ',' || relative {
+				break
+			}
+			p.pos++
+			p.skipSpaces()
+		}
+		if len(union.steps) == 1 {
+			result = union.steps[0]
+		} else {
+			result = union
+		}
+	case c == '-' || isDigit(c):
+		var union unionStep
+		for {
+			idx, err := p.parseInt()
+			if err != nil {
+				return nil, err
+			}
+			union.steps = append(union.steps, indexStep{index: idx})
+			p.skipSpaces()
+			if p.peek() != ',' || relative {
+				break
+			}
+			p.pos++
+			p.skipSpaces()
+		}
+		if len(union.steps) == 1 {
+			result = union.steps[0]
+		} else {
+			result = union
+		}
+	default:
+		return nil, p.errorf("unexpected character %q in brackets", c)
+	}
+
+	p.skipSpaces()
+	if err := p.expect("]"); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (p *pathParser) parseInt() (int, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.eof() && isDigit(p.src[p.pos]) {
+		p.pos++
+	}
+	v, err := strconv.Atoi(p.src[start:p.pos])
+	if err != nil {
+		p.pos = start
+		return 0, p.errorf("invalid index")
+	}
+	return v, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// parseQuoted parses single or double quoted string. Backslash escapes
+// the next character in both quoting styles.
+func (p *pathParser) parseQuoted() (string, error) {
+	quote := p.peek()
+	start := p.pos
+	p.pos++
+
+	var sb strings.Builder
+	for {
+		if p.eof() {
+			p.pos = start
+			return "", p.errorf("unterminated string")
+		}
+		c := p.src[p.pos]
+		switch c {
+		case quote:
+			p.pos++
+			return sb.String(), nil
+		case '\\':
+			p.pos++
+			if p.eof() {
+				continue
+			}
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			sb.WriteRune(r)
+			p.pos += size
+		default:
+			sb.WriteByte(c)
+			p.pos++
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Package yamlpath contains types and functions to query YAML AST with path expressions.
+//
+// Path expression is a sequence of steps applied to the root node:
+//
+//	$                        root node (optional)
+//	.name or ['name']        mapping value with given key
+//	.* or [*]                all mapping values or sequence elements
+//	[n]                      sequence element with given index (negative index counts from the end)
+//	..name, ..*, ..[n]       recursive descent: applies the step to the node and all its descendants
+//	[?(@.kind == "Service")] sequence elements or mapping values satisfying the filter
+//
+// Filters support comparison operators (==, !=, <, <=, >, >=), logical operators (&&, ||, !),
+// parentheses and existence checks like [?(@.name)]. Relative paths inside filters start with '@'
+// and may contain only name and index steps. Literals are double or single quoted strings, numbers,
+// true, false and null.
+package yamlpath
+
+import (
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/ast/astalias"
+)
+
+// Match is a single node matched by path expression.
+type Match struct {
+	// Node is the matched node. Aliases are resolved, i.e. Node is never an alias node,
+	// but it can be a content node holding properties (tag and anchor) of the actual content.
+	Node ast.Node
+	// Parent is the node holding Node in AST: a mapping entry for mapping values
+	// or a sequence for sequence elements. Parent is nil for the root node.
+	Parent ast.Node
+	// Collection is the mapping or sequence node containing Node. Collection is nil for the root node.
+	Collection ast.Node
+	// Index is the position of Parent (for mappings) or Node (for sequences) in the entries of Collection.
+	Index int
+}
+
+// Content returns the matched node without properties wrapper.
+func (m Match) Content() ast.Node {
+	return unwrapContent(m.Node)
+}
+
+// Path is a compiled path expression.
+type Path struct {
+	expr  string
+	steps []step
+}
+
+// Compile parses given path expression.
+func Compile(expr string) (*Path, error) {
+	p := pathParser{src: expr}
+	steps, err := p.parsePath()
+	if err != nil {
+		return nil, err
+	}
+	return &Path{expr: expr, steps: steps}, nil
+}
+
+// MustCompile is like Compile, but panics if the expression cannot be parsed.
+func MustCompile(expr string) *Path {
+	p, err := Compile(expr)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// String returns the source expression of the path.
+func (p *Path) String() string {
+	return p.expr
+}
+
+// Find evaluates the path against given AST and returns matching nodes in document order.
+// If root is a stream node, the path is evaluated against every document of the stream.
+func (p *Path) Find(root ast.Node) ([]Match, error) {
+	if !ast.ValidNode(root) {
+		return nil, nil
+	}
+
+	e := newEvaluator(root)
+	if e.err != nil {
+		return nil, e.err
+	}
+
+	var current []Match
+	if root.Type() == ast.StreamType {
+		for _, doc := range root.(*ast.StreamNode).Documents() { // nolint: forcetypeassert
+			current = append(current, Match{Node: e.resolve(doc)})
+		}
+	} else {
+		current = []Match{{Node: e.resolve(root)}}
+	}
+
+	for _, s := range p.steps {
+		var next []Match
+		for _, m := range current {
+			next = s.apply(e, m, next)
+		}
+		current = next
+	}
+	return current, nil
+}
+
+// Find compiles given path expression and evaluates it against given AST.
+func Find(root ast.Node, expr string) ([]Match, error) {
+	p, err := Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	return p.Find(root)
+}
+
+// evaluator holds the information about AST required to evaluate path expressions.
+type evaluator struct {
+	aliases map[*ast.AliasNode]ast.Node
+	err     error
+}
+
+func newEvaluator(root ast.Node) *evaluator {
+	aliases, err := astalias.Bind(root)
+	return &evaluator{aliases: aliases, err: err}
+}
+
+// resolve replaces alias node with the node bound to the alias' anchor.
+func (e *evaluator) resolve(n ast.Node) ast.Node {
+	if alias, ok := n.(*ast.AliasNode); ok {
+		return e.aliases[alias]
+	}
+	return n
+}
+
+// content returns the actual content of the node (i.e. without properties and aliases).
+func (e *evaluator) content(n ast.Node) ast.Node {
+	return unwrapContent(e.resolve(unwrapContent(n)))
+}
+
+func (e *evaluator) children(m Match, dst []Match) []Match {
+	switch n := e.content(m.Node).(type) {
+	case *ast.MappingNode:
+		for i, entry := range n.Entries() {
+			if entry, ok := entry.(*ast.MappingEntryNode); ok {
+				dst = append(dst, Match{Node: e.resolve(entry.Value()), Parent: entry, Collection: n, Index: i})
+			}
+		}
+	case *ast.SequenceNode:
+		for i, elem := range n.Entries() {
+			dst = append(dst, Match{Node: e.resolve(elem), Parent: n, Collection: n, Index: i})
+		}
+	}
+	return dst
+}
+
+func (e *evaluator) childByKey(m Match, key string, dst []Match) []Match {
+	mapping, ok := e.content(m.Node).(*ast.MappingNode)
+	if !ok {
+		return dst
+	}
+	for i, entry := range mapping.Entries() {
+		entry, ok := entry.(*ast.MappingEntryNode)
+		if !ok {
+			continue
+		}
+		if k, ok := e.text(entry.Key()); ok && k == key {
+			dst = append(dst, Match{Node: e.resolve(entry.Value()), Parent: entry, Collection: mapping, Index: i})
+		}
+	}
+	return dst
+}
+
+func (e *evaluator) childByIndex(m Match, idx int, dst []Match) []Match {
+	seq, ok := e.content(m.Node).(*ast.SequenceNode)
+	if !ok {
+		return dst
+	}
+	entries := seq.Entries()
+	if idx < 0 {
+		idx += len(entries)
+	}
+	if idx < 0 || idx >= len(entries) {
+		return dst
+	}
+	return append(dst, Match{Node: e.resolve(entries[idx]), Parent: seq, Collection: seq, Index: idx})
+}
+
+// descendants appends the node and all its descendants to dst in document order.
+// Aliases are not followed to avoid visiting the same subtree several times.
+func (e *evaluator) descendants(m Match, dst []Match) []Match {
+	dst = append(dst, m)
+	switch n := unwrapContent(m.Node).(type) {
+	case *ast.MappingNode:
+		for i, entry := range n.Entries() {
+			if entry, ok := entry.(*ast.MappingEntryNode); ok && ast.ValidNode(entry.Value()) {
+				if entry.Value().Type() != ast.AliasType {
+					dst = e.descendants(Match{Node: entry.Value(), Parent: entry, Collection: n, Index: i}, dst)
+				}
+			}
+		}
+	case *ast.SequenceNode:
+		for i, elem := range n.Entries() {
+			if ast.ValidNode(elem) && elem.Type() != ast.AliasType {
+				dst = e.descendants(Match{Node: elem, Parent: n, Collection: n, Index: i}, dst)
+			}
+		}
+	}
+	return dst
+}
+
+// text returns the text of scalar node.
+func (e *evaluator) text(n ast.Node) (string, bool) {
+	switch n := e.content(n).(type) {
+	case *ast.TextNode:
+		return n.Text(), true
+	case *ast.NullNode:
+		return "", true
+	default:
+		return "", false
+	}
+}
+
+func unwrapContent(n ast.Node) ast.Node {
+	for {
+		c, ok := n.(*ast.ContentNode)
+		if !ok {
+			return n
+		}
+		n = c.Content()
+	}
+}
@@ -0,0 +1,300 @@
+package yamlpath_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KSpaceer/yamly/engines/yayamls/ast"
+	"github.com/KSpaceer/yamly/engines/yayamls/parser"
+	"github.com/KSpaceer/yamly/engines/yayamls/yamlpath"
+)
+
+const manifest = `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: web
+  labels:
+    app: &app frontend
+spec:
+  replicas: 3
+  containers:
+    - name: nginx
+      image: nginx:1.25
+      port: 80
+    - name: sidecar
+      image: "envoy:1.28"
+      port: 9901
+      debug: true
+    - name: logger
+      image: fluentd
+  selector:
+    app: *app
+`
+
+func TestFind(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name     string
+		src      string
+		expr     string
+		expected []string
+	}
+
+	tcases := []tcase{
+		{
+			name:     "root",
+			src:      "value",
+			expr:     "$",
+			expected: []string{"value"},
+		},
+		{
+			name:     "dot names",
+			src:      manifest,
+			expr:     "$.metadata.name",
+			expected: []string{"web"},
+		},
+		{
+			name:     "without root symbol",
+			src:      manifest,
+			expr:     ".kind",
+			expected: []string{"Pod"},
+		},
+		{
+			name:     "bracket names",
+			src:      manifest,
+			expr:     "$['metadata'][\"labels\"].app",
+			expected: []string{"frontend"},
+		},
+		{
+			name:     "sequence wildcard",
+			src:      manifest,
+			expr:     "$.spec.containers[*].image",
+			expected: []string{"nginx:1.25", "envoy:1.28", "fluentd"},
+		},
+		{
+			name:     "mapping wildcard",
+			src:      manifest,
+			expr:     "$.metadata.*",
+			expected: []string{"web", "<mapping>"},
+		},
+		{
+			name:     "index",
+			src:      manifest,
+			expr:     "$.spec.containers[1].name",
+			expected: []string{"sidecar"},
+		},
+		{
+			name:     "negative index",
+			src:      manifest,
+			expr:     "$.spec.containers[-1].name",
+			expected: []string{"logger"},
+		},
+		{
+			name:     "index out of range",
+			src:      manifest,
+			expr:     "$.spec.containers[3]",
+			expected: nil,
+		},
+		{
+			name:     "index union",
+			src:      manifest,
+			expr:     "$.spec.containers[0, 2].name",
+			expected: []string{"nginx", "logger"},
+		},
+		{
+			name:     "key union",
+			src:      manifest,
+			expr:     "$['kind', 'apiVersion']",
+			expected: []string{"Pod", "v1"},
+		},
+		{
+			name:     "recursive descent",
+			src:      manifest,
+			expr:     "$..name",
+			expected: []string{"web", "nginx", "sidecar", "logger"},
+		},
+		{
+			name:     "recursive descent with index",
+			src:      "a: [1, [2, 3]]\nb: [4]",
+			expr:     "..[0]",
+			expected: []string{"1", "2", "4"},
+		},
+		{
+			name:     "alias resolution",
+			src:      manifest,
+			expr:     "$.spec.selector.app",
+			expected: []string{"frontend"},
+		},
+		{
+			name:     "filter with string equality",
+			src:      manifest,
+			expr:     "$.spec.containers[?(@.name == 'sidecar')].image",
+			expected: []string{"envoy:1.28"},
+		},
+		{
+			name:     "filter with number comparison",
+			src:      manifest,
+			expr:     "$.spec.containers[?(@.port >= 100)].name",
+			expected: []string{"sidecar"},
+		},
+		{
+			name:     "filter with existence check",
+			src:      manifest,
+			expr:     "$.spec.containers[?(@.port)].name",
+			expected: []string{"nginx", "sidecar"},
+		},
+		{
+			name:     "filter with negation",
+			src:      manifest,
+			expr:     "$.spec.containers[?(!@.port)].name",
+			expected: []string{"logger"},
+		},
+		{
+			name:     "filter with logical operators",
+			src:      manifest,
+			expr:     `$.spec.containers[?(@.debug == true || (@.port < 100 && @.name != "logger"))].name`,
+			expected: []string{"nginx", "sidecar"},
+		},
+		{
+			name:     "filter does not treat quoted strings as numbers",
+			src:      "- v: '80'\n- v: 80\n- v: 0x50",
+			expr:     "[?(@.v == 80)].v",
+			expected: []string{"80", "0x50"},
+		},
+		{
+			name:     "filter with null",
+			src:      "- {a: 1, b: ~}\n- {a: 2, b: 3}\n- {a: 3, b: }",
+			expr:     "[?(@.b == null)].a",
+			expected: []string{"1", "3"},
+		},
+		{
+			name:     "filter on mapping values",
+			src:      "x: {size: 1}\ny: {size: 10}\nz: {size: 5}",
+			expr:     "[?(@.size > 2)].size",
+			expected: []string{"10", "5"},
+		},
+		{
+			name:     "multiple documents",
+			src:      "---\nname: a\n---\nname: b\n",
+			expr:     "$.name",
+			expected: []string{"a", "b"},
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree, err := parser.ParseString(tc.src)
+			if err != nil {
+				t.Fatalf("failed to parse source: %v", err)
+			}
+
+			matches, err := yamlpath.Find(tree, tc.expr)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			var result []string
+			for _, m := range matches {
+				result = append(result, describe(m.Content()))
+			}
+
+			if !reflect.DeepEqual(result, tc.expected) {
+				t.Errorf("expected %q, but got %q", tc.expected, result)
+			}
+		})
+	}
+}
+
+func TestFind_Parent(t *testing.T) {
+	t.Parallel()
+
+	tree, err := parser.ParseString(manifest)
+	if err != nil {
+		t.Fatalf("failed to parse source: %v", err)
+	}
+
+	matches, err := yamlpath.Find(tree, "$.spec.containers[0].name")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, but got %d", len(matches))
+	}
+
+	entry, ok := matches[0].Parent.(*ast.MappingEntryNode)
+	if !ok {
+		t.Fatalf("expected mapping entry parent, but got %T", matches[0].Parent)
+	}
+	if key := describe(entry.Key()); key != "name" {
+		t.Errorf("expected parent key %q, but got %q", "name", key)
+	}
+
+	matches, err = yamlpath.Find(tree, "$.spec.containers[0]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(matches) != 1 {
+		t.Fatalf("expected 1 match, but got %d", len(matches))
+	}
+	if _, ok := matches[0].Parent.(*ast.SequenceNode); !ok {
+		t.Errorf("expected sequence parent, but got %T", matches[0].Parent)
+	}
+}
+
+func TestCompile_SyntaxError(t *testing.T) {
+	t.Parallel()
+
+	exprs := []string{
+		"$.",
+		"$[",
+		"$['unterminated]",
+		"$[abc]",
+		"$..",
+		"$[?(@.a ==)]",
+		"$[?(@.a == 1]",
+		"$[?(1)]",
+		"$[?(@..a)]",
+		"$[?(@.*)]",
+	}
+
+	for _, expr := range exprs {
+		expr := expr
+		t.Run(expr, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := yamlpath.Compile(expr)
+			var syntaxErr *yamlpath.SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Errorf("expected syntax error, but got %v", err)
+			}
+		})
+	}
+}
+
+func describe(n ast.Node) string {
+	for {
+		c, ok := n.(*ast.ContentNode)
+		if !ok {
+			break
+		}
+		n = c.Content()
+	}
+	switch n := n.(type) {
+	case *ast.TextNode:
+		return n.Text()
+	case *ast.NullNode:
+		return "<null>"
+	case *ast.MappingNode:
+		return "<mapping>"
+	case *ast.SequenceNode:
+		return "<sequence>"
+	default:
+		return "<unknown>"
+	}
+}
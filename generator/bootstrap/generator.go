@@ -8,6 +8,8 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+
+	"github.com/KSpaceer/yamly/generator/parser"
 )
 
 const generatorPackage = "github.com/KSpaceer/yamly/generator"
@@ -24,6 +26,15 @@ type Generator struct {
 
 	EncodePointerReceiver bool
 	InlineEmbedded        bool
+	ReflectFallback       bool
+	FieldNaming           string
+	CaseInsensitiveKeys   bool
+	IgnoreKeySeparators   bool
+	DurationUnit          string
+	TimeFormat            string
+	TimeNanoseconds       bool
+	Codecs                []string
+	Unions                []parser.Union
 
 	EngineGeneratorPackage string
 	EngineGenerator        string
@@ -80,6 +91,9 @@ func (g *Generator) generateMain() (string, error) {
 	fmt.Fprintln(f, "import (")
 	fmt.Fprintln(f, "  \"fmt\"")
 	fmt.Fprintln(f, "  \"os\"")
+	if len(g.Unions) > 0 {
+		fmt.Fprintln(f, "  \"reflect\"")
+	}
 	fmt.Fprintln(f)
 	fmt.Fprintf(f, " generator %q\n", generatorPackage)
 	fmt.Fprintln(f)
@@ -100,6 +114,34 @@ func (g *Generator) generateMain() (string, error) {
 	fmt.Fprintf(f, "  g.SetDisallowUnknownFields(%t)\n", g.DisallowUnknownFields)
 	fmt.Fprintf(f, "  g.SetEncodePointerReceiver(%t)\n", g.EncodePointerReceiver)
 	fmt.Fprintf(f, "  g.SetInlineEmbedded(%t)\n", g.InlineEmbedded)
+	fmt.Fprintf(f, "  g.SetReflectFallback(%t)\n", g.ReflectFallback)
+	fmt.Fprintf(f, "  g.SetCaseInsensitiveKeys(%t)\n", g.CaseInsensitiveKeys)
+	fmt.Fprintf(f, "  g.SetIgnoreKeySeparators(%t)\n", g.IgnoreKeySeparators)
+	if g.FieldNaming != "" {
+		fmt.Fprintf(f, "  g.SetFieldNaming(%q)\n", g.FieldNaming)
+	}
+	if g.DurationUnit != "" {
+		fmt.Fprintf(f, "  g.SetDurationUnit(%q)\n", g.DurationUnit)
+	}
+	if g.TimeFormat != "" {
+		fmt.Fprintf(f, "  g.SetTimeFormat(%q)\n", g.TimeFormat)
+	}
+	fmt.Fprintf(f, "  g.SetTimeNanoseconds(%t)\n", g.TimeNanoseconds)
+	for _, codec := range g.Codecs {
+		fmt.Fprintf(f, "  g.AddCodec(%q)\n", codec)
+	}
+	for _, u := range g.Unions {
+		fmt.Fprintf(f, "  g.AddUnion(reflect.TypeOf((*pkg.%s)(nil)).Elem(), %q, []generator.UnionVariant{\n",
+			u.Interface, u.Key)
+		for _, v := range u.Variants {
+			typeExpr := "reflect.TypeOf((*pkg." + v.Type + ")(nil))"
+			if !v.Pointer {
+				typeExpr += ".Elem()"
+			}
+			fmt.Fprintf(f, "    {Value: %q, Type: %s},\n", v.Value, typeExpr)
+		}
+		fmt.Fprintln(f, "  })")
+	}
 	fmt.Fprintf(f, "  g.AddType(pkg.Exporter_yamly_%s(nil))\n", g.Type)
 
 	fmt.Fprintln(f, "  if err := g.Generate(os.Stdout); err != nil {")
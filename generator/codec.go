@@ -0,0 +1,128 @@
+package generator
+
+import (
+	"fmt"
+	"go/token"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// funcRef is a reference to package-level function used in generated code.
+type funcRef struct {
+	// pkgPath is the import path of function package, empty for the package of generated code
+	pkgPath string
+	name    string
+}
+
+// customCodec is a pair of functions processing values of some type T:
+// func(yamly.Decoder, *T) for decoding and func(yamly.Inserter, T) for encoding.
+type customCodec struct {
+	decode funcRef
+	encode funcRef
+}
+
+// parseQualifiedName splits qualified name like "github.com/shopspring/decimal.Decimal"
+// into package path and name. Name without package path refers to the package of generated code.
+func parseQualifiedName(s string) (pkgPath, name string, err error) {
+	if i := strings.LastIndexByte(s, '.'); i > strings.LastIndexByte(s, '/') {
+		pkgPath, name = s[:i], s[i+1:]
+	} else {
+		name = s
+	}
+	if !token.IsIdentifier(name) || (pkgPath == "" && strings.Contains(s, "/")) {
+		return "", "", fmt.Errorf("invalid qualified name %q: expected [package path.]Name", s)
+	}
+	return pkgPath, name, nil
+}
+
+// parseCodecFuncs parses the pair of codec functions given as "pkg.DecodeFn,pkg.EncodeFn".
+func parseCodecFuncs(s string) (customCodec, error) {
+	decodeFn, encodeFn, ok := strings.Cut(s, ",")
+	if !ok {
+		return customCodec{}, fmt.Errorf("invalid codec %q: expected DecodeFn,EncodeFn", s)
+	}
+	var (
+		c   customCodec
+		err error
+	)
+	if c.decode.pkgPath, c.decode.name, err = parseQualifiedName(strings.TrimSpace(decodeFn)); err != nil {
+		return customCodec{}, err
+	}
+	if c.encode.pkgPath, c.encode.name, err = parseQualifiedName(strings.TrimSpace(encodeFn)); err != nil {
+		return customCodec{}, err
+	}
+	return c, nil
+}
+
+// parseCodecs parses registered codecs given as "pkg.Type=pkg.DecodeFn,pkg.EncodeFn",
+// using package path and type name as key.
+func (g *Generator) parseCodecs() error {
+	g.codecs = make(map[string]customCodec, len(g.codecSpecs))
+	for _, spec := range g.codecSpecs {
+		typeName, funcs, ok := strings.Cut(spec, "=")
+		if !ok {
+			return fmt.Errorf("invalid codec %q: expected pkg.Type=pkg.DecodeFn,pkg.EncodeFn", spec)
+		}
+		pkgPath, name, err := parseQualifiedName(strings.TrimSpace(typeName))
+		if err != nil {
+			return fmt.Errorf("invalid codec %q: %w", spec, err)
+		}
+		if pkgPath == "" {
+			pkgPath = g.pkgPath
+		}
+		c, err := parseCodecFuncs(funcs)
+		if err != nil {
+			return fmt.Errorf("invalid codec %q: %w", spec, err)
+		}
+		key := pkgPath + "." + name
+		if _, exists := g.codecs[key]; exists {
+			return fmt.Errorf("several codecs are registered for type %s", key)
+		}
+		g.codecs[key] = c
+	}
+	return nil
+}
+
+// lookupCodec returns the codec of type t given with "codec" option of "yamly" field tag
+// or registered for the type. Pointers are not processed by codecs and pass them to their elements.
+func (g *Generator) lookupCodec(t reflect.Type, tags fieldTags) (customCodec, bool, error) {
+	if t.Kind() == reflect.Pointer {
+		return customCodec{}, false, nil
+	}
+	if tags.Codec != "" {
+		c, err := parseCodecFuncs(tags.Codec)
+		if err != nil {
+			return customCodec{}, false, fmt.Errorf("invalid codec of %s field: %w", t, err)
+		}
+		return c, true, nil
+	}
+	if t.PkgPath() == "" {
+		return customCodec{}, false, nil
+	}
+	c, ok := g.codecs[t.PkgPath()+"."+t.Name()]
+	return c, ok, nil
+}
+
+func (g *Generator) funcName(f funcRef) string {
+	if f.pkgPath == "" || f.pkgPath == g.pkgPath {
+		return f.name
+	}
+	return g.pkgAlias(f.pkgPath) + "." + f.name
+}
+
+// codecArgs returns reflectcodec options registering codecs for reflection-based codec.
+func (g *Generator) codecArgs(alias string) string {
+	keys := make([]string, 0, len(g.codecs))
+	for key := range g.codecs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var args string
+	for _, key := range keys {
+		c := g.codecs[key]
+		args += ", " + alias + ".WithCodec(" + g.funcName(c.decode) + ", " + g.funcName(c.encode) + ")"
+	}
+	return args
+}
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
 )
 
 var basicDecoders = map[reflect.Kind]string{
@@ -27,10 +28,6 @@ var basicDecoders = map[reflect.Kind]string{
 	reflect.Float64: "in.Float(64)",
 }
 
-var customDecoders = map[string]string{
-	"time.Time": "in.Timestamp()",
-}
-
 func (g *Generator) generateUnmarshaler(t reflect.Type) error {
 	fname := g.decoderFunctionName(t)
 	tname := g.extractTypeName(t)
@@ -87,27 +84,70 @@ func (g *Generator) generateStructDecoder(t reflect.Type) error {
 		fmt.Fprintln(g.out, "  out."+f.Name+" = new("+g.extractTypeName(f.Type.Elem())+")")
 	}
 
-	fields, err := g.getStructFields(t)
+	keys, normalization, err := yamltag.Keys(t, g.inlineEmbedded, g.fieldNaming, g.keyNormalization())
 	if err != nil {
 		return fmt.Errorf("cannot generate decoder for %s: %w", t, err)
 	}
+	remain, hasRemain, err := yamltag.RemainField(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate decoder for %s: %w", t, err)
+	}
+
+	// variables keeping the key used to set the field with aliases to detect conflicts
+	seenKeyVars := make(map[string]string)
+	for _, key := range keys {
+		if len(key.AliasMatches) > 0 {
+			seenKeyVars[key.Match] = g.generateVarName("SeenKey")
+			fmt.Fprintln(g.out, "  var "+seenKeyVars[key.Match]+" string")
+		}
+	}
+
+	matchExpr := func(keyVar string) string {
+		if normalization == 0 {
+			return keyVar
+		}
+		return "yamly.NormalizeKey(" + keyVar + ", " + keyNormalizationExpr(normalization) + ")"
+	}
 
 	fmt.Fprintln(g.out, "  structMappingState := in.Mapping()")
 	fmt.Fprintln(g.out, "  for structMappingState.HasUnprocessedItems() {")
 	fmt.Fprintln(g.out, "    key := in.String()")
+	keyMatch := "key"
+	if normalization != 0 {
+		// normalized key is computed once, because it is matched several times
+		fmt.Fprintln(g.out, "    match := "+matchExpr("key"))
+		keyMatch = "match"
+	}
+	// aliases are checked before null values to detect conflicts and deprecated keys regardless of the value
+	g.generateAliasesCheck(keys, keyMatch, matchExpr, seenKeyVars)
 	fmt.Fprintln(g.out, "    if in.TryNull() {")
+	if hasRemain {
+		// null values of unknown keys are kept in remain field too
+		g.generateRemainNullDecoder(remain, keys, keyMatch)
+	}
 	fmt.Fprintln(g.out, "      continue")
 	fmt.Fprintln(g.out, "    }")
-	fmt.Fprintln(g.out, "    switch key {")
-	for _, f := range fields {
-		if err = g.generateStructFieldDecoder(f); err != nil {
+	fmt.Fprintln(g.out, "    switch "+keyMatch+" {")
+	labels := make(map[string]bool)
+	for _, key := range keys {
+		if err = g.generateStructFieldDecoder(key); err != nil {
 			return err
 		}
+		labels[key.Match] = true
+		for _, aliasMatch := range key.AliasMatches {
+			labels[aliasMatch] = true
+		}
 	}
+	g.generateDiscriminatorSkips(t, labels, normalization)
 	fmt.Fprintln(g.out, "    default:")
-	if g.disallowUnknownFields {
+	switch {
+	case hasRemain:
+		if err = g.generateRemainDecoder(remain, 6); err != nil {
+			return err
+		}
+	case g.disallowUnknownFields:
 		fmt.Fprintln(g.out, "      in.AddError(&yamly.UnknownFieldError{Field: key})")
-	} else {
+	default:
 		fmt.Fprintln(g.out, "      in.Skip()")
 	}
 	fmt.Fprintln(g.out, "    }")
@@ -116,19 +156,103 @@ func (g *Generator) generateStructDecoder(t reflect.Type) error {
 	return nil
 }
 
-func (g *Generator) generateStructFieldDecoder(f reflect.StructField) error {
-	tags := parseTags(f.Tag)
+func (g *Generator) generateStructFieldDecoder(key yamltag.FieldKey) error {
+	fmt.Fprintln(g.out, "    case "+strings.Join(keyLabels(key), ", ")+":")
+	return g.generateDecoderBody(key.Field.Type, "out."+key.Field.Name, key.Tags, 6, true)
+}
 
-	if tags.omitField {
-		return nil
+// generateAliasesCheck generates detection of conflicting keys and deprecated aliases for fields with aliases.
+// Keys are compared by the labels they match, so differently written keys of the same label do not conflict.
+func (g *Generator) generateAliasesCheck(
+	keys []yamltag.FieldKey,
+	keyMatch string,
+	matchExpr func(keyVar string) string,
+	seenKeyVars map[string]string,
+) {
+	if len(seenKeyVars) == 0 {
+		return
+	}
+	fmt.Fprintln(g.out, "    switch "+keyMatch+" {")
+	for _, key := range keys {
+		seenKeyVar, ok := seenKeyVars[key.Match]
+		if !ok {
+			continue
+		}
+		quotedKey := strconv.Quote(key.Name)
+		fmt.Fprintln(g.out, "    case "+strings.Join(keyLabels(key), ", ")+":")
+		fmt.Fprintln(g.out, "      if "+seenKeyVar+" != \"\" && "+matchExpr(seenKeyVar)+" != "+keyMatch+" {")
+		fmt.Fprintln(g.out, "        in.AddError(&yamly.KeyConflictError{Key: "+quotedKey+
+			", First: "+seenKeyVar+", Second: key})")
+		fmt.Fprintln(g.out, "      }")
+		fmt.Fprintln(g.out, "      "+seenKeyVar+" = key")
+		fmt.Fprintln(g.out, "      if win, ok := in.(yamly.WarningsDecoder); ok && "+keyMatch+" != "+
+			strconv.Quote(key.Match)+" {")
+		fmt.Fprintln(g.out, "        win.AddWarning(&yamly.DeprecatedKeyWarning{Key: "+quotedKey+", Alias: key})")
+		fmt.Fprintln(g.out, "      }")
+	}
+	fmt.Fprintln(g.out, "    }")
+}
+
+// keyLabels returns quoted matches of the key and its aliases.
+func keyLabels(key yamltag.FieldKey) []string {
+	labels := []string{strconv.Quote(key.Match)}
+	for _, aliasMatch := range key.AliasMatches {
+		labels = append(labels, strconv.Quote(aliasMatch))
+	}
+	return labels
+}
+
+// generateRemainDecoder generates decoding of the value with unknown key into remain field.
+func (g *Generator) generateRemainDecoder(remain reflect.StructField, indent int) error {
+	whitespace := strings.Repeat(" ", indent)
+	outArg := "out." + remain.Name
+	valueVar := g.generateVarName("Value")
+	fmt.Fprintln(g.out, whitespace+"if "+outArg+" == nil {")
+	fmt.Fprintln(g.out, whitespace+"  "+outArg+" = make("+g.extractTypeName(remain.Type)+")")
+	fmt.Fprintln(g.out, whitespace+"}")
+	fmt.Fprintln(g.out, whitespace+"var "+valueVar+" "+g.extractTypeName(remain.Type.Elem()))
+	if err := g.generateDecoderBody(remain.Type.Elem(), valueVar, parseTags(remain.Tag), indent, true); err != nil {
+		return err
+	}
+	fmt.Fprintln(g.out, whitespace+outArg+"["+g.extractTypeName(remain.Type.Key())+"(key)] = "+valueVar)
+	return nil
+}
+
+// generateRemainNullDecoder generates storing of zero value into remain field for unknown key with null value.
+func (g *Generator) generateRemainNullDecoder(remain reflect.StructField, keys []yamltag.FieldKey, matchExpr string) {
+	var labels []string
+	for _, key := range keys {
+		labels = append(labels, keyLabels(key)...)
+	}
+
+	whitespace := "      "
+	if len(labels) > 0 {
+		fmt.Fprintln(g.out, "      switch "+matchExpr+" {")
+		fmt.Fprintln(g.out, "      case "+strings.Join(labels, ", ")+":")
+		fmt.Fprintln(g.out, "      default:")
+		whitespace = "        "
 	}
-	name := f.Name
-	if tags.name != "" {
-		name = tags.name
+	outArg := "out." + remain.Name
+	fmt.Fprintln(g.out, whitespace+"if "+outArg+" == nil {")
+	fmt.Fprintln(g.out, whitespace+"  "+outArg+" = make("+g.extractTypeName(remain.Type)+")")
+	fmt.Fprintln(g.out, whitespace+"}")
+	fmt.Fprintln(g.out, whitespace+outArg+"["+g.extractTypeName(remain.Type.Key())+"(key)] = "+
+		"*new("+g.extractTypeName(remain.Type.Elem())+")")
+	if len(labels) > 0 {
+		fmt.Fprintln(g.out, "      }")
 	}
+}
 
-	fmt.Fprintln(g.out, "    case \""+name+"\":")
-	return g.generateDecoderBody(f.Type, "out."+f.Name, tags, 6, true)
+// keyNormalizationExpr returns Go expression of given key normalization.
+func keyNormalizationExpr(n yamly.KeyNormalization) string {
+	var flags []string
+	if n&yamly.KeyCaseInsensitive != 0 {
+		flags = append(flags, "yamly.KeyCaseInsensitive")
+	}
+	if n&yamly.KeyIgnoreSeparators != 0 {
+		flags = append(flags, "yamly.KeyIgnoreSeparators")
+	}
+	return strings.Join(flags, "|")
 }
 
 func (g *Generator) generateDecoderBody(
@@ -138,8 +262,25 @@ func (g *Generator) generateDecoderBody(
 	indent int,
 	complexTypeElem bool,
 ) error {
+	if c, ok, err := g.lookupCodec(t, tags); err != nil {
+		return err
+	} else if ok {
+		ptr := "&" + outArg
+		if strings.HasPrefix(outArg, "*") {
+			ptr = outArg[1:]
+		}
+		fmt.Fprintln(g.out, strings.Repeat(" ", indent)+g.funcName(c.decode)+"(in, "+ptr+")")
+		return nil
+	}
+	if u, ok := g.unions[t]; ok {
+		return g.generateUnionDecoder(u, outArg, indent)
+	}
+
 	var finishingText string
-	if t != g.currentType {
+	// standard library types have registered codecs instead of their interfaces implementations
+	// (e.g. time.Time implements encoding.TextMarshaler), so field tags can change their representation.
+	// Interfaces are checked while generating their body.
+	if _, std := lookupStdCodec(t); t != g.currentType && !std && t.Kind() != reflect.Interface {
 		whitespace := strings.Repeat(" ", indent)
 
 		unmarshalIface := reflect.TypeOf((*yamly.UnmarshalerYamly)(nil)).Elem()
@@ -184,8 +325,8 @@ func (g *Generator) generateDecoderBodyWithoutCheck(
 	complexTypeElem bool,
 ) error {
 	whitespace := strings.Repeat(" ", indent)
-	if dec := customDecoders[t.String()]; dec != "" {
-		fmt.Fprintln(g.out, whitespace+outArg+" = "+dec)
+	if c, ok := lookupStdCodec(t); ok {
+		fmt.Fprintln(g.out, whitespace+outArg+" = "+c.decode(g, tags))
 		return nil
 	} else if dec := basicDecoders[t.Kind()]; dec != "" {
 		fmt.Fprintln(g.out, whitespace+outArg+" = "+g.extractTypeName(t)+"("+dec+")")
@@ -244,6 +385,11 @@ func (g *Generator) generateDecoderBodyWithoutCheck(
 			fmt.Fprintln(g.out, whitespace+"}")
 		}
 	case reflect.Struct:
+		if g.usesReflectCodec(t) {
+			g.generateReflectDecoder(outArg, indent)
+			return nil
+		}
+
 		dec := g.decoderFunctionName(t)
 		g.addType(t)
 
@@ -275,7 +421,7 @@ func (g *Generator) generateDecoderBodyWithoutCheck(
 		fmt.Fprintln(g.out, whitespace+"  "+outArg+" = nil")
 		fmt.Fprintln(g.out, whitespace+"} else {")
 		fmt.Fprintln(g.out, whitespace+"  "+mapStateVar+" := in.Mapping()")
-		if g.omitempty || tags.omitempty {
+		if g.omitempty || tags.Omitempty {
 			fmt.Fprintln(g.out, whitespace+"  if "+mapStateVar+".Size() == 0 {")
 			fmt.Fprintln(g.out, "    "+outArg+" = nil")
 			fmt.Fprintln(g.out, "  } else {")
@@ -307,7 +453,7 @@ func (g *Generator) generateDecoderBodyWithoutCheck(
 	case reflect.Interface:
 		if t.NumMethod() > 0 {
 			if implementsUnmarshalerYamly(t) {
-				fmt.Fprintln(g.out, whitespace+"_ = "+outArg+".UnmarshalYamly(in)")
+				fmt.Fprintln(g.out, whitespace+outArg+".UnmarshalYamly(in)")
 			} else if implResult, err := g.engineGen.UnmarshalersImplementationCheck(g.out, t, outArg, indent); err != nil {
 				return err
 			} else {
@@ -322,7 +468,7 @@ func (g *Generator) generateDecoderBodyWithoutCheck(
 			}
 		} else {
 			fmt.Fprintln(g.out, whitespace+"if m, ok := "+outArg+".(yamly.UnmarshalerYamly); ok {")
-			fmt.Fprintln(g.out, whitespace+"  in.AddError(m.UnmarshalYamly(in))")
+			fmt.Fprintln(g.out, whitespace+"  m.UnmarshalYamly(in)")
 			fmt.Fprintln(g.out, whitespace+"} else {")
 			if err := g.engineGen.GenerateUnmarshalEmptyInterfaceAssertions(g.out, outArg, indent+indentDelta); err != nil {
 				return err
@@ -330,11 +476,24 @@ func (g *Generator) generateDecoderBodyWithoutCheck(
 			fmt.Fprintln(g.out, whitespace+"}")
 		}
 	default:
-		return fmt.Errorf("can't decode type %s", t)
+		if !g.reflectFallback {
+			return fmt.Errorf("can't decode type %s", t)
+		}
+		g.generateReflectDecoder(outArg, indent)
 	}
 	return nil
 }
 
+func (g *Generator) generateReflectDecoder(outArg string, indent int) {
+	whitespace := strings.Repeat(" ", indent)
+	if len(outArg) > 0 && outArg[0] == '*' {
+		outArg = outArg[1:]
+	} else {
+		outArg = "&" + outArg
+	}
+	fmt.Fprintln(g.out, whitespace+g.pkgAlias(pkgReflectCodec)+".Decode(in, "+outArg+g.reflectCodecArgs()+")")
+}
+
 func implementsUnmarshalerYamly(t reflect.Type) bool {
 	return t.Implements(reflect.TypeOf((*yamly.UnmarshalerYamly)(nil)).Elem())
 }
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
 )
 
 var basicEncoderFormatStrings = map[reflect.Kind]string{
@@ -26,8 +27,12 @@ var basicEncoderFormatStrings = map[reflect.Kind]string{
 	reflect.Float64: "out.InsertFloat(float64(%v))",
 }
 
-var customEncoderFormatStrings = map[string]string{
-	"time.Time": "out.InsertTimestamp(time.Time(%v))",
+var styleNames = map[yamly.Style]string{
+	yamly.FlowStyle:         "yamly.FlowStyle",
+	yamly.LiteralStyle:      "yamly.LiteralStyle",
+	yamly.FoldedStyle:       "yamly.FoldedStyle",
+	yamly.SingleQuotedStyle: "yamly.SingleQuotedStyle",
+	yamly.DoubleQuotedStyle: "yamly.DoubleQuotedStyle",
 }
 
 func (g *Generator) generateMarshaler(t reflect.Type) error {
@@ -93,12 +98,27 @@ func (g *Generator) generateStructEncoder(t reflect.Type) error {
 		return fmt.Errorf("cannot generate encoder for %s: %w", t, err)
 	}
 
+	remain, hasRemain, err := yamltag.RemainField(t)
+	if err != nil {
+		return fmt.Errorf("cannot generate encoder for %s: %w", t, err)
+	}
+
 	fmt.Fprintln(g.out, "  out.StartMapping()")
 	for _, f := range fs {
 		if err := g.generateStructFieldEncoder(f); err != nil {
 			return err
 		}
 	}
+	if hasRemain {
+		// entries with unknown keys are written after known fields
+		keyVar, valueVar := g.generateVarName("Key"), g.generateVarName("Value")
+		fmt.Fprintln(g.out, "  for "+keyVar+", "+valueVar+" := range in."+remain.Name+" {")
+		fmt.Fprintln(g.out, "    out.InsertString(string("+keyVar+"))")
+		if err := g.generateEncoderBody(remain.Type.Elem(), valueVar, parseTags(remain.Tag), 4, true); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, "  }")
+	}
 	fmt.Fprintln(g.out, "  out.EndMapping()")
 	fmt.Fprintln(g.out, "}")
 	return nil
@@ -107,14 +127,11 @@ func (g *Generator) generateStructEncoder(t reflect.Type) error {
 func (g *Generator) generateStructFieldEncoder(f reflect.StructField) error {
 	tags := parseTags(f.Tag)
 
-	if tags.omitField {
+	if tags.OmitField {
 		return nil
 	}
-	name := f.Name
-	if tags.name != "" {
-		name = tags.name
-	}
-	canBeNull := !(g.omitempty || tags.omitempty)
+	name := g.fieldNaming.Key(f, tags)
+	canBeNull := !(g.omitempty || tags.Omitempty)
 
 	if !canBeNull {
 		fmt.Fprintln(g.out, "  if "+g.generateNotEmptyCheck(f.Type, "in."+f.Name)+" {")
@@ -158,17 +175,44 @@ func (g *Generator) generateEncoderBody(
 	indent int,
 	canBeNull bool,
 ) error {
+	if style := styleNames[tags.Style]; style != "" && t.Kind() != reflect.Pointer {
+		// pointers pass the style to their elements
+		whitespace := strings.Repeat(" ", indent)
+		fmt.Fprintln(g.out, whitespace+"if sout, ok := out.(yamly.StyledInserter); ok {")
+		fmt.Fprintln(g.out, whitespace+"  sout.SetStyle("+style+")")
+		fmt.Fprintln(g.out, whitespace+"}")
+	}
+
+	if c, ok, err := g.lookupCodec(t, tags); err != nil {
+		return err
+	} else if ok {
+		fmt.Fprintln(g.out, strings.Repeat(" ", indent)+g.funcName(c.encode)+"(out, "+inArg+")")
+		return nil
+	}
+	if u, ok := g.unions[t]; ok {
+		return g.generateUnionEncoder(u, inArg, indent)
+	}
+
 	var finishingText string
-	if t != g.currentType {
+	// standard library types have registered codecs instead of their interfaces implementations
+	// (e.g. time.Time implements encoding.TextMarshaler), so field tags can change their representation.
+	// Interfaces are checked while generating their body.
+	if _, std := lookupStdCodec(t); t != g.currentType && !std && t.Kind() != reflect.Interface {
 		whitespace := strings.Repeat(" ", indent)
 
+		// dereferenced pointer must be parenthesized to call methods
+		receiver := inArg
+		if strings.HasPrefix(inArg, "*") {
+			receiver = "(" + inArg + ")"
+		}
+
 		marshalIface := reflect.TypeOf((*yamly.MarshalerYamly)(nil)).Elem()
 		if reflect.PtrTo(t).Implements(marshalIface) {
-			fmt.Fprintln(g.out, whitespace+inArg+".MarshalYamly(out)")
+			fmt.Fprintln(g.out, whitespace+receiver+".MarshalYamly(out)")
 			return nil
 		}
 
-		implResult, err := g.engineGen.MarshalersImplementationCheck(g.out, t, inArg, indent)
+		implResult, err := g.engineGen.MarshalersImplementationCheck(g.out, t, receiver, indent)
 		if err != nil {
 			return err
 		}
@@ -183,7 +227,7 @@ func (g *Generator) generateEncoderBody(
 
 		marshalIface = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
 		if reflect.PtrTo(t).Implements(marshalIface) {
-			fmt.Fprintln(g.out, whitespace+"out.InsertRawText("+inArg+".MarshalText())")
+			fmt.Fprintln(g.out, whitespace+"out.InsertRawText("+receiver+".MarshalText())")
 			return nil
 		}
 	}
@@ -205,11 +249,11 @@ func (g *Generator) generateEncoderBodyWithoutCheck(
 ) error {
 	whitespace := strings.Repeat(" ", indent)
 
-	if enc := basicEncoderFormatStrings[t.Kind()]; enc != "" {
-		fmt.Fprintf(g.out, whitespace+enc+"\n", inArg)
+	// standard library types are checked first, because some of them have basic kind (e.g. time.Duration)
+	if c, ok := lookupStdCodec(t); ok {
+		fmt.Fprintf(g.out, whitespace+c.encode(g, tags)+"\n", inArg)
 		return nil
-	} else if enc := customEncoderFormatStrings[t.String()]; enc != "" {
-		g.imports[t.PkgPath()] = t.Name() // assume it is a standard library
+	} else if enc := basicEncoderFormatStrings[t.Kind()]; enc != "" {
 		fmt.Fprintf(g.out, whitespace+enc+"\n", inArg)
 		return nil
 	}
@@ -259,6 +303,11 @@ func (g *Generator) generateEncoderBodyWithoutCheck(
 			fmt.Fprintln(g.out, whitespace+"out.EndSequence()")
 		}
 	case reflect.Struct:
+		if g.usesReflectCodec(t) {
+			g.generateReflectEncoder(inArg, indent)
+			return nil
+		}
+
 		enc := g.encoderFunctionName(t)
 		g.addType(t)
 		if g.encodePointerReceiver {
@@ -301,7 +350,9 @@ func (g *Generator) generateEncoderBodyWithoutCheck(
 		fmt.Fprintln(g.out, whitespace+"  out.StartMapping()")
 		fmt.Fprintln(g.out, whitespace+"  for "+keyVar+", "+valueVar+" := range "+inArg+" {")
 
-		if err := g.generateEncoderBody(key, keyVar, tags, indent+4, true); err != nil {
+		keyTags := tags
+		keyTags.Style = yamly.DefaultStyle
+		if err := g.generateEncoderBody(key, keyVar, keyTags, indent+4, true); err != nil {
 			return err
 		}
 
@@ -315,7 +366,7 @@ func (g *Generator) generateEncoderBodyWithoutCheck(
 	case reflect.Interface:
 		if t.NumMethod() > 0 {
 			if implementMarshalerYamly(t) {
-				fmt.Fprintln(g.out, whitespace+"_ = "+inArg+".MarshalYamly(out)")
+				fmt.Fprintln(g.out, whitespace+inArg+".MarshalYamly(out)")
 			} else if implResult, err := g.engineGen.MarshalersImplementationCheck(g.out, t, inArg, indent); err != nil {
 				return err
 			} else {
@@ -329,7 +380,7 @@ func (g *Generator) generateEncoderBodyWithoutCheck(
 				}
 			}
 		} else {
-			fmt.Fprintln(g.out, whitespace+"if m, ok := "+inArg+".(yamly.MarshalerYamly) {")
+			fmt.Fprintln(g.out, whitespace+"if m, ok := "+inArg+".(yamly.MarshalerYamly); ok {")
 			fmt.Fprintln(g.out, whitespace+"  m.MarshalYamly(out)")
 			fmt.Fprintln(g.out, whitespace+"} else {")
 			if err := g.engineGen.GenerateMarshalEmptyInterfaceAssertions(g.out, inArg, indent+2); err != nil {
@@ -338,11 +389,19 @@ func (g *Generator) generateEncoderBodyWithoutCheck(
 			fmt.Fprintln(g.out, whitespace+"}")
 		}
 	default:
-		return fmt.Errorf("can't encode type %s", t)
+		if !g.reflectFallback {
+			return fmt.Errorf("can't encode type %s", t)
+		}
+		g.generateReflectEncoder(inArg, indent)
 	}
 	return nil
 }
 
+func (g *Generator) generateReflectEncoder(inArg string, indent int) {
+	whitespace := strings.Repeat(" ", indent)
+	fmt.Fprintln(g.out, whitespace+g.pkgAlias(pkgReflectCodec)+".Encode(out, "+inArg+g.reflectCodecArgs()+")")
+}
+
 func implementMarshalerYamly(t reflect.Type) bool {
 	return t.Implements(reflect.TypeOf((*yamly.MarshalerYamly)(nil)).Elem())
 }
@@ -11,10 +11,14 @@ import (
 	"strconv"
 	"strings"
 	"unicode"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
 )
 
 const (
-	pkgYamly = "github.com/KSpaceer/yamly"
+	pkgYamly        = "github.com/KSpaceer/yamly"
+	pkgReflectCodec = "github.com/KSpaceer/yamly/reflectcodec"
 
 	indentDelta = 2
 )
@@ -32,6 +36,18 @@ type Generator struct {
 	disallowUnknownFields bool
 	encodePointerReceiver bool
 	inlineEmbedded        bool
+	reflectFallback       bool
+	fieldNaming           yamltag.FieldNaming
+	caseInsensitiveKeys   bool
+	ignoreKeySeparators   bool
+	durationUnit          string
+	timeFormat            string
+	timeNanoseconds       bool
+	codecSpecs            []string
+	codecs                map[string]customCodec
+	unions                map[reflect.Type]union
+	// discriminatorKeys are the keys of unions the struct is variant of
+	discriminatorKeys map[reflect.Type][]string
 
 	engineGen EngineGenerator
 
@@ -55,6 +71,9 @@ func New(outputFile string) *Generator {
 		imports:        map[string]string{pkgYamly: "yamly"},
 		generatedTypes: make(map[reflect.Type]bool),
 		funcNames:      make(map[string]reflect.Type),
+		unions:         make(map[reflect.Type]union),
+
+		timeNanoseconds: true,
 	}
 }
 
@@ -95,6 +114,67 @@ func (g *Generator) SetInlineEmbedded(inlineEmbedded bool) {
 	g.inlineEmbedded = inlineEmbedded
 }
 
+// SetReflectFallback makes generated code use reflection-based codec from reflectcodec package
+// for structs from other packages and types which can't be processed by generated code.
+func (g *Generator) SetReflectFallback(reflectFallback bool) {
+	g.reflectFallback = reflectFallback
+}
+
+// SetFieldNaming sets a strategy of naming mapping keys for fields without explicit name in "yaml" tag.
+// Available strategies are "camel", "snake", "kebab", "lower" and "as-is" (default).
+func (g *Generator) SetFieldNaming(naming string) {
+	g.fieldNaming = yamltag.FieldNaming(naming)
+}
+
+// SetCaseInsensitiveKeys makes generated struct decoders match mapping keys regardless of letter case.
+// Struct can override this setting with directive in "yamly" tag of blank field.
+func (g *Generator) SetCaseInsensitiveKeys(caseInsensitive bool) {
+	g.caseInsensitiveKeys = caseInsensitive
+}
+
+// SetIgnoreKeySeparators makes generated struct decoders match mapping keys ignoring '_' and '-' separators.
+// Struct can override this setting with directive in "yamly" tag of blank field.
+func (g *Generator) SetIgnoreKeySeparators(ignoreSeparators bool) {
+	g.ignoreKeySeparators = ignoreSeparators
+}
+
+// SetDurationUnit sets the unit of bare integers decoded into time.Duration, e.g. "s" or "ms".
+// Available units are "ns" (default), "us", "ms", "s", "m" and "h".
+func (g *Generator) SetDurationUnit(unit string) {
+	g.durationUnit = unit
+}
+
+// SetTimeFormat sets the default layout (see time.Format) of time.Time values.
+// Fields can override it with "timeformat", "unix" and "unixmilli" tag options.
+func (g *Generator) SetTimeFormat(layout string) {
+	g.timeFormat = layout
+}
+
+// SetTimeNanoseconds defines if generated code keeps nanoseconds of time.Time values without explicit layout,
+// encoding them in RFC 3339 format with fractional seconds (default). Otherwise sub-second precision is dropped.
+func (g *Generator) SetTimeNanoseconds(keepNanoseconds bool) {
+	g.timeNanoseconds = keepNanoseconds
+}
+
+// AddCodec registers functions processing values of some type, given as "pkg.Type=pkg.DecodeFn,pkg.EncodeFn",
+// where "pkg" is package import path. Decoding function has signature func(yamly.Decoder, *T)
+// and encoding function has signature func(yamly.Inserter, T). Names without package path
+// refer to the package of generated code.
+func (g *Generator) AddCodec(spec string) {
+	g.codecSpecs = append(g.codecSpecs, spec)
+}
+
+func (g *Generator) keyNormalization() yamly.KeyNormalization {
+	var n yamly.KeyNormalization
+	if g.caseInsensitiveKeys {
+		n |= yamly.KeyCaseInsensitive
+	}
+	if g.ignoreKeySeparators {
+		n |= yamly.KeyIgnoreSeparators
+	}
+	return n
+}
+
 // AddType sets a target type for which methods are generated.
 func (g *Generator) AddType(v any) {
 	t := reflect.TypeOf(v)
@@ -119,6 +199,19 @@ func (g *Generator) addType(t reflect.Type) {
 
 // Generate generates code for marshalling methods, writing it into given io.Writer.
 func (g *Generator) Generate(w io.Writer) error {
+	if err := g.fieldNaming.Validate(); err != nil {
+		return err
+	}
+	if err := validateDurationUnit(g.durationUnit); err != nil {
+		return err
+	}
+	if err := g.parseCodecs(); err != nil {
+		return err
+	}
+	if err := g.validateUnions(); err != nil {
+		return err
+	}
+
 	g.out = &bytes.Buffer{}
 
 	for len(g.pendingTypes) > 0 {
@@ -385,70 +478,51 @@ func joinFunctionNameParts(keepFirst bool, parts ...string) string {
 	return buf.String()
 }
 
-func (g *Generator) getStructFields(t reflect.Type) ([]reflect.StructField, error) {
-	if t.Kind() != reflect.Struct {
-		return nil, fmt.Errorf("expected struct, but got %s", t)
-	}
-
-	var (
-		embeddedFields []reflect.StructField
-		fields         []reflect.StructField
-	)
-
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		tags := parseTags(f.Tag)
-		if !(f.Anonymous && g.inlineEmbedded && tags.name == "") && !tags.inline {
-			continue
-		}
-
-		t := f.Type
-		if t.Kind() == reflect.Pointer {
-			t = t.Elem()
-		}
+// usesReflectCodec reports whether struct type t is processed by reflectcodec package
+// instead of generated functions.
+func (g *Generator) usesReflectCodec(t reflect.Type) bool {
+	return g.reflectFallback && t.PkgPath() != "" && t.PkgPath() != g.pkgPath
+}
 
-		if t.Kind() == reflect.Struct {
-			fs, err := g.getStructFields(t)
-			if err != nil {
-				return nil, fmt.Errorf("error processing embedded field: %w", err)
-			}
-			embeddedFields = mergeStructFields(embeddedFields, fs)
-		} else if (t.Kind() >= reflect.Bool && t.Kind() <= reflect.Complex128) || t.Kind() == reflect.String {
-			// kind is basic
-			if strings.Contains(f.Name, ".") || unicode.IsUpper([]rune(f.Name)[0]) {
-				fields = append(fields, f)
-			}
-		}
+// reflectCodecArgs returns the reflectcodec options matching generator settings
+// as call arguments list, including leading comma.
+func (g *Generator) reflectCodecArgs() string {
+	alias := g.pkgAlias(pkgReflectCodec)
+	var args string
+	if g.omitempty {
+		args += ", " + alias + ".WithOmitempty()"
 	}
-
-	for i := 0; i < t.NumField(); i++ {
-		f := t.Field(i)
-		tags := parseTags(f.Tag)
-		if (f.Anonymous && g.inlineEmbedded && tags.name == "") || tags.inline {
-			continue
-		}
-
-		c := []rune(f.Name)[0]
-		if unicode.IsUpper(c) {
-			fields = append(fields, f)
-		}
+	if g.disallowUnknownFields {
+		args += ", " + alias + ".WithDisallowUnknownFields()"
 	}
-
-	return mergeStructFields(embeddedFields, fields), nil
-}
-
-func mergeStructFields(firstFields, secondFields []reflect.StructField) []reflect.StructField {
-	fields := make([]reflect.StructField, 0, len(firstFields))
-	used := make(map[string]bool)
-	for _, f := range secondFields {
-		used[f.Name] = true
-		fields = append(fields, f)
+	if g.inlineEmbedded {
+		args += ", " + alias + ".WithInlineEmbedded()"
+	}
+	if g.caseInsensitiveKeys {
+		args += ", " + alias + ".WithCaseInsensitiveKeys()"
+	}
+	if g.ignoreKeySeparators {
+		args += ", " + alias + ".WithIgnoreKeySeparators()"
+	}
+	if g.fieldNaming != "" && g.fieldNaming != yamltag.FieldNamingAsIs {
+		args += ", " + alias + ".WithFieldNaming(" + strconv.Quote(string(g.fieldNaming)) + ")"
 	}
+	if g.durationUnit != "" && g.durationUnit != "ns" {
+		args += ", " + alias + ".WithDurationUnit(" + g.durationUnitExpr() + ")"
+	}
+	if g.timeFormat != "" {
+		args += ", " + alias + ".WithTimeFormat(" + strconv.Quote(g.timeFormat) + ")"
+	}
+	if !g.timeNanoseconds {
+		args += ", " + alias + ".WithTimeNanoseconds(false)"
+	}
+	args += g.codecArgs(alias)
+	return args
+}
 
-	for _, f := range firstFields {
-		if !used[f.Name] {
-			fields = append(fields, f)
-		}
+func (g *Generator) getStructFields(t reflect.Type) ([]reflect.StructField, error) {
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected struct, but got %s", t)
 	}
-	return fields
+	return yamltag.Fields(t, g.inlineEmbedded), nil
 }
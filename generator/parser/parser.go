@@ -1,4 +1,4 @@
-// Package parser contains parser for package name and path.
+// Package parser contains parser for package name, path and yamly directives.
 package parser
 
 import (
@@ -6,16 +6,18 @@ import (
 	"go/parser"
 	"go/token"
 	"io/fs"
+	"sort"
 	"strings"
 )
 
-// Parser is used to parse package name and path from provided directory.
+// Parser is used to parse package name, path and tagged unions from provided directory.
 type Parser struct {
 	PkgName string
 	PkgPath string
+	Unions  []Union
 }
 
-// Parse parses files in dirPath and sets PkgName and PkgPath.
+// Parse parses files in dirPath and sets PkgName, PkgPath and Unions.
 func (p *Parser) Parse(dirPath string) error {
 	var err error
 	if p.PkgPath, err = findPkgPath(dirPath); err != nil {
@@ -27,14 +29,24 @@ func (p *Parser) Parse(dirPath string) error {
 		func(info fs.FileInfo) bool {
 			return !strings.HasSuffix(info.Name(), "_test.go")
 		},
-		parser.PackageClauseOnly,
+		parser.ParseComments,
 	)
 	if err != nil {
 		return err
 	}
 	for _, pkg := range packages {
 		ast.Walk((*pkgNameVisitor)(&p.PkgName), pkg)
+		for _, f := range pkg.Files {
+			unions, err := findUnions(f)
+			if err != nil {
+				return err
+			}
+			p.Unions = append(p.Unions, unions...)
+		}
 	}
+	sort.Slice(p.Unions, func(i, j int) bool {
+		return p.Unions[i].Interface < p.Unions[j].Interface
+	})
 	return nil
 }
 
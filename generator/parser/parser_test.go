@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"reflect"
 	"testing"
 
 	"github.com/KSpaceer/yamly/generator/parser"
@@ -49,3 +50,34 @@ func TestParser_Parse(t *testing.T) {
 		})
 	}
 }
+
+func TestParser_Unions(t *testing.T) {
+	t.Parallel()
+
+	p := parser.Parser{}
+	if err := p.Parse("testdata/unions"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []parser.Union{
+		{
+			Interface: "Shape",
+			Key:       "kind",
+			Variants: []parser.UnionVariant{
+				{Value: "circle", Type: "Circle", Pointer: true},
+				{Value: "square", Type: "Square"},
+			},
+		},
+		{
+			Interface: "Step",
+			Key:       "type",
+			Variants:  []parser.UnionVariant{{Value: "run", Type: "Run", Pointer: true}},
+		},
+	}
+	if !reflect.DeepEqual(expected, p.Unions) {
+		t.Errorf("wrong unions:\n\texpected: %+v\n\tgot: %+v", expected, p.Unions)
+	}
+
+	if err := (&parser.Parser{}).Parse("testdata/badunion"); err == nil {
+		t.Errorf("expected error for unexported variant type")
+	}
+}
@@ -0,0 +1,10 @@
+package badunion
+
+//yamly:union kind=circle:*circle
+type Shape interface {
+	Area() float64
+}
+
+type circle struct{ Radius float64 }
+
+func (c *circle) Area() float64 { return 3 * c.Radius * c.Radius }
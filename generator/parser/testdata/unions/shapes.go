@@ -0,0 +1,27 @@
+package unions
+
+// Shape is a geometric figure.
+//
+//yamly:union kind=circle:*Circle,square:Square
+type Shape interface {
+	Area() float64
+}
+
+type (
+	// Step is a pipeline step.
+	//
+	//yamly:union type=run:*Run
+	Step interface{ step() }
+
+	Run struct{ Command string }
+)
+
+type Circle struct{ Radius float64 }
+
+func (c *Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type Square struct{ Side float64 }
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+func (*Run) step() {}
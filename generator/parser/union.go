@@ -0,0 +1,115 @@
+package parser
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"strings"
+)
+
+const unionDirective = "//yamly:union "
+
+// Union is a tagged union declared with directive in doc comment of interface type, e.g.
+//
+//	//yamly:union kind=Circle:*Circle,Square:Square
+//	type Shape interface{ Area() float64 }
+//
+// The value of discriminator key ("kind") of YAML mapping selects the concrete type of interface value.
+type Union struct {
+	// Interface is the name of interface type.
+	Interface string
+	// Key is the discriminator key.
+	Key string
+	// Variants are the concrete types of the union in declaration order.
+	Variants []UnionVariant
+}
+
+// UnionVariant is a concrete type of tagged union.
+type UnionVariant struct {
+	// Value is the value of discriminator key selecting the variant.
+	Value string
+	// Type is the name of the variant type declared in the same package.
+	Type string
+	// Pointer shows if the variant is a pointer to Type.
+	Pointer bool
+}
+
+// findUnions returns tagged unions declared in file f.
+func findUnions(f *ast.File) ([]Union, error) {
+	var unions []Union
+	for _, decl := range f.Decls {
+		genDecl, ok := decl.(*ast.GenDecl)
+		if !ok || genDecl.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range genDecl.Specs {
+			typeSpec := spec.(*ast.TypeSpec) // nolint: forcetypeassert
+			doc := typeSpec.Doc
+			if doc == nil && len(genDecl.Specs) == 1 {
+				doc = genDecl.Doc
+			}
+			directive, ok := findUnionDirective(doc)
+			if !ok {
+				continue
+			}
+			if _, isInterface := typeSpec.Type.(*ast.InterfaceType); !isInterface {
+				return nil, fmt.Errorf("yamly:union directive of %s: type must be an interface", typeSpec.Name)
+			}
+			if !typeSpec.Name.IsExported() {
+				return nil, fmt.Errorf("yamly:union directive of %s: type must be exported", typeSpec.Name)
+			}
+			u, err := parseUnion(directive)
+			if err != nil {
+				return nil, fmt.Errorf("yamly:union directive of %s: %w", typeSpec.Name, err)
+			}
+			u.Interface = typeSpec.Name.Name
+			unions = append(unions, u)
+		}
+	}
+	return unions, nil
+}
+
+func findUnionDirective(doc *ast.CommentGroup) (string, bool) {
+	if doc == nil {
+		return "", false
+	}
+	// directives are not included into CommentGroup.Text, so comments are checked one by one
+	for _, c := range doc.List {
+		if directive, ok := strings.CutPrefix(c.Text, unionDirective); ok {
+			return strings.TrimSpace(directive), true
+		}
+	}
+	return "", false
+}
+
+// parseUnion parses directive like "kind=Circle:*Circle,Square:Square".
+func parseUnion(directive string) (Union, error) {
+	key, variants, ok := strings.Cut(directive, "=")
+	if !ok || key == "" || variants == "" {
+		return Union{}, fmt.Errorf("invalid directive %q: expected key=Value:Type,...", directive)
+	}
+
+	u := Union{Key: key}
+	values := make(map[string]bool)
+	types := make(map[string]bool)
+	for _, variant := range strings.Split(variants, ",") {
+		value, typeName, ok := strings.Cut(variant, ":")
+		if !ok || value == "" {
+			return Union{}, fmt.Errorf("invalid variant %q: expected Value:Type", variant)
+		}
+		v := UnionVariant{Value: value}
+		v.Type, v.Pointer = strings.CutPrefix(typeName, "*")
+		if !token.IsIdentifier(v.Type) || !token.IsExported(v.Type) {
+			return Union{}, fmt.Errorf("invalid variant %q: type must be exported type of the same package", variant)
+		}
+		if values[value] {
+			return Union{}, fmt.Errorf("duplicate variant value %q", value)
+		}
+		if types[typeName] {
+			return Union{}, fmt.Errorf("duplicate variant type %s", typeName)
+		}
+		values[value], types[typeName] = true, true
+		u.Variants = append(u.Variants, v)
+	}
+	return u, nil
+}
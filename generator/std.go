@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// stdCodec describes generated code for well-known standard library type.
+type stdCodec struct {
+	// decode returns expression decoding the value from "in" decoder
+	decode func(g *Generator, tags fieldTags) string
+	// encode returns a format string of statement inserting the value given as argument into "out" inserter
+	encode func(g *Generator, tags fieldTags) string
+}
+
+// stdCodecs is a registry of well-known standard library types processed by generated code,
+// using package path and type name as key. Types implementing encoding.TextMarshaler
+// and encoding.TextUnmarshaler (e.g. net.IP, netip.Addr or *regexp.Regexp) are processed as text
+// and don't need to be registered.
+var stdCodecs = map[string]stdCodec{
+	"time.Time": {
+		decode: (*Generator).timeDecoder,
+		encode: (*Generator).timeEncoder,
+	},
+	"time.Duration": {
+		decode: func(g *Generator, _ fieldTags) string {
+			return "yamly.DecodeDuration(in, " + g.durationUnitExpr() + ")"
+		},
+		encode: func(*Generator, fieldTags) string { return "out.InsertString((%v).String())" },
+	},
+	"net/url.URL": {
+		decode: func(*Generator, fieldTags) string { return "yamly.DecodeURL(in)" },
+		encode: func(*Generator, fieldTags) string { return "out.InsertString((%v).String())" },
+	},
+}
+
+func lookupStdCodec(t reflect.Type) (stdCodec, bool) {
+	if t.PkgPath() == "" {
+		return stdCodec{}, false
+	}
+	c, ok := stdCodecs[t.PkgPath()+"."+t.Name()]
+	return c, ok
+}
+
+// durationUnits maps supported units of bare integer durations to the names of time package constants.
+var durationUnits = map[string]string{
+	"ns": "Nanosecond",
+	"us": "Microsecond",
+	"µs": "Microsecond",
+	"ms": "Millisecond",
+	"s":  "Second",
+	"m":  "Minute",
+	"h":  "Hour",
+}
+
+// validateDurationUnit returns an error if unit of bare integer durations is unknown.
+// Empty unit means nanoseconds.
+func validateDurationUnit(unit string) error {
+	if _, ok := durationUnits[unit]; unit != "" && !ok {
+		return fmt.Errorf("unknown duration unit %q: expected one of ns, us, ms, s, m or h", unit)
+	}
+	return nil
+}
+
+func (g *Generator) durationUnitExpr() string {
+	name, ok := durationUnits[g.durationUnit]
+	if !ok {
+		name = "Nanosecond"
+	}
+	return g.pkgAlias("time") + "." + name
+}
+
+// unixUnitNames maps units of integer Unix time to the names of time package constants.
+var unixUnitNames = map[time.Duration]string{
+	time.Second:      "Second",
+	time.Millisecond: "Millisecond",
+}
+
+func (g *Generator) timeDecoder(tags fieldTags) string {
+	if name, ok := unixUnitNames[tags.UnixUnit]; ok {
+		return "yamly.DecodeUnixTime(in, " + g.pkgAlias("time") + "." + name + ")"
+	}
+	if layout := g.timeLayout(tags); layout != "" {
+		return "yamly.DecodeTime(in, " + strconv.Quote(layout) + ")"
+	}
+	return "in.Timestamp()"
+}
+
+func (g *Generator) timeEncoder(tags fieldTags) string {
+	switch tags.UnixUnit {
+	case time.Second:
+		return "out.InsertInteger((%v).Unix())"
+	case time.Millisecond:
+		return "out.InsertInteger((%v).UnixMilli())"
+	}
+	if layout := g.timeLayout(tags); layout != "" {
+		return "out.InsertString((%v).Format(" + strings.ReplaceAll(strconv.Quote(layout), "%", "%%") + "))"
+	}
+	if g.timeNanoseconds {
+		return "out.InsertString((%v).Format(" + g.pkgAlias("time") + ".RFC3339Nano))"
+	}
+	return "out.InsertTimestamp(%v)"
+}
+
+// timeLayout returns the layout of time.Time value: the layout from field tags
+// or the default one set for generator.
+func (g *Generator) timeLayout(tags fieldTags) string {
+	if tags.TimeFormat != "" {
+		return tags.TimeFormat
+	}
+	return g.timeFormat
+}
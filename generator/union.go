@@ -0,0 +1,180 @@
+package generator
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
+)
+
+// UnionVariant is a concrete type of tagged union.
+type UnionVariant struct {
+	// Value is the value of discriminator key selecting the variant.
+	Value string
+	// Type is the concrete type, either struct or pointer to struct.
+	Type reflect.Type
+}
+
+// union is a tagged union registered with AddUnion.
+type union struct {
+	key      string
+	variants []UnionVariant
+}
+
+// AddUnion declares interface type iface as tagged union: the value of discriminator key of YAML mapping
+// selects the variant the mapping is decoded into. Generated encoders write the discriminator key
+// as the first entry of variant mapping.
+func (g *Generator) AddUnion(iface reflect.Type, key string, variants []UnionVariant) {
+	g.unions[iface] = union{key: key, variants: variants}
+}
+
+// validateUnions checks registered unions and collects discriminator keys of variant structs.
+func (g *Generator) validateUnions() error {
+	g.discriminatorKeys = make(map[reflect.Type][]string)
+	for iface, u := range g.unions {
+		if iface.Kind() != reflect.Interface {
+			return fmt.Errorf("union type %s must be an interface", iface)
+		}
+		if u.key == "" || len(u.variants) == 0 {
+			return fmt.Errorf("union %s must have discriminator key and variants", iface)
+		}
+		for _, v := range u.variants {
+			if !v.Type.Implements(iface) {
+				return fmt.Errorf("variant %s of union %s does not implement it", v.Type, iface)
+			}
+			structType := v.Type
+			if structType.Kind() == reflect.Pointer {
+				structType = structType.Elem()
+			}
+			if structType.Kind() != reflect.Struct {
+				return fmt.Errorf("variant %s of union %s must be a struct or pointer to struct", v.Type, iface)
+			}
+			if f, ok := g.discriminatorField(v.Type, u.key); ok && f.Field.Type.Kind() != reflect.String {
+				return fmt.Errorf("discriminator field %s of variant %s of union %s must be a string",
+					f.Field.Name, v.Type, iface)
+			}
+			g.discriminatorKeys[structType] = append(g.discriminatorKeys[structType], u.key)
+		}
+	}
+	return nil
+}
+
+// discriminatorField returns the field of struct variant t matching discriminator key
+// like struct decoder matches it. In this case discriminator is processed as the field.
+func (g *Generator) discriminatorField(t reflect.Type, key string) (yamltag.FieldKey, bool) {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	keys, normalization, err := yamltag.Keys(t, g.inlineEmbedded, g.fieldNaming, g.keyNormalization())
+	if err != nil {
+		return yamltag.FieldKey{}, false
+	}
+	match := yamly.NormalizeKey(key, normalization)
+	for _, k := range keys {
+		if k.Match == match {
+			return k, true
+		}
+	}
+	return yamltag.FieldKey{}, false
+}
+
+// generateDiscriminatorSkips generates skipping of discriminator keys of unions the struct t is variant of.
+// labels are the keys of struct fields which are already matched.
+func (g *Generator) generateDiscriminatorSkips(
+	t reflect.Type,
+	labels map[string]bool,
+	normalization yamly.KeyNormalization,
+) {
+	for _, key := range g.discriminatorKeys[t] {
+		match := yamly.NormalizeKey(key, normalization)
+		if labels[match] {
+			continue
+		}
+		labels[match] = true
+		fmt.Fprintln(g.out, "    case "+strconv.Quote(match)+":")
+		fmt.Fprintln(g.out, "      in.Skip()")
+	}
+}
+
+// generateUnionDecoder generates decoding of union value selecting the variant by discriminator key.
+func (g *Generator) generateUnionDecoder(u union, outArg string, indent int) error {
+	whitespace := strings.Repeat(" ", indent)
+	quotedKey := strconv.Quote(u.key)
+	fmt.Fprintln(g.out, whitespace+"if in.TryNull() {")
+	fmt.Fprintln(g.out, whitespace+"  "+outArg+" = nil")
+	fmt.Fprintln(g.out, whitespace+"} else {")
+	normalization := keyNormalizationExpr(g.keyNormalization())
+	if normalization == "" {
+		normalization = "0"
+	}
+	fmt.Fprintln(g.out, whitespace+"  yamly.DecodeUnion(in, "+quotedKey+", "+normalization+
+		", func(variant string, in yamly.Decoder) {")
+	fmt.Fprintln(g.out, whitespace+"    switch variant {")
+	for _, v := range u.variants {
+		vVar := g.generateVarName()
+		fmt.Fprintln(g.out, whitespace+"    case "+strconv.Quote(v.Value)+":")
+		fmt.Fprintln(g.out, whitespace+"      var "+vVar+" "+g.extractTypeName(v.Type))
+		if err := g.generateDecoderBody(v.Type, vVar, fieldTags{}, indent+6, true); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, whitespace+"      "+outArg+" = "+vVar)
+	}
+	fmt.Fprintln(g.out, whitespace+"    default:")
+	fmt.Fprintln(g.out, whitespace+"      in.AddError(&yamly.UnknownVariantError{Key: "+quotedKey+", Value: variant})")
+	fmt.Fprintln(g.out, whitespace+"    }")
+	fmt.Fprintln(g.out, whitespace+"  })")
+	fmt.Fprintln(g.out, whitespace+"}")
+	return nil
+}
+
+// generateDiscriminatorFieldSet generates setting of the discriminator field of variant value
+// to the registered value. Pointer variants are copied to keep the original value unchanged.
+func (g *Generator) generateDiscriminatorFieldSet(v UnionVariant, f yamltag.FieldKey, vVar string, indent int) {
+	whitespace := strings.Repeat(" ", indent)
+	value := strconv.Quote(v.Value)
+	if v.Type.Kind() != reflect.Pointer {
+		fmt.Fprintln(g.out, whitespace+vVar+"."+f.Field.Name+" = "+value)
+		return
+	}
+	copyVar := g.generateVarName("Copy")
+	fmt.Fprintln(g.out, whitespace+"if "+vVar+" != nil {")
+	fmt.Fprintln(g.out, whitespace+"  "+copyVar+" := *"+vVar)
+	fmt.Fprintln(g.out, whitespace+"  "+copyVar+"."+f.Field.Name+" = "+value)
+	fmt.Fprintln(g.out, whitespace+"  "+vVar+" = &"+copyVar)
+	fmt.Fprintln(g.out, whitespace+"}")
+}
+
+// generateUnionEncoder generates encoding of union value with type switch over the variants.
+func (g *Generator) generateUnionEncoder(u union, inArg string, indent int) error {
+	whitespace := strings.Repeat(" ", indent)
+	quotedKey := strconv.Quote(u.key)
+	vVar := g.generateVarName()
+	fmt.Fprintln(g.out, whitespace+"switch "+vVar+" := ("+inArg+").(type) {")
+	fmt.Fprintln(g.out, whitespace+"case nil:")
+	fmt.Fprintln(g.out, whitespace+"  out.InsertNull()")
+	for _, v := range u.variants {
+		fmt.Fprintln(g.out, whitespace+"case "+g.extractTypeName(v.Type)+":")
+		if f, ok := g.discriminatorField(v.Type, u.key); ok {
+			// discriminator is written by the variant itself, so the field is set to the registered value
+			g.generateDiscriminatorFieldSet(v, f, vVar, indent+2)
+			if err := g.generateEncoderBody(v.Type, vVar, fieldTags{}, indent+2, true); err != nil {
+				return err
+			}
+			continue
+		}
+		fmt.Fprintln(g.out, whitespace+"  yamly.EncodeUnion(out, "+quotedKey+", "+strconv.Quote(v.Value)+
+			", func(out yamly.Inserter) {")
+		if err := g.generateEncoderBody(v.Type, vVar, fieldTags{}, indent+4, true); err != nil {
+			return err
+		}
+		fmt.Fprintln(g.out, whitespace+"  })")
+	}
+	fmt.Fprintln(g.out, whitespace+"default:")
+	fmt.Fprintln(g.out, whitespace+"  out.InsertRaw(nil, &yamly.UnknownVariantError{Key: "+quotedKey+", Type: "+
+		g.pkgAlias("fmt")+".Sprintf(\"%T\", "+vVar+")})")
+	fmt.Fprintln(g.out, whitespace+"}")
+	return nil
+}
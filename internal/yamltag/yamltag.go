@@ -0,0 +1,379 @@
+// Package yamltag contains rules of "yaml" struct tags processing shared
+// by generated code and reflection-based codec.
+package yamltag
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/KSpaceer/yamly"
+)
+
+const (
+	aliasOption      = "alias="
+	timeFormatOption = "timeformat="
+	codecOption      = "codec="
+)
+
+// Tags describes options given to struct field with "yaml" struct tag.
+type Tags struct {
+	Name string
+
+	OmitField bool
+	Omitempty bool
+	Inline    bool
+	// Remain marks the map field collecting mapping entries with unknown keys.
+	Remain bool
+
+	// Aliases are deprecated keys of the field.
+	Aliases []string
+
+	// Style is the rendering style of the field value. If several style options are given, the last one is used.
+	Style yamly.Style
+
+	// TimeFormat is the layout (see time.Format) of time.Time field value. Empty layout means default format.
+	TimeFormat string
+	// UnixUnit is the unit of integer Unix time representing time.Time field value:
+	// time.Second for "unix" option and time.Millisecond for "unixmilli" option.
+	// Zero unit means that the value is represented as string.
+	UnixUnit time.Duration
+
+	// Codec is the pair of functions "pkg.DecodeFn,pkg.EncodeFn" processing the field value,
+	// given with "codec" option of "yamly" tag.
+	Codec string
+}
+
+var styleOptions = map[string]yamly.Style{
+	"flow":         yamly.FlowStyle,
+	"literal":      yamly.LiteralStyle,
+	"folded":       yamly.FoldedStyle,
+	"singlequoted": yamly.SingleQuotedStyle,
+	"doublequoted": yamly.DoubleQuotedStyle,
+}
+
+// Parse parses "yaml" struct tag.
+func Parse(f reflect.StructTag) Tags {
+	t := Tags{}
+
+	options := strings.Split(f.Get("yaml"), ",")
+
+	if len(options) == 1 && options[0] == "-" {
+		t.OmitField = true
+	}
+
+	for i, s := range options {
+		switch {
+		case i == 0:
+			t.Name = s
+		case s == "omitempty":
+			t.Omitempty = true
+		case s == "inline":
+			t.Inline = true
+		case s == "remain":
+			t.Remain = true
+		case strings.HasPrefix(s, aliasOption):
+			t.Aliases = strings.Split(strings.TrimPrefix(s, aliasOption), "|")
+		case strings.HasPrefix(s, timeFormatOption):
+			t.TimeFormat = strings.TrimPrefix(s, timeFormatOption)
+		case s == "unix":
+			t.UnixUnit = time.Second
+		case s == "unixmilli":
+			t.UnixUnit = time.Millisecond
+		case styleOptions[s] != yamly.DefaultStyle:
+			t.Style = styleOptions[s]
+		}
+	}
+
+	if codec, ok := strings.CutPrefix(f.Get(directiveTag), codecOption); ok {
+		t.Codec = codec
+	}
+
+	return t
+}
+
+// Fields returns exported fields of struct type t which are represented in YAML mapping.
+// Fields of inlined structs are included into the result with their Index set relative to t.
+// If inlineEmbedded is true, embedded fields without explicit name are inlined.
+func Fields(t reflect.Type, inlineEmbedded bool) []reflect.StructField {
+	var (
+		embeddedFields []reflect.StructField
+		fields         []reflect.StructField
+	)
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := Parse(f.Tag)
+		if !isInlined(f, tags, inlineEmbedded) || isRemain(f, tags) {
+			continue
+		}
+
+		t := f.Type
+		if t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		if t.Kind() == reflect.Struct {
+			fs := Fields(t, inlineEmbedded)
+			for j := range fs {
+				fs[j].Index = append([]int{i}, fs[j].Index...)
+			}
+			embeddedFields = merge(embeddedFields, fs)
+		} else if (t.Kind() >= reflect.Bool && t.Kind() <= reflect.Complex128) || t.Kind() == reflect.String {
+			// kind is basic
+			if strings.Contains(f.Name, ".") || unicode.IsUpper([]rune(f.Name)[0]) {
+				fields = append(fields, f)
+			}
+		}
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if tags := Parse(f.Tag); isInlined(f, tags, inlineEmbedded) || isRemain(f, tags) {
+			continue
+		}
+
+		c := []rune(f.Name)[0]
+		if unicode.IsUpper(c) {
+			fields = append(fields, f)
+		}
+	}
+
+	return merge(embeddedFields, fields)
+}
+
+func isInlined(f reflect.StructField, tags Tags, inlineEmbedded bool) bool {
+	return (f.Anonymous && inlineEmbedded && tags.Name == "") || tags.Inline
+}
+
+func isRemain(f reflect.StructField, tags Tags) bool {
+	return tags.Remain || (tags.Inline && f.Type.Kind() == reflect.Map)
+}
+
+// RemainField returns the field of struct t collecting mapping entries with unknown keys.
+// The field is marked with "remain" option or is a map with "inline" option. Only fields of t itself are checked,
+// not the fields of inlined structs. It returns an error if there are several such fields
+// or the field is not a map with string keys.
+func RemainField(t reflect.Type) (reflect.StructField, bool, error) {
+	var (
+		remain reflect.StructField
+		found  bool
+	)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !isRemain(f, Parse(f.Tag)) {
+			continue
+		}
+		if f.Type.Kind() != reflect.Map || f.Type.Key().Kind() != reflect.String {
+			return reflect.StructField{}, false, fmt.Errorf("remain field %s of %s must be a map with string keys, "+
+				"but got %s", f.Name, t, f.Type)
+		}
+		if found {
+			return reflect.StructField{}, false, fmt.Errorf("%s has several remain fields: %s and %s",
+				t, remain.Name, f.Name)
+		}
+		remain, found = f, true
+	}
+	return remain, found, nil
+}
+
+// merge merges two lists of fields, preferring the fields from secondFields
+// if both lists contain a field with the same name.
+func merge(firstFields, secondFields []reflect.StructField) []reflect.StructField {
+	fields := make([]reflect.StructField, 0, len(firstFields))
+	used := make(map[string]bool)
+	for _, f := range secondFields {
+		used[f.Name] = true
+		fields = append(fields, f)
+	}
+
+	for _, f := range firstFields {
+		if !used[f.Name] {
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// FieldNaming is a strategy of naming mapping keys for fields without explicit name in "yaml" tag.
+type FieldNaming string
+
+const (
+	// FieldNamingAsIs uses Go field name as is, e.g. MaxRetries.
+	FieldNamingAsIs FieldNaming = "as-is"
+	// FieldNamingCamel converts Go field name into lower camel case, e.g. maxRetries.
+	FieldNamingCamel FieldNaming = "camel"
+	// FieldNamingSnake converts Go field name into snake case, e.g. max_retries.
+	FieldNamingSnake FieldNaming = "snake"
+	// FieldNamingKebab converts Go field name into kebab case, e.g. max-retries.
+	FieldNamingKebab FieldNaming = "kebab"
+	// FieldNamingLower converts Go field name into lower case, e.g. maxretries.
+	FieldNamingLower FieldNaming = "lower"
+)
+
+// Validate returns an error if n is not a known strategy. Empty strategy is treated as FieldNamingAsIs.
+func (n FieldNaming) Validate() error {
+	switch n {
+	case "", FieldNamingAsIs, FieldNamingCamel, FieldNamingSnake, FieldNamingKebab, FieldNamingLower:
+		return nil
+	default:
+		return fmt.Errorf("unknown field naming %q: expected one of camel, snake, kebab, lower or as-is", string(n))
+	}
+}
+
+// Key returns the mapping key of field f. Explicit name in tags always takes precedence.
+func (n FieldNaming) Key(f reflect.StructField, tags Tags) string {
+	if tags.Name != "" {
+		return tags.Name
+	}
+
+	switch n {
+	case FieldNamingCamel:
+		words := splitWords(f.Name)
+		words[0] = strings.ToLower(words[0])
+		return strings.Join(words, "")
+	case FieldNamingSnake:
+		return strings.ToLower(strings.Join(splitWords(f.Name), "_"))
+	case FieldNamingKebab:
+		return strings.ToLower(strings.Join(splitWords(f.Name), "-"))
+	case FieldNamingLower:
+		return strings.ToLower(f.Name)
+	default:
+		return f.Name
+	}
+}
+
+// splitWords splits Go identifier into words, keeping acronyms together (e.g. HTTPServer -> HTTP, Server).
+func splitWords(name string) []string {
+	var (
+		words []string
+		word  []rune
+	)
+	runes := []rune(name)
+	for i, r := range runes {
+		if r == '_' {
+			if len(word) > 0 {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+			continue
+		}
+		if unicode.IsUpper(r) && len(word) > 0 {
+			prev := word[len(word)-1]
+			nextIsLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if !unicode.IsUpper(prev) || nextIsLower {
+				words = append(words, string(word))
+				word = word[:0]
+			}
+		}
+		word = append(word, r)
+	}
+	if len(word) > 0 {
+		words = append(words, string(word))
+	}
+	if len(words) == 0 {
+		return []string{name}
+	}
+	return words
+}
+
+// Struct directive options given with "yamly" tag of blank field, e.g.
+//
+//	_ struct{} `yamly:"case-insensitive-keys,ignore-key-separators"`
+const (
+	directiveCaseInsensitiveKeys = "case-insensitive-keys"
+	directiveIgnoreKeySeparators = "ignore-key-separators"
+	directiveExactKeys           = "exact-keys"
+	directiveFieldName           = "_"
+	directiveTag                 = "yamly"
+)
+
+// KeyMatching returns key normalization used to match mapping keys with fields of struct t.
+// Struct can override the given default normalization with directive placed in "yamly" tag of blank field.
+func KeyMatching(t reflect.Type, defaultNormalization yamly.KeyNormalization) (yamly.KeyNormalization, error) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		directive, ok := f.Tag.Lookup(directiveTag)
+		if f.Name != directiveFieldName || !ok {
+			continue
+		}
+
+		var n yamly.KeyNormalization
+		for _, option := range strings.Split(directive, ",") {
+			switch strings.TrimSpace(option) {
+			case directiveCaseInsensitiveKeys:
+				n |= yamly.KeyCaseInsensitive
+			case directiveIgnoreKeySeparators:
+				n |= yamly.KeyIgnoreSeparators
+			case directiveExactKeys, "":
+			default:
+				return 0, fmt.Errorf("unknown struct directive option %q in %s", option, t)
+			}
+		}
+		return n, nil
+	}
+	return defaultNormalization, nil
+}
+
+// FieldKey describes mapping key of struct field.
+type FieldKey struct {
+	Field reflect.StructField
+	Tags  Tags
+	// Name is the key written into mapping.
+	Name string
+	// Match is the normalized key used to match keys of decoded mapping.
+	Match string
+	// AliasMatches are the normalized aliases of the key.
+	AliasMatches []string
+}
+
+// Keys returns the keys of struct t fields represented in YAML mapping. Key normalization
+// is defined by struct directive or by given default normalization.
+// It returns an error if several fields can't be distinguished after normalization.
+func Keys(
+	t reflect.Type,
+	inlineEmbedded bool,
+	naming FieldNaming,
+	defaultNormalization yamly.KeyNormalization,
+) ([]FieldKey, yamly.KeyNormalization, error) {
+	n, err := KeyMatching(t, defaultNormalization)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	fields := Fields(t, inlineEmbedded)
+	keys := make([]FieldKey, 0, len(fields))
+	// matchedKey keeps the field and its key (or alias) to report ambiguity
+	type matchedKey struct {
+		field string
+		key   string
+	}
+	byMatch := make(map[string]matchedKey, len(fields))
+	for _, f := range fields {
+		tags := Parse(f.Tag)
+		if tags.OmitField {
+			continue
+		}
+		name := naming.Key(f, tags)
+		key := FieldKey{Field: f, Tags: tags, Name: name, Match: yamly.NormalizeKey(name, n)}
+		for _, alias := range tags.Aliases {
+			key.AliasMatches = append(key.AliasMatches, yamly.NormalizeKey(alias, n))
+		}
+
+		for i, match := range append([]string{key.Match}, key.AliasMatches...) {
+			current := name
+			if i > 0 {
+				current = tags.Aliases[i-1]
+			}
+			if other, ok := byMatch[match]; ok {
+				return nil, 0, fmt.Errorf("fields %s and %s of %s have ambiguous keys %q and %q",
+					other.field, f.Name, t, other.key, current)
+			}
+			byMatch[match] = matchedKey{field: f.Name, key: current}
+		}
+		keys = append(keys, key)
+	}
+	return keys, n, nil
+}
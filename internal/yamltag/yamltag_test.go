@@ -0,0 +1,262 @@
+package yamltag_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
+)
+
+func TestFieldNaming_Key(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		field    string
+		tag      reflect.StructTag
+		naming   yamltag.FieldNaming
+		expected string
+	}
+
+	tcases := []tcase{
+		{field: "MaxRetries", naming: "", expected: "MaxRetries"},
+		{field: "MaxRetries", naming: yamltag.FieldNamingAsIs, expected: "MaxRetries"},
+		{field: "MaxRetries", naming: yamltag.FieldNamingCamel, expected: "maxRetries"},
+		{field: "MaxRetries", naming: yamltag.FieldNamingSnake, expected: "max_retries"},
+		{field: "MaxRetries", naming: yamltag.FieldNamingKebab, expected: "max-retries"},
+		{field: "MaxRetries", naming: yamltag.FieldNamingLower, expected: "maxretries"},
+		{field: "HTTPServer", naming: yamltag.FieldNamingCamel, expected: "httpServer"},
+		{field: "HTTPServer", naming: yamltag.FieldNamingSnake, expected: "http_server"},
+		{field: "UserID", naming: yamltag.FieldNamingCamel, expected: "userID"},
+		{field: "UserID", naming: yamltag.FieldNamingKebab, expected: "user-id"},
+		{field: "ID", naming: yamltag.FieldNamingCamel, expected: "id"},
+		{field: "Base64Value", naming: yamltag.FieldNamingSnake, expected: "base64_value"},
+		{field: "Max_Retries", naming: yamltag.FieldNamingCamel, expected: "maxRetries"},
+		{field: "MaxRetries", tag: `yaml:"MAX"`, naming: yamltag.FieldNamingSnake, expected: "MAX"},
+		{field: "MaxRetries", tag: `yaml:",omitempty"`, naming: yamltag.FieldNamingKebab, expected: "max-retries"},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(string(tc.naming)+" "+tc.field, func(t *testing.T) {
+			t.Parallel()
+
+			f := reflect.StructField{Name: tc.field, Tag: tc.tag}
+			if key := tc.naming.Key(f, yamltag.Parse(f.Tag)); key != tc.expected {
+				t.Errorf("expected key %q, but got %q", tc.expected, key)
+			}
+		})
+	}
+}
+
+func TestFieldNaming_Validate(t *testing.T) {
+	t.Parallel()
+
+	for _, naming := range []yamltag.FieldNaming{"", "as-is", "camel", "snake", "kebab", "lower"} {
+		if err := naming.Validate(); err != nil {
+			t.Errorf("unexpected error for %q: %v", naming, err)
+		}
+	}
+	if err := yamltag.FieldNaming("pascal").Validate(); err == nil {
+		t.Errorf("expected error for unknown naming")
+	}
+}
+
+func TestKeys(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name                  string
+		value                 any
+		naming                yamltag.FieldNaming
+		normalization         yamly.KeyNormalization
+		expectedMatches       []string
+		expectedAliasMatches  []string
+		expectedNormalization yamly.KeyNormalization
+		expectError           bool
+	}
+
+	tcases := []tcase{
+		{
+			name: "exact",
+			value: struct {
+				MaxRetries  int
+				Max_Retries int `yaml:"max_retries"`
+			}{},
+			expectedMatches: []string{"MaxRetries", "max_retries"},
+		},
+		{
+			name: "ambiguous",
+			value: struct {
+				MaxRetries  int
+				Max_Retries int `yaml:"max_retries"`
+			}{},
+			normalization: yamly.KeyCaseInsensitive | yamly.KeyIgnoreSeparators,
+			expectError:   true,
+		},
+		{
+			name: "case insensitive",
+			value: struct {
+				MaxRetries int
+				Timeout    int `yaml:"time-out"`
+			}{},
+			naming:                yamltag.FieldNamingSnake,
+			normalization:         yamly.KeyCaseInsensitive,
+			expectedMatches:       []string{"max_retries", "time-out"},
+			expectedNormalization: yamly.KeyCaseInsensitive,
+		},
+		{
+			name: "directive",
+			value: struct {
+				_          struct{} `yamly:"ignore-key-separators"`
+				MaxRetries int      `yaml:"max-retries"`
+			}{},
+			normalization:         yamly.KeyCaseInsensitive,
+			expectedMatches:       []string{"maxretries"},
+			expectedNormalization: yamly.KeyIgnoreSeparators,
+		},
+		{
+			name: "exact directive",
+			value: struct {
+				_          struct{} `yamly:"exact-keys"`
+				MaxRetries int
+			}{},
+			normalization:   yamly.KeyCaseInsensitive | yamly.KeyIgnoreSeparators,
+			expectedMatches: []string{"MaxRetries"},
+		},
+		{
+			name: "aliases",
+			value: struct {
+				Timeout int `yaml:"timeout,omitempty,alias=timeoutSeconds|timeout_s"`
+			}{},
+			normalization:         yamly.KeyCaseInsensitive,
+			expectedMatches:       []string{"timeout"},
+			expectedAliasMatches:  []string{"timeoutseconds", "timeout_s"},
+			expectedNormalization: yamly.KeyCaseInsensitive,
+		},
+		{
+			name: "alias is ambiguous with key",
+			value: struct {
+				Timeout        int `yaml:"timeout,alias=timeoutSeconds"`
+				TimeoutSeconds int `yaml:"timeout_seconds"`
+			}{},
+			normalization: yamly.KeyCaseInsensitive | yamly.KeyIgnoreSeparators,
+			expectError:   true,
+		},
+		{
+			name: "unknown directive",
+			value: struct {
+				_          struct{} `yamly:"fuzzy-keys"`
+				MaxRetries int
+			}{},
+			expectError: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			keys, normalization, err := yamltag.Keys(reflect.TypeOf(tc.value), false, tc.naming, tc.normalization)
+			if tc.expectError {
+				if err == nil {
+					t.Fatalf("expected error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if normalization != tc.expectedNormalization {
+				t.Errorf("expected normalization %d, but got %d", tc.expectedNormalization, normalization)
+			}
+			matches := make([]string, 0, len(keys))
+			var aliasMatches []string
+			for _, key := range keys {
+				matches = append(matches, key.Match)
+				aliasMatches = append(aliasMatches, key.AliasMatches...)
+			}
+			if !reflect.DeepEqual(tc.expectedMatches, matches) {
+				t.Errorf("expected matches %v, but got %v", tc.expectedMatches, matches)
+			}
+			if !reflect.DeepEqual(tc.expectedAliasMatches, aliasMatches) {
+				t.Errorf("expected alias matches %v, but got %v", tc.expectedAliasMatches, aliasMatches)
+			}
+		})
+	}
+}
+
+func TestRemainField(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name      string
+		value     any
+		expected  string
+		expectErr bool
+	}
+
+	tcases := []tcase{
+		{
+			name: "remain option",
+			value: struct {
+				Name  string
+				Extra map[string]any `yaml:",remain"`
+			}{},
+			expected: "Extra",
+		},
+		{
+			name: "inlined map",
+			value: struct {
+				Extra map[string]yamly.RawNode `yaml:",inline"`
+			}{},
+			expected: "Extra",
+		},
+		{
+			name:  "no remain field",
+			value: struct{ Name string }{},
+		},
+		{
+			name: "non-string keys",
+			value: struct {
+				Extra map[int]any `yaml:",remain"`
+			}{},
+			expectErr: true,
+		},
+		{
+			name: "several remain fields",
+			value: struct {
+				First  map[string]any `yaml:",remain"`
+				Second map[string]any `yaml:",inline"`
+			}{},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			typ := reflect.TypeOf(tc.value)
+			f, ok, err := yamltag.RemainField(typ)
+			if err != nil {
+				if !tc.expectErr {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			} else if tc.expectErr {
+				t.Fatalf("expected error, but got nil")
+			}
+			if ok != (tc.expected != "") || f.Name != tc.expected {
+				t.Errorf("expected remain field %q, but got %q (found: %t)", tc.expected, f.Name, ok)
+			}
+			for _, field := range yamltag.Fields(typ, false) {
+				if field.Name == tc.expected {
+					t.Errorf("remain field %s must not be in fields", field.Name)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,54 @@
+package yamly
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// KeyNormalization defines how mapping keys are normalized before matching struct fields.
+type KeyNormalization uint8
+
+const (
+	// KeyCaseInsensitive makes keys match regardless of letter case.
+	KeyCaseInsensitive KeyNormalization = 1 << iota
+	// KeyIgnoreSeparators makes keys match ignoring '_' and '-' separators.
+	KeyIgnoreSeparators
+)
+
+// NormalizeKey normalizes given mapping key. It is used by generated struct decoders
+// to match keys with fields.
+func NormalizeKey(key string, n KeyNormalization) string {
+	if n == 0 || !needsNormalization(key, n) {
+		return key
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(key))
+	for _, r := range key {
+		if n&KeyIgnoreSeparators != 0 && (r == '_' || r == '-') {
+			continue
+		}
+		if n&KeyCaseInsensitive != 0 {
+			r = unicode.ToLower(r)
+		}
+		sb.WriteRune(r)
+	}
+	return sb.String()
+}
+
+// needsNormalization allows to avoid allocation for already normalized keys.
+func needsNormalization(key string, n KeyNormalization) bool {
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		switch {
+		case c >= utf8.RuneSelf:
+			return true
+		case n&KeyIgnoreSeparators != 0 && (c == '_' || c == '-'):
+			return true
+		case n&KeyCaseInsensitive != 0 && 'A' <= c && c <= 'Z':
+			return true
+		}
+	}
+	return false
+}
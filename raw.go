@@ -0,0 +1,19 @@
+package yamly
+
+// RawNode keeps YAML subtree serialized by engine. It allows to delay decoding of the subtree
+// or to pass it through unchanged, e.g. as value type of remain field.
+type RawNode []byte
+
+// UnmarshalYamly supports UnmarshalerYamly interface.
+func (r *RawNode) UnmarshalYamly(in Decoder) {
+	*r = append((*r)[:0], in.Raw()...)
+}
+
+// MarshalYamly supports MarshalerYamly interface.
+func (r RawNode) MarshalYamly(out Inserter) {
+	if len(r) == 0 {
+		out.InsertNull()
+		return
+	}
+	out.InsertRaw(r, nil)
+}
@@ -0,0 +1,279 @@
+package reflectcodec
+
+import (
+	"encoding"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
+)
+
+// Decode decodes current subtree of the decoder into the value pointed to by v.
+// Errors are added into the decoder.
+func Decode(in yamly.Decoder, v any, opts ...Option) {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		in.AddError(fmt.Errorf("%w, but got %s", ErrInvalidTarget, reflect.TypeOf(v)))
+		in.Skip()
+		return
+	}
+
+	d := decoder{in: in, opts: newOptions(opts)}
+	d.decode(rv.Elem(), yamltag.Tags{})
+}
+
+type decoder struct {
+	in   yamly.Decoder
+	opts options
+}
+
+func (d *decoder) decode(v reflect.Value, tags yamltag.Tags) {
+	t := v.Type()
+	if c, ok := d.opts.codecs[t]; ok {
+		c.decode(d.in, v)
+		return
+	}
+	switch t {
+	case timeType:
+		v.Set(reflect.ValueOf(d.decodeTime(tags)))
+		return
+	case durationType:
+		v.Set(reflect.ValueOf(yamly.DecodeDuration(d.in, d.opts.durationUnit)))
+		return
+	case urlType:
+		v.Set(reflect.ValueOf(yamly.DecodeURL(d.in)))
+		return
+	}
+
+	pt := reflect.PointerTo(t)
+	if pt.Implements(unmarshalerYamlyType) {
+		v.Addr().Interface().(yamly.UnmarshalerYamly).UnmarshalYamly(d.in) // nolint: forcetypeassert
+		return
+	}
+	if pt.Implements(textUnmarshalerType) {
+		u := v.Addr().Interface().(encoding.TextUnmarshaler) // nolint: forcetypeassert
+		d.in.AddError(u.UnmarshalText([]byte(d.in.String())))
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		v.SetString(d.in.String())
+	case reflect.Bool:
+		v.SetBool(d.in.Boolean())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(d.in.Integer(t.Bits()))
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		v.SetUint(d.in.Unsigned(t.Bits()))
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(d.in.Float(t.Bits()))
+	case reflect.Complex64, reflect.Complex128:
+		c, err := strconv.ParseComplex(d.in.String(), t.Bits())
+		if err != nil {
+			d.in.AddError(yamly.DenyError(err))
+			return
+		}
+		v.SetComplex(c)
+	case reflect.Slice:
+		d.decodeSlice(v, tags)
+	case reflect.Array:
+		d.decodeArray(v, tags)
+	case reflect.Struct:
+		d.decodeStruct(v)
+	case reflect.Pointer:
+		if d.in.TryNull() {
+			v.SetZero()
+			return
+		}
+		if v.IsNil() {
+			v.Set(reflect.New(t.Elem()))
+		}
+		d.decode(v.Elem(), tags)
+	case reflect.Map:
+		d.decodeMap(v, tags)
+	case reflect.Interface:
+		d.decodeInterface(v)
+	default:
+		d.in.AddError(fmt.Errorf("%w: can't decode type %s", ErrUnsupportedType, t))
+		d.in.Skip()
+	}
+}
+
+func (d *decoder) decodeTime(tags yamltag.Tags) time.Time {
+	if tags.UnixUnit != 0 {
+		return yamly.DecodeUnixTime(d.in, tags.UnixUnit)
+	}
+	if layout := d.opts.timeLayout(tags); layout != "" {
+		return yamly.DecodeTime(d.in, layout)
+	}
+	return d.in.Timestamp()
+}
+
+func (d *decoder) decodeSlice(v reflect.Value, tags yamltag.Tags) {
+	if d.in.TryNull() {
+		v.SetZero()
+		return
+	}
+
+	t := v.Type()
+	if isByteSequence(t) {
+		v.Set(reflect.ValueOf([]byte(d.in.String())).Convert(t))
+		return
+	}
+
+	state := d.in.Sequence()
+	s := reflect.MakeSlice(t, 0, state.Size())
+	for state.HasUnprocessedItems() {
+		elem := reflect.New(t.Elem()).Elem()
+		d.decode(elem, tags)
+		s = reflect.Append(s, elem)
+	}
+	v.Set(s)
+}
+
+func (d *decoder) decodeArray(v reflect.Value, tags yamltag.Tags) {
+	if d.in.TryNull() {
+		return
+	}
+
+	if isByteSequence(v.Type()) {
+		reflect.Copy(v, reflect.ValueOf(d.in.String()))
+		return
+	}
+
+	state := d.in.Sequence()
+	for i := 0; state.HasUnprocessedItems(); i++ {
+		if i < v.Len() {
+			d.decode(v.Index(i), tags)
+		} else {
+			d.in.Skip()
+		}
+	}
+}
+
+func (d *decoder) decodeStruct(v reflect.Value) {
+	if d.in.TryNull() {
+		v.SetZero()
+		return
+	}
+
+	info, err := cachedStructInfo(v.Type(), &d.opts)
+	if err != nil {
+		d.in.AddError(err)
+		d.in.Skip()
+		return
+	}
+
+	// keys used to set fields with aliases to detect conflicts
+	var seenKeys map[*field]string
+	if info.hasAliases {
+		seenKeys = make(map[*field]string)
+	}
+
+	state := d.in.Mapping()
+	for state.HasUnprocessedItems() {
+		key := d.in.String()
+		match := yamly.NormalizeKey(key, info.normalization)
+		f, ok := info.byMatch[match]
+		// aliases are checked before null values to detect conflicts and deprecated keys regardless of the value
+		if ok && f.hasAliases {
+			// keys are compared by their matches, so differently written keys of the same match do not conflict
+			if seen, ok := seenKeys[f.field]; ok && yamly.NormalizeKey(seen, info.normalization) != match {
+				d.in.AddError(&yamly.KeyConflictError{Key: f.name, First: seen, Second: key})
+			}
+			seenKeys[f.field] = key
+			if win, ok := d.in.(yamly.WarningsDecoder); ok && f.alias {
+				win.AddWarning(&yamly.DeprecatedKeyWarning{Key: f.name, Alias: key})
+			}
+		}
+		if d.in.TryNull() {
+			if !ok && info.remain != nil {
+				// null values of unknown keys are kept in remain field too
+				d.setRemainValue(v, info.remain, key, true)
+			}
+			continue
+		}
+
+		if !ok {
+			if info.remain != nil {
+				d.setRemainValue(v, info.remain, key, false)
+				continue
+			}
+			if d.opts.disallowUnknownFields {
+				d.in.AddError(&yamly.UnknownFieldError{Field: key})
+			}
+			d.in.Skip()
+			continue
+		}
+
+		fv, ok := fieldByIndex(v, f.index, true)
+		if !ok {
+			d.in.AddError(fmt.Errorf("can't set field %s: embedded pointer to unexported struct is nil", key))
+			d.in.Skip()
+			continue
+		}
+		d.decode(fv, f.tags)
+	}
+}
+
+// setRemainValue stores the value of unknown key into remain field of struct v.
+// If null is true, zero value is stored, otherwise the value is decoded.
+func (d *decoder) setRemainValue(v reflect.Value, remain *field, key string, null bool) {
+	m := v.FieldByIndex(remain.index)
+	t := m.Type()
+	if m.IsNil() {
+		m.Set(reflect.MakeMap(t))
+	}
+	value := reflect.New(t.Elem()).Elem()
+	if !null {
+		d.decode(value, remain.tags)
+	}
+	m.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), value)
+}
+
+func (d *decoder) decodeMap(v reflect.Value, tags yamltag.Tags) {
+	if d.in.TryNull() {
+		v.SetZero()
+		return
+	}
+
+	t := v.Type()
+	state := d.in.Mapping()
+	if (d.opts.omitempty || tags.Omitempty) && state.Size() == 0 {
+		v.SetZero()
+	} else {
+		v.Set(reflect.MakeMapWithSize(t, state.Size()))
+	}
+
+	for state.HasUnprocessedItems() {
+		key := reflect.New(t.Key()).Elem()
+		d.decode(key, tags)
+		value := reflect.New(t.Elem()).Elem()
+		d.decode(value, tags)
+		v.SetMapIndex(key, value)
+	}
+}
+
+func (d *decoder) decodeInterface(v reflect.Value) {
+	if !v.IsNil() {
+		if u, ok := v.Interface().(yamly.UnmarshalerYamly); ok {
+			u.UnmarshalYamly(d.in)
+			return
+		}
+	}
+
+	if v.NumMethod() > 0 {
+		d.in.AddError(yamly.ErrUnmarshalerImplementation)
+		d.in.Skip()
+		return
+	}
+
+	if value := d.in.Any(); value == nil {
+		v.SetZero()
+	} else {
+		v.Set(reflect.ValueOf(value))
+	}
+}
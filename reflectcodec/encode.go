@@ -0,0 +1,214 @@
+package reflectcodec
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
+)
+
+// Encode inserts v into the inserter. Errors are added into the inserter.
+func Encode(out yamly.Inserter, v any, opts ...Option) {
+	e := encoder{out: out, opts: newOptions(opts)}
+	e.encode(reflect.ValueOf(v), yamltag.Tags{}, true)
+}
+
+type encoder struct {
+	out  yamly.Inserter
+	opts options
+}
+
+func (e *encoder) encode(v reflect.Value, tags yamltag.Tags, canBeNull bool) {
+	if !v.IsValid() {
+		e.out.InsertNull()
+		return
+	}
+
+	t := v.Type()
+	if tags.Style != yamly.DefaultStyle && t.Kind() != reflect.Pointer && t.Kind() != reflect.Interface {
+		// pointers and interfaces pass the style to their elements
+		if styledOut, ok := e.out.(yamly.StyledInserter); ok {
+			styledOut.SetStyle(tags.Style)
+		}
+	}
+
+	if c, ok := e.opts.codecs[t]; ok {
+		c.encode(e.out, v)
+		return
+	}
+
+	switch t {
+	case timeType:
+		e.encodeTime(v.Interface().(time.Time), tags) // nolint: forcetypeassert
+		return
+	case durationType:
+		e.out.InsertString(time.Duration(v.Int()).String())
+		return
+	case urlType:
+		e.out.InsertString(addressable(v).Interface().(*url.URL).String()) // nolint: forcetypeassert
+		return
+	}
+
+	pt := reflect.PointerTo(t)
+	if pt.Implements(marshalerYamlyType) {
+		addressable(v).Interface().(yamly.MarshalerYamly).MarshalYamly(e.out) // nolint: forcetypeassert
+		return
+	}
+	if pt.Implements(textMarshalerType) {
+		e.out.InsertRawText(addressable(v).Interface().(encoding.TextMarshaler).MarshalText()) // nolint: forcetypeassert
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		e.out.InsertString(v.String())
+	case reflect.Bool:
+		e.out.InsertBoolean(v.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		e.out.InsertInteger(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		e.out.InsertUnsigned(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		e.out.InsertFloat(v.Float())
+	case reflect.Complex64, reflect.Complex128:
+		e.out.InsertString(strconv.FormatComplex(v.Complex(), 'g', -1, t.Bits()))
+	case reflect.Slice:
+		if v.IsNil() && canBeNull {
+			e.out.InsertNull()
+			return
+		}
+		e.encodeSequence(v, tags)
+	case reflect.Array:
+		e.encodeSequence(v, tags)
+	case reflect.Struct:
+		e.encodeStruct(v)
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			e.out.InsertNull()
+			return
+		}
+		e.encode(v.Elem(), tags, true)
+	case reflect.Map:
+		if v.IsNil() && canBeNull {
+			e.out.InsertNull()
+			return
+		}
+		e.encodeMap(v, tags)
+	default:
+		e.out.InsertRaw(nil, fmt.Errorf("%w: can't encode type %s", ErrUnsupportedType, t))
+	}
+}
+
+func (e *encoder) encodeTime(t time.Time, tags yamltag.Tags) {
+	switch {
+	case tags.UnixUnit == time.Second:
+		e.out.InsertInteger(t.Unix())
+	case tags.UnixUnit == time.Millisecond:
+		e.out.InsertInteger(t.UnixMilli())
+	case e.opts.timeLayout(tags) != "":
+		e.out.InsertString(t.Format(e.opts.timeLayout(tags)))
+	case e.opts.timeNanoseconds:
+		e.out.InsertString(t.Format(time.RFC3339Nano))
+	default:
+		e.out.InsertTimestamp(t)
+	}
+}
+
+func (e *encoder) encodeSequence(v reflect.Value, tags yamltag.Tags) {
+	if isByteSequence(v.Type()) {
+		b := make([]byte, v.Len())
+		reflect.Copy(reflect.ValueOf(b), v)
+		e.out.InsertString(string(b))
+		return
+	}
+
+	e.out.StartSequence()
+	for i := 0; i < v.Len(); i++ {
+		e.encode(v.Index(i), tags, true)
+	}
+	e.out.EndSequence()
+}
+
+func (e *encoder) encodeStruct(v reflect.Value) {
+	info, err := cachedStructInfo(v.Type(), &e.opts)
+	if err != nil {
+		e.out.InsertRaw(nil, err)
+		return
+	}
+
+	e.out.StartMapping()
+	for i := range info.fields {
+		f := &info.fields[i]
+		fv, ok := fieldByIndex(v, f.index, false)
+		if !ok {
+			// field of nil inlined struct
+			continue
+		}
+		canBeNull := !(e.opts.omitempty || f.tags.Omitempty)
+		if !canBeNull && isEmpty(fv) {
+			continue
+		}
+		e.out.InsertString(f.name)
+		e.encode(fv, f.tags, canBeNull)
+	}
+	if info.remain != nil {
+		// entries with unknown keys are written after known fields
+		iter := v.FieldByIndex(info.remain.index).MapRange()
+		for iter.Next() {
+			e.out.InsertString(iter.Key().String())
+			e.encode(iter.Value(), info.remain.tags, true)
+		}
+	}
+	e.out.EndMapping()
+}
+
+func (e *encoder) encodeMap(v reflect.Value, tags yamltag.Tags) {
+	e.out.StartMapping()
+	iter := v.MapRange()
+	keyTags := tags
+	keyTags.Style = yamly.DefaultStyle
+	for iter.Next() {
+		e.encode(iter.Key(), keyTags, true)
+		e.encode(iter.Value(), tags, true)
+	}
+	e.out.EndMapping()
+}
+
+// addressable returns v itself if it is addressable or its addressable copy otherwise,
+// allowing to call methods with pointer receiver.
+func addressable(v reflect.Value) reflect.Value {
+	if v.CanAddr() {
+		return v.Addr()
+	}
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p
+}
+
+// isEmpty reports whether v is empty in terms of "omitempty" option.
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Interface, reflect.Pointer:
+		return v.IsNil()
+	case reflect.Bool:
+		return !v.Bool()
+	case reflect.String:
+		return v.Len() == 0
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int() == 0
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return v.Uint() == 0
+	case reflect.Float32, reflect.Float64:
+		return v.Float() == 0
+	default:
+		// array does not have "empty" value
+		return false
+	}
+}
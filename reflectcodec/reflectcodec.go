@@ -0,0 +1,267 @@
+// Package reflectcodec contains reflection-based decoding and encoding of arbitrary Go values
+// via yamly.Decoder and yamly.Inserter. It is used as a fallback for the types
+// generated code can't be created for (e.g. structs from other modules).
+//
+// The values are processed according to the same "yaml" struct tags rules as in generated code.
+package reflectcodec
+
+import (
+	"encoding"
+	"errors"
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/internal/yamltag"
+)
+
+var (
+	// ErrUnsupportedType indicates that value of given type can't be decoded or encoded.
+	ErrUnsupportedType = errors.New("unsupported type")
+	// ErrInvalidTarget indicates that value given to Decode is not a non-nil pointer.
+	ErrInvalidTarget = errors.New("decoding target must be a non-nil pointer")
+)
+
+var (
+	timeType             = reflect.TypeOf(time.Time{})
+	durationType         = reflect.TypeOf(time.Duration(0))
+	urlType              = reflect.TypeOf(url.URL{})
+	unmarshalerYamlyType = reflect.TypeOf((*yamly.UnmarshalerYamly)(nil)).Elem()
+	marshalerYamlyType   = reflect.TypeOf((*yamly.MarshalerYamly)(nil)).Elem()
+	textUnmarshalerType  = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	textMarshalerType    = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+type options struct {
+	omitempty             bool
+	disallowUnknownFields bool
+	inlineEmbedded        bool
+	fieldNaming           yamltag.FieldNaming
+	keyNormalization      yamly.KeyNormalization
+	durationUnit          time.Duration
+	timeFormat            string
+	timeNanoseconds       bool
+	codecs                map[reflect.Type]codec
+}
+
+// codec is a pair of functions registered with WithCodec.
+type codec struct {
+	decode func(in yamly.Decoder, v reflect.Value)
+	encode func(out yamly.Inserter, v reflect.Value)
+}
+
+// Option allows to modify Decode and Encode behavior.
+// The options correspond to the generator flags with the same names.
+type Option func(*options)
+
+// WithOmitempty makes all struct fields behave as if they had "omitempty" option.
+func WithOmitempty() Option {
+	return func(o *options) {
+		o.omitempty = true
+	}
+}
+
+// WithDisallowUnknownFields makes Decode add yamly.UnknownFieldError into decoder
+// if struct does not have a field for mapping key.
+func WithDisallowUnknownFields() Option {
+	return func(o *options) {
+		o.disallowUnknownFields = true
+	}
+}
+
+// WithInlineEmbedded makes embedded fields without explicit name to be inlined.
+func WithInlineEmbedded() Option {
+	return func(o *options) {
+		o.inlineEmbedded = true
+	}
+}
+
+// WithFieldNaming sets a strategy of naming mapping keys for fields without explicit name in "yaml" tag.
+// Available strategies are "camel", "snake", "kebab", "lower" and "as-is" (default).
+// It panics if the strategy is unknown.
+func WithFieldNaming(naming string) Option {
+	fieldNaming := yamltag.FieldNaming(naming)
+	if err := fieldNaming.Validate(); err != nil {
+		panic("reflectcodec: " + err.Error())
+	}
+	return func(o *options) {
+		o.fieldNaming = fieldNaming
+	}
+}
+
+// WithCaseInsensitiveKeys makes Decode match mapping keys with struct fields regardless of letter case.
+func WithCaseInsensitiveKeys() Option {
+	return func(o *options) {
+		o.keyNormalization |= yamly.KeyCaseInsensitive
+	}
+}
+
+// WithIgnoreKeySeparators makes Decode match mapping keys with struct fields ignoring '_' and '-' separators.
+func WithIgnoreKeySeparators() Option {
+	return func(o *options) {
+		o.keyNormalization |= yamly.KeyIgnoreSeparators
+	}
+}
+
+// WithDurationUnit sets the unit of bare integers decoded into time.Duration. Default unit is nanosecond.
+func WithDurationUnit(unit time.Duration) Option {
+	return func(o *options) {
+		o.durationUnit = unit
+	}
+}
+
+// WithTimeFormat sets the default layout (see time.Format) of time.Time values.
+// Fields can override it with "timeformat", "unix" and "unixmilli" tag options.
+func WithTimeFormat(layout string) Option {
+	return func(o *options) {
+		o.timeFormat = layout
+	}
+}
+
+// WithTimeNanoseconds defines if Encode keeps nanoseconds of time.Time values without explicit layout,
+// writing them in RFC 3339 format with fractional seconds (default). Otherwise sub-second precision is dropped.
+func WithTimeNanoseconds(keep bool) Option {
+	return func(o *options) {
+		o.timeNanoseconds = keep
+	}
+}
+
+// WithCodec registers functions processing values of type T instead of default rules,
+// e.g. for types from third-party packages. It corresponds to the generator "codec" flag.
+func WithCodec[T any](decode func(yamly.Decoder, *T), encode func(yamly.Inserter, T)) Option {
+	c := codec{
+		decode: func(in yamly.Decoder, v reflect.Value) {
+			decode(in, v.Addr().Interface().(*T)) // nolint: forcetypeassert
+		},
+		encode: func(out yamly.Inserter, v reflect.Value) {
+			encode(out, v.Interface().(T)) // nolint: forcetypeassert
+		},
+	}
+	t := reflect.TypeOf((*T)(nil)).Elem()
+	return func(o *options) {
+		if o.codecs == nil {
+			o.codecs = make(map[reflect.Type]codec)
+		}
+		o.codecs[t] = c
+	}
+}
+
+func newOptions(opts []Option) options {
+	o := options{durationUnit: time.Nanosecond, timeNanoseconds: true}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// timeLayout returns the layout of time.Time value: the layout from field tags or the default one.
+func (o *options) timeLayout(tags yamltag.Tags) string {
+	if tags.TimeFormat != "" {
+		return tags.TimeFormat
+	}
+	return o.timeFormat
+}
+
+type field struct {
+	name       string
+	index      []int
+	tags       yamltag.Tags
+	hasAliases bool
+}
+
+// fieldMatch is a field matched by normalized key.
+type fieldMatch struct {
+	*field
+	alias bool
+}
+
+type structInfo struct {
+	fields        []field
+	byMatch       map[string]fieldMatch
+	normalization yamly.KeyNormalization
+	hasAliases    bool
+	// remain is the field collecting entries with unknown keys, nil if struct has no such field
+	remain *field
+}
+
+type structKey struct {
+	t                reflect.Type
+	inlineEmbedded   bool
+	fieldNaming      yamltag.FieldNaming
+	keyNormalization yamly.KeyNormalization
+}
+
+var structInfoCache sync.Map // map[structKey]*structInfo
+
+func cachedStructInfo(t reflect.Type, opts *options) (*structInfo, error) {
+	key := structKey{
+		t:                t,
+		inlineEmbedded:   opts.inlineEmbedded,
+		fieldNaming:      opts.fieldNaming,
+		keyNormalization: opts.keyNormalization,
+	}
+	if info, ok := structInfoCache.Load(key); ok {
+		return info.(*structInfo), nil // nolint: forcetypeassert
+	}
+
+	keys, normalization, err := yamltag.Keys(t, opts.inlineEmbedded, opts.fieldNaming, opts.keyNormalization)
+	if err != nil {
+		return nil, err
+	}
+	remain, hasRemain, err := yamltag.RemainField(t)
+	if err != nil {
+		return nil, err
+	}
+	info := &structInfo{
+		fields:        make([]field, 0, len(keys)),
+		byMatch:       make(map[string]fieldMatch, len(keys)),
+		normalization: normalization,
+	}
+	if hasRemain {
+		info.remain = &field{name: remain.Name, index: remain.Index, tags: yamltag.Parse(remain.Tag)}
+	}
+	for _, k := range keys {
+		info.fields = append(info.fields, field{
+			name:       k.Name,
+			index:      k.Field.Index,
+			tags:       k.Tags,
+			hasAliases: len(k.AliasMatches) > 0,
+		})
+		info.hasAliases = info.hasAliases || len(k.AliasMatches) > 0
+	}
+	for i := range info.fields {
+		info.byMatch[keys[i].Match] = fieldMatch{field: &info.fields[i]}
+		for _, aliasMatch := range keys[i].AliasMatches {
+			info.byMatch[aliasMatch] = fieldMatch{field: &info.fields[i], alias: true}
+		}
+	}
+
+	actual, _ := structInfoCache.LoadOrStore(key, info)
+	return actual.(*structInfo), nil // nolint: forcetypeassert
+}
+
+// fieldByIndex returns the nested field of struct v by index.
+// If alloc is true, nil pointers to inlined structs are allocated,
+// otherwise false is returned for them.
+func fieldByIndex(v reflect.Value, index []int, alloc bool) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 && v.Kind() == reflect.Pointer {
+			if v.IsNil() {
+				if !alloc || !v.CanSet() {
+					return reflect.Value{}, false
+				}
+				v.Set(reflect.New(v.Type().Elem()))
+			}
+			v = v.Elem()
+		}
+		v = v.Field(x)
+	}
+	return v, true
+}
+
+func isByteSequence(t reflect.Type) bool {
+	elem := t.Elem()
+	return elem.Kind() == reflect.Uint8 && elem.Name() == "uint8"
+}
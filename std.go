@@ -0,0 +1,64 @@
+package yamly
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// DecodeDuration decodes time.Duration from Go duration string (e.g. "1m30s").
+// Bare integers are treated as the amount of given unit, e.g. 30 means 30 seconds if unit is time.Second.
+// If the value can't be decoded, a ErrDenied error is stored in Decoder.
+func DecodeDuration(in Decoder, unit time.Duration) time.Duration {
+	s := in.String()
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		d := time.Duration(n) * unit
+		if unit != 0 && d/unit != time.Duration(n) {
+			in.AddError(DenyError(fmt.Errorf("duration %d of unit %s overflows", n, unit)))
+			return 0
+		}
+		return d
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		in.AddError(DenyError(err))
+		return 0
+	}
+	return d
+}
+
+// DecodeURL decodes url.URL from string.
+// If the value can't be parsed, a ErrDenied error is stored in Decoder.
+func DecodeURL(in Decoder) url.URL {
+	u, err := url.Parse(in.String())
+	if err != nil {
+		in.AddError(DenyError(err))
+		return url.URL{}
+	}
+	return *u
+}
+
+// DecodeTime decodes time.Time from string with given layout (see time.Parse).
+// If the value can't be parsed, a ErrDenied error is stored in Decoder.
+func DecodeTime(in Decoder, layout string) time.Time {
+	t, err := time.Parse(layout, in.String())
+	if err != nil {
+		in.AddError(DenyError(err))
+		return time.Time{}
+	}
+	return t
+}
+
+// DecodeUnixTime decodes time.Time from integer amount of given units elapsed since
+// January 1, 1970 UTC, e.g. seconds if unit is time.Second. Unit must divide a second evenly.
+// The result is in UTC.
+func DecodeUnixTime(in Decoder, unit time.Duration) time.Time {
+	n := in.Integer(64)
+	if unit <= 0 || unit > time.Second || time.Second%unit != 0 {
+		in.AddError(fmt.Errorf("invalid unix time unit %s", unit))
+		return time.Time{}
+	}
+	perSecond := int64(time.Second / unit)
+	return time.Unix(n/perSecond, n%perSecond*int64(unit)).UTC()
+}
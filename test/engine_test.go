@@ -0,0 +1,200 @@
+package test_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/engines/goyaml"
+	"github.com/KSpaceer/yamly/engines/yayamls"
+)
+
+type person struct {
+	Name      string
+	Nicknames []string
+}
+
+func (p *person) UnmarshalYamly(in yamly.Decoder) {
+	if in.TryNull() {
+		*p = person{}
+		return
+	}
+	state := in.Mapping()
+	for state.HasUnprocessedItems() {
+		switch key := in.String(); key {
+		case "name":
+			p.Name = in.String()
+		case "nicknames":
+			p.Nicknames = nil
+			seqState := in.Sequence()
+			for seqState.HasUnprocessedItems() {
+				p.Nicknames = append(p.Nicknames, in.String())
+			}
+		default:
+			in.Skip()
+		}
+	}
+}
+
+func (p person) MarshalYamly(out yamly.Inserter) {
+	out.StartMapping()
+	out.InsertString("name")
+	out.InsertString(p.Name)
+	out.InsertString("nicknames")
+	out.StartSequence()
+	for _, nickname := range p.Nicknames {
+		out.InsertString(nickname)
+	}
+	out.EndSequence()
+	out.EndMapping()
+}
+
+func TestEngines(t *testing.T) {
+	t.Parallel()
+
+	expected := []string{goyaml.EngineName, yayamls.EngineName}
+	if engines := yamly.Engines(); !reflect.DeepEqual(expected, engines) {
+		t.Errorf("expected engines %v, but got %v", expected, engines)
+	}
+}
+
+func TestMarshalUnmarshal(t *testing.T) {
+	t.Parallel()
+
+	src := person{Name: "yamly", Nicknames: []string{"yaml", "yay"}}
+
+	for _, marshalEngine := range yamly.Engines() {
+		for _, unmarshalEngine := range yamly.Engines() {
+			marshalEngine, unmarshalEngine := marshalEngine, unmarshalEngine
+			t.Run(marshalEngine+" to "+unmarshalEngine, func(t *testing.T) {
+				t.Parallel()
+
+				data, err := yamly.Marshal(src, yamly.WithEngine(marshalEngine))
+				if err != nil {
+					t.Fatalf("failed to marshal: %v", err)
+				}
+				var dst person
+				if err = yamly.Unmarshal(data, &dst, yamly.WithEngine(unmarshalEngine)); err != nil {
+					t.Fatalf("failed to unmarshal: %v", err)
+				}
+				if !reflect.DeepEqual(src, dst) {
+					t.Errorf("values are not equal:\nexpected: %v\ngot: %v", src, dst)
+				}
+			})
+		}
+	}
+}
+
+func TestMarshal_EngineSelection(t *testing.T) {
+	t.Parallel()
+
+	// both engines are imported, so engine can't be chosen automatically
+	if _, err := yamly.Marshal(person{}); !errors.Is(err, yamly.ErrEngineNotSelected) {
+		t.Errorf("expected error %v, but got %v", yamly.ErrEngineNotSelected, err)
+	}
+	if err := yamly.Unmarshal([]byte("name: yamly"), &person{}); !errors.Is(err, yamly.ErrEngineNotSelected) {
+		t.Errorf("expected error %v, but got %v", yamly.ErrEngineNotSelected, err)
+	}
+	if _, err := yamly.Marshal(person{}, yamly.WithEngine("unknown")); err == nil {
+		t.Errorf("expected error for unknown engine")
+	}
+}
+
+func TestUnmarshal_UniqueKeys(t *testing.T) {
+	t.Parallel()
+
+	for _, engine := range yamly.Engines() {
+		src := []byte("name: yamly\nnicknames: [y]\nname: again")
+		if err := yamly.Unmarshal(src, &person{}, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: unexpected error without option: %v", engine, err)
+		}
+		err := yamly.Unmarshal(src, &person{}, yamly.WithEngine(engine), yamly.WithUniqueKeys())
+		if !errors.Is(err, &yamly.DuplicateKeyError{}) {
+			t.Errorf("%s: expected duplicate key error, but got %v", engine, err)
+		}
+
+		// map targets decoded with reflection
+		var dst reflected[map[string][]map[string]int]
+		err = yamly.Unmarshal([]byte("a:\n  - {b: 1, b: 2}"), &dst, yamly.WithEngine(engine), yamly.WithUniqueKeys())
+		if !errors.Is(err, &yamly.DuplicateKeyError{}) {
+			t.Errorf("%s: expected duplicate key error for map target, but got %v", engine, err)
+		}
+	}
+}
+
+// pet is a tagged union decoded with yamly.DecodeUnion
+type pet struct {
+	kind string
+	who  person
+}
+
+func (p *pet) UnmarshalYamly(in yamly.Decoder) {
+	yamly.DecodeUnion(in, "kind", 0, func(variant string, in yamly.Decoder) {
+		p.kind = variant
+		state := in.Mapping()
+		for state.HasUnprocessedItems() {
+			switch in.String() {
+			case "owner":
+				p.who.UnmarshalYamly(in)
+			default:
+				in.Skip()
+			}
+		}
+	})
+}
+
+func (p pet) MarshalYamly(out yamly.Inserter) {
+	yamly.EncodeUnion(out, "kind", p.kind, func(out yamly.Inserter) {
+		out.StartMapping()
+		out.InsertString("owner")
+		p.who.MarshalYamly(out)
+		out.EndMapping()
+	})
+}
+
+func TestDecodeUnion(t *testing.T) {
+	t.Parallel()
+
+	src := pet{kind: "cat", who: person{Name: "yamly", Nicknames: []string{"y"}}}
+	for _, engine := range yamly.Engines() {
+		data, err := yamly.Marshal(src, yamly.WithEngine(engine))
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %v", engine, err)
+		}
+		var dst pet
+		if err = yamly.Unmarshal(data, &dst, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if !reflect.DeepEqual(src, dst) {
+			t.Errorf("%s: values are not equal:\nexpected: %v\ngot: %v", engine, src, dst)
+		}
+
+		// discriminator key is not required to be the first one
+		dst = pet{}
+		if err = yamly.Unmarshal([]byte("owner: {name: yamly}\nkind: dog"), &dst, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if dst.kind != "dog" || dst.who.Name != "yamly" {
+			t.Errorf("%s: unexpected value %v", engine, dst)
+		}
+
+		err = yamly.Unmarshal([]byte("owner: {name: yamly}"), &dst, yamly.WithEngine(engine))
+		if !errors.Is(err, &yamly.UnknownVariantError{}) {
+			t.Errorf("%s: expected unknown variant error, but got %v", engine, err)
+		}
+
+		// anchors defined outside of the union are visible in the buffered mapping
+		var aliased reflected[struct {
+			Owner person `yaml:"owner"`
+			Pet   pet    `yaml:"pet"`
+		}]
+		src := []byte("owner: &p {name: yamly}\npet: {kind: cat, owner: *p}")
+		if err = yamly.Unmarshal(src, &aliased, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: failed to unmarshal with alias: %v", engine, err)
+		}
+		if p := aliased.value.Pet; p.kind != "cat" || p.who.Name != "yamly" {
+			t.Errorf("%s: unexpected value with alias %v", engine, p)
+		}
+	}
+}
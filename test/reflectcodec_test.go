@@ -0,0 +1,506 @@
+package test_test
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"net"
+	"net/netip"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/KSpaceer/yamly"
+	"github.com/KSpaceer/yamly/reflectcodec"
+)
+
+// reflected adapts arbitrary value to yamly interfaces using reflectcodec.
+type reflected[T any] struct {
+	value T
+	opts  []reflectcodec.Option
+}
+
+func (r *reflected[T]) UnmarshalYamly(in yamly.Decoder) {
+	reflectcodec.Decode(in, &r.value, r.opts...)
+}
+
+func (r *reflected[T]) MarshalYamly(out yamly.Inserter) {
+	reflectcodec.Encode(out, r.value, r.opts...)
+}
+
+type reflectedInner struct {
+	Nested string `yaml:"nested"`
+}
+
+type reflectedStruct struct {
+	Name     string `yaml:"my_name"`
+	Age      int8   `yaml:"age,omitempty"`
+	Ignored  int    `yaml:"-"`
+	Inner    reflectedInner
+	Inlined  reflectedInner `yaml:",inline"`
+	Ptr      *float64
+	Bytes    []byte
+	Array    [2]uint16
+	Map      map[string][]bool
+	Time     time.Time
+	IP       net.IP
+	Any      any
+	Point    complex64
+	hidden   int
+	Embedded *reflectedInner
+}
+
+func reflectRoundTrip[T any](t *testing.T, src T, opts ...reflectcodec.Option) {
+	t.Helper()
+
+	for _, marshalEngine := range yamly.Engines() {
+		for _, unmarshalEngine := range yamly.Engines() {
+			data, err := yamly.Marshal(&reflected[T]{value: src, opts: opts}, yamly.WithEngine(marshalEngine))
+			if err != nil {
+				t.Fatalf("%s: failed to marshal: %v", marshalEngine, err)
+			}
+			dst := reflected[T]{opts: opts}
+			if err = yamly.Unmarshal(data, &dst, yamly.WithEngine(unmarshalEngine)); err != nil {
+				t.Fatalf("%s to %s: failed to unmarshal: %v\n%s", marshalEngine, unmarshalEngine, err, data)
+			}
+			if !reflect.DeepEqual(src, dst.value) {
+				t.Errorf("%s to %s: values are not equal:\nexpected: %#v\ngot: %#v",
+					marshalEngine, unmarshalEngine, src, dst.value)
+			}
+		}
+	}
+}
+
+func TestReflectCodec_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	f := 3.5
+	reflectRoundTrip(t, reflectedStruct{
+		Name:     "yamly",
+		Inner:    reflectedInner{Nested: "inner"},
+		Inlined:  reflectedInner{Nested: "inlined"},
+		Ptr:      &f,
+		Bytes:    []byte("bytes"),
+		Array:    [2]uint16{1, 2},
+		Map:      map[string][]bool{"flags": {true, false}},
+		Time:     time.Date(2023, time.August, 26, 12, 0, 0, 0, time.UTC),
+		IP:       net.IPv4(127, 0, 0, 1),
+		Any:      "any",
+		Point:    complex(1, 2),
+		Embedded: &reflectedInner{Nested: "embedded"},
+	})
+	reflectRoundTrip(t, []*reflectedInner{{Nested: "first"}, nil})
+	reflectRoundTrip(t, map[int]string{1: "one"})
+}
+
+func TestReflectCodec_Decode(t *testing.T) {
+	t.Parallel()
+
+	type tcase struct {
+		name          string
+		src           string
+		expected      reflectedStruct
+		opts          []reflectcodec.Option
+		expectedError error
+	}
+
+	tcases := []tcase{
+		{
+			name: "tags",
+			src:  "my_name: yamly\nage: 3\nIgnored: 10\nnested: inlined\nInner: {nested: inner}",
+			expected: reflectedStruct{
+				Name:    "yamly",
+				Age:     3,
+				Inner:   reflectedInner{Nested: "inner"},
+				Inlined: reflectedInner{Nested: "inlined"},
+			},
+		},
+		{
+			name:     "unknown field",
+			src:      "my_name: yamly\nunknown: value",
+			expected: reflectedStruct{Name: "yamly"},
+		},
+		{
+			name:          "disallowed unknown field",
+			src:           "my_name: yamly\nunknown: value",
+			opts:          []reflectcodec.Option{reflectcodec.WithDisallowUnknownFields()},
+			expectedError: &yamly.UnknownFieldError{},
+		},
+		{
+			name:          "denied value",
+			src:           "age: text",
+			expectedError: yamly.ErrDenied,
+		},
+	}
+
+	for _, tc := range tcases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			for _, engine := range yamly.Engines() {
+				dst := reflected[reflectedStruct]{opts: tc.opts}
+				err := yamly.Unmarshal([]byte(tc.src), &dst, yamly.WithEngine(engine))
+				if tc.expectedError != nil {
+					if !errors.Is(err, tc.expectedError) {
+						t.Errorf("%s: expected error %v, but got %v", engine, tc.expectedError, err)
+					}
+					continue
+				}
+				if err != nil {
+					t.Fatalf("%s: unexpected error: %v", engine, err)
+				}
+				if !reflect.DeepEqual(tc.expected, dst.value) {
+					t.Errorf("%s: values are not equal:\nexpected: %#v\ngot: %#v", engine, tc.expected, dst.value)
+				}
+			}
+		})
+	}
+}
+
+func TestReflectCodec_UnsupportedType(t *testing.T) {
+	t.Parallel()
+
+	for _, engine := range yamly.Engines() {
+		src := reflected[chan int]{value: make(chan int)}
+		if _, err := yamly.Marshal(&src, yamly.WithEngine(engine)); !errors.Is(err, reflectcodec.ErrUnsupportedType) {
+			t.Errorf("%s: expected error %v, but got %v", engine, reflectcodec.ErrUnsupportedType, err)
+		}
+		var dst reflected[chan int]
+		err := yamly.Unmarshal([]byte("value"), &dst, yamly.WithEngine(engine))
+		if !errors.Is(err, reflectcodec.ErrUnsupportedType) {
+			t.Errorf("%s: expected error %v, but got %v", engine, reflectcodec.ErrUnsupportedType, err)
+		}
+	}
+}
+
+func TestReflectCodec_FieldNaming(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		MaxRetries int
+		HTTPServer string `yaml:"server"`
+	}
+
+	src := reflected[value]{
+		value: value{MaxRetries: 3, HTTPServer: "yamly"},
+		opts:  []reflectcodec.Option{reflectcodec.WithFieldNaming("kebab")},
+	}
+	for _, engine := range yamly.Engines() {
+		data, err := yamly.Marshal(&src, yamly.WithEngine(engine))
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %v", engine, err)
+		}
+		var dst reflected[map[string]any]
+		if err = yamly.Unmarshal(data, &dst, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		expected := map[string]any{"max-retries": uint64(3), "server": "yamly"}
+		if !reflect.DeepEqual(expected, dst.value) {
+			t.Errorf("%s: values are not equal:\nexpected: %#v\ngot: %#v", engine, expected, dst.value)
+		}
+	}
+}
+
+func TestReflectCodec_NormalizedKeys(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		MaxRetries int
+		Timeout    int `yaml:"time_out"`
+	}
+
+	expected := value{MaxRetries: 3, Timeout: 10}
+	opts := []reflectcodec.Option{reflectcodec.WithCaseInsensitiveKeys(), reflectcodec.WithIgnoreKeySeparators()}
+	for _, src := range []string{
+		"maxRetries: 3\ntime_out: 10",
+		"max_retries: 3\nTIME-OUT: 10",
+		"MAX-RETRIES: 3\nTimeOut: 10",
+	} {
+		for _, engine := range yamly.Engines() {
+			dst := reflected[value]{opts: opts}
+			if err := yamly.Unmarshal([]byte(src), &dst, yamly.WithEngine(engine)); err != nil {
+				t.Fatalf("%s: failed to unmarshal %q: %v", engine, src, err)
+			}
+			if !reflect.DeepEqual(expected, dst.value) {
+				t.Errorf("%s: values are not equal for %q:\nexpected: %#v\ngot: %#v", engine, src, expected, dst.value)
+			}
+		}
+	}
+}
+
+func TestReflectCodec_KeyAliases(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		Timeout int `yaml:"timeout,alias=timeoutSeconds|timeout_s"`
+	}
+
+	for _, engine := range yamly.Engines() {
+		var (
+			dst      reflected[value]
+			warnings []error
+		)
+		err := yamly.Unmarshal([]byte("timeoutSeconds: 10"), &dst, yamly.WithEngine(engine), yamly.WithWarnings(&warnings))
+		if err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if dst.value.Timeout != 10 {
+			t.Errorf("%s: expected timeout 10, but got %d", engine, dst.value.Timeout)
+		}
+		expected := []error{&yamly.DeprecatedKeyWarning{Key: "timeout", Alias: "timeoutSeconds"}}
+		if !reflect.DeepEqual(expected, warnings) {
+			t.Errorf("%s: expected warnings %v, but got %v", engine, expected, warnings)
+		}
+
+		warnings = nil
+		err = yamly.Unmarshal([]byte("timeout: 10"), &dst, yamly.WithEngine(engine), yamly.WithWarnings(&warnings))
+		if err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if len(warnings) != 0 {
+			t.Errorf("%s: expected no warnings, but got %v", engine, warnings)
+		}
+
+		err = yamly.Unmarshal([]byte("timeout: 10\ntimeout_s: 20"), &dst, yamly.WithEngine(engine))
+		if !errors.Is(err, &yamly.KeyConflictError{}) {
+			t.Errorf("%s: expected key conflict error, but got %v", engine, err)
+		}
+
+		// null values of aliases are reported too
+		warnings = nil
+		err = yamly.Unmarshal([]byte("timeout_s: ~"), &dst, yamly.WithEngine(engine), yamly.WithWarnings(&warnings))
+		if err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		expected = []error{&yamly.DeprecatedKeyWarning{Key: "timeout", Alias: "timeout_s"}}
+		if !reflect.DeepEqual(expected, warnings) {
+			t.Errorf("%s: expected warnings %v, but got %v", engine, expected, warnings)
+		}
+		err = yamly.Unmarshal([]byte("timeout_s: ~\ntimeout: 20"), &dst, yamly.WithEngine(engine))
+		if !errors.Is(err, &yamly.KeyConflictError{}) {
+			t.Errorf("%s: expected key conflict error for null alias, but got %v", engine, err)
+		}
+	}
+}
+
+func TestReflectCodec_NormalizedKeyAliases(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		_       struct{} `yamly:"case-insensitive-keys"`
+		Timeout int      `yaml:"timeout,alias=timeoutSeconds"`
+	}
+
+	for _, engine := range yamly.Engines() {
+		var (
+			dst      reflected[value]
+			warnings []error
+		)
+		// differently written keys of the same field are not aliases of each other
+		err := yamly.Unmarshal([]byte("timeout: 10\nTimeout: 20"), &dst, yamly.WithEngine(engine),
+			yamly.WithWarnings(&warnings))
+		if err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if dst.value.Timeout != 20 || len(warnings) != 0 {
+			t.Errorf("%s: expected timeout 20 without warnings, but got %d %v", engine, dst.value.Timeout, warnings)
+		}
+
+		err = yamly.Unmarshal([]byte("TIMEOUTSECONDS: 10\nTimeout: 20"), &dst, yamly.WithEngine(engine))
+		if !errors.Is(err, &yamly.KeyConflictError{}) {
+			t.Errorf("%s: expected key conflict error, but got %v", engine, err)
+		}
+	}
+}
+
+func TestReflectCodec_Styles(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		Tags   []string          `yaml:"tags,flow"`
+		Script string            `yaml:"script,literal"`
+		Labels map[string]string `yaml:"labels,singlequoted"`
+	}
+
+	src := value{
+		Tags:   []string{"a", "b"},
+		Script: "echo 1\necho 2\n",
+		Labels: map[string]string{"app": "yamly"},
+	}
+	for _, engine := range yamly.Engines() {
+		data, err := yamly.Marshal(&reflected[value]{value: src}, yamly.WithEngine(engine))
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %v", engine, err)
+		}
+		for _, expected := range []string{`"tags": ["a", "b"]`, `"script": |`, `"app": 'yamly'`} {
+			if !strings.Contains(string(data), expected) {
+				t.Errorf("%s: expected %q in output:\n%s", engine, expected, data)
+			}
+		}
+	}
+	reflectRoundTrip(t, src)
+}
+
+func TestReflectCodec_Remain(t *testing.T) {
+	t.Parallel()
+
+	type anyRemain struct {
+		Name  string         `yaml:"name"`
+		Extra map[string]any `yaml:",remain"`
+	}
+	reflectRoundTrip(t, anyRemain{
+		Name:  "yamly",
+		Extra: map[string]any{"x-internal": true, "x-empty": nil},
+	})
+
+	type rawRemain struct {
+		Name  string                   `yaml:"name"`
+		Extra map[string]yamly.RawNode `yaml:",inline"`
+	}
+	for _, engine := range yamly.Engines() {
+		var dst reflected[rawRemain]
+		src := "name: yamly\nx-tags: [a, b]\nx-owner: {team: core}"
+		if err := yamly.Unmarshal([]byte(src), &dst, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if dst.value.Name != "yamly" || len(dst.value.Extra) != 2 {
+			t.Fatalf("%s: unexpected result %v", engine, dst.value)
+		}
+
+		data, err := yamly.Marshal(&dst, yamly.WithEngine(engine))
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %v", engine, err)
+		}
+		var restored reflected[map[string]any]
+		if err = yamly.Unmarshal(data, &restored, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		expected := map[string]any{
+			"name":    "yamly",
+			"x-tags":  []any{"a", "b"},
+			"x-owner": map[string]any{"team": "core"},
+		}
+		if !reflect.DeepEqual(expected, restored.value) {
+			t.Errorf("%s: expected %v, but got %v", engine, expected, restored.value)
+		}
+	}
+}
+
+func TestReflectCodec_StdTypes(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		Timeout time.Duration
+		Link    *url.URL
+		Addr    netip.Addr
+		Pattern *regexp.Regexp
+	}
+	reflectRoundTrip(t, value{
+		Timeout: 90 * time.Second,
+		Link:    &url.URL{Scheme: "https", Host: "example.com", Path: "/yamly"},
+		Addr:    netip.MustParseAddr("10.0.0.1"),
+		Pattern: regexp.MustCompile("^ya?ml$"),
+	})
+
+	for _, engine := range yamly.Engines() {
+		dst := reflected[value]{opts: []reflectcodec.Option{reflectcodec.WithDurationUnit(time.Millisecond)}}
+		err := yamly.Unmarshal([]byte("Timeout: 1500\nLink: https://example.com"), &dst, yamly.WithEngine(engine))
+		if err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if dst.value.Timeout != 1500*time.Millisecond || dst.value.Link.Host != "example.com" {
+			t.Errorf("%s: unexpected result %v", engine, dst.value)
+		}
+
+		err = yamly.Unmarshal([]byte("Timeout: soon"), &dst, yamly.WithEngine(engine))
+		if !errors.Is(err, yamly.ErrDenied) {
+			t.Errorf("%s: expected denied error, but got %v", engine, err)
+		}
+	}
+}
+
+func TestReflectCodec_TimeFormats(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		Date    time.Time  `yaml:"date,timeformat=2006-01-02"`
+		Created time.Time  `yaml:"created,unix"`
+		Updated *time.Time `yaml:"updated,unixmilli"`
+		Due     time.Time  `yaml:"due"`
+	}
+	updated := time.Date(2024, time.March, 1, 10, 20, 30, 123000000, time.UTC)
+	src := value{
+		Date:    time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC),
+		Created: time.Date(2024, time.March, 1, 10, 20, 30, 0, time.UTC),
+		Updated: &updated,
+		Due:     time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC),
+	}
+	reflectRoundTrip(t, src, reflectcodec.WithTimeFormat("02.01.2006"))
+
+	src.Due = time.Date(2024, time.March, 31, 23, 59, 59, 999999999, time.UTC)
+	reflectRoundTrip(t, src)
+
+	for _, engine := range yamly.Engines() {
+		dst := reflected[value]{opts: []reflectcodec.Option{reflectcodec.WithTimeFormat("02.01.2006")}}
+		data := "date: 2024-03-01\ncreated: 1709288430\nupdated: 1709288430123\ndue: 31.03.2024"
+		if err := yamly.Unmarshal([]byte(data), &dst, yamly.WithEngine(engine)); err != nil {
+			t.Fatalf("%s: failed to unmarshal: %v", engine, err)
+		}
+		if !dst.value.Created.Equal(src.Created) || !dst.value.Updated.Equal(updated) ||
+			dst.value.Due.Format(time.DateOnly) != "2024-03-31" {
+			t.Errorf("%s: unexpected result %v", engine, dst.value)
+		}
+
+		err := yamly.Unmarshal([]byte("date: 01.03.2024"), &dst, yamly.WithEngine(engine))
+		if !errors.Is(err, yamly.ErrDenied) {
+			t.Errorf("%s: expected denied error, but got %v", engine, err)
+		}
+
+		truncated := reflected[value]{value: src, opts: []reflectcodec.Option{reflectcodec.WithTimeNanoseconds(false)}}
+		out, err := yamly.Marshal(&truncated, yamly.WithEngine(engine))
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %v", engine, err)
+		}
+		if !strings.Contains(string(out), "2024-03-31T23:59:59Z") {
+			t.Errorf("%s: expected time without fractional seconds, but got\n%s", engine, out)
+		}
+	}
+}
+
+func TestReflectCodec_Codecs(t *testing.T) {
+	t.Parallel()
+
+	type value struct {
+		Bounds image.Rectangle
+		Areas  map[string]*image.Rectangle
+	}
+	rectCodec := reflectcodec.WithCodec(
+		func(in yamly.Decoder, r *image.Rectangle) {
+			_, err := fmt.Sscanf(in.String(), "%d,%d,%d,%d", &r.Min.X, &r.Min.Y, &r.Max.X, &r.Max.Y)
+			in.AddError(err)
+		},
+		func(out yamly.Inserter, r image.Rectangle) {
+			out.InsertString(fmt.Sprintf("%d,%d,%d,%d", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y))
+		},
+	)
+	src := value{
+		Bounds: image.Rect(0, 0, 4, 3),
+		Areas:  map[string]*image.Rectangle{"a": {Max: image.Pt(1, 1)}, "b": nil},
+	}
+	reflectRoundTrip(t, src, rectCodec)
+
+	for _, engine := range yamly.Engines() {
+		data, err := yamly.Marshal(&reflected[value]{value: src, opts: []reflectcodec.Option{rectCodec}},
+			yamly.WithEngine(engine))
+		if err != nil {
+			t.Fatalf("%s: failed to marshal: %v", engine, err)
+		}
+		if !strings.Contains(string(data), "0,0,4,3") || !strings.Contains(string(data), "0,0,1,1") {
+			t.Errorf("%s: expected rectangles encoded with codec, but got\n%s", engine, data)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/exec"
 	"path"
+	"slices"
 	"strings"
 	"testing"
 	"text/template"
@@ -48,37 +49,23 @@ func main() {
         fmt.Fprintln(os.Stderr, err)
         os.Exit(1)
     }
-	if reflect.DeepEqual(v, v2) {
-		fmt.Print("SUCCESS")
-    } else {
+	if !reflect.DeepEqual(v, v2) {
 		fmt.Printf("start: %v\n\n\nfinish: %v", v, v2)
+		return
 	}
+	{{ if .Check }}
+	if err := {{ .PkgName }}.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	{{ end }}
+	fmt.Print("SUCCESS")
 }
 `
 
 	mainCodeTemplate := template.Must(template.New("maincode").Parse(mainCode))
 
-	typeDefinitionCode := `
-package {{ .PkgName }}
-
-{{ if .Imports }}
-import (
-  {{ range $import := .Imports }}
-  "{{ $import }}"
-  {{ end }}
-)
-{{ end }}
-
-type TestType {{ .TypeDef }}
-
-{{ range $i, $typedef := .ExtraTypeDefs }}
-type ExtraType{{ $i }} {{ $typedef }}
-{{ end }}
-`
-
-	typeDefinitionCodeTemplate := template.Must(template.New("typedef").Parse(typeDefinitionCode))
-
-	runEngineTest(t, mainCodeTemplate, typeDefinitionCodeTemplate, "goyaml")
+	runEngineTest(t, mainCodeTemplate, "goyaml")
 }
 
 func TestGenerator_EngineYAYAMLS(t *testing.T) {
@@ -114,24 +101,112 @@ func main() {
         fmt.Fprintln(os.Stderr, err)
         os.Exit(1)
     }
-	if reflect.DeepEqual(v, v2) {
-		fmt.Print("SUCCESS")
-    } else {
+	if !reflect.DeepEqual(v, v2) {
 		fmt.Printf("start: %v\n\n\nfinish: %v", v, v2)
+		return
+	}
+	{{ if .Check }}
+	if err := {{ .PkgName }}.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	{{ end }}
+	fmt.Print("SUCCESS")
+}
+`
+
+	mainCodeTemplate := template.Must(template.New("maincode").Parse(mainCode))
+
+	runEngineTest(t, mainCodeTemplate, "yayamls")
+}
+
+func TestGenerator_RuntimeEngines(t *testing.T) {
+	t.Parallel()
+	mainCode := `
+package main
+
+import (
+  "fmt"
+  "reflect"
+  "os"
+  {{ range $import := .Imports }}
+  "{{ $import }}"
+  {{ end }}
+
+  "github.com/KSpaceer/yamly"
+  _ "github.com/KSpaceer/yamly/engines/goyaml"
+  _ "github.com/KSpaceer/yamly/engines/yayamls"
+
+  "github.com/KSpaceer/yamly/test/{{ .TmpRoot }}/{{ .PkgName }}"
+)
+
+func main() {
+	var v {{ if .UsePointer -}}*{{- end -}}{{ .PkgName }}.TestType
+	v = {{ .Value }}
+	for _, marshalEngine := range yamly.Engines() {
+		for _, unmarshalEngine := range yamly.Engines() {
+			data, err := yamly.Marshal(v, yamly.WithEngine(marshalEngine))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			var v2 {{ if .UsePointer -}}*{{- end -}}{{ .PkgName }}.TestType
+			{{ if .UsePointer }}
+			v2 = new({{ .PkgName }}.TestType)
+			{{ end }}
+			err = yamly.Unmarshal(data, {{ if not .UsePointer -}}&{{- end -}}v2, yamly.WithEngine(unmarshalEngine))
+			if err != nil {
+				fmt.Fprintln(os.Stderr, err)
+				os.Exit(1)
+			}
+			if !reflect.DeepEqual(v, v2) {
+				fmt.Printf("%s to %s\n\nstart: %v\n\n\nfinish: %v", marshalEngine, unmarshalEngine, v, v2)
+				return
+			}
+		}
 	}
+	{{ if .Check }}
+	if err := {{ .PkgName }}.Check(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	{{ end }}
+	fmt.Print("SUCCESS")
 }
 `
 
 	mainCodeTemplate := template.Must(template.New("maincode").Parse(mainCode))
 
-	typeDefinitionCode := `
+	// the code generated for one engine is run by both engines
+	for _, engine := range []string{"goyaml", "yayamls"} {
+		engine := engine
+		t.Run(engine, func(t *testing.T) {
+			t.Parallel()
+			runEngineTest(t, mainCodeTemplate, engine)
+		})
+	}
+}
+
+// typeDefinitionTemplate defines the package with tested type. If the case has Check code,
+// the package also exports Check function running it with every engine.
+var typeDefinitionTemplate = template.Must(template.New("typedef").Parse(`
 package {{ .PkgName }}
 
-{{ if .Imports }}
+{{ if or .Imports .TypeImports .Check }}
 import (
   {{ range $import := .Imports }}
   "{{ $import }}"
   {{ end }}
+  {{ range $import := .TypeImports }}
+  "{{ $import }}"
+  {{ end }}
+  {{ if .Check }}
+  "fmt"
+
+  "github.com/KSpaceer/yamly"
+  _ "github.com/KSpaceer/yamly/engines/goyaml"
+  _ "github.com/KSpaceer/yamly/engines/yayamls"
+  {{ end }}
 )
 {{ end }}
 
@@ -140,33 +215,60 @@ type TestType {{ .TypeDef }}
 {{ range $i, $typedef := .ExtraTypeDefs }}
 type ExtraType{{ $i }} {{ $typedef }}
 {{ end }}
-`
 
-	typeDefinitionCodeTemplate := template.Must(template.New("typedef").Parse(typeDefinitionCode))
+{{ .ExtraCode }}
+
+{{ if .Check }}
+// Check runs the checks of the case with every engine.
+func Check() error {
+	for _, engine := range yamly.Engines() {
+		if err := check(engine); err != nil {
+			return fmt.Errorf("%s: %w", engine, err)
+		}
+	}
+	return nil
+}
+
+func check(engine string) error {
+{{ .Check }}
+}
+
+// marshal and unmarshal assert yamly interfaces, because the generator is bootstrapped with stub methods
 
-	runEngineTest(t, mainCodeTemplate, typeDefinitionCodeTemplate, "yayamls")
+func marshal(v any, engine string) ([]byte, error) {
+	return yamly.Marshal(v.(yamly.MarshalerYamly), yamly.WithEngine(engine))
 }
 
-func runEngineTest(
-	t *testing.T,
-	mainCodeTemplate, typeDefinitionTemplate *template.Template,
-	engine string,
-) {
+func unmarshal(data []byte, v any, engine string, opts ...yamly.Option) error {
+	return yamly.Unmarshal(data, v.(yamly.UnmarshalerYamly), append(opts, yamly.WithEngine(engine))...)
+}
+{{ end }}
+`))
+
+func runEngineTest(t *testing.T, mainCodeTemplate *template.Template, engine string) {
 	t.Helper()
 	type tcase struct {
 		name string
 
 		flags []string
+		// engines limits the engines the case is run for. Empty list means all engines.
+		engines []string
 
 		TmpRoot string
 
-		Imports    []string
-		PkgName    string
-		TypeDef    string
-		Value      string
-		UsePointer bool
+		Imports []string
+		// TypeImports are imported only by the package with type definitions
+		TypeImports []string
+		PkgName     string
+		TypeDef     string
+		Value       string
+		UsePointer  bool
 
 		ExtraTypeDefs []string
+		ExtraCode     string
+		// Check is the body of function checking generated code with the engine given by "engine" argument.
+		// The function can use marshal and unmarshal helpers and returns error if check fails.
+		Check string
 	}
 
 	tcases := []tcase{
@@ -264,10 +366,654 @@ func runEngineTest(
 				"struct{ Nested string `yaml:\"nested\"`; }",
 			},
 		},
+		{
+			name:    "reflect fallback",
+			flags:   []string{"--reflect-fallback"},
+			PkgName: "reflected",
+			Imports: []string{"image"},
+			TypeDef: "struct{ Link image.Point; Point complex128; Links []*image.Point }",
+			Value: `reflected.TestType{Link: image.Point{X: 1, Y: 2}, ` +
+				`Point: complex(1, -2), Links: []*image.Point{{X: 3}, nil}}`,
+		},
+		{
+			name:    "custom codecs",
+			flags:   []string{"--codec", "image.Rectangle=decodeRect,encodeRect"},
+			PkgName: "customcodecs",
+			Imports: []string{"image"},
+			TypeDef: "struct{ Bounds image.Rectangle; Area *image.Rectangle; Areas []image.Rectangle; " +
+				"Point complex128 `yamly:\"codec=decodeComplex,encodeComplex\"` }",
+			Value: `customcodecs.TestType{Bounds: image.Rect(0, 0, 4, 3), Area: &image.Rectangle{Max: image.Pt(1, 1)}, ` +
+				`Areas: []image.Rectangle{image.Rect(1, 2, 3, 4)}, Point: complex(1, -2)}`,
+			TypeImports: []string{"fmt", "strconv", "github.com/KSpaceer/yamly"},
+			ExtraCode: `
+func decodeRect(in yamly.Decoder, r *image.Rectangle) {
+	_, err := fmt.Sscanf(in.String(), "%d,%d,%d,%d", &r.Min.X, &r.Min.Y, &r.Max.X, &r.Max.Y)
+	if err != nil {
+		in.AddError(err)
+	}
+}
+
+func encodeRect(out yamly.Inserter, r image.Rectangle) {
+	out.InsertString(fmt.Sprintf("%d,%d,%d,%d", r.Min.X, r.Min.Y, r.Max.X, r.Max.Y))
+}
+
+func decodeComplex(in yamly.Decoder, c *complex128) {
+	v, err := strconv.ParseComplex(in.String(), 128)
+	if err != nil {
+		in.AddError(err)
+	}
+	*c = v
+}
+
+func encodeComplex(out yamly.Inserter, c complex128) {
+	out.InsertString(strconv.FormatComplex(c, 'g', -1, 128))
+}
+`,
+		},
+		{
+			name:    "tagged unions",
+			flags:   []string{"--disallow-unknown-fields"},
+			PkgName: "unions",
+			TypeDef: "struct{ Main Shape; Steps []Shape; Named map[string]Shape; Missing Shape }",
+			Value: `unions.TestType{Main: &unions.Circle{Radius: 2}, Steps: []unions.Shape{unions.Square{Side: 3}, nil, ` +
+				`&unions.Labeled{Kind: "labeled", Label: "x"}}, Named: map[string]unions.Shape{"c": &unions.Circle{}}}`,
+			ExtraCode: `
+//yamly:union kind=circle:*Circle,square:Square,labeled:*Labeled
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64 "yaml:\"radius\""
+}
+
+func (c *Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type Square struct {
+	Side float64 "yaml:\"side\""
+}
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// Labeled keeps discriminator in its own field
+type Labeled struct {
+	Kind  string "yaml:\"kind\""
+	Label string "yaml:\"label\""
+}
+
+func (*Labeled) Area() float64 { return 0 }
+`,
+		},
+		{
+			name:        "tagged union errors",
+			PkgName:     "unionerrors",
+			TypeDef:     "struct{ Main Shape }",
+			Value:       `unionerrors.TestType{Main: unionerrors.Square{Side: 1}}`,
+			TypeImports: []string{"errors", "strings"},
+			ExtraCode: `
+//yamly:union kind=circle:*Circle,square:Square,labeled:*Labeled
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64 "yaml:\"radius\""
+}
+
+func (c *Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+type Square struct {
+	Side float64 "yaml:\"side\""
+}
+
+func (s Square) Area() float64 { return s.Side * s.Side }
+
+// Labeled keeps discriminator in its own field
+type Labeled struct {
+	Kind  string "yaml:\"kind\""
+	Label string "yaml:\"label\""
+}
+
+func (*Labeled) Area() float64 { return 0 }
+
+type Triangle struct{}
+
+func (Triangle) Area() float64 { return 0 }
+`,
+			Check: `
+	data, err := marshal(TestType{Main: &Circle{Radius: 1}}, engine)
+	if err != nil || !strings.Contains(string(data), "circle") {
+		return fmt.Errorf("expected discriminator in output, got %s (%v)", data, err)
+	}
+	var v TestType
+	if err = unmarshal([]byte("Main: {radius: 1, kind: circle}"), &v, engine); err != nil || v.Main.Area() != 3 {
+		return fmt.Errorf("failed to decode union with discriminator after fields: %v", err)
+	}
+	for _, src := range []string{"Main: {kind: hexagon}", "Main: {side: 1}"} {
+		if err = unmarshal([]byte(src), &v, engine); !errors.Is(err, &yamly.UnknownVariantError{}) {
+			return fmt.Errorf("expected unknown variant error for %q, but got %v", src, err)
+		}
+	}
+	if _, err = marshal(TestType{Main: Triangle{}}, engine); !errors.Is(err, &yamly.UnknownVariantError{}) {
+		return fmt.Errorf("expected unknown variant error for Triangle, but got %v", err)
+	}
+
+	// discriminator field of the variant is always written with the registered value
+	for _, kind := range []string{"", "circle"} {
+		labeled := &Labeled{Kind: kind, Label: "x"}
+		if data, err = marshal(TestType{Main: labeled}, engine); err != nil {
+			return fmt.Errorf("failed to encode variant with discriminator %q: %v", kind, err)
+		}
+		if labeled.Kind != kind {
+			return fmt.Errorf("encoding changed the variant: %v", labeled)
+		}
+		if strings.Count(string(data), "kind") != 1 {
+			return fmt.Errorf("expected single discriminator in output, got %s", data)
+		}
+		if err = unmarshal(data, &v, engine); err != nil {
+			return fmt.Errorf("failed to decode variant with discriminator %q: %v", kind, err)
+		}
+		if l, ok := v.Main.(*Labeled); !ok || l.Kind != "labeled" || l.Label != "x" {
+			return fmt.Errorf("unexpected result for discriminator %q: %#v", kind, v.Main)
+		}
+	}
+	return nil
+`,
+		},
+		{
+			name:        "normalized tagged unions",
+			flags:       []string{"--case-insensitive-keys"},
+			PkgName:     "normalizedunions",
+			TypeDef:     "struct{ Main Shape }",
+			Value:       `normalizedunions.TestType{Main: &normalizedunions.Labeled{Kind: "labeled", Label: "x"}}`,
+			TypeImports: []string{"strings"},
+			ExtraCode: `
+//yamly:union kind=circle:*Circle,labeled:*Labeled
+type Shape interface {
+	Area() float64
+}
+
+type Circle struct {
+	Radius float64
+}
+
+func (c *Circle) Area() float64 { return 3 * c.Radius * c.Radius }
+
+// Labeled keeps discriminator in its own field matching the key after normalization
+type Labeled struct {
+	Kind  string
+	Label string
+}
+
+func (*Labeled) Area() float64 { return 0 }
+`,
+			Check: `
+	var v TestType
+	if err := unmarshal([]byte("Main: {Radius: 1, KIND: circle}"), &v, engine); err != nil || v.Main.Area() != 3 {
+		return fmt.Errorf("failed to decode union with normalized discriminator key: %v", err)
+	}
+	data, err := marshal(TestType{Main: &Labeled{Label: "x"}}, engine)
+	if err != nil {
+		return err
+	}
+	if strings.Count(strings.ToLower(string(data)), "kind") != 1 {
+		return fmt.Errorf("expected single discriminator in output, got %s", data)
+	}
+	return nil
+`,
+		},
+		{
+			name:    "standard library types",
+			PkgName: "stdtypes",
+			Imports: []string{"net", "net/netip", "net/url", "regexp", "time"},
+			TypeDef: "struct{ Timeout time.Duration; Link url.URL; IP net.IP; Addr netip.Addr; " +
+				"Pattern *regexp.Regexp; Intervals []time.Duration }",
+			Value: `stdtypes.TestType{Timeout: 90 * time.Second, ` +
+				`Link: url.URL{Scheme: "https", Host: "example.com", Path: "/yamly"}, IP: net.IPv4(127, 0, 0, 1), ` +
+				`Addr: netip.MustParseAddr("::1"), Pattern: regexp.MustCompile("^ya?ml$"), ` +
+				`Intervals: []time.Duration{time.Millisecond, time.Hour}}`,
+			TypeImports: []string{"strings"},
+			Check: `
+	// net.IP, netip.Addr and *regexp.Regexp are not registered codecs, they are processed as text
+	v := TestType{IP: net.IPv4(10, 0, 0, 1), Addr: netip.MustParseAddr("fe80::1"), Pattern: regexp.MustCompile("a+b")}
+	data, err := marshal(v, engine)
+	if err != nil {
+		return err
+	}
+	for _, expected := range []string{"10.0.0.1", "fe80::1", "a+b"} {
+		if !strings.Contains(string(data), expected) {
+			return fmt.Errorf("expected text %q in output:\n%s", expected, data)
+		}
+	}
+	var v2 TestType
+	if err = unmarshal([]byte("IP: 10.0.0.1\nAddr: fe80::1\nPattern: a+b"), &v2, engine); err != nil {
+		return err
+	}
+	if !v2.IP.Equal(v.IP) || v2.Addr != v.Addr || v2.Pattern == nil || v2.Pattern.String() != "a+b" {
+		return fmt.Errorf("unexpected result: %v", v2)
+	}
+	for _, src := range []string{"IP: 10.0.0", "Addr: fe80:::1", "Pattern: a(b"} {
+		if err = unmarshal([]byte(src), &v2, engine); err == nil {
+			return fmt.Errorf("expected error for invalid text %q", src)
+		}
+	}
+	return nil
+`,
+		},
+		{
+			name:        "duration unit",
+			flags:       []string{"--duration-unit", "s"},
+			PkgName:     "durationunit",
+			TypeDef:     "struct{ Timeout time.Duration; Intervals []time.Duration }",
+			Value:       `durationunit.TestType{Timeout: 90 * time.Second}`,
+			Imports:     []string{"time"},
+			TypeImports: []string{"reflect"},
+			Check: `
+	var v TestType
+	if err := unmarshal([]byte("Timeout: 30\nIntervals: [1m30s, 2]"), &v, engine); err != nil {
+		return err
+	}
+	if v.Timeout != 30*time.Second || !reflect.DeepEqual(v.Intervals, []time.Duration{90 * time.Second, 2 * time.Second}) {
+		return fmt.Errorf("unexpected durations: %v %v", v.Timeout, v.Intervals)
+	}
+	if err := unmarshal([]byte("Timeout: soon"), &v, engine); err == nil {
+		return fmt.Errorf("expected error for invalid duration")
+	}
+	return nil
+`,
+		},
+		{
+			name:    "time formats",
+			flags:   []string{"--time-format", "02.01.2006"},
+			PkgName: "timeformats",
+			Imports: []string{"time"},
+			TypeDef: "struct{ Date time.Time `yaml:\"date,timeformat=2006-01-02\"`; Created time.Time `yaml:\",unix\"`; " +
+				"Updated *time.Time `yaml:\",unixmilli\"`; Due time.Time; " +
+				"Stamps []time.Time `yaml:\",timeformat=2006-01-02 15:04\"` }",
+			Value: `timeformats.TestType{Date: time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC), ` +
+				`Created: time.Unix(1709288430, 0).UTC(), Updated: func() *time.Time { ` +
+				`t := time.UnixMilli(1709288430123).UTC(); return &t }(), ` +
+				`Due: time.Date(2024, time.March, 31, 0, 0, 0, 0, time.UTC), ` +
+				`Stamps: []time.Time{time.Date(2024, time.March, 1, 10, 20, 0, 0, time.UTC)}}`,
+		},
+		{
+			name:        "time without nanoseconds",
+			flags:       []string{"--time-nanoseconds=false"},
+			PkgName:     "timeseconds",
+			Imports:     []string{"time"},
+			TypeDef:     "struct{ Created time.Time; Stamps map[string]time.Time }",
+			Value:       `timeseconds.TestType{Created: time.Date(2024, time.March, 1, 10, 20, 30, 0, time.UTC)}`,
+			TypeImports: []string{"strings"},
+			Check: `
+	v := TestType{Stamps: map[string]time.Time{"due": time.Date(2024, time.March, 31, 23, 59, 59, 999999999, time.UTC)}}
+	data, err := marshal(v, engine)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(string(data), "2024-03-31T23:59:59Z") {
+		return fmt.Errorf("expected time without fractional seconds, got %s", data)
+	}
+	return nil
+`,
+		},
+		{
+			name:    "field naming",
+			flags:   []string{"--field-naming", "snake", "--reflect-fallback"},
+			PkgName: "snakecase",
+			Imports: []string{"net/url"},
+			TypeDef: "struct{ MaxRetries int; HTTPServer string `yaml:\"server\"`; Endpoint url.URL; }",
+			Value:   `snakecase.TestType{MaxRetries: 3, HTTPServer: "yamly", Endpoint: url.URL{Host: "example.com"}}`,
+		},
+		{
+			name:        "normalized keys",
+			flags:       []string{"--case-insensitive-keys", "--ignore-key-separators"},
+			PkgName:     "normalizedkeys",
+			TypeDef:     "struct{ MaxRetries int; Inner ExtraType0; }",
+			Value:       `normalizedkeys.TestType{MaxRetries: 3, Inner: normalizedkeys.ExtraType0{InnerKey: "yamly"}}`,
+			TypeImports: []string{"reflect"},
+			ExtraTypeDefs: []string{
+				"struct{ _ struct{} `yamly:\"exact-keys\"`; InnerKey string; }",
+			},
+			Check: `
+	expected := TestType{MaxRetries: 3, Inner: ExtraType0{InnerKey: "yamly"}}
+	for _, src := range []string{
+		"maxRetries: 3\ninner: {InnerKey: yamly, inner_key: skipped}",
+		"max_retries: 3\nINNER: {InnerKey: yamly}",
+		"MAX-RETRIES: 3\nInner: {InnerKey: yamly}",
+	} {
+		var v TestType
+		if err := unmarshal([]byte(src), &v, engine); err != nil || !reflect.DeepEqual(expected, v) {
+			return fmt.Errorf("unexpected result for %q: %v %v", src, v, err)
+		}
+	}
+	return nil
+`,
+		},
+		{
+			name:        "key aliases",
+			PkgName:     "keyaliases",
+			TypeDef:     "struct{ Timeout int `yaml:\"timeout,alias=timeoutSeconds|timeout_s\"`; Name string; }",
+			Value:       `keyaliases.TestType{Timeout: 10, Name: "yamly"}`,
+			TypeImports: []string{"errors", "reflect"},
+			Check: `
+	decodeWithWarnings := func(src string) (TestType, []error, error) {
+		var (
+			v        TestType
+			warnings []error
+		)
+		err := unmarshal([]byte(src), &v, engine, yamly.WithWarnings(&warnings))
+		return v, warnings, err
+	}
+
+	expected := TestType{Timeout: 10, Name: "yamly"}
+	v, warnings, err := decodeWithWarnings("timeout_s: 10\nName: yamly")
+	expectedWarnings := []error{&yamly.DeprecatedKeyWarning{Key: "timeout", Alias: "timeout_s"}}
+	if err != nil || !reflect.DeepEqual(expected, v) || !reflect.DeepEqual(expectedWarnings, warnings) {
+		return fmt.Errorf("unexpected result for alias: %v %v %v", v, warnings, err)
+	}
+	if _, warnings, err = decodeWithWarnings("timeout: 10\nName: yamly"); err != nil || len(warnings) != 0 {
+		return fmt.Errorf("unexpected result for key: %v %v", warnings, err)
+	}
+	if _, _, err = decodeWithWarnings("timeoutSeconds: 10\ntimeout: 20"); !errors.Is(err, &yamly.KeyConflictError{}) {
+		return fmt.Errorf("expected key conflict error, but got %v", err)
+	}
+	// null values of aliases are reported too
+	expectedWarnings = []error{&yamly.DeprecatedKeyWarning{Key: "timeout", Alias: "timeoutSeconds"}}
+	if _, warnings, err = decodeWithWarnings("timeoutSeconds: ~"); err != nil ||
+		!reflect.DeepEqual(expectedWarnings, warnings) {
+		return fmt.Errorf("unexpected result for null alias: %v %v", warnings, err)
+	}
+	if _, _, err = decodeWithWarnings("timeoutSeconds: ~\ntimeout: 20"); !errors.Is(err, &yamly.KeyConflictError{}) {
+		return fmt.Errorf("expected key conflict error for null alias, but got %v", err)
+	}
+	return nil
+`,
+		},
+		{
+			name:        "normalized key aliases",
+			flags:       []string{"--case-insensitive-keys"},
+			PkgName:     "normalizedkeyaliases",
+			TypeDef:     "struct{ Timeout int `yaml:\"timeout,alias=timeoutSeconds\"`; }",
+			Value:       `normalizedkeyaliases.TestType{Timeout: 10}`,
+			TypeImports: []string{"errors"},
+			Check: `
+	decodeWithWarnings := func(src string) (TestType, []error, error) {
+		var (
+			v        TestType
+			warnings []error
+		)
+		err := unmarshal([]byte(src), &v, engine, yamly.WithWarnings(&warnings))
+		return v, warnings, err
+	}
+
+	// differently written keys of the same field are not aliases of each other
+	v, warnings, err := decodeWithWarnings("timeout: 10\nTimeout: 20")
+	if err != nil || v.Timeout != 20 || len(warnings) != 0 {
+		return fmt.Errorf("unexpected result for keys of different case: %v %v %v", v, warnings, err)
+	}
+	if _, _, err = decodeWithWarnings("TIMEOUTSECONDS: 10\nTimeout: 20"); !errors.Is(err, &yamly.KeyConflictError{}) {
+		return fmt.Errorf("expected key conflict error, but got %v", err)
+	}
+	return nil
+`,
+		},
+		{
+			name:    "field styles",
+			PkgName: "fieldstyles",
+			TypeDef: "struct{ Tags []string `yaml:\"tags,flow\"`; Script string `yaml:\"script,literal\"`; " +
+				"Note *string `yaml:\"note,singlequoted\"`; }",
+			Value:       `fieldstyles.TestType{Tags: []string{"a", "b"}, Script: "echo 1\necho 2\n"}`,
+			TypeImports: []string{"strings", "github.com/KSpaceer/yamly/engines/yayamls/encode"},
+			ExtraCode: `
+// plainInserter hides optional interfaces of the wrapped inserter.
+type plainInserter struct {
+	yamly.Inserter
+}
+`,
+			Check: `
+	note := "it is"
+	data, err := marshal(TestType{Tags: []string{"a", "b"}, Script: "echo 1\necho 2\n", Note: &note}, engine)
+	if err != nil {
+		return err
+	}
+	for _, expected := range []string{"\"tags\": [\"a\", \"b\"]", "\"script\": |", "echo 1\n", "\"note\": 'it is'"} {
+		if !strings.Contains(string(data), expected) {
+			return fmt.Errorf("expected %q in output:\n%s", expected, data)
+		}
+	}
+
+	// inserters without styles support are accepted too, the styles are just not applied
+	b := encode.NewASTBuilder()
+	any(TestType{Tags: []string{"a", "b"}}).(yamly.MarshalerYamly).MarshalYamly(plainInserter{b})
+	tree, err := b.Result()
+	if err != nil {
+		return err
+	}
+	data, err = encode.NewASTWriter().WriteBytes(tree)
+	if err != nil {
+		return err
+	}
+	if strings.Contains(string(data), "[") {
+		return fmt.Errorf("expected block sequence in output:\n%s", data)
+	}
+	return nil
+`,
+		},
+		{
+			name:    "remain field",
+			PkgName: "remain",
+			TypeDef: "struct{ Name string `yaml:\"name\"`; Extra map[string]any `yaml:\",remain\"`; }",
+			Value:   `remain.TestType{Name: "yamly", Extra: map[string]any{"x-internal": true, "x-empty": nil}}`,
+		},
+		{
+			name:    "inlined raw nodes map",
+			PkgName: "remainraw",
+			TypeDef: "struct{ Name string `yaml:\"name\"`; Extra map[string]yamly.RawNode `yaml:\",inline\"`; }",
+			Value:   `remainraw.TestType{Name: "yamly"}`,
+			Check: `
+	var v TestType
+	if err := unmarshal([]byte("name: yamly\nx-tags: [a, b]\nx-null: ~"), &v, engine); err != nil {
+		return err
+	}
+	if v.Name != "yamly" || len(v.Extra) != 2 || string(v.Extra["x-tags"]) == "" || v.Extra["x-null"] != nil {
+		return fmt.Errorf("unexpected result: %#v", v)
+	}
+	data, err := marshal(v, engine)
+	if err != nil {
+		return err
+	}
+	var restored TestType
+	if err = unmarshal(data, &restored, engine); err != nil {
+		return err
+	}
+	if restored.Name != "yamly" || len(restored.Extra) != 2 || restored.Extra["x-null"] != nil {
+		return fmt.Errorf("unexpected restored result: %#v\n%s", restored, data)
+	}
+	return nil
+`,
+		},
+		{
+			name:        "node marshalers",
+			engines:     []string{"yayamls"},
+			PkgName:     "nodemarshalers",
+			TypeDef:     "struct{ Name string; Extra ExtraType0; }",
+			Value:       `nodemarshalers.TestType{Name: "yamly", Extra: nodemarshalers.ExtraType0{}.WithValue("hidden")}`,
+			TypeImports: []string{"fmt", "github.com/KSpaceer/yamly/engines/yayamls/ast"},
+			ExtraTypeDefs: []string{
+				"struct{ value string }",
+			},
+			// unexported field can be restored only by node marshalers
+			ExtraCode: `
+func (e ExtraType0) WithValue(v string) ExtraType0 {
+	e.value = v
+	return e
+}
+
+func (e ExtraType0) MarshalYAMLNode() (ast.Node, error) {
+	return ast.NewTextNode(e.value), nil
+}
+
+func (e *ExtraType0) UnmarshalYAMLNode(n ast.Node) error {
+	txt, ok := n.(*ast.TextNode)
+	if !ok {
+		return fmt.Errorf("expected text node, got %s", n.Type())
+	}
+	e.value = txt.Text()
+	return nil
+}
+`,
+		},
+		{
+			name:        "interface fields",
+			PkgName:     "ifaces",
+			TypeDef:     "struct{ Value any; Values []any; Named map[string]any; Custom *ExtraType0; }",
+			Value:       `ifaces.TestType{Value: "text", Values: []any{"a", true}, Named: map[string]any{"k": "v"}}`,
+			TypeImports: []string{"reflect"},
+			ExtraTypeDefs: []string{
+				"struct{ Counter Counter; }",
+			},
+			ExtraCode: `
+type Counter interface {
+	yamly.UnmarshalerYamly
+	yamly.MarshalerYamly
+}
+
+type counter struct{ n int64 }
+
+func (c *counter) UnmarshalYamly(in yamly.Decoder) { c.n = in.Integer(64) }
+
+func (c *counter) MarshalYamly(out yamly.Inserter) { out.InsertInteger(c.n) }
+`,
+			Check: `
+	v := TestType{Custom: &ExtraType0{Counter: &counter{n: 3}}}
+	data, err := marshal(v, engine)
+	if err != nil {
+		return err
+	}
+	// non-empty interfaces are decoded into the values they hold
+	restored := TestType{Custom: &ExtraType0{Counter: &counter{}}}
+	if err = unmarshal(data, &restored, engine); err != nil || !reflect.DeepEqual(v, restored) {
+		return fmt.Errorf("unexpected result: %v %v", restored.Custom.Counter, err)
+	}
+	return nil
+`,
+		},
+		{
+			name:        "goyaml interface fields",
+			engines:     []string{"goyaml"},
+			PkgName:     "goyamlifaces",
+			TypeDef:     "struct{ Name string; Custom *ExtraType0; }",
+			Value:       `goyamlifaces.TestType{Name: "yamly"}`,
+			TypeImports: []string{"errors", "reflect", "gopkg.in/yaml.v3"},
+			ExtraTypeDefs: []string{
+				"struct{ Node Node; }",
+			},
+			ExtraCode: `
+type Node interface {
+	yaml.Unmarshaler
+	yaml.Marshaler
+}
+
+type word struct{ text string }
+
+func (w *word) UnmarshalYAML(n *yaml.Node) error {
+	w.text = n.Value
+	return nil
+}
+
+func (w *word) MarshalYAML() (any, error) { return w.text, nil }
+`,
+			Check: `
+	v := TestType{Name: "yamly", Custom: &ExtraType0{Node: &word{text: "hidden"}}}
+	data, err := marshal(v, engine)
+	if err != nil {
+		return err
+	}
+	restored := TestType{Custom: &ExtraType0{Node: &word{}}}
+	err = unmarshal(data, &restored, engine)
+	if engine != "goyaml" {
+		// go-yaml unmarshalers require go-yaml nodes
+		if !errors.Is(err, yamly.ErrUnmarshalerImplementation) {
+			return fmt.Errorf("expected unmarshaler implementation error, but got %v", err)
+		}
+		return nil
+	}
+	if err != nil || !reflect.DeepEqual(v, restored) {
+		return fmt.Errorf("unexpected result: %v %v\n%s", restored.Custom.Node, err, data)
+	}
+	return nil
+`,
+		},
+		{
+			name:    "yayamls interface fields",
+			engines: []string{"yayamls"},
+			PkgName: "yayamlsifaces",
+			TypeDef: "struct{ Name string; Custom *ExtraType0; }",
+			Value:   `yayamlsifaces.TestType{Name: "yamly"}`,
+			TypeImports: []string{
+				"reflect", "strings",
+				"github.com/KSpaceer/yamly/engines/yayamls", "github.com/KSpaceer/yamly/engines/yayamls/ast",
+			},
+			ExtraTypeDefs: []string{
+				"struct{ Node Node; Raw Raw; }",
+			},
+			ExtraCode: `
+type Node interface {
+	yayamls.NodeUnmarshaler
+	yayamls.NodeMarshaler
+}
+
+type Raw interface {
+	yayamls.Unmarshaler
+	yayamls.Marshaler
+}
+
+type word struct{ text string }
+
+func (w *word) UnmarshalYAMLNode(n ast.Node) error {
+	txt, ok := n.(*ast.TextNode)
+	if !ok {
+		return fmt.Errorf("expected text node, got %s", n.Type())
+	}
+	w.text = txt.Text()
+	return nil
+}
+
+func (w *word) MarshalYAMLNode() (ast.Node, error) { return ast.NewTextNode(w.text), nil }
+
+type rawWord struct{ text string }
+
+func (w *rawWord) UnmarshalYAML(data []byte) error {
+	w.text = strings.TrimSpace(string(data))
+	return nil
+}
+
+func (w *rawWord) MarshalYAML() ([]byte, error) { return []byte(w.text), nil }
+`,
+			Check: `
+	if engine != "yayamls" {
+		// yayamls marshalers are not supported by other engines
+		return nil
+	}
+	v := TestType{Name: "yamly", Custom: &ExtraType0{Node: &word{text: "hidden"}, Raw: &rawWord{text: "raw"}}}
+	data, err := marshal(v, engine)
+	if err != nil {
+		return err
+	}
+	restored := TestType{Custom: &ExtraType0{Node: &word{}, Raw: &rawWord{}}}
+	if err = unmarshal(data, &restored, engine); err != nil || !reflect.DeepEqual(v, restored) {
+		return fmt.Errorf("unexpected result: %v %v %v\n%s", restored.Custom.Node, restored.Custom.Raw, err, data)
+	}
+	return nil
+`,
+		},
 	}
 
 	for _, tc := range tcases {
 		t.Run(tc.name, func(t *testing.T) {
+			if len(tc.engines) > 0 && !slices.Contains(tc.engines, engine) {
+				t.Skipf("case is not supported by engine %s", engine)
+			}
 			root, err := os.MkdirTemp(".", "tmptest*")
 			if err != nil {
 				t.Fatalf("failed to create temporary directory: %v", err)
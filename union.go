@@ -0,0 +1,154 @@
+package yamly
+
+import (
+	"errors"
+	"strconv"
+)
+
+// ErrBufferingUnsupported indicates that Decoder does not implement BufferingDecoder
+// required to decode tagged union.
+var ErrBufferingUnsupported = errors.New("decoder does not support buffering")
+
+// BufferingDecoder is implemented by decoders which can read the same subtree several times,
+// e.g. to find the discriminator of tagged union before decoding the concrete type.
+type BufferingDecoder interface {
+	Decoder
+
+	// Buffer consumes current subtree and returns a function creating independent Decoders reading it.
+	// If the subtree can't be buffered, nil is returned and the error is stored in Decoder.
+	Buffer() func() Decoder
+}
+
+// UnknownVariantError is used by generated code of tagged unions to indicate that
+// the concrete type of the union value can't be determined.
+type UnknownVariantError struct {
+	// Key is the discriminator key of the union.
+	Key string
+	// Value is the value of discriminator key found in YAML document. It is empty if the key is absent.
+	Value string
+	// Type is the name of Go type which is not a variant of the union. It is set only during encoding.
+	Type string
+}
+
+func (uve *UnknownVariantError) Error() string {
+	switch {
+	case uve.Type != "":
+		return "type " + uve.Type + " is not a variant of union with discriminator key " + strconv.Quote(uve.Key)
+	case uve.Value == "":
+		return "missing discriminator key " + strconv.Quote(uve.Key)
+	default:
+		return "unknown value " + strconv.Quote(uve.Value) + " of discriminator key " + strconv.Quote(uve.Key)
+	}
+}
+
+func (uve *UnknownVariantError) Is(err error) bool {
+	_, ok := err.(*UnknownVariantError)
+	return ok
+}
+
+// DecodeUnion decodes the value of tagged union from mapping, whose discriminator key defines
+// the concrete type of the value. Keys of the mapping are matched with the discriminator key
+// after given normalization, like struct decoders match keys of fields.
+// The mapping is buffered, and decode is called with the value of the key
+// and Decoder reading the whole mapping again (including the discriminator key). Errors and warnings
+// of the Decoder given to decode are passed to in. If the key is absent, UnknownVariantError is stored in in.
+func DecodeUnion(in Decoder, key string, normalization KeyNormalization, decode func(variant string, in Decoder)) {
+	b, ok := in.(BufferingDecoder)
+	if !ok {
+		in.AddError(ErrBufferingUnsupported)
+		return
+	}
+	next := b.Buffer()
+	if next == nil {
+		return
+	}
+
+	match := NormalizeKey(key, normalization)
+	probe := next()
+	var (
+		variant string
+		found   bool
+	)
+	state := probe.Mapping()
+	for state.HasUnprocessedItems() {
+		if k := probe.String(); NormalizeKey(k, normalization) == match && !found {
+			variant, found = probe.String(), true
+		} else {
+			probe.Skip()
+		}
+	}
+	if err := probe.Error(); err != nil {
+		in.AddError(err)
+		return
+	}
+	if !found {
+		in.AddError(&UnknownVariantError{Key: key})
+		return
+	}
+
+	sub := next()
+	decode(variant, sub)
+	if err := sub.Error(); err != nil {
+		in.AddError(err)
+	}
+	if wsub, ok := sub.(WarningsDecoder); ok {
+		if win, ok := in.(WarningsDecoder); ok {
+			for _, w := range wsub.Warnings() {
+				win.AddWarning(w)
+			}
+		}
+	}
+}
+
+// EncodeUnion encodes the value of tagged union with given encode function,
+// inserting discriminator key with the variant name as the first entry of the encoded mapping.
+func EncodeUnion(out Inserter, key, variant string, encode func(out Inserter)) {
+	encode(&discriminatorInserter{Inserter: out, key: key, variant: variant})
+}
+
+// AsExtendedInserter returns out as ExtendedInserter[T] if it supports inserting nodes of type T.
+// Unlike type assertion, it also finds the support in inserters wrapped by EncodeUnion.
+func AsExtendedInserter[T any](out Inserter) (ExtendedInserter[T], bool) { // nolint: ireturn
+	switch out := out.(type) {
+	case ExtendedInserter[T]:
+		return out, true
+	case *discriminatorInserter:
+		if ext, ok := AsExtendedInserter[T](out.Inserter); ok {
+			return extendedDiscriminatorInserter[T]{discriminatorInserter: out, ext: ext}, true
+		}
+	}
+	return nil, false
+}
+
+// discriminatorInserter inserts discriminator entry into the first started mapping.
+type discriminatorInserter struct {
+	Inserter
+	key, variant string
+	inserted     bool
+}
+
+// SetStyle passes the style to wrapped inserter if it supports styles.
+func (d *discriminatorInserter) SetStyle(style Style) {
+	if styled, ok := d.Inserter.(StyledInserter); ok {
+		styled.SetStyle(style)
+	}
+}
+
+func (d *discriminatorInserter) StartMapping() {
+	d.Inserter.StartMapping()
+	if !d.inserted {
+		d.inserted = true
+		d.Inserter.InsertString(d.key)
+		d.Inserter.InsertString(d.variant)
+	}
+}
+
+// extendedDiscriminatorInserter passes inserted nodes to the inserter wrapped by discriminatorInserter.
+type extendedDiscriminatorInserter[T any] struct {
+	*discriminatorInserter
+	ext ExtendedInserter[T]
+}
+
+func (e extendedDiscriminatorInserter[T]) InsertNode(node T, err error) {
+	e.ext.InsertNode(node, err)
+}